@@ -6,6 +6,7 @@ import (
 	"log"
 	"os"
 	"os/signal"
+	"strings"
 	"syscall"
 
 	"github.com/iryzzh/y-gophkeeper/internal/store"
@@ -25,10 +26,13 @@ func run() error {
 	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGINT, syscall.SIGTERM)
 	defer cancel()
 
-	cfg, err := config.NewClientConfig()
+	cfg, err := config.NewClientConfig(profileArg(os.Args[1:]))
 	if err != nil {
 		return fmt.Errorf("config init failed: %v", err.Error())
 	}
+	for _, notice := range cfg.Notices {
+		fmt.Fprintln(os.Stderr, notice)
+	}
 
 	var st store.Store
 	switch cfg.DB.Type {
@@ -49,3 +53,22 @@ func run() error {
 
 	return nil
 }
+
+// profileArg returns the value of the global `--profile` flag. The
+// configuration is read before the flags are parsed by the cli.
+func profileArg(args []string) string {
+	for i := 0; i < len(args) && strings.HasPrefix(args[i], "-"); i++ {
+		switch arg := args[i]; {
+		case arg == "--profile" || arg == "-profile":
+			if i+1 < len(args) {
+				return args[i+1]
+			}
+		case strings.HasPrefix(arg, "--profile="):
+			return strings.TrimPrefix(arg, "--profile=")
+		case strings.HasPrefix(arg, "-profile="):
+			return strings.TrimPrefix(arg, "-profile=")
+		}
+	}
+
+	return ""
+}
@@ -0,0 +1,60 @@
+package main
+
+import (
+	"crypto/tls"
+	"fmt"
+	"io"
+
+	"github.com/iryzzh/y-gophkeeper/internal/config"
+	"github.com/iryzzh/y-gophkeeper/internal/logger"
+	"github.com/pkg/errors"
+	"github.com/urfave/cli/v2"
+)
+
+// errCheckFailed is returned when any of the checks fails.
+var errCheckFailed = errors.New("some checks failed")
+
+// check checks the configuration of the logger and the TLS certificate
+// and the reachability and the migrations of the store, printing the
+// result of every check.
+func check(cfg *config.ServerCfg) error {
+	failed := false
+	report := func(name string, err error) {
+		if err != nil {
+			failed = true
+			fmt.Printf("FAIL  %s: %v\n", name, err)
+			return
+		}
+		fmt.Printf("ok    %s\n", name)
+	}
+
+	_, err := logger.New(io.Discard, cfg.Log.Format, cfg.Log.Level)
+	report("log", err)
+
+	if cfg.Web.EnableHTTPS {
+		_, err = tls.LoadX509KeyPair(cfg.Web.TLSCertPath, cfg.Web.TLSKeyPath)
+		report("tls certificate", err)
+	}
+
+	st, err := openStore(cfg)
+	report("store", err)
+	if err == nil {
+		defer func() { _ = st.Close() }()
+
+		err = st.Ping()
+		report("store ping", err)
+		if err == nil {
+			status, err := st.Migrations()
+			if err == nil && !status.UpToDate() {
+				err = fmt.Errorf("the store is at version %d of %d, run 'migrate up'", status.Version, status.Latest)
+			}
+			report("migrations", err)
+		}
+	}
+
+	if failed {
+		return cli.Exit(errCheckFailed.Error(), 1)
+	}
+
+	return nil
+}
@@ -9,12 +9,10 @@ import (
 	"syscall"
 
 	"github.com/iryzzh/y-gophkeeper/internal/config"
-	"github.com/iryzzh/y-gophkeeper/internal/server"
-	"github.com/iryzzh/y-gophkeeper/internal/services/item"
-	"github.com/iryzzh/y-gophkeeper/internal/services/token"
 	"github.com/iryzzh/y-gophkeeper/internal/services/user"
 	"github.com/iryzzh/y-gophkeeper/internal/store"
 	"github.com/iryzzh/y-gophkeeper/internal/store/sqlite"
+	"github.com/urfave/cli/v2"
 )
 
 func main() {
@@ -29,31 +27,94 @@ func run() error {
 
 	cfg, err := config.NewServerConfig()
 	if err != nil {
-		panic(err)
+		return fmt.Errorf("config: %v", err.Error())
 	}
 
-	var st store.Store
+	app := &cli.App{
+		Name:    "gophkeeper-server",
+		Usage:   "the gophkeeper server",
+		Version: cfg.Version.Version,
+		Action: func(cCtx *cli.Context) error {
+			return serve(cCtx.Context, cfg)
+		},
+		Commands: []*cli.Command{
+			{
+				Name:  "serve",
+				Usage: "apply the migrations and serve the api (default)",
+				Action: func(cCtx *cli.Context) error {
+					return serve(cCtx.Context, cfg)
+				},
+			},
+			{
+				Name:        "migrate",
+				Usage:       "manage the migrations of the store",
+				Subcommands: migrateCommands(cfg),
+			},
+			{
+				Name:        "users",
+				Usage:       "manage the users",
+				Subcommands: usersCommands(cfg),
+			},
+			{
+				Name:  "check",
+				Usage: "check the configuration and the store",
+				Action: func(cCtx *cli.Context) error {
+					return check(cfg)
+				},
+			},
+		},
+	}
+
+	return app.RunContext(ctx, os.Args)
+}
+
+// adminStore is the store with the migrations managed by the server.
+type adminStore interface {
+	store.Store
+	MigrateUp() error
+	MigrateDown(steps int) error
+}
+
+// openStore opens the store of the configuration without applying the
+// migrations.
+func openStore(cfg *config.ServerCfg) (adminStore, error) {
 	switch cfg.DB.Type {
 	case "sqlite3":
-		st, err = sqlite.NewStore(cfg.DB.DSN, cfg.DB.MigrationsPath)
+		st, err := sqlite.Open(cfg.DB.DSN, cfg.DB.MigrationsPath)
 		if err != nil {
-			return fmt.Errorf("store init: %v", err.Error())
+			return nil, fmt.Errorf("store init: %v", err.Error())
 		}
+		return st, nil
 	default:
-		return fmt.Errorf("not implemented DB type: %v", cfg.DB.Type)
+		return nil, fmt.Errorf("not implemented DB type: %v", cfg.DB.Type)
 	}
+}
 
-	fmt.Println(cfg.Version.String())
+// openMigratedStore opens the store of the configuration and checks
+// that its migrations are applied.
+func openMigratedStore(cfg *config.ServerCfg) (adminStore, error) {
+	st, err := openStore(cfg)
+	if err != nil {
+		return nil, err
+	}
 
-	tokenSvc := token.NewService(
-		st,
-		cfg.Security.AtExpiresIn,
-		cfg.Security.RtExpiresIn,
-		[]byte(cfg.Security.AccessSecret),
-		[]byte(cfg.Security.RefreshSecret),
-	)
+	status, err := st.Migrations()
+	if err != nil {
+		_ = st.Close()
+		return nil, fmt.Errorf("migrations status: %v", err.Error())
+	}
+	if !status.UpToDate() {
+		_ = st.Close()
+		return nil, fmt.Errorf("the store is at version %d of %d, run 'migrate up' first", status.Version, status.Latest)
+	}
+
+	return st, nil
+}
 
-	userSvc := user.NewService(
+// newUserService creates the user service with the security parameters
+// of the configuration.
+func newUserService(cfg *config.ServerCfg, st store.Store) *user.Service {
+	return user.NewService(
 		st,
 		cfg.Security.HashMemory,
 		cfg.Security.HashIterations,
@@ -61,14 +122,4 @@ func run() error {
 		cfg.Security.SaltLength,
 		cfg.Security.KeyLength,
 	)
-
-	itemSvc := item.NewService(st)
-
-	srv := server.NewServer(&cfg.Web, tokenSvc, userSvc, itemSvc, true)
-
-	if err := srv.Run(ctx); err != nil {
-		return fmt.Errorf("server run: %v", err.Error())
-	}
-
-	return nil
 }
@@ -0,0 +1,91 @@
+package main
+
+import (
+	"fmt"
+
+	"github.com/iryzzh/y-gophkeeper/internal/config"
+	"github.com/urfave/cli/v2"
+)
+
+// migrateCommands returns the commands managing the migrations.
+func migrateCommands(cfg *config.ServerCfg) []*cli.Command {
+	return []*cli.Command{
+		{
+			Name:  "up",
+			Usage: "apply all the migrations",
+			Action: func(cCtx *cli.Context) error {
+				st, err := openStore(cfg)
+				if err != nil {
+					return err
+				}
+				defer func() { _ = st.Close() }()
+
+				if err = st.MigrateUp(); err != nil {
+					return fmt.Errorf("migrate up: %v", err.Error())
+				}
+
+				return printMigrations(st)
+			},
+		},
+		{
+			Name:  "down",
+			Usage: "roll back the applied migrations",
+			Flags: []cli.Flag{
+				&cli.IntFlag{
+					Name:  "steps",
+					Usage: "the number of the migrations to roll back",
+					Value: 1,
+				},
+			},
+			Action: func(cCtx *cli.Context) error {
+				if cCtx.Int("steps") < 1 {
+					return cli.Exit("the steps must be positive", 1)
+				}
+
+				st, err := openStore(cfg)
+				if err != nil {
+					return err
+				}
+				defer func() { _ = st.Close() }()
+
+				if err = st.MigrateDown(cCtx.Int("steps")); err != nil {
+					return fmt.Errorf("migrate down: %v", err.Error())
+				}
+
+				return printMigrations(st)
+			},
+		},
+		{
+			Name:  "status",
+			Usage: "show the version of the store",
+			Action: func(cCtx *cli.Context) error {
+				st, err := openStore(cfg)
+				if err != nil {
+					return err
+				}
+				defer func() { _ = st.Close() }()
+
+				return printMigrations(st)
+			},
+		},
+	}
+}
+
+// printMigrations prints the status of the migrations of the store.
+func printMigrations(st adminStore) error {
+	status, err := st.Migrations()
+	if err != nil {
+		return fmt.Errorf("migrations status: %v", err.Error())
+	}
+
+	state := "up to date"
+	switch {
+	case status.Dirty:
+		state = "dirty, fix the store and force the version"
+	case !status.UpToDate():
+		state = fmt.Sprintf("%d migration(s) pending", status.Latest-status.Version)
+	}
+	fmt.Printf("version %d of %d: %s\n", status.Version, status.Latest, state)
+
+	return nil
+}
@@ -0,0 +1,127 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+
+	"github.com/iryzzh/y-gophkeeper/internal/blob"
+	"github.com/iryzzh/y-gophkeeper/internal/config"
+	"github.com/iryzzh/y-gophkeeper/internal/logger"
+	"github.com/iryzzh/y-gophkeeper/internal/models"
+	"github.com/iryzzh/y-gophkeeper/internal/notify"
+	"github.com/iryzzh/y-gophkeeper/internal/server"
+	"github.com/iryzzh/y-gophkeeper/internal/server/metrics"
+	"github.com/iryzzh/y-gophkeeper/internal/services/audit"
+	"github.com/iryzzh/y-gophkeeper/internal/services/emergency"
+	"github.com/iryzzh/y-gophkeeper/internal/services/item"
+	"github.com/iryzzh/y-gophkeeper/internal/services/org"
+	"github.com/iryzzh/y-gophkeeper/internal/services/token"
+	"github.com/iryzzh/y-gophkeeper/internal/store"
+	"github.com/iryzzh/y-gophkeeper/internal/store/sqlite"
+	"github.com/sirupsen/logrus"
+)
+
+// serve applies the migrations and serves the api until the context
+// is done.
+func serve(ctx context.Context, cfg *config.ServerCfg) error {
+	l, err := logger.New(os.Stderr, cfg.Log.Format, cfg.Log.Level)
+	if err != nil {
+		return fmt.Errorf("logger init: %v", err.Error())
+	}
+
+	var st store.Store
+	switch cfg.DB.Type {
+	case "sqlite3":
+		s, err := sqlite.NewStore(cfg.DB.DSN, cfg.DB.MigrationsPath)
+		if err != nil {
+			return fmt.Errorf("store init: %v", err.Error())
+		}
+		st = s
+
+		blobs, err := newBlobStorage(&cfg.Blob, s)
+		if err != nil {
+			_ = st.Close()
+			return fmt.Errorf("blob storage init: %v", err.Error())
+		}
+		s.SetBlobStorage(blobs)
+	default:
+		return fmt.Errorf("not implemented DB type: %v", cfg.DB.Type)
+	}
+	defer func() {
+		_ = st.Close()
+	}()
+
+	l.WithFields(logrus.Fields{
+		"version":    cfg.Version.Version,
+		"build_date": cfg.Version.BuildDate,
+		"commit":     cfg.Version.Commit,
+	}).Info("gophkeeper server")
+
+	tokenSvc := token.NewService(
+		st,
+		cfg.Security.AtExpiresIn,
+		cfg.Security.RtExpiresIn,
+		[]byte(cfg.Security.AccessSecret),
+		[]byte(cfg.Security.RefreshSecret),
+	)
+
+	userSvc := newUserService(cfg, st)
+
+	itemSvc := item.NewService(st, &models.Quota{
+		MaxBytes:     cfg.Quota.MaxBytes,
+		MaxItems:     cfg.Quota.MaxItems,
+		MaxItemBytes: cfg.Quota.MaxItemBytes,
+	})
+
+	orgSvc := org.NewService(st)
+
+	emergencySvc := emergency.NewService(st, cfg.Emergency.Wait)
+
+	auditSvc := audit.NewService(st)
+
+	var notifier notify.Notifier
+	if cfg.Reminder.Output != "" {
+		w, err := notify.Open(cfg.Reminder.Output)
+		if err != nil {
+			return fmt.Errorf("notifier init: %v", err.Error())
+		}
+		defer func() {
+			_ = w.Close()
+		}()
+		notifier = w
+	}
+
+	srv := server.NewServer(&cfg.Web, &cfg.Trash, &cfg.Emergency, &cfg.Audit, &cfg.Reminder, tokenSvc, userSvc, itemSvc,
+		orgSvc, emergencySvc, auditSvc, notifier, metrics.New(st), l, st, cfg.Version, true)
+
+	if err := srv.Run(ctx); err != nil {
+		return fmt.Errorf("server run: %v", err.Error())
+	}
+
+	return nil
+}
+
+// newBlobStorage returns the blob storage of the item data, the
+// storage in the database of the store by default.
+func newBlobStorage(cfg *config.BlobConfig, s *sqlite.Store) (blob.Storage, error) {
+	switch cfg.Storage {
+	case "", "db":
+		return s.DBStorage(), nil
+	case "fs":
+		return blob.NewFS(cfg.Path)
+	case "s3":
+		return blob.NewS3(blob.S3Config{
+			Endpoint:     cfg.S3.Endpoint,
+			Region:       cfg.S3.Region,
+			Bucket:       cfg.S3.Bucket,
+			Prefix:       cfg.S3.Prefix,
+			AccessKey:    cfg.S3.AccessKey,
+			SecretKey:    cfg.S3.SecretKey,
+			SessionToken: cfg.S3.SessionToken,
+			PathStyle:    cfg.S3.PathStyle,
+		})
+	default:
+		return nil, fmt.Errorf("not implemented blob storage: %v", cfg.Storage)
+	}
+}
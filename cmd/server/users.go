@@ -0,0 +1,162 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/iryzzh/y-gophkeeper/internal/config"
+	"github.com/urfave/cli/v2"
+)
+
+// usersCommands returns the commands managing the users.
+func usersCommands(cfg *config.ServerCfg) []*cli.Command {
+	return []*cli.Command{
+		{
+			Name:  "list",
+			Usage: "list the users",
+			Action: func(cCtx *cli.Context) error {
+				st, err := openMigratedStore(cfg)
+				if err != nil {
+					return err
+				}
+				defer func() { _ = st.Close() }()
+
+				users, err := newUserService(cfg, st).List(cCtx.Context)
+				if err != nil {
+					return err
+				}
+				if len(users) == 0 {
+					fmt.Println("no users found")
+					return nil
+				}
+
+				w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0) //nolint:gomnd
+				_, _ = fmt.Fprintln(w, "LOGIN\tID\tROLE\tSTATUS")
+				for _, u := range users {
+					status := "active"
+					if u.DisabledAt != nil {
+						status = "disabled since " + u.DisabledAt.Local().Format(time.RFC822)
+					}
+					role := "user"
+					if u.Admin {
+						role = "admin"
+					}
+					_, _ = fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", u.Login, u.ID, role, status)
+				}
+
+				return w.Flush()
+			},
+		},
+		{
+			Name:      "disable",
+			Usage:     "disable the user, so that the user cannot log in",
+			ArgsUsage: "<login>",
+			Action: func(cCtx *cli.Context) error {
+				return setDisabled(cCtx, cfg, true)
+			},
+		},
+		{
+			Name:      "enable",
+			Usage:     "enable the disabled user",
+			ArgsUsage: "<login>",
+			Action: func(cCtx *cli.Context) error {
+				return setDisabled(cCtx, cfg, false)
+			},
+		},
+		{
+			Name:      "promote",
+			Usage:     "grant the admin role to the user",
+			ArgsUsage: "<login>",
+			Action: func(cCtx *cli.Context) error {
+				return setAdmin(cCtx, cfg, true)
+			},
+		},
+		{
+			Name:      "demote",
+			Usage:     "revoke the admin role from the user",
+			ArgsUsage: "<login>",
+			Action: func(cCtx *cli.Context) error {
+				return setAdmin(cCtx, cfg, false)
+			},
+		},
+		{
+			Name:      "reset-2fa",
+			Usage:     "disable the second factor of the user who lost it",
+			ArgsUsage: "<login>",
+			Action: func(cCtx *cli.Context) error {
+				return resetTwoFactor(cCtx, cfg)
+			},
+		},
+	}
+}
+
+// setDisabled disables or enables the user with the login given as the
+// argument.
+func setDisabled(cCtx *cli.Context, cfg *config.ServerCfg, disabled bool) error {
+	login := cCtx.Args().First()
+	if login == "" {
+		return cli.Exit(fmt.Sprintf("usage: users %s <login>", cCtx.Command.Name), 1)
+	}
+
+	st, err := openMigratedStore(cfg)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = st.Close() }()
+
+	if err = newUserService(cfg, st).SetDisabled(cCtx.Context, login, disabled); err != nil {
+		return fmt.Errorf("users %s: %v", cCtx.Command.Name, err.Error())
+	}
+
+	fmt.Printf("user '%s' is %sd\n", login, cCtx.Command.Name)
+
+	return nil
+}
+
+// setAdmin grants or revokes the admin role of the user with the login
+// given as the argument.
+func setAdmin(cCtx *cli.Context, cfg *config.ServerCfg, admin bool) error {
+	login := cCtx.Args().First()
+	if login == "" {
+		return cli.Exit(fmt.Sprintf("usage: users %s <login>", cCtx.Command.Name), 1)
+	}
+
+	st, err := openMigratedStore(cfg)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = st.Close() }()
+
+	if err = newUserService(cfg, st).SetAdmin(cCtx.Context, login, admin); err != nil {
+		return fmt.Errorf("users %s: %v", cCtx.Command.Name, err.Error())
+	}
+
+	fmt.Printf("user '%s' is %sd\n", login, cCtx.Command.Name)
+
+	return nil
+}
+
+// resetTwoFactor disables the second factor of the user with the login
+// given as the argument.
+func resetTwoFactor(cCtx *cli.Context, cfg *config.ServerCfg) error {
+	login := cCtx.Args().First()
+	if login == "" {
+		return cli.Exit(fmt.Sprintf("usage: users %s <login>", cCtx.Command.Name), 1)
+	}
+
+	st, err := openMigratedStore(cfg)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = st.Close() }()
+
+	if err = newUserService(cfg, st).ResetTwoFactor(cCtx.Context, login); err != nil {
+		return fmt.Errorf("users %s: %v", cCtx.Command.Name, err.Error())
+	}
+
+	fmt.Printf("two-factor authentication of user '%s' is reset\n", login)
+
+	return nil
+}
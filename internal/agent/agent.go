@@ -0,0 +1,299 @@
+// Package agent implements the agent holding the key of the unlocked
+// local vault in memory, so that the master password is not asked for
+// every command. The agent serves a Unix socket in a directory only
+// the user can access, and wipes the key and exits when it is locked
+// or after it has not been asked for the key for the idle timeout.
+// Both ends refuse a socket directory owned by another user or
+// accessible to others, and, where the platform tells, a peer running
+// as another user.
+package agent
+
+import (
+	"bufio"
+	"context"
+	"net"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/iryzzh/y-gophkeeper/internal/keys"
+	jsoniter "github.com/json-iterator/go"
+	"github.com/pkg/errors"
+)
+
+const (
+	opGet  = "get"
+	opSet  = "set"
+	opLock = "lock"
+)
+
+// dialTimeout limits the time of a request to the agent.
+const dialTimeout = 5 * time.Second
+
+// dirMode is the only mode of the socket directory accepted.
+const dirMode os.FileMode = 0o700
+
+var (
+	// ErrLocked is returned when the agent is not running or has no key.
+	ErrLocked = errors.New("the vault is locked")
+	// ErrRunning is returned when another agent listens on the socket.
+	ErrRunning = errors.New("the agent is already running")
+	// ErrInsecure is returned when the socket directory or the peer of
+	// the connection is not the user's own.
+	ErrInsecure = errors.New("the agent socket is not secure")
+	// errUnknownOp is returned by the agent for an unknown request.
+	errUnknownOp = errors.New("unknown operation")
+)
+
+type request struct {
+	Op      string        `json:"op"`
+	Key     []byte        `json:"key,omitempty"`
+	Timeout time.Duration `json:"timeout,omitempty"`
+}
+
+type response struct {
+	Key   []byte `json:"key,omitempty"`
+	Error string `json:"error,omitempty"`
+}
+
+// Listen listens on the Unix socket at the path. The directory of the
+// socket is created accessible to the user only, an existing one must
+// be owned by the user and accessible to the user only, and the stale
+// socket of an agent that has exited is removed.
+func Listen(path string) (net.Listener, error) {
+	dir := filepath.Dir(path)
+	if err := os.MkdirAll(dir, dirMode); err != nil {
+		return nil, err
+	}
+	if err := checkDir(dir); err != nil {
+		return nil, err
+	}
+	if conn, err := net.DialTimeout("unix", path, dialTimeout); err == nil {
+		_ = conn.Close()
+		return nil, ErrRunning
+	}
+	_ = os.Remove(path)
+
+	l, err := net.Listen("unix", path)
+	if err != nil {
+		return nil, err
+	}
+
+	return l, os.Chmod(path, 0o600)
+}
+
+// agent is the state of the running agent.
+type agent struct {
+	mu      sync.Mutex
+	key     *[keys.KeySize]byte
+	timeout time.Duration
+	idle    *time.Timer
+	done    chan struct{}
+	once    sync.Once
+}
+
+// Serve serves the agent on the listener until it is locked, it is
+// idle for the timeout, or the context is done. The key is wiped
+// before it returns.
+func Serve(ctx context.Context, l net.Listener, timeout time.Duration) error {
+	a := &agent{timeout: timeout, done: make(chan struct{})}
+	a.idle = time.AfterFunc(timeout, a.stop)
+	defer a.stop()
+
+	go func() {
+		select {
+		case <-ctx.Done():
+		case <-a.done:
+		}
+		_ = l.Close()
+	}()
+
+	for {
+		conn, err := l.Accept()
+		if err != nil {
+			select {
+			case <-a.done:
+				return nil
+			case <-ctx.Done():
+				return nil
+			default:
+				return err
+			}
+		}
+
+		go a.handle(conn)
+	}
+}
+
+// handle serves a request of the connection.
+func (a *agent) handle(conn net.Conn) {
+	defer func() { _ = conn.Close() }()
+	if err := checkPeer(conn); err != nil {
+		return
+	}
+	_ = conn.SetDeadline(time.Now().Add(dialTimeout))
+
+	json := jsoniter.ConfigCompatibleWithStandardLibrary
+
+	var req request
+	var resp response
+	if err := json.NewDecoder(bufio.NewReader(conn)).Decode(&req); err != nil {
+		resp.Error = err.Error()
+	} else if err = a.serve(&req, &resp); err != nil {
+		resp.Error = err.Error()
+	}
+
+	_ = json.NewEncoder(conn).Encode(&resp)
+
+	if req.Op == opLock {
+		a.stop()
+	}
+}
+
+// serve processes the request.
+func (a *agent) serve(req *request, resp *response) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	switch req.Op {
+	case opGet:
+		if a.key == nil {
+			return ErrLocked
+		}
+		a.idle.Reset(a.timeout)
+		resp.Key = append([]byte(nil), a.key[:]...)
+	case opSet:
+		key := new([keys.KeySize]byte)
+		if len(req.Key) != keys.KeySize {
+			return keys.ErrInvalidKey
+		}
+		copy(key[:], req.Key)
+		a.wipe()
+		a.key = key
+		if req.Timeout > 0 {
+			a.timeout = req.Timeout
+		}
+		a.idle.Reset(a.timeout)
+	case opLock:
+		a.wipe()
+	default:
+		return errUnknownOp
+	}
+
+	return nil
+}
+
+// stop wipes the key and stops the agent.
+func (a *agent) stop() {
+	a.once.Do(func() {
+		a.mu.Lock()
+		a.wipe()
+		a.idle.Stop()
+		a.mu.Unlock()
+		close(a.done)
+	})
+}
+
+// wipe overwrites the key in memory. The caller must hold the lock.
+func (a *agent) wipe() {
+	if a.key == nil {
+		return
+	}
+	for i := range a.key {
+		a.key[i] = 0
+	}
+	a.key = nil
+}
+
+// Get returns the key held by the agent listening at the path, or
+// `ErrLocked` if there is none.
+func Get(path string) (*[keys.KeySize]byte, error) {
+	resp, err := call(path, &request{Op: opGet})
+	if err != nil {
+		return nil, err
+	}
+	if len(resp.Key) != keys.KeySize {
+		return nil, ErrLocked
+	}
+
+	key := new([keys.KeySize]byte)
+	copy(key[:], resp.Key)
+
+	return key, nil
+}
+
+// Set hands the key over to the agent listening at the path. The agent
+// keeps it until it is idle for the timeout, or its current timeout if
+// zero.
+func Set(path string, key *[keys.KeySize]byte, timeout time.Duration) error {
+	_, err := call(path, &request{Op: opSet, Key: key[:], Timeout: timeout})
+
+	return err
+}
+
+// Lock wipes the key of the agent listening at the path and stops the
+// agent. It returns `ErrLocked` if the agent is not running.
+func Lock(path string) error {
+	_, err := call(path, &request{Op: opLock})
+
+	return err
+}
+
+// call sends the request to the agent listening at the path. The
+// agent which is not running is reported as `ErrLocked`. Nothing is
+// sent unless the socket directory and the agent are the user's own.
+func call(path string, req *request) (*response, error) {
+	err := checkDir(filepath.Dir(path))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, ErrLocked
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	conn, err := net.DialTimeout("unix", path, dialTimeout)
+	if err != nil {
+		return nil, ErrLocked
+	}
+	defer func() { _ = conn.Close() }()
+	if err = checkPeer(conn); err != nil {
+		return nil, err
+	}
+	_ = conn.SetDeadline(time.Now().Add(dialTimeout))
+
+	json := jsoniter.ConfigCompatibleWithStandardLibrary
+	if err = json.NewEncoder(conn).Encode(req); err != nil {
+		return nil, err
+	}
+
+	var resp response
+	if err = json.NewDecoder(conn).Decode(&resp); err != nil {
+		return nil, err
+	}
+	switch resp.Error {
+	case "":
+		return &resp, nil
+	case ErrLocked.Error():
+		return nil, ErrLocked
+	}
+
+	return nil, errors.New(resp.Error)
+}
+
+// checkDir checks that the socket directory is a directory, not a
+// symbolic link, owned by the user and accessible to the user only.
+func checkDir(dir string) error {
+	fi, err := os.Lstat(dir)
+	if err != nil {
+		return err
+	}
+	if !fi.IsDir() {
+		return errors.Wrapf(ErrInsecure, "'%v' is not a directory", dir)
+	}
+	if fi.Mode().Perm() != dirMode {
+		return errors.Wrapf(ErrInsecure, "'%v' has mode %v, want %v", dir, fi.Mode().Perm(), dirMode)
+	}
+
+	return checkOwner(dir, fi)
+}
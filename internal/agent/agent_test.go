@@ -0,0 +1,102 @@
+package agent
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/iryzzh/y-gophkeeper/internal/keys"
+	"github.com/stretchr/testify/require"
+)
+
+func serve(t *testing.T, timeout time.Duration) (string, <-chan error) {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "agent", "agent.sock")
+	l, err := Listen(path)
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	done := make(chan error, 1)
+	go func() { done <- Serve(ctx, l, timeout) }()
+
+	return path, done
+}
+
+func TestAgent(t *testing.T) {
+	path, done := serve(t, time.Minute)
+
+	_, err := Get(path)
+	require.ErrorIs(t, err, ErrLocked)
+
+	_, err = Listen(path)
+	require.ErrorIs(t, err, ErrRunning)
+
+	key, err := keys.NewItemKey()
+	require.NoError(t, err)
+	require.NoError(t, Set(path, key, 0))
+
+	got, err := Get(path)
+	require.NoError(t, err)
+	require.Equal(t, key, got)
+
+	require.NoError(t, Lock(path))
+	select {
+	case err = <-done:
+		require.NoError(t, err)
+	case <-time.After(time.Second):
+		t.Fatal("the agent is still running after Lock()")
+	}
+
+	_, err = Get(path)
+	require.ErrorIs(t, err, ErrLocked)
+	require.ErrorIs(t, Lock(path), ErrLocked)
+}
+
+func TestAgent_idle(t *testing.T) {
+	path, done := serve(t, 200*time.Millisecond)
+
+	key, err := keys.NewItemKey()
+	require.NoError(t, err)
+	require.NoError(t, Set(path, key, 0))
+
+	select {
+	case err = <-done:
+		require.NoError(t, err)
+	case <-time.After(2 * time.Second):
+		t.Fatal("the agent is still running after the idle timeout")
+	}
+
+	_, err = Get(path)
+	require.ErrorIs(t, err, ErrLocked)
+
+	// The socket of the agent which has exited can be reused.
+	l, err := Listen(path)
+	require.NoError(t, err)
+	require.NoError(t, l.Close())
+}
+
+func TestAgent_insecureDir(t *testing.T) {
+	path, _ := serve(t, time.Minute)
+	dir := filepath.Dir(path)
+	key, err := keys.NewItemKey()
+	require.NoError(t, err)
+
+	require.NoError(t, os.Chmod(dir, 0o755))
+	_, err = Listen(path)
+	require.ErrorIs(t, err, ErrInsecure)
+	require.ErrorIs(t, Set(path, key, 0), ErrInsecure)
+	_, err = Get(path)
+	require.ErrorIs(t, err, ErrInsecure)
+
+	link := filepath.Join(t.TempDir(), "link")
+	require.NoError(t, os.Chmod(dir, 0o700))
+	require.NoError(t, os.Symlink(dir, link))
+	require.ErrorIs(t, Set(filepath.Join(link, filepath.Base(path)), key, 0), ErrInsecure)
+
+	require.NoError(t, Set(path, key, 0))
+}
@@ -0,0 +1,23 @@
+//go:build !windows
+
+package agent
+
+import (
+	"os"
+	"syscall"
+
+	"github.com/pkg/errors"
+)
+
+// checkOwner checks that the file is owned by the user.
+func checkOwner(path string, fi os.FileInfo) error {
+	st, ok := fi.Sys().(*syscall.Stat_t)
+	if !ok {
+		return errors.Wrapf(ErrInsecure, "'%v': unknown owner", path)
+	}
+	if int(st.Uid) != os.Getuid() {
+		return errors.Wrapf(ErrInsecure, "'%v' is owned by uid %d", path, st.Uid)
+	}
+
+	return nil
+}
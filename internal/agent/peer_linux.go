@@ -0,0 +1,40 @@
+//go:build linux
+
+package agent
+
+import (
+	"net"
+	"os"
+	"syscall"
+
+	"github.com/pkg/errors"
+)
+
+// checkPeer checks that the process at the other end of the connection
+// runs as the user, with the credentials of the peer of the socket.
+func checkPeer(conn net.Conn) error {
+	uc, ok := conn.(*net.UnixConn)
+	if !ok {
+		return errors.Wrap(ErrInsecure, "not a unix socket")
+	}
+	raw, err := uc.SyscallConn()
+	if err != nil {
+		return err
+	}
+
+	var cred *syscall.Ucred
+	var credErr error
+	if err = raw.Control(func(fd uintptr) {
+		cred, credErr = syscall.GetsockoptUcred(int(fd), syscall.SOL_SOCKET, syscall.SO_PEERCRED)
+	}); err != nil {
+		return err
+	}
+	if credErr != nil {
+		return errors.Wrap(credErr, "peer credentials")
+	}
+	if int(cred.Uid) != os.Getuid() {
+		return errors.Wrapf(ErrInsecure, "the peer runs as uid %d", cred.Uid)
+	}
+
+	return nil
+}
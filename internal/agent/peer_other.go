@@ -0,0 +1,11 @@
+//go:build !linux
+
+package agent
+
+import "net"
+
+// checkPeer does nothing where the credentials of the peer are not
+// available: the socket directory is the only check.
+func checkPeer(net.Conn) error {
+	return nil
+}
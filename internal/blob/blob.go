@@ -0,0 +1,69 @@
+// Package blob implements the storages of the item data outside of the
+// database. The blobs are content-addressed: the key of a blob is the
+// hash of its data, so that identical data is stored once. The item
+// repository counts the references to the blobs and deletes the
+// unreferenced ones from the storage.
+package blob
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"hash"
+	"io"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+var (
+	// ErrNotFound is returned when the blob is not found.
+	ErrNotFound = errors.New("blob not found")
+	// ErrInvalidKey is returned when the key is not a hash of `Key`.
+	ErrInvalidKey = errors.New("invalid blob key")
+)
+
+// Storage stores the blobs under their keys.
+type Storage interface {
+	// Put saves the content read from the reader under its key, unless
+	// it is already saved, and returns the key and the size of the
+	// content. The content is never held in memory as a whole.
+	Put(ctx context.Context, r io.Reader) (key string, size int64, err error)
+	// Get returns the content saved under the key, which must be closed
+	// by the caller, or `ErrNotFound`.
+	Get(ctx context.Context, key string) (io.ReadCloser, error)
+	// Delete deletes the data saved under the key, if any.
+	Delete(ctx context.Context, key string) error
+}
+
+// Key returns the key of the data: the hex-encoded SHA-256 hash.
+func Key(data []byte) string {
+	sum := sha256.Sum256(data)
+
+	return hex.EncodeToString(sum[:])
+}
+
+// Hasher returns the hash of the content written to it, so that the key
+// of the content copied through `io.TeeReader` is returned by `HashKey`.
+func Hasher() hash.Hash {
+	return sha256.New()
+}
+
+// HashKey returns the key of the content written to the hash of
+// `Hasher`.
+func HashKey(h hash.Hash) string {
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// validKey returns `ErrInvalidKey` unless the key is a key of `Key`, so
+// that it can safely be used as a file name or an object name.
+func validKey(key string) error {
+	if len(key) != sha256.Size*2 {
+		return errors.Wrapf(ErrInvalidKey, "'%v'", key)
+	}
+	if _, err := hex.DecodeString(key); err != nil || key != strings.ToLower(key) {
+		return errors.Wrapf(ErrInvalidKey, "'%v'", key)
+	}
+
+	return nil
+}
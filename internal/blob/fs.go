@@ -0,0 +1,113 @@
+package blob
+
+import (
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/pkg/errors"
+)
+
+const dirPermission = 0o700
+
+// FS is the storage of the blobs in a directory. Every blob is a file
+// named after its key in the subdirectory named after the first two
+// characters of the key, so that the directories stay small.
+type FS struct {
+	dir string
+}
+
+// NewFS returns the storage of the blobs in the directory, creating it
+// if needed.
+func NewFS(dir string) (*FS, error) {
+	if err := os.MkdirAll(dir, dirPermission); err != nil {
+		return nil, errors.Wrap(err, "blob storage init")
+	}
+
+	return &FS{dir: dir}, nil
+}
+
+// Put copies the content to a temporary file while hashing it, then
+// renames the file to the file of the key, unless it exists, so that
+// the file of the key is never partially written. The temporary file
+// is only readable by the owner.
+func (s *FS) Put(_ context.Context, r io.Reader) (string, int64, error) {
+	f, err := os.CreateTemp(s.dir, "put.*.tmp")
+	if err != nil {
+		return "", 0, err
+	}
+	defer func() { _ = os.Remove(f.Name()) }()
+
+	h := Hasher()
+	size, err := io.Copy(f, io.TeeReader(r, h))
+	if err == nil {
+		err = f.Sync()
+	}
+	if err != nil {
+		_ = f.Close()
+		return "", 0, err
+	}
+	if err = f.Close(); err != nil {
+		return "", 0, err
+	}
+
+	key := HashKey(h)
+	path, err := s.path(key)
+	if err != nil {
+		return "", 0, err
+	}
+	if _, err = os.Stat(path); err == nil {
+		return key, size, nil
+	}
+	if err = os.MkdirAll(filepath.Dir(path), dirPermission); err != nil {
+		return "", 0, err
+	}
+
+	if err = os.Rename(f.Name(), path); err != nil {
+		return "", 0, err
+	}
+
+	return key, size, nil
+}
+
+// Get opens the file of the key.
+func (s *FS) Get(_ context.Context, key string) (io.ReadCloser, error) {
+	path, err := s.path(key)
+	if err != nil {
+		return nil, err
+	}
+
+	f, err := os.Open(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, errors.Wrapf(ErrNotFound, "'%v'", key)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return f, nil
+}
+
+// Delete removes the file of the key, if any.
+func (s *FS) Delete(_ context.Context, key string) error {
+	path, err := s.path(key)
+	if err != nil {
+		return err
+	}
+
+	if err = os.Remove(path); err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+
+	return nil
+}
+
+// path returns the path of the file of the key.
+func (s *FS) path(key string) (string, error) {
+	if err := validKey(key); err != nil {
+		return "", err
+	}
+
+	return filepath.Join(s.dir, key[:2], key), nil
+}
@@ -0,0 +1,87 @@
+package blob
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+	"testing/iotest"
+
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+)
+
+// testStorage checks the behavior common to the storages.
+func testStorage(t *testing.T, s Storage) {
+	t.Helper()
+	ctx := context.Background()
+
+	data := []byte("sealed data")
+	key := Key(data)
+	_, err := s.Get(ctx, key)
+	require.ErrorIs(t, err, ErrNotFound)
+
+	for i := 0; i < 2; i++ {
+		got, size, err := s.Put(ctx, bytes.NewReader(data))
+		require.NoError(t, err)
+		require.Equal(t, key, got)
+		require.Equal(t, int64(len(data)), size)
+	}
+	require.Equal(t, data, readBlob(t, s, key))
+
+	empty, size, err := s.Put(ctx, bytes.NewReader(nil))
+	require.NoError(t, err)
+	require.Equal(t, Key(nil), empty)
+	require.Zero(t, size)
+	require.Empty(t, readBlob(t, s, empty))
+
+	require.NoError(t, s.Delete(ctx, key))
+	require.NoError(t, s.Delete(ctx, key))
+	_, err = s.Get(ctx, key)
+	require.ErrorIs(t, err, ErrNotFound)
+
+	for _, invalid := range []string{"", "../../etc/passwd", key[:10], "G" + key[1:]} {
+		_, err = s.Get(ctx, invalid)
+		require.ErrorIs(t, err, ErrInvalidKey, invalid)
+		require.ErrorIs(t, s.Delete(ctx, invalid), ErrInvalidKey, invalid)
+	}
+}
+
+// readBlob returns the content saved under the key.
+func readBlob(t *testing.T, s Storage, key string) []byte {
+	t.Helper()
+
+	rc, err := s.Get(context.Background(), key)
+	require.NoError(t, err)
+	defer func() { _ = rc.Close() }()
+	data, err := io.ReadAll(rc)
+	require.NoError(t, err)
+
+	return data
+}
+
+func TestFS(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "blobs")
+	s, err := NewFS(dir)
+	require.NoError(t, err)
+
+	testStorage(t, s)
+
+	data := []byte("content")
+	key, _, err := s.Put(context.Background(), bytes.NewReader(data))
+	require.NoError(t, err)
+	files, err := os.ReadDir(filepath.Join(dir, key[:2]))
+	require.NoError(t, err)
+	require.Len(t, files, 1)
+	require.Equal(t, key, files[0].Name())
+
+	_, _, err = s.Put(context.Background(), iotest.ErrReader(errors.New("read failed")))
+	require.Error(t, err)
+	files, err = os.ReadDir(dir)
+	require.NoError(t, err)
+	for _, f := range files {
+		require.True(t, f.IsDir(), f.Name())
+	}
+}
@@ -0,0 +1,320 @@
+package blob
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	// DefaultRegion is the region of the bucket if none is configured.
+	DefaultRegion = "us-east-1"
+
+	s3Timeout     = time.Minute
+	amzDateLayout = "20060102T150405Z"
+	amzAlgorithm  = "AWS4-HMAC-SHA256"
+	// emptyPayload is the hash of the empty payload of the requests
+	// without body.
+	emptyPayload = "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855"
+)
+
+// S3Config contains the configuration of the S3 storage.
+type S3Config struct {
+	// Endpoint is the URL of the S3-compatible service, such as
+	// 'https://s3.eu-west-1.amazonaws.com' or 'http://localhost:9000'.
+	Endpoint string
+	// Region is the region of the bucket, `DefaultRegion` if empty.
+	Region string
+	// Bucket is the name of the bucket.
+	Bucket string
+	// Prefix is prepended to the keys of the blobs to get the names
+	// of the objects, e.g. 'blobs/'.
+	Prefix    string
+	AccessKey string
+	SecretKey string
+	// SessionToken is the token of the temporary credentials, if any.
+	SessionToken string
+	// PathStyle addresses the bucket in the path of the URL instead of
+	// the host name, as most self-hosted services require.
+	PathStyle bool
+}
+
+// S3 is the storage of the blobs as the objects of a bucket of an
+// S3-compatible service. The requests are signed with the AWS
+// Signature Version 4.
+type S3 struct {
+	cfg      S3Config
+	endpoint *url.URL
+	client   *http.Client
+	now      func() time.Time
+}
+
+// NewS3 returns the storage of the blobs in the bucket.
+func NewS3(cfg S3Config) (*S3, error) {
+	endpoint, err := url.Parse(cfg.Endpoint)
+	if err != nil {
+		return nil, errors.Wrap(err, "s3 endpoint")
+	}
+	if endpoint.Scheme != "http" && endpoint.Scheme != "https" || endpoint.Host == "" {
+		return nil, errors.Errorf("s3 endpoint: invalid url '%v'", cfg.Endpoint)
+	}
+	if cfg.Bucket == "" {
+		return nil, errors.New("s3 bucket is required")
+	}
+	if cfg.Region == "" {
+		cfg.Region = DefaultRegion
+	}
+
+	return &S3{
+		cfg:      cfg,
+		endpoint: endpoint,
+		client:   &http.Client{Timeout: s3Timeout},
+		now:      time.Now,
+	}, nil
+}
+
+// Put uploads the content as the object of its key, unless it exists.
+// The content is copied to a temporary file while hashing it first:
+// the key is the hash of the payload to sign, which must be known
+// before the upload.
+func (s *S3) Put(ctx context.Context, r io.Reader) (string, int64, error) {
+	f, err := os.CreateTemp("", "blob.*.tmp")
+	if err != nil {
+		return "", 0, err
+	}
+	defer func() {
+		_ = f.Close()
+		_ = os.Remove(f.Name())
+	}()
+
+	h := Hasher()
+	size, err := io.Copy(f, io.TeeReader(r, h))
+	if err != nil {
+		return "", 0, err
+	}
+	key := HashKey(h)
+
+	resp, err := s.do(ctx, http.MethodHead, key, nil, 0, emptyPayload)
+	if err != nil {
+		return "", 0, err
+	}
+	_ = resp.Body.Close()
+	if resp.StatusCode == http.StatusOK {
+		return key, size, nil
+	}
+	if resp.StatusCode != http.StatusNotFound {
+		return "", 0, s3Error(http.MethodHead, key, resp)
+	}
+
+	if _, err = f.Seek(0, io.SeekStart); err != nil {
+		return "", 0, err
+	}
+	if resp, err = s.do(ctx, http.MethodPut, key, f, size, key); err != nil {
+		return "", 0, err
+	}
+	defer func() { _ = resp.Body.Close() }()
+	if resp.StatusCode != http.StatusOK {
+		return "", 0, s3Error(http.MethodPut, key, resp)
+	}
+
+	return key, size, nil
+}
+
+// Get downloads the object of the key. The content is read from the
+// response as it is received.
+func (s *S3) Get(ctx context.Context, key string) (io.ReadCloser, error) {
+	resp, err := s.do(ctx, http.MethodGet, key, nil, 0, emptyPayload)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode == http.StatusOK {
+		return resp.Body, nil
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	err = s3Error(http.MethodGet, key, resp)
+	if errors.Is(err, errNoSuchKey) {
+		return nil, errors.Wrapf(ErrNotFound, "'%v'", key)
+	}
+
+	return nil, err
+}
+
+// Delete deletes the object of the key, if any.
+func (s *S3) Delete(ctx context.Context, key string) error {
+	resp, err := s.do(ctx, http.MethodDelete, key, nil, 0, emptyPayload)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	switch resp.StatusCode {
+	case http.StatusOK, http.StatusNoContent, http.StatusNotFound:
+		return nil
+	}
+
+	return s3Error(http.MethodDelete, key, resp)
+}
+
+// do sends the signed request for the object of the key with the body
+// of the size and the hex-encoded SHA-256 hash of the payload.
+func (s *S3) do(ctx context.Context, method, key string, body io.Reader, size int64, payload string,
+) (*http.Response, error) {
+	if err := validKey(key); err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, s.objectURL(s.cfg.Prefix+key), body)
+	if err != nil {
+		return nil, err
+	}
+	if body != nil {
+		req.ContentLength = size
+		if size == 0 {
+			req.Body = http.NoBody
+		}
+		req.Header.Set("Content-Type", "application/octet-stream")
+	}
+	if s.cfg.SessionToken != "" {
+		req.Header.Set("X-Amz-Security-Token", s.cfg.SessionToken)
+	}
+	sign(req, payload, s.cfg.AccessKey, s.cfg.SecretKey, s.cfg.Region, s.now())
+
+	return s.client.Do(req)
+}
+
+// objectURL returns the URL of the object with the name.
+func (s *S3) objectURL(name string) string {
+	u := *s.endpoint
+	path := strings.TrimSuffix(u.Path, "/")
+	if s.cfg.PathStyle {
+		path += "/" + s.cfg.Bucket
+	} else {
+		u.Host = s.cfg.Bucket + "." + u.Host
+	}
+	u.Path = path + "/" + name
+	u.RawPath = uriEncode(u.Path, false)
+
+	return u.String()
+}
+
+// sign signs the request with the AWS Signature Version 4, setting the
+// `X-Amz-Date`, `X-Amz-Content-Sha256` and `Authorization` headers.
+// The payload is the hex-encoded SHA-256 hash of the body. All the
+// other headers of the request are signed. The request must have no
+// query.
+func sign(req *http.Request, payload, accessKey, secretKey, region string, now time.Time) {
+	const service = "s3"
+
+	amzDate := now.UTC().Format(amzDateLayout)
+	date := amzDate[:8]
+	req.Header.Set("X-Amz-Date", amzDate)
+	req.Header.Set("X-Amz-Content-Sha256", payload)
+
+	headers := map[string]string{"host": req.URL.Host}
+	for name, values := range req.Header {
+		if name == "Authorization" {
+			continue
+		}
+		headers[strings.ToLower(name)] = strings.TrimSpace(strings.Join(values, ","))
+	}
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var canonicalHeaders strings.Builder
+	for _, name := range names {
+		canonicalHeaders.WriteString(name + ":" + headers[name] + "\n")
+	}
+	signedHeaders := strings.Join(names, ";")
+
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		uriEncode(req.URL.EscapedPath(), true),
+		"", // the requests have no query.
+		canonicalHeaders.String(),
+		signedHeaders,
+		payload,
+	}, "\n")
+
+	scope := strings.Join([]string{date, region, service, "aws4_request"}, "/")
+	hashed := sha256.Sum256([]byte(canonicalRequest))
+	stringToSign := strings.Join([]string{amzAlgorithm, amzDate, scope, hex.EncodeToString(hashed[:])}, "\n")
+
+	key := []byte("AWS4" + secretKey)
+	for _, part := range []string{date, region, service, "aws4_request"} {
+		key = hmacSHA256(key, part)
+	}
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+
+	req.Header.Set("Authorization", fmt.Sprintf("%s Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		amzAlgorithm, accessKey, scope, signedHeaders, signature))
+}
+
+// hmacSHA256 returns the HMAC-SHA256 of the data with the key.
+func hmacSHA256(key []byte, data string) []byte {
+	h := hmac.New(sha256.New, key)
+	_, _ = h.Write([]byte(data))
+
+	return h.Sum(nil)
+}
+
+// uriEncode percent-encodes every byte of the string except the
+// unreserved characters and the slashes. If decode is set, the string
+// is unescaped first, so that the escaped paths are not encoded twice.
+func uriEncode(s string, decode bool) string {
+	if decode {
+		if unescaped, err := url.PathUnescape(s); err == nil {
+			s = unescaped
+		}
+	}
+
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if 'A' <= c && c <= 'Z' || 'a' <= c && c <= 'z' || '0' <= c && c <= '9' ||
+			c == '-' || c == '_' || c == '.' || c == '~' || c == '/' {
+			b.WriteByte(c)
+			continue
+		}
+		fmt.Fprintf(&b, "%%%02X", c)
+	}
+
+	return b.String()
+}
+
+// errNoSuchKey is the cause of the errors of the responses of the
+// service telling that the object does not exist.
+var errNoSuchKey = errors.New("NoSuchKey")
+
+// s3Error returns the error of the response, with the code and the
+// message of the error document of the service if any.
+func s3Error(method, key string, resp *http.Response) error {
+	var doc struct {
+		Code    string `xml:"Code"`
+		Message string `xml:"Message"`
+	}
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 64*1024)) //nolint:gomnd
+	if err := xml.Unmarshal(body, &doc); err != nil || doc.Code == "" {
+		return errors.Errorf("s3: %s '%s': %s", method, key, resp.Status)
+	}
+	if doc.Code == errNoSuchKey.Error() {
+		return errors.Wrapf(errNoSuchKey, "s3: %s '%s': %s", method, key, resp.Status)
+	}
+
+	return errors.Errorf("s3: %s '%s': %s: %s: %s", method, key, resp.Status, doc.Code, doc.Message)
+}
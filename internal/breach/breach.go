@@ -0,0 +1,198 @@
+// Package breach implements the offline check of the passwords against
+// a local list of the SHA-1 hashes of the breached passwords, in the
+// format of Have I Been Pwned. The list is either a single file of
+// `HASH:COUNT` lines sorted by hash, or a directory of the files named
+// after the first 5 characters of the hashes, `ABCDE.txt`, of
+// `SUFFIX:COUNT` lines sorted by suffix. The files are searched in
+// place with a binary search over their bytes, so they are never
+// loaded in memory.
+package breach
+
+import (
+	"bytes"
+	"crypto/sha1" //nolint:gosec // the hashes of the list are SHA-1
+	"encoding/hex"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	hashLength   = 40
+	prefixLength = 5
+	// chunkSize is the size of the reads, longer than a line.
+	chunkSize = 128
+)
+
+// ErrInvalidLine is returned when a line of the list is malformed.
+var ErrInvalidLine = errors.New("invalid line of the breached passwords list")
+
+// List is the local list of the breached passwords.
+type List struct {
+	path string
+	dir  bool
+}
+
+// Open opens the list at the path: a file of full hashes or a directory
+// of the files of the hashes by prefix.
+func Open(path string) (*List, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+
+	return &List{path: path, dir: info.IsDir()}, nil
+}
+
+// Count returns the number of times the password appears in the
+// breaches, zero if it is not in the list.
+func (l *List) Count(password string) (int, error) {
+	sum := sha1.Sum([]byte(password)) //nolint:gosec
+
+	return l.CountHash(strings.ToUpper(hex.EncodeToString(sum[:])))
+}
+
+// CountHash returns the number of times the password with the SHA-1
+// hash, hex encoded, appears in the breaches.
+func (l *List) CountHash(hash string) (int, error) {
+	hash = strings.ToUpper(hash)
+	if len(hash) != hashLength {
+		return 0, errors.Errorf("invalid SHA-1 hash %q", hash)
+	}
+
+	path, key := l.path, hash
+	if l.dir {
+		path, key = filepath.Join(l.path, hash[:prefixLength]+".txt"), hash[prefixLength:]
+	}
+
+	f, err := os.Open(path)
+	if l.dir && errors.Is(err, os.ErrNotExist) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	defer func() { _ = f.Close() }()
+
+	info, err := f.Stat()
+	if err != nil {
+		return 0, err
+	}
+
+	return search(f, info.Size(), key)
+}
+
+// search returns the count of the line of the key in the sorted lines
+// of the file, or zero if there is none. It searches the first line
+// not less than the key with a binary search over the bytes of the
+// file: the middle of the range is moved to the beginning of the next
+// line, so that the lines are read at most once per step.
+func search(r io.ReaderAt, size int64, key string) (int, error) {
+	lo, hi := int64(0), size
+	for lo < hi {
+		mid := lo + (hi-lo)/2 //nolint:gomnd
+		start, err := lineStart(r, size, mid)
+		if err != nil {
+			return 0, err
+		}
+		if start >= hi {
+			hi = mid
+			continue
+		}
+
+		line, err := readLine(r, size, start)
+		if err != nil {
+			return 0, err
+		}
+		lineKey, _, err := parseLine(line, len(key))
+		if err != nil {
+			return 0, err
+		}
+		if lineKey < key {
+			lo = start + int64(len(line)) + 1
+		} else {
+			hi = start
+		}
+	}
+
+	if lo >= size {
+		return 0, nil
+	}
+	line, err := readLine(r, size, lo)
+	if err != nil {
+		return 0, err
+	}
+	lineKey, count, err := parseLine(line, len(key))
+	if err != nil || lineKey != key {
+		return 0, err
+	}
+
+	return count, nil
+}
+
+// lineStart returns the offset of the first line starting at the
+// offset or after it, or the size if there is none.
+func lineStart(r io.ReaderAt, size, offset int64) (int64, error) {
+	if offset == 0 {
+		return 0, nil
+	}
+
+	buf := make([]byte, chunkSize)
+	for pos := offset - 1; pos < size; pos += chunkSize {
+		n, err := r.ReadAt(buf, pos)
+		if err != nil && !errors.Is(err, io.EOF) {
+			return 0, err
+		}
+		if i := bytes.IndexByte(buf[:n], '\n'); i >= 0 {
+			return pos + int64(i) + 1, nil
+		}
+	}
+
+	return size, nil
+}
+
+// readLine returns the line starting at the offset, without the line
+// break.
+func readLine(r io.ReaderAt, size, offset int64) ([]byte, error) {
+	var line []byte
+	buf := make([]byte, chunkSize)
+	for pos := offset; pos < size; pos += chunkSize {
+		n, err := r.ReadAt(buf, pos)
+		if err != nil && !errors.Is(err, io.EOF) {
+			return nil, err
+		}
+		if i := bytes.IndexByte(buf[:n], '\n'); i >= 0 {
+			return append(line, buf[:i]...), nil
+		}
+		line = append(line, buf[:n]...)
+	}
+
+	return line, nil
+}
+
+// parseLine returns the key of the length, upper-cased, and the count
+// of the line.
+func parseLine(line []byte, keyLength int) (string, int, error) {
+	line = bytes.TrimRight(line, "\r")
+	if len(line) < keyLength {
+		return "", 0, errors.Wrapf(ErrInvalidLine, "%q", line)
+	}
+
+	key := strings.ToUpper(string(line[:keyLength]))
+	count := 1
+	if rest := line[keyLength:]; len(rest) > 0 {
+		if rest[0] != ':' {
+			return "", 0, errors.Wrapf(ErrInvalidLine, "%q", line)
+		}
+		var err error
+		if count, err = strconv.Atoi(string(rest[1:])); err != nil {
+			return "", 0, errors.Wrapf(ErrInvalidLine, "%q", line)
+		}
+	}
+
+	return key, count, nil
+}
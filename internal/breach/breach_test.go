@@ -0,0 +1,83 @@
+package breach
+
+import (
+	"crypto/sha1" //nolint:gosec
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func hashOf(password string) string {
+	sum := sha1.Sum([]byte(password)) //nolint:gosec
+
+	return strings.ToUpper(hex.EncodeToString(sum[:]))
+}
+
+func TestList_file(t *testing.T) {
+	var lines []string
+	for i := 0; i < 5000; i++ {
+		lines = append(lines, fmt.Sprintf("%s:%d", hashOf(fmt.Sprintf("password%d", i)), i+1))
+	}
+	sort.Strings(lines)
+
+	path := filepath.Join(t.TempDir(), "pwned.txt")
+	require.NoError(t, os.WriteFile(path, []byte(strings.Join(lines, "\r\n")+"\r\n"), 0o600))
+
+	list, err := Open(path)
+	require.NoError(t, err)
+
+	for i := 0; i < 5000; i += 7 {
+		count, err := list.Count(fmt.Sprintf("password%d", i))
+		require.NoError(t, err)
+		require.Equal(t, i+1, count)
+	}
+	for _, password := range []string{"", "not breached", "password5000"} {
+		count, err := list.Count(password)
+		require.NoError(t, err)
+		require.Zero(t, count, password)
+	}
+
+	count, err := list.CountHash(strings.ToLower(hashOf("password42")))
+	require.NoError(t, err)
+	require.Equal(t, 43, count)
+
+	_, err = list.CountHash("abc")
+	require.Error(t, err)
+}
+
+func TestList_dir(t *testing.T) {
+	dir := t.TempDir()
+	hash := hashOf("letmein")
+	data := fmt.Sprintf("0000000000000000000000000000000000A:1\n%s:3\nFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFFF:2\n", hash[prefixLength:])
+	require.NoError(t, os.WriteFile(filepath.Join(dir, hash[:prefixLength]+".txt"), []byte(data), 0o600))
+
+	list, err := Open(dir)
+	require.NoError(t, err)
+
+	count, err := list.Count("letmein")
+	require.NoError(t, err)
+	require.Equal(t, 3, count)
+
+	count, err = list.Count("correct horse battery staple")
+	require.NoError(t, err)
+	require.Zero(t, count)
+}
+
+func TestList_invalid(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "pwned.txt")
+	require.NoError(t, os.WriteFile(path, []byte("not a hash\n"), 0o600))
+
+	list, err := Open(path)
+	require.NoError(t, err)
+	_, err = list.Count("password")
+	require.ErrorIs(t, err, ErrInvalidLine)
+
+	_, err = Open(filepath.Join(t.TempDir(), "missing"))
+	require.Error(t, err)
+}
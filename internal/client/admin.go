@@ -0,0 +1,191 @@
+package client
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"text/tabwriter"
+
+	"github.com/fatih/color"
+	"github.com/iryzzh/y-gophkeeper/internal/models"
+	"github.com/urfave/cli/v2"
+)
+
+// adminUsers prints the users with their usage and quotas.
+func (c *Client) adminUsers(_ *cli.Context) error {
+	if err := c.clientSvc.RefreshToken(); err != nil {
+		return err
+	}
+
+	users, err := c.clientSvc.AdminUsers()
+	if err != nil {
+		return exitError(err)
+	}
+	if len(users) == 0 {
+		color.Yellow("no users found")
+		return nil
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0) //nolint:gomnd
+	_, _ = fmt.Fprintln(w, "LOGIN\tROLE\tSTATUS\t2FA\tITEMS\tSTORAGE\tQUOTA")
+	for _, u := range users {
+		_, _ = fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\t%s\n", u.Login, userRole(u), userStatus(u),
+			twoFactorString(u), usageItems(u.Usage), usageBytes(u.Usage), quotaString(u.Quota))
+	}
+
+	return w.Flush()
+}
+
+// adminUsage prints the storage usage and the quota of a user.
+func (c *Client) adminUsage(cCtx *cli.Context) error {
+	login := cCtx.Args().First()
+	if login == "" {
+		return cli.Exit("usage: admin usage <login>", 1)
+	}
+
+	if err := c.clientSvc.RefreshToken(); err != nil {
+		return err
+	}
+
+	u, err := c.clientSvc.AdminUser(login)
+	if err != nil {
+		return exitError(err)
+	}
+
+	return printAccount(u, true)
+}
+
+// adminDisable disables the account of a user and logs the user out.
+func (c *Client) adminDisable(cCtx *cli.Context) error {
+	return c.adminAction(cCtx, c.clientSvc.AdminDisable, "✅ '%v' was disabled!")
+}
+
+// adminEnable enables the disabled account of a user.
+func (c *Client) adminEnable(cCtx *cli.Context) error {
+	return c.adminAction(cCtx, c.clientSvc.AdminEnable, "✅ '%v' was enabled!")
+}
+
+// adminLogout revokes all the sessions of a user.
+func (c *Client) adminLogout(cCtx *cli.Context) error {
+	return c.adminAction(cCtx, c.clientSvc.AdminLogout, "✅ '%v' was logged out!")
+}
+
+// adminAction applies the action to the user with the login given as
+// the argument.
+func (c *Client) adminAction(cCtx *cli.Context, action func(login string) error, msg string) error {
+	login := cCtx.Args().First()
+	if login == "" {
+		return cli.Exit(fmt.Sprintf("usage: admin %s <login>", cCtx.Command.Name), 1)
+	}
+
+	if err := c.clientSvc.RefreshToken(); err != nil {
+		return err
+	}
+
+	if err := action(login); err != nil {
+		return exitError(err)
+	}
+
+	color.Green(msg, login)
+
+	return nil
+}
+
+// adminQuota sets the quota of a user. Limits that are not set are
+// kept, zero removes the limit. With `--reset` the default quota of the
+// server applies to the user again.
+func (c *Client) adminQuota(cCtx *cli.Context) error {
+	login := cCtx.Args().First()
+	if login == "" {
+		return cli.Exit("usage: admin quota [--bytes n] [--items n] [--item-bytes n] [--reset] <login>", 1)
+	}
+
+	if err := c.clientSvc.RefreshToken(); err != nil {
+		return err
+	}
+
+	if cCtx.Bool("reset") {
+		if err := c.clientSvc.AdminResetQuota(login); err != nil {
+			return exitError(err)
+		}
+		color.Green("✅ the default quota applies to '%v'", login)
+		return nil
+	}
+
+	u, err := c.clientSvc.AdminUser(login)
+	if err != nil {
+		return exitError(err)
+	}
+
+	quota := &models.Quota{}
+	if u.Quota != nil {
+		*quota = *u.Quota
+	}
+	if cCtx.IsSet("bytes") {
+		quota.MaxBytes = cCtx.Int64("bytes")
+	}
+	if cCtx.IsSet("items") {
+		quota.MaxItems = cCtx.Int64("items")
+	}
+	if cCtx.IsSet("item-bytes") {
+		quota.MaxItemBytes = cCtx.Int64("item-bytes")
+	}
+
+	if err = c.clientSvc.AdminSetQuota(login, quota); err != nil {
+		return exitError(err)
+	}
+
+	color.Green("✅ the quota of '%v' is %v", login, quotaString(quota))
+
+	return nil
+}
+
+func userRole(u *models.User) string {
+	if u.Admin {
+		return "admin"
+	}
+
+	return "user"
+}
+
+func userStatus(u *models.User) string {
+	if u.DisabledAt != nil {
+		return "disabled"
+	}
+
+	return "active"
+}
+
+func twoFactorString(u *models.User) string {
+	if u.TwoFactor {
+		return "on"
+	}
+
+	return "off"
+}
+
+func usageItems(usage *models.Usage) string {
+	if usage == nil {
+		return "-"
+	}
+
+	return strconv.FormatInt(usage.Items, 10)
+}
+
+func usageBytes(usage *models.Usage) string {
+	if usage == nil {
+		return "-"
+	}
+
+	return formatBytes(usage.Bytes)
+}
+
+// quotaString returns the quota as a human-readable string.
+func quotaString(q *models.Quota) string {
+	if q == nil {
+		q = &models.Quota{}
+	}
+
+	return fmt.Sprintf("bytes=%s items=%s item-bytes=%s",
+		limitString(q.MaxBytes, true), limitString(q.MaxItems, false), limitString(q.MaxItemBytes, true))
+}
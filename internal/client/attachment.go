@@ -0,0 +1,288 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"text/tabwriter"
+
+	"github.com/fatih/color"
+	"github.com/iryzzh/y-gophkeeper/internal/config"
+	"github.com/iryzzh/y-gophkeeper/internal/keys"
+	"github.com/iryzzh/y-gophkeeper/internal/models"
+	"github.com/iryzzh/y-gophkeeper/internal/services/item"
+	"github.com/pkg/errors"
+	"github.com/urfave/cli/v2"
+)
+
+// maxAttachmentSize is the maximum size of an attachment in the client:
+// the attachments are encrypted as a whole in memory.
+const maxAttachmentSize = 64 << 20
+
+// errAttachmentTooLarge is returned when an attachment is larger than
+// `maxAttachmentSize`.
+var errAttachmentTooLarge = errors.Errorf("the attachment is over the limit of %d bytes", maxAttachmentSize)
+
+// attach attaches the file to the entry locally and on the remote
+// server, replacing the attachment with the same name. The attachments
+// of the shared entries are encrypted on the remote server with the
+// item key.
+func (c *Client) attach(cCtx *cli.Context) error {
+	name, path := cCtx.Args().Get(0), cCtx.Args().Get(1)
+	if name == "" || path == "" {
+		return cli.Exit("usage: attach [--name <attachment>] <entry> <file>", 1)
+	}
+
+	userID, found, err := c.findEntry(cCtx.Context, name)
+	if err != nil {
+		return err
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return exitError(err)
+	}
+	defer func() { _ = f.Close() }()
+	info, err := f.Stat()
+	if err != nil {
+		return exitError(err)
+	}
+	if info.Size() > maxAttachmentSize {
+		return exitError(errAttachmentTooLarge)
+	}
+
+	attachment := &models.Attachment{Name: cCtx.String("name")}
+	if attachment.Name == "" {
+		attachment.Name = filepath.Base(path)
+	}
+
+	if err = c.itemSvc.Attach(cCtx.Context, userID, strconv.Itoa(found.ID), attachment, f); err != nil {
+		return exitError(err)
+	}
+	if _, err = f.Seek(0, io.SeekStart); err != nil {
+		return err
+	}
+
+	if err = c.clientSvc.RefreshToken(); err != nil {
+		return err
+	}
+
+	var content io.Reader = f
+	if found.Key != nil {
+		if content, err = c.encryptedAttachment(found, f); err != nil {
+			return err
+		}
+	}
+	if _, err = c.clientSvc.Attach(found.ID, attachment.Name, content); err != nil {
+		return exitError(err)
+	}
+
+	color.Green("✅ '%v' (%s) was attached to '%v'", attachment.Name, formatBytes(attachment.Size), found.Meta)
+
+	return nil
+}
+
+// detach deletes the attachment of the entry locally and on the remote
+// server.
+func (c *Client) detach(cCtx *cli.Context) error {
+	name, attachment := cCtx.Args().Get(0), cCtx.Args().Get(1)
+	if name == "" || attachment == "" {
+		return cli.Exit("usage: detach <entry> <attachment>", 1)
+	}
+
+	userID, found, err := c.findEntry(cCtx.Context, name)
+	if err != nil {
+		return err
+	}
+
+	err = c.itemSvc.Detach(cCtx.Context, userID, strconv.Itoa(found.ID), attachment)
+	if errors.Is(err, item.ErrAttachmentNotFound) {
+		return cli.Exit(fmt.Sprintf("attachment '%v' not found", attachment), 1)
+	}
+	if err != nil {
+		return exitError(err)
+	}
+
+	if err = c.clientSvc.RefreshToken(); err != nil {
+		return err
+	}
+	if err = c.clientSvc.Detach(found.ID, attachment); err != nil {
+		return exitError(err)
+	}
+
+	color.Green("✅ '%v' was detached from '%v'", attachment, found.Meta)
+
+	return nil
+}
+
+// attachments lists the attachments of the entry.
+func (c *Client) attachments(cCtx *cli.Context) error {
+	name := cCtx.Args().First()
+	if name == "" {
+		return cli.Exit("usage: attachments <entry>", 1)
+	}
+
+	userID, found, err := c.findEntry(cCtx.Context, name)
+	if err != nil {
+		return err
+	}
+
+	attachments, err := c.itemSvc.Attachments(cCtx.Context, userID, strconv.Itoa(found.ID))
+	if err != nil {
+		return exitError(err)
+	}
+	if len(attachments) == 0 {
+		color.Yellow("⚠️ '%v' has no attachments", found.Meta)
+		return nil
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0) //nolint:gomnd
+	_, _ = fmt.Fprintln(w, "NAME\tSIZE\tADDED")
+	for _, a := range attachments {
+		var added string
+		if a.CreatedAt != nil {
+			added = a.CreatedAt.Local().Format(dateLayout)
+		}
+		_, _ = fmt.Fprintf(w, "%s\t%s\t%s\n", a.Name, formatBytes(a.Size), added)
+	}
+
+	return w.Flush()
+}
+
+// download saves the attachment of the entry to the file, which must
+// not exist, or prints it if the file is '-'. The file defaults to the
+// name of the attachment in the current directory.
+func (c *Client) download(cCtx *cli.Context) error {
+	name, attachment := cCtx.Args().Get(0), cCtx.Args().Get(1)
+	if name == "" || attachment == "" {
+		return cli.Exit("usage: download [--out <file>] <entry> <attachment>", 1)
+	}
+
+	userID, found, err := c.findEntry(cCtx.Context, name)
+	if err != nil {
+		return err
+	}
+
+	_, rc, err := c.itemSvc.OpenAttachment(cCtx.Context, userID, strconv.Itoa(found.ID), attachment)
+	if errors.Is(err, item.ErrAttachmentNotFound) {
+		return cli.Exit(fmt.Sprintf("attachment '%v' not found", attachment), 1)
+	}
+	if err != nil {
+		return exitError(err)
+	}
+	defer func() { _ = rc.Close() }()
+
+	out := cCtx.String("out")
+	if out == "" {
+		out = attachment
+	}
+	if out == "-" {
+		_, err = io.Copy(os.Stdout, rc)
+		return err
+	}
+
+	f, err := os.OpenFile(out, os.O_WRONLY|os.O_CREATE|os.O_EXCL, config.FilePermission)
+	if err != nil {
+		return exitError(err)
+	}
+	if _, err = io.Copy(f, rc); err != nil {
+		_ = f.Close()
+		return err
+	}
+	if err = f.Close(); err != nil {
+		return err
+	}
+
+	color.Green("✅ '%v' was saved to '%v'", attachment, out)
+
+	return nil
+}
+
+// findEntry returns the id of the vault and the entry with the name.
+func (c *Client) findEntry(ctx context.Context, name string) (string, *models.Item, error) {
+	userID, err := c.vaultID()
+	if err != nil {
+		return "", nil, err
+	}
+
+	found, err := c.itemSvc.FindByMetaName(ctx, userID, name)
+	if errors.Is(err, item.ErrItemNotFound) {
+		return "", nil, cli.Exit(fmt.Sprintf("entry '%v' not found", name), 1)
+	}
+	if err != nil {
+		return "", nil, err
+	}
+
+	return userID, found, nil
+}
+
+// encryptedAttachment returns the content of the attachment of the
+// shared item encrypted with the item key.
+func (c *Client) encryptedAttachment(it *models.Item, r io.Reader) (io.Reader, error) {
+	itemKey, err := c.itemKey(it)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := readAttachment(r)
+	if err != nil {
+		return nil, err
+	}
+	if data, err = keys.Encrypt(data, itemKey); err != nil {
+		return nil, err
+	}
+
+	return bytes.NewReader(data), nil
+}
+
+// pullAttachments downloads the attachments of the item with the given
+// id on the remote server and attaches them to the local item. The
+// attachments of the shared items are decrypted with the item key.
+func (c *Client) pullAttachments(ctx context.Context, remoteID int, it *models.Item) error {
+	for _, a := range it.Attachments {
+		rc, err := c.clientSvc.OpenAttachment(remoteID, a.Name)
+		if err != nil {
+			return err
+		}
+		data, err := readAttachment(rc)
+		_ = rc.Close()
+		if err != nil {
+			return err
+		}
+
+		if it.Key != nil {
+			itemKey, err := c.itemKey(it)
+			if err != nil {
+				return err
+			}
+			if data, err = keys.Decrypt(data, itemKey); err != nil {
+				return err
+			}
+		}
+
+		if err = c.itemSvc.Attach(ctx, it.UserID, strconv.Itoa(it.ID), &models.Attachment{Name: a.Name},
+			bytes.NewReader(data)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// readAttachment reads the content of the attachment, failing with
+// `errAttachmentTooLarge` once it is larger than `maxAttachmentSize`.
+func readAttachment(r io.Reader) ([]byte, error) {
+	data, err := io.ReadAll(io.LimitReader(r, maxAttachmentSize+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(data) > maxAttachmentSize {
+		return nil, errAttachmentTooLarge
+	}
+
+	return data, nil
+}
@@ -0,0 +1,65 @@
+package client
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"text/tabwriter"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/iryzzh/y-gophkeeper/internal/models"
+	"github.com/urfave/cli/v2"
+)
+
+// sessionLen is the number of the characters of the session id shown
+// in the audit log.
+const sessionLen = 8
+
+// audit prints the events of the audit log of the user, the most
+// recent first.
+func (c *Client) audit(cCtx *cli.Context) error {
+	filter := &models.AuditFilter{
+		Action: models.AuditAction(cCtx.String("action")),
+		Limit:  cCtx.Int("limit"),
+	}
+	if since := cCtx.Duration("since"); since > 0 {
+		t := time.Now().Add(-since)
+		filter.Since = &t
+	}
+
+	if err := c.clientSvc.RefreshToken(); err != nil {
+		return err
+	}
+
+	events, err := c.clientSvc.Audit(filter)
+	if err != nil {
+		return exitError(err)
+	}
+	if len(events) == 0 {
+		color.Yellow("no events found")
+		return nil
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0) //nolint:gomnd
+	for _, e := range events {
+		var createdAt string
+		if e.CreatedAt != nil {
+			createdAt = e.CreatedAt.Local().Format(dateLayout + " 15:04:05")
+		}
+
+		object := e.Target
+		if e.ItemID != 0 {
+			object = "item " + strconv.Itoa(e.ItemID)
+		}
+
+		session := e.SessionID
+		if len(session) > sessionLen {
+			session = session[:sessionLen]
+		}
+
+		_, _ = fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", createdAt, e.Action, object, e.IP, session)
+	}
+
+	return w.Flush()
+}
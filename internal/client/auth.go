@@ -1,10 +1,12 @@
 package client
 
 import (
+	"errors"
 	"fmt"
 
 	"github.com/fatih/color"
 	"github.com/iryzzh/y-gophkeeper/internal/models"
+	"github.com/iryzzh/y-gophkeeper/internal/services/api_client"
 	"github.com/iryzzh/y-gophkeeper/internal/tui"
 	"github.com/urfave/cli/v2"
 )
@@ -15,7 +17,8 @@ func (c *Client) auth(cCtx *cli.Context) error {
 		Remote: cCtx.String("remote"),
 		User: &models.User{
 			Login:    cCtx.String("user"),
-			Password: cCtx.String("password")},
+			Password: cCtx.String("password"),
+			OTP:      cCtx.String("otp")},
 	}
 
 	fmt.Printf("%v\n", logo)
@@ -31,20 +34,35 @@ func (c *Client) auth(cCtx *cli.Context) error {
 		return cli.Exit("usage: auth <remote> <auth> <password>", 1)
 	}
 
+	masterPassword, err := c.masterPassword(cCtx, initModel.User.Password)
+	if err != nil {
+		return exitError(err)
+	}
+
 	fmt.Printf("🗣️ logging on to %v...\n", initModel.Remote)
 
+	// the vault is unlocked first, so that the credentials are saved
+	// encrypted with its key.
+	if err = c.unlockVault(cCtx.Context, masterPassword, 0); err != nil {
+		return exitError(err)
+	}
+
 	c.clientSvc.SetBaseURL(initModel.Remote)
-	if err := c.clientSvc.Login(initModel.User); err != nil {
+	if err = c.login(initModel.User); err != nil {
 		return err
 	}
 
 	_ = c.userSvc.Create(cCtx.Context, initModel.User)
 
-	if err := c.cfg.SaveConfig(); err != nil {
+	if err = c.registerKeys(); err != nil {
 		return err
 	}
 
-	if err := c.pull(cCtx.Context); err != nil {
+	if err = c.cfg.SaveConfig(); err != nil {
+		return err
+	}
+
+	if err = c.pull(cCtx.Context); err != nil {
 		return err
 	}
 
@@ -52,3 +70,21 @@ func (c *Client) auth(cCtx *cli.Context) error {
 
 	return nil
 }
+
+// login logs on to the remote server. The one-time code is asked for
+// if the user has the second factor enabled and the code is not set.
+// The code is cleared, so that it is not saved with the credentials.
+func (c *Client) login(u *models.User) error {
+	defer func() { u.OTP = "" }()
+
+	err := c.clientSvc.Login(u)
+	if !errors.Is(err, api_client.ErrOTPRequired) || u.OTP != "" {
+		return err
+	}
+
+	if u.OTP, err = tui.AskPassword("One-time code:"); err != nil {
+		return err
+	}
+
+	return c.clientSvc.Login(u)
+}
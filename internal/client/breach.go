@@ -0,0 +1,137 @@
+package client
+
+import (
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"github.com/fatih/color"
+	"github.com/iryzzh/y-gophkeeper/internal/breach"
+	"github.com/iryzzh/y-gophkeeper/internal/models"
+	"github.com/urfave/cli/v2"
+)
+
+// breached is a password of an entry found in the breaches.
+type breached struct {
+	field string
+	count int
+}
+
+// breachCheck checks the passwords of the local vault against the
+// local list of the breached passwords.
+func (c *Client) breachCheck(cCtx *cli.Context) error {
+	path := cCtx.String("list")
+	if path == "" {
+		path = c.cfg.BreachList
+	}
+	if path == "" {
+		return cli.Exit("usage: breach-check --list <path>, or set 'breach_list' in the configuration", 1)
+	}
+
+	list, err := breach.Open(path)
+	if err != nil {
+		return exitError(err)
+	}
+
+	userID, err := c.vaultID()
+	if err != nil {
+		return err
+	}
+	items, err := c.allItems(cCtx.Context, userID, &models.ItemFilter{Types: []string{models.EntryTypeText}})
+	if err != nil {
+		return exitError(err)
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0) //nolint:gomnd
+	var found int
+	for _, it := range items {
+		results, err := checkBreached(list, it)
+		if err != nil {
+			return err
+		}
+		for _, result := range results {
+			if found == 0 {
+				_, _ = fmt.Fprintln(w, "ENTRY\tFIELD\tBREACHES")
+			}
+			found++
+			_, _ = fmt.Fprintf(w, "%s\t%s\t%d\n", it.Meta, result.field, result.count)
+		}
+	}
+
+	if found == 0 {
+		color.Green("✅ none of the %d entries has a breached password", len(items))
+		return nil
+	}
+	if err = w.Flush(); err != nil {
+		return err
+	}
+	color.Red("❌ %d breached passwords found, please change them", found)
+
+	return nil
+}
+
+// warnBreached warns about the passwords of the text entry found in
+// the breached passwords list, if it is configured.
+func (c *Client) warnBreached(it *models.Item) {
+	if c.cfg.BreachList == "" || it.DataType != models.EntryTypeText {
+		return
+	}
+
+	list, err := breach.Open(c.cfg.BreachList)
+	if err != nil {
+		color.Yellow("⚠️ the breached passwords cannot be checked: %v", err)
+		return
+	}
+
+	results, err := checkBreached(list, it)
+	if err != nil {
+		color.Yellow("⚠️ the breached passwords cannot be checked: %v", err)
+		return
+	}
+	for _, result := range results {
+		if result.field == "" {
+			color.Yellow("⚠️ the value appears in %d breaches, please change it", result.count)
+		} else {
+			color.Yellow("⚠️ the field '%v' appears in %d breaches, please change it", result.field, result.count)
+		}
+	}
+}
+
+// checkBreached returns the value and the secret fields of the text
+// entry found in the list.
+func checkBreached(list *breach.List, it *models.Item) ([]*breached, error) {
+	var results []*breached
+	check := func(field, value string) error {
+		if value == "" {
+			return nil
+		}
+		count, err := list.Count(value)
+		if err != nil {
+			return err
+		}
+		if count > 0 {
+			results = append(results, &breached{field: field, count: count})
+		}
+		return nil
+	}
+
+	if it.ItemData != nil {
+		value, err := it.ItemData.DecodeDataToString()
+		if err != nil {
+			return nil, err
+		}
+		if err = check("", value); err != nil {
+			return nil, err
+		}
+	}
+	for _, field := range it.Fields {
+		if !field.Secret {
+			continue
+		}
+		if err := check(field.Name, field.Value); err != nil {
+			return nil, err
+		}
+	}
+
+	return results, nil
+}
@@ -2,15 +2,22 @@ package client
 
 import (
 	"context"
+	"net/url"
 	"os"
+	"time"
 
+	"github.com/fatih/color"
+	"github.com/iryzzh/y-gophkeeper/internal/agent"
 	"github.com/iryzzh/y-gophkeeper/internal/config"
+	"github.com/iryzzh/y-gophkeeper/internal/keys"
 	"github.com/iryzzh/y-gophkeeper/internal/models"
 	"github.com/iryzzh/y-gophkeeper/internal/services/api_client"
 	"github.com/iryzzh/y-gophkeeper/internal/services/item"
 	"github.com/iryzzh/y-gophkeeper/internal/services/user"
 	"github.com/iryzzh/y-gophkeeper/internal/store"
+	"github.com/iryzzh/y-gophkeeper/internal/store/sealed"
 	jsoniter "github.com/json-iterator/go"
+	"github.com/pkg/errors"
 	"github.com/urfave/cli/v2"
 )
 
@@ -22,6 +29,8 @@ type Client struct {
 	userSvc   *user.Service
 	itemSvc   *item.Service
 	clientSvc *api_client.ApiClient
+	sealed    *sealed.Store
+	key       *[keys.KeySize]byte
 }
 
 func NewClient(cfg *config.ClientCfg, s store.Store) *Client {
@@ -40,13 +49,23 @@ func NewClient(cfg *config.ClientCfg, s store.Store) *Client {
 		cfg.Security.KeyLength,
 	)
 
-	c.itemSvc = item.NewService(s)
+	cfg.SetKey(c.vaultKey)
+	c.sealed = sealed.NewStore(s, c.vaultKey)
+	c.itemSvc = item.NewService(c.sealed, nil)
 
 	c.clientSvc = api_client.NewApiClient(&cfg.API, cfg.SkipVerify)
+	c.clientSvc.SetCollection(cfg.Vault.CollectionID)
 
 	commands := c.getCommands()
 	c.app = &cli.App{
 		Commands: commands,
+		Flags: []cli.Flag{
+			&cli.StringFlag{
+				Name:    "profile",
+				Usage:   "Profile to use, see 'profile'",
+				EnvVars: []string{config.ProfileEnv},
+			},
+		},
 	}
 	c.app.Name = "gophkeeper-cli"
 	c.app.Version = cfg.Version.Version
@@ -54,25 +73,112 @@ func NewClient(cfg *config.ClientCfg, s store.Store) *Client {
 	return c
 }
 
+// Run runs the cli. The errors of the remote server and of the locked
+// vault returned by the commands are reported with the friendly
+// messages and exit codes.
 func (c *Client) Run(ctx context.Context) error {
-	return c.app.RunContext(ctx, os.Args)
+	err := c.app.RunContext(ctx, os.Args)
+
+	var apiErr *api_client.Error
+	var urlErr *url.Error
+	if errors.As(err, &apiErr) || errors.As(err, &urlErr) || errors.Is(err, agent.ErrLocked) {
+		cli.HandleExitCoder(exitError(err))
+	}
+
+	return err
 }
 
+// push creates the item on the remote server and records its id there,
+// so that its deletion on the remote server is pulled.
+func (c *Client) push(ctx context.Context, it *models.Item) error {
+	remoteID, err := c.clientSvc.CreateItem(it)
+	if err != nil {
+		return err
+	}
+
+	return c.itemSvc.SetRemoteID(ctx, it.UserID, it.ID, remoteID)
+}
+
+// pull fetches the items of the user from the remote server. The local
+// items deleted on the remote server since they were last modified are
+// moved to the trash, the missing ones are created with their
+// attachments. The deleted items are matched by their id on the remote
+// server, so the items never pushed are kept, and only the deletions
+// since the last pull to the vault are fetched.
 func (c *Client) pull(ctx context.Context) error {
 	var err error
 	if err = c.clientSvc.RefreshToken(); err != nil {
 		return err
 	}
 
+	userID, err := c.vaultID()
+	if err != nil {
+		return err
+	}
+
+	synced := c.cfg.Synced[userID]
+	var tombstones []*models.Tombstone
+	if tombstones, err = c.clientSvc.Tombstones(synced); err != nil {
+		return err
+	}
+	for _, t := range tombstones {
+		if t.DeletedAt.After(synced) {
+			synced = t.DeletedAt
+		}
+
+		found, findErr := c.itemSvc.FindByRemoteID(ctx, userID, t.ItemID)
+		if errors.Is(findErr, item.ErrItemNotFound) {
+			continue
+		}
+		if findErr != nil {
+			return findErr
+		}
+
+		modified := found.CreatedAt
+		if found.UpdatedAt != nil {
+			modified = found.UpdatedAt
+		}
+		if modified != nil && modified.After(t.DeletedAt) {
+			continue
+		}
+
+		if err = c.itemSvc.Delete(ctx, found); err != nil {
+			return err
+		}
+	}
+
 	var items []*models.Item
 	if items, err = c.clientSvc.GetItems(); err != nil {
 		return err
 	}
 	for _, value := range items {
-		if err = c.itemSvc.Create(ctx, value); err != nil {
+		if err = c.decrypt(value); err != nil {
+			color.Yellow("⚠️ entry '%v' cannot be decrypted: %v", value.Meta, err)
+			continue
+		}
+		remoteID := value.ID
+		err = c.itemSvc.Create(ctx, value)
+		if errors.Is(err, item.ErrItemExists) {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		if err = c.itemSvc.SetRemoteID(ctx, userID, value.ID, remoteID); err != nil {
+			return err
+		}
+		if err = c.pullAttachments(ctx, remoteID, value); err != nil {
 			return err
 		}
 	}
 
-	return nil
+	if synced.IsZero() {
+		return nil
+	}
+	if c.cfg.Synced == nil {
+		c.cfg.Synced = make(map[string]time.Time)
+	}
+	c.cfg.Synced[userID] = synced
+
+	return c.cfg.SaveConfig()
 }
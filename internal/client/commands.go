@@ -3,6 +3,8 @@ package client
 import (
 	"fmt"
 
+	"github.com/iryzzh/y-gophkeeper/internal/health"
+	"github.com/iryzzh/y-gophkeeper/internal/models"
 	"github.com/urfave/cli/v2"
 )
 
@@ -28,6 +30,10 @@ func (c *Client) getCommands() []*cli.Command {
 					Aliases: []string{"p"},
 					Usage:   "Password",
 				},
+				&cli.StringFlag{
+					Name:  "master-password",
+					Usage: "Master password of the local vault, other than the password. Asked for if not set",
+				},
 			},
 		},
 		{
@@ -50,6 +56,14 @@ func (c *Client) getCommands() []*cli.Command {
 					Aliases: []string{"p"},
 					Usage:   "Password",
 				},
+				&cli.StringFlag{
+					Name:  "master-password",
+					Usage: "Master password of the local vault, other than the password. Asked for if not set",
+				},
+				&cli.StringFlag{
+					Name:  "otp",
+					Usage: "One-time code of the second factor. Asked for if required and not set",
+				},
 			},
 		},
 		{
@@ -57,7 +71,7 @@ func (c *Client) getCommands() []*cli.Command {
 			Usage:  "Add an entry",
 			Action: c.entryNew,
 			Before: c.isInitialized,
-			Flags: []cli.Flag{
+			Flags: append([]cli.Flag{
 				&cli.StringFlag{
 					Name:    "name",
 					Aliases: []string{"n"},
@@ -75,14 +89,19 @@ func (c *Client) getCommands() []*cli.Command {
 					Value:   "text",
 					Aliases: []string{"t"},
 				},
-			},
+				&cli.BoolFlag{
+					Name:    "generate",
+					Aliases: []string{"g"},
+					Usage:   "Generate the value: a password, or a passphrase with --passphrase",
+				},
+			}, append(append(metadataFlags(), scheduleFlags()...), generatorFlags()...)...),
 			Subcommands: []*cli.Command{
 				{
 					Name:   "card",
 					Usage:  "add a new bank card",
 					Before: c.isInitialized,
 					Action: c.entryNewCard,
-					Flags: []cli.Flag{
+					Flags: append([]cli.Flag{
 						&cli.StringFlag{
 							Name:    "name",
 							Aliases: []string{"n"},
@@ -107,10 +126,73 @@ func (c *Client) getCommands() []*cli.Command {
 							Name:  "cvv",
 							Usage: "CVV",
 						},
-					},
+					}, metadataFlags()...),
 				},
 			},
 		},
+		{
+			Name:   "health",
+			Usage:  "Report the weak, reused and old passwords and the cards expiring soon",
+			Action: c.vaultHealth,
+			Before: c.isInitialized,
+			Flags: []cli.Flag{
+				&cli.Float64Flag{
+					Name:  "min-entropy",
+					Usage: "Estimated entropy in bits below which a password is weak",
+					Value: health.DefaultOptions.MinEntropy,
+				},
+				&cli.IntFlag{
+					Name:  "max-age",
+					Usage: "Days after which an entry not changed needs to be rotated",
+					Value: int(health.DefaultOptions.MaxAge / day),
+				},
+				&cli.IntFlag{
+					Name:  "expiry-window",
+					Usage: "Days before the expiration from which a card expires soon",
+					Value: int(health.DefaultOptions.ExpiryWindow / day),
+				},
+				&cli.BoolFlag{
+					Name:  "json",
+					Usage: "Print the report as JSON",
+				},
+			},
+		},
+		{
+			Name:   "due",
+			Usage:  "List the entries expiring or due for rotation",
+			Action: c.due,
+			Before: c.isInitialized,
+			Flags: []cli.Flag{
+				&cli.IntFlag{
+					Name:  "within",
+					Usage: "Number of days ahead to look at, the overdue entries are always listed",
+					Value: defaultDueDays,
+				},
+				&cli.BoolFlag{
+					Name:  "json",
+					Usage: "Print the entries as JSON",
+				},
+			},
+		},
+		{
+			Name:   "breach-check",
+			Usage:  "Check the passwords against a local list of the breached passwords",
+			Action: c.breachCheck,
+			Before: c.isInitialized,
+			Flags: []cli.Flag{
+				&cli.StringFlag{
+					Name: "list",
+					Usage: "Path of the list of the SHA-1 hashes of the breached passwords: a file of sorted" +
+						" 'HASH:COUNT' lines, or a directory of 'ABCDE.txt' files by hash prefix",
+				},
+			},
+		},
+		{
+			Name:   "generate",
+			Usage:  "Generate a password or a passphrase",
+			Action: c.generate,
+			Flags:  generatorFlags(),
+		},
 		{
 			Name:   "view",
 			Usage:  "View entries",
@@ -118,18 +200,645 @@ func (c *Client) getCommands() []*cli.Command {
 			Before: c.isInitialized,
 		},
 		{
-			Name:    "list",
-			Aliases: []string{"ls", "l"},
-			Usage:   "List entries",
-			Action:  c.entryList,
-			Before:  c.isInitialized,
+			Name:      "edit",
+			Usage:     "Edit an entry",
+			ArgsUsage: "<name>",
+			Action:    c.entryEdit,
+			Before:    c.isInitialized,
+			Flags: append([]cli.Flag{
+				&cli.StringFlag{
+					Name:    "name",
+					Aliases: []string{"n"},
+					Usage:   "New name of the entry",
+				},
+				&cli.StringFlag{
+					Name:    "value",
+					Aliases: []string{"v"},
+					Usage:   "New value of the entry",
+				},
+				&cli.StringSliceFlag{
+					Name:  "untag",
+					Usage: "Tag to remove, can be repeated",
+				},
+				&cli.StringSliceFlag{
+					Name:  "remove-field",
+					Usage: "Name of the custom field to remove, can be repeated",
+				},
+			}, append(metadataFlags(), scheduleFlags()...)...),
+		},
+		{
+			Name:      "list",
+			Aliases:   []string{"ls", "l"},
+			Usage:     "List entries",
+			ArgsUsage: "[folder]",
+			Action:    c.entryList,
+			Before:    c.isInitialized,
+			Flags: []cli.Flag{
+				&cli.StringSliceFlag{
+					Name:  "tag",
+					Usage: "List only the entries with the tag, can be repeated",
+				},
+			},
+		},
+		{
+			Name:      "search",
+			Aliases:   []string{"s"},
+			Usage:     "Search entries by name",
+			ArgsUsage: "<query>",
+			Action:    c.entrySearch,
+			Before:    c.isInitialized,
+			Flags: []cli.Flag{
+				&cli.BoolFlag{
+					Name:    "meta",
+					Aliases: []string{"m"},
+					Usage:   "Match the non-secret metadata as well",
+				},
+				&cli.StringSliceFlag{
+					Name:    "type",
+					Aliases: []string{"t"},
+					Usage:   "Type of the entries. Should be one of: text, file, image or card",
+				},
+				&cli.TimestampFlag{
+					Name:   "since",
+					Usage:  "Entries modified on or after the date, e.g. '2022-12-31'",
+					Layout: dateLayout,
+				},
+				&cli.TimestampFlag{
+					Name:   "until",
+					Usage:  "Entries modified on or before the date, e.g. '2022-12-31'",
+					Layout: dateLayout,
+				},
+				&cli.IntFlag{
+					Name:    "limit",
+					Aliases: []string{"l"},
+					Usage:   "Maximum number of entries",
+				},
+			},
+		},
+		{
+			Name:      "attach",
+			Usage:     "Attach a file to an entry, replacing the attachment with the same name",
+			ArgsUsage: "<entry> <file>",
+			Action:    c.attach,
+			Before:    c.isInitialized,
+			Flags: []cli.Flag{
+				&cli.StringFlag{
+					Name:    "name",
+					Aliases: []string{"n"},
+					Usage:   "Name of the attachment, the name of the file by default",
+				},
+			},
+		},
+		{
+			Name:      "detach",
+			Usage:     "Remove an attachment from an entry",
+			ArgsUsage: "<entry> <attachment>",
+			Action:    c.detach,
+			Before:    c.isInitialized,
+		},
+		{
+			Name:      "attachments",
+			Usage:     "List the attachments of an entry",
+			ArgsUsage: "<entry>",
+			Action:    c.attachments,
+			Before:    c.isInitialized,
+		},
+		{
+			Name:      "download",
+			Usage:     "Save an attachment of an entry to a file",
+			ArgsUsage: "<entry> <attachment>",
+			Action:    c.download,
+			Before:    c.isInitialized,
+			Flags: []cli.Flag{
+				&cli.StringFlag{
+					Name:    "out",
+					Aliases: []string{"o"},
+					Usage:   "File to save the attachment to, or '-' to print it, the name of the attachment by default",
+				},
+			},
+		},
+		{
+			Name:      "move",
+			Aliases:   []string{"mv"},
+			Usage:     "Rename or move an entry or a folder",
+			ArgsUsage: "<entry or folder> <new name>",
+			Action:    c.entryMove,
+			Before:    c.isInitialized,
+		},
+		{
+			Name:      "delete",
+			Aliases:   []string{"rm"},
+			Usage:     "Move an entry to the trash",
+			ArgsUsage: "<name>",
+			Action:    c.entryDelete,
+			Before:    c.isInitialized,
+			Flags: []cli.Flag{
+				&cli.BoolFlag{
+					Name:    "yes",
+					Aliases: []string{"y"},
+					Usage:   "Do not ask for confirmation",
+				},
+			},
+		},
+		{
+			Name:   "trash",
+			Usage:  "List, restore or permanently delete the entries in the trash",
+			Action: c.trashList,
+			Before: c.isInitialized,
+			Subcommands: []*cli.Command{
+				{
+					Name:    "list",
+					Aliases: []string{"ls"},
+					Usage:   "List the entries in the trash",
+					Action:  c.trashList,
+					Before:  c.isInitialized,
+				},
+				{
+					Name:      "restore",
+					Usage:     "Restore an entry from the trash",
+					ArgsUsage: "<name>",
+					Action:    c.trashRestore,
+					Before:    c.isInitialized,
+				},
+				{
+					Name:   "empty",
+					Usage:  "Permanently delete the entries in the trash",
+					Action: c.trashEmpty,
+					Before: c.isInitialized,
+					Flags: []cli.Flag{
+						&cli.BoolFlag{
+							Name:    "yes",
+							Aliases: []string{"y"},
+							Usage:   "Do not ask for confirmation",
+						},
+					},
+				},
+			},
+		},
+		{
+			Name:   "sync",
+			Usage:  "Synchronise entries with the remote server",
+			Action: c.sync,
+			Before: c.isInitialized,
+		},
+		{
+			Name:      "share",
+			Usage:     "Share an entry with another user",
+			ArgsUsage: "<name>",
+			Action:    c.share,
+			Before:    c.isInitialized,
+			Flags: []cli.Flag{
+				&cli.StringFlag{
+					Name:     "with",
+					Aliases:  []string{"w"},
+					Usage:    "Login of the user to share the entry with",
+					Required: true,
+				},
+				&cli.BoolFlag{
+					Name:  "read-only",
+					Usage: "Do not allow the user to modify the entry",
+				},
+			},
+		},
+		{
+			Name:      "unshare",
+			Usage:     "Stop sharing an entry with another user",
+			ArgsUsage: "<name>",
+			Action:    c.unshare,
+			Before:    c.isInitialized,
+			Flags: []cli.Flag{
+				&cli.StringFlag{
+					Name:     "with",
+					Aliases:  []string{"w"},
+					Usage:    "Login of the user to stop sharing the entry with",
+					Required: true,
+				},
+			},
+		},
+		{
+			Name:      "shared",
+			Usage:     "List the entries shared with you or view one of them",
+			ArgsUsage: "[name]",
+			Action:    c.shared,
+			Before:    c.isInitialized,
+			Flags: []cli.Flag{
+				&cli.StringFlag{
+					Name:  "from",
+					Usage: "Login of the owner of the entry",
+				},
+			},
+		},
+		{
+			Name:   "vault",
+			Usage:  "Show or switch the current vault",
+			Action: c.vaultShow,
+			Before: c.isInitialized,
+			Subcommands: []*cli.Command{
+				{
+					Name:      "use",
+					Usage:     "Switch to the personal vault or to a collection of an organization",
+					ArgsUsage: "<personal | org/collection>",
+					Action:    c.vaultUse,
+					Before:    c.isInitialized,
+				},
+			},
+		},
+		{
+			Name:   "org",
+			Usage:  "Manage the organizations and their members",
+			Action: c.orgList,
+			Before: c.isInitialized,
+			Subcommands: []*cli.Command{
+				{
+					Name:    "list",
+					Aliases: []string{"ls"},
+					Usage:   "List your organizations",
+					Action:  c.orgList,
+					Before:  c.isInitialized,
+				},
+				{
+					Name:      "create",
+					Usage:     "Create an organization owned by you",
+					ArgsUsage: "<name>",
+					Action:    c.orgCreate,
+					Before:    c.isInitialized,
+				},
+				{
+					Name:      "delete",
+					Usage:     "Delete an organization without collections",
+					ArgsUsage: "<name>",
+					Action:    c.orgDelete,
+					Before:    c.isInitialized,
+					Flags: []cli.Flag{
+						&cli.BoolFlag{
+							Name:    "yes",
+							Aliases: []string{"y"},
+							Usage:   "Do not ask for confirmation",
+						},
+					},
+				},
+				{
+					Name:      "members",
+					Usage:     "List the members of an organization",
+					ArgsUsage: "<org>",
+					Action:    c.orgMembers,
+					Before:    c.isInitialized,
+				},
+				{
+					Name:      "add",
+					Usage:     "Add a user to an organization or change the role of a member",
+					ArgsUsage: "<org> <login>",
+					Action:    c.orgAdd,
+					Before:    c.isInitialized,
+					Flags: []cli.Flag{
+						&cli.StringFlag{
+							Name:    "role",
+							Aliases: []string{"r"},
+							Usage:   "Role of the member: member, admin or owner",
+							Value:   string(models.RoleMember),
+						},
+					},
+				},
+				{
+					Name:      "remove",
+					Usage:     "Remove a member from an organization",
+					ArgsUsage: "<org> <login>",
+					Action:    c.orgRemove,
+					Before:    c.isInitialized,
+				},
+			},
+		},
+		{
+			Name:   "collection",
+			Usage:  "Manage the collections of an organization",
+			Before: c.isInitialized,
+			Subcommands: []*cli.Command{
+				{
+					Name:      "list",
+					Aliases:   []string{"ls"},
+					Usage:     "List the collections of an organization",
+					ArgsUsage: "<org>",
+					Action:    c.collectionList,
+					Before:    c.isInitialized,
+				},
+				{
+					Name:      "create",
+					Usage:     "Create a collection in an organization",
+					ArgsUsage: "<org> <name>",
+					Action:    c.collectionCreate,
+					Before:    c.isInitialized,
+				},
+				{
+					Name:      "delete",
+					Usage:     "Delete an empty collection of an organization",
+					ArgsUsage: "<org> <name>",
+					Action:    c.collectionDelete,
+					Before:    c.isInitialized,
+					Flags: []cli.Flag{
+						&cli.BoolFlag{
+							Name:    "yes",
+							Aliases: []string{"y"},
+							Usage:   "Do not ask for confirmation",
+						},
+					},
+				},
+			},
+		},
+		{
+			Name:   "audit",
+			Usage:  "Show the audit log of your account",
+			Action: c.audit,
+			Before: c.isInitialized,
+			Flags: []cli.Flag{
+				&cli.StringFlag{
+					Name:    "action",
+					Aliases: []string{"a"},
+					Usage:   "Show only the events of the action, e.g. 'login_failed' or 'item_read'",
+				},
+				&cli.DurationFlag{
+					Name:  "since",
+					Usage: "Show only the events of the last period, e.g. '24h'",
+				},
+				&cli.IntFlag{
+					Name:    "limit",
+					Aliases: []string{"l"},
+					Usage:   "Maximum number of the events to show",
+					Value:   50, //nolint:gomnd
+				},
+			},
 		},
 		{
-			Name:    "delete",
-			Aliases: []string{"rm"},
-			Usage:   "Delete entries",
-			Action:  c.entryDelete,
-			Before:  c.isInitialized,
+			Name:  "emergency",
+			Usage: "Manage the emergency access to the vaults",
+			Subcommands: []*cli.Command{
+				{
+					Name:   "list",
+					Usage:  "List the emergency access granted by you and to you",
+					Action: c.emergencyList,
+					Before: c.isInitialized,
+				},
+				{
+					Name:      "grant",
+					Usage:     "Grant a trusted contact the emergency access to your vault",
+					ArgsUsage: "<login>",
+					Action:    c.emergencyGrant,
+					Before:    c.isInitialized,
+					Flags: []cli.Flag{
+						&cli.DurationFlag{
+							Name:  "wait",
+							Usage: "Waiting period before the requested access is released. The server default is used if not set",
+						},
+					},
+				},
+				{
+					Name:      "revoke",
+					Usage:     "Revoke the emergency access of a trusted contact",
+					ArgsUsage: "<login>",
+					Action:    c.emergencyRevoke,
+					Before:    c.isInitialized,
+				},
+				{
+					Name:      "request",
+					Usage:     "Request the emergency access to the vault of a user",
+					ArgsUsage: "<login>",
+					Action:    c.emergencyRequest,
+					Before:    c.isInitialized,
+				},
+				{
+					Name:      "approve",
+					Usage:     "Release the requested emergency access without waiting",
+					ArgsUsage: "<login>",
+					Action:    c.emergencyApprove,
+					Before:    c.isInitialized,
+				},
+				{
+					Name:      "deny",
+					Usage:     "Deny the requested emergency access or lock the released one",
+					ArgsUsage: "<login>",
+					Action:    c.emergencyDeny,
+					Before:    c.isInitialized,
+				},
+				{
+					Name:      "view",
+					Usage:     "List the entries of the vault of a user or view one of them",
+					ArgsUsage: "<login> [name]",
+					Action:    c.emergencyView,
+					Before:    c.isInitialized,
+				},
+			},
+		},
+		{
+			Name:   "unlock",
+			Usage:  "Unlock the local vault with the master password",
+			Action: c.unlock,
+			Before: c.isCreated,
+			Flags: []cli.Flag{
+				&cli.StringFlag{
+					Name:    "password",
+					Aliases: []string{"p"},
+					Usage:   "Master password. Asked for if not set",
+				},
+				&cli.DurationFlag{
+					Name:  "timeout",
+					Usage: "Lock the vault after the given time of inactivity. The configured timeout is used if not set",
+				},
+			},
+		},
+		{
+			Name:   "lock",
+			Usage:  "Lock the local vault",
+			Action: c.lock,
+		},
+		{
+			Name:   "agent",
+			Usage:  "Run the agent holding the key of the unlocked vault",
+			Action: c.agent,
+			Hidden: true,
+			Flags: []cli.Flag{
+				&cli.StringFlag{
+					Name:     "socket",
+					Usage:    "Path of the Unix socket of the agent",
+					Required: true,
+				},
+				&cli.DurationFlag{
+					Name:  "timeout",
+					Usage: "Idle timeout of the agent",
+				},
+			},
+		},
+		{
+			Name:  "profile",
+			Usage: "Manage the profiles, each with its own remote server, credentials and local database",
+			Subcommands: []*cli.Command{
+				{
+					Name:   "list",
+					Usage:  "List the profiles",
+					Action: c.profileList,
+				},
+				{
+					Name:      "add",
+					Usage:     "Add a profile",
+					ArgsUsage: "<name>",
+					Action:    c.profileAdd,
+					Flags: []cli.Flag{
+						&cli.StringFlag{
+							Name:    "remote",
+							Aliases: []string{"r"},
+							Usage:   "Remote server of the profile",
+						},
+						&cli.BoolFlag{
+							Name:  "use",
+							Usage: "Switch to the profile",
+						},
+					},
+				},
+				{
+					Name:      "use",
+					Usage:     "Switch to a profile",
+					ArgsUsage: "<name>",
+					Action:    c.profileUse,
+				},
+				{
+					Name:      "remove",
+					Usage:     "Remove a profile along with its local database",
+					ArgsUsage: "<name>",
+					Action:    c.profileRemove,
+					Flags: []cli.Flag{
+						&cli.BoolFlag{
+							Name:    "force",
+							Aliases: []string{"f"},
+							Usage:   "Do not ask for confirmation",
+						},
+					},
+				},
+			},
+		},
+		{
+			Name:   "usage",
+			Usage:  "Show the storage usage and the quota",
+			Action: c.usage,
+			Before: c.isInitialized,
+		},
+		{
+			Name:  "2fa",
+			Usage: "Manage the two-factor authentication of the login",
+			Subcommands: []*cli.Command{
+				{
+					Name:   "enable",
+					Usage:  "Enable the one-time codes of an authenticator app as the second factor",
+					Action: c.twoFactorEnable,
+					Before: c.isInitialized,
+					Flags: []cli.Flag{
+						&cli.StringFlag{
+							Name:  "code",
+							Usage: "One-time code confirming the secret. Asked for if not set",
+						},
+					},
+				},
+				{
+					Name:   "disable",
+					Usage:  "Disable the second factor",
+					Action: c.twoFactorDisable,
+					Before: c.isInitialized,
+					Flags: []cli.Flag{
+						&cli.StringFlag{
+							Name:  "code",
+							Usage: "One-time code of the second factor. Asked for if not set",
+						},
+					},
+				},
+			},
+		},
+		{
+			Name:  "admin",
+			Usage: "Manage the users of the server. Requires the admin role",
+			Subcommands: []*cli.Command{
+				{
+					Name:   "users",
+					Usage:  "List the users with their storage usage and quotas",
+					Action: c.adminUsers,
+					Before: c.isInitialized,
+				},
+				{
+					Name:      "usage",
+					Usage:     "Show the storage usage and the quota of a user",
+					ArgsUsage: "<login>",
+					Action:    c.adminUsage,
+					Before:    c.isInitialized,
+				},
+				{
+					Name:      "disable",
+					Usage:     "Disable the account of a user and log the user out",
+					ArgsUsage: "<login>",
+					Action:    c.adminDisable,
+					Before:    c.isInitialized,
+				},
+				{
+					Name:      "enable",
+					Usage:     "Enable the disabled account of a user",
+					ArgsUsage: "<login>",
+					Action:    c.adminEnable,
+					Before:    c.isInitialized,
+				},
+				{
+					Name:      "logout",
+					Usage:     "Log a user out of all the sessions",
+					ArgsUsage: "<login>",
+					Action:    c.adminLogout,
+					Before:    c.isInitialized,
+				},
+				{
+					Name:      "reset-2fa",
+					Usage:     "Disable the second factor of a user who lost it",
+					ArgsUsage: "<login>",
+					Action:    c.adminResetTwoFactor,
+					Before:    c.isInitialized,
+				},
+				{
+					Name:      "quota",
+					Usage:     "Set the quota of a user. Zero removes the limit",
+					ArgsUsage: "<login>",
+					Action:    c.adminQuota,
+					Before:    c.isInitialized,
+					Flags: []cli.Flag{
+						&cli.Int64Flag{
+							Name:  "bytes",
+							Usage: "Maximum total size of the entries in bytes",
+						},
+						&cli.Int64Flag{
+							Name:  "items",
+							Usage: "Maximum number of the entries",
+						},
+						&cli.Int64Flag{
+							Name:  "item-bytes",
+							Usage: "Maximum size of a single entry in bytes",
+						},
+						&cli.BoolFlag{
+							Name:  "reset",
+							Usage: "Apply the default quota of the server",
+						},
+					},
+				},
+			},
+		},
+		{
+			Name:   "inject",
+			Usage:  "Render a template with the secrets filled in",
+			Action: c.inject,
+			Before: c.isInitialized,
+			Flags: []cli.Flag{
+				&cli.StringFlag{
+					Name:     "in",
+					Aliases:  []string{"i"},
+					Usage:    "Template file, e.g. '{{ secret \"db/password\" }}' or '{{ (secret \"cards/visa\").Number }}'",
+					Required: true,
+				},
+				&cli.StringFlag{
+					Name:    "out",
+					Aliases: []string{"o"},
+					Usage:   "Output file. Stdout is used if not set",
+				},
+			},
 		},
 		{
 			Name:    "version",
@@ -0,0 +1,11 @@
+//go:build !windows
+
+package client
+
+import "syscall"
+
+// detached returns the attributes starting the process in a new
+// session, so that it outlives the terminal of the cli.
+func detached() *syscall.SysProcAttr {
+	return &syscall.SysProcAttr{Setsid: true}
+}
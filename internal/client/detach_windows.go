@@ -0,0 +1,13 @@
+//go:build windows
+
+package client
+
+import "syscall"
+
+// detached returns the attributes starting the process detached from
+// the console of the cli.
+func detached() *syscall.SysProcAttr {
+	const detachedProcess = 0x00000008
+
+	return &syscall.SysProcAttr{CreationFlags: detachedProcess}
+}
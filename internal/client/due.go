@@ -0,0 +1,104 @@
+package client
+
+import (
+	"fmt"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/iryzzh/y-gophkeeper/internal/models"
+	"github.com/urfave/cli/v2"
+)
+
+// defaultDueDays is the default period of the due entries in days.
+const defaultDueDays = 30
+
+// scheduleFlags returns the flags setting the expiry date and the
+// rotation period of an entry.
+func scheduleFlags() []cli.Flag {
+	return []cli.Flag{
+		&cli.StringFlag{
+			Name:  "expires",
+			Usage: "Expiry date of the entry 'YYYY-MM-DD', 'never' to remove it",
+		},
+		&cli.IntFlag{
+			Name:  "rotate-days",
+			Usage: "Rotate the entry every number of days after its last change, 0 to stop",
+		},
+	}
+}
+
+// parseSchedule sets the expiry date and the rotation period of the
+// item set with `scheduleFlags`.
+func parseSchedule(cCtx *cli.Context, it *models.Item) error {
+	if cCtx.IsSet("expires") {
+		switch v := cCtx.String("expires"); v {
+		case "", "never":
+			it.ExpiresAt = nil
+		default:
+			expires, err := time.ParseInLocation(dateLayout, v, time.Local)
+			if err != nil {
+				return fmt.Errorf("invalid expiry date '%v', expected 'YYYY-MM-DD'", v)
+			}
+			it.ExpiresAt = &expires
+		}
+	}
+
+	if cCtx.IsSet("rotate-days") {
+		days := cCtx.Int("rotate-days")
+		if days < 0 {
+			return fmt.Errorf("invalid rotation period %d, expected a number of days", days)
+		}
+		it.RotateDays = days
+	}
+
+	return nil
+}
+
+// due lists the entries of the local vault expiring or due for
+// rotation within the period, the overdue ones first.
+func (c *Client) due(cCtx *cli.Context) error {
+	days := cCtx.Int("within")
+	if days < 0 {
+		return cli.Exit("usage: due [--within <days>] [--json]", 1)
+	}
+
+	userID, err := c.vaultID()
+	if err != nil {
+		return err
+	}
+
+	due, err := c.itemSvc.Due(cCtx.Context, userID, time.Duration(days)*day)
+	if err != nil {
+		return exitError(err)
+	}
+
+	if cCtx.Bool("json") {
+		data, err := c.json.MarshalIndent(due, "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(data))
+
+		return nil
+	}
+
+	if len(due) == 0 {
+		color.Green("✅ nothing expires or is due for rotation in the next %d days", days)
+		return nil
+	}
+
+	now := time.Now()
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0) //nolint:gomnd
+	_, _ = fmt.Fprintln(w, "ENTRY\tREASON\tDATE\tWHEN")
+	for _, d := range due {
+		when := fmt.Sprintf("in %d days", int((d.DueAt.Sub(now)+day-1)/day))
+		if d.Overdue {
+			when = color.RedString("%d days overdue", int(now.Sub(d.DueAt)/day))
+		}
+		_, _ = fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", d.Meta, d.Reason, d.DueAt.Local().Format(dateLayout), when)
+	}
+
+	return w.Flush()
+}
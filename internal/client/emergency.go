@@ -0,0 +1,264 @@
+package client
+
+import (
+	"fmt"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/iryzzh/y-gophkeeper/internal/keys"
+	"github.com/iryzzh/y-gophkeeper/internal/models"
+	"github.com/urfave/cli/v2"
+)
+
+// emergencyGrant grants the user the emergency access to the vault. The
+// vault key, that is the private key of the keypair, is sealed with
+// the public key of the contact, so only the contact can open it once
+// the server releases it.
+func (c *Client) emergencyGrant(cCtx *cli.Context) error {
+	login := cCtx.Args().First()
+	if login == "" {
+		return cli.Exit("usage: emergency grant [--wait duration] <login>", 1)
+	}
+
+	if err := c.clientSvc.RefreshToken(); err != nil {
+		return err
+	}
+
+	if err := c.registerKeys(); err != nil {
+		return err
+	}
+	if err := c.cfg.SaveConfig(); err != nil {
+		return err
+	}
+
+	kp, err := c.keyPair()
+	if err != nil {
+		return err
+	}
+
+	contactKey, err := c.clientSvc.PublicKey(login)
+	if err != nil {
+		return err
+	}
+
+	sealed, err := keys.Seal(kp.Private, contactKey)
+	if err != nil {
+		return err
+	}
+
+	access := &models.EmergencyAccess{Grantee: login, Key: sealed, Wait: cCtx.Duration("wait")}
+	if err = c.clientSvc.Grant(access); err != nil {
+		return exitError(err)
+	}
+
+	color.Green("✅ '%v' was granted the emergency access to your vault!", login)
+
+	return nil
+}
+
+// emergencyList prints the emergency access granted by the user and
+// granted to the user.
+func (c *Client) emergencyList(_ *cli.Context) error {
+	if err := c.clientSvc.RefreshToken(); err != nil {
+		return err
+	}
+
+	granted, err := c.clientSvc.Granted()
+	if err != nil {
+		return err
+	}
+	trusted, err := c.clientSvc.Trusted()
+	if err != nil {
+		return err
+	}
+	if len(granted) == 0 && len(trusted) == 0 {
+		color.Yellow("no emergency access found")
+		return nil
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0) //nolint:gomnd
+	for _, a := range granted {
+		_, _ = fmt.Fprintf(w, "granted to\t%s\t%s\t%s\n", a.Grantee, a.Wait, emergencyStatus(a))
+	}
+	for _, a := range trusted {
+		_, _ = fmt.Fprintf(w, "trusted by\t%s\t%s\t%s\n", a.Grantor, a.Wait, emergencyStatus(a))
+	}
+
+	return w.Flush()
+}
+
+// emergencyRevoke revokes the emergency access granted to the user.
+func (c *Client) emergencyRevoke(cCtx *cli.Context) error {
+	login := cCtx.Args().First()
+	if login == "" {
+		return cli.Exit("usage: emergency revoke <login>", 1)
+	}
+
+	if err := c.clientSvc.RefreshToken(); err != nil {
+		return err
+	}
+
+	if err := c.clientSvc.Revoke(login); err != nil {
+		return exitError(err)
+	}
+
+	color.Green("✅ the emergency access of '%v' was revoked!", login)
+
+	return nil
+}
+
+// emergencyRequest requests the emergency access to the vault of the
+// user.
+func (c *Client) emergencyRequest(cCtx *cli.Context) error {
+	login := cCtx.Args().First()
+	if login == "" {
+		return cli.Exit("usage: emergency request <login>", 1)
+	}
+
+	if err := c.clientSvc.RefreshToken(); err != nil {
+		return err
+	}
+
+	access, err := c.clientSvc.RequestAccess(login)
+	if err != nil {
+		return exitError(err)
+	}
+
+	color.Green("✅ the emergency access to the vault of '%v' was requested!", login)
+	if access.RequestedAt != nil {
+		color.Yellow("it is released at %v unless '%v' denies it",
+			access.RequestedAt.Add(access.Wait).Local().Format(time.RFC822), login)
+	}
+
+	return nil
+}
+
+// emergencyApprove approves the emergency access requested by the user.
+func (c *Client) emergencyApprove(cCtx *cli.Context) error {
+	login := cCtx.Args().First()
+	if login == "" {
+		return cli.Exit("usage: emergency approve <login>", 1)
+	}
+
+	if err := c.clientSvc.RefreshToken(); err != nil {
+		return err
+	}
+
+	if err := c.clientSvc.Approve(login); err != nil {
+		return exitError(err)
+	}
+
+	color.Green("✅ the emergency access of '%v' was approved!", login)
+
+	return nil
+}
+
+// emergencyDeny denies the emergency access requested by the user.
+func (c *Client) emergencyDeny(cCtx *cli.Context) error {
+	login := cCtx.Args().First()
+	if login == "" {
+		return cli.Exit("usage: emergency deny <login>", 1)
+	}
+
+	if err := c.clientSvc.RefreshToken(); err != nil {
+		return err
+	}
+
+	if err := c.clientSvc.Deny(login); err != nil {
+		return exitError(err)
+	}
+
+	color.Green("✅ the emergency access of '%v' was denied!", login)
+
+	return nil
+}
+
+// emergencyView lists the entries of the vault of the user accessed
+// with the approved emergency access or, if the name is given, shows
+// the entry. The vault key is opened with the keypair of this device.
+func (c *Client) emergencyView(cCtx *cli.Context) error {
+	login, name := cCtx.Args().Get(0), cCtx.Args().Get(1)
+	if login == "" {
+		return cli.Exit("usage: emergency view <login> [name]", 1)
+	}
+
+	if err := c.clientSvc.RefreshToken(); err != nil {
+		return err
+	}
+
+	access, err := c.clientSvc.EmergencyAccess(login)
+	if err != nil {
+		return exitError(err)
+	}
+
+	kp, err := c.keyPair()
+	if err != nil {
+		return err
+	}
+	vaultKey, err := kp.Open(access.Key)
+	if err != nil {
+		color.Red("❌ the vault key cannot be opened: %v", err)
+		return cli.Exit("", 1)
+	}
+	grantorKey, err := c.clientSvc.PublicKey(login)
+	if err != nil {
+		return err
+	}
+	grantor, err := keys.NewKeyPair(grantorKey, vaultKey[:])
+	if err != nil {
+		return err
+	}
+
+	items, err := c.clientSvc.EmergencyItems(login)
+	if err != nil {
+		return err
+	}
+
+	if name == "" {
+		if len(items) == 0 {
+			color.Yellow("no entries found")
+			return nil
+		}
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0) //nolint:gomnd
+		for _, v := range items {
+			_, _ = fmt.Fprintf(w, "%s\t%s\n", v.Meta, v.DataType)
+		}
+
+		return w.Flush()
+	}
+
+	for _, v := range items {
+		if v.Meta != name {
+			continue
+		}
+
+		if v.Key != nil && v.ItemData != nil {
+			itemKey, err := grantor.Open(v.Key)
+			if err == nil {
+				v.ItemData.Data, err = keys.Decrypt(v.ItemData.Data, itemKey)
+			}
+			if err != nil {
+				color.Red("❌ entry '%v' cannot be decrypted: %v", name, err)
+				return cli.Exit("", 1)
+			}
+		}
+
+		return viewItem(v)
+	}
+
+	return cli.Exit(fmt.Sprintf("entry '%v' not found", name), 1)
+}
+
+// emergencyStatus returns the status of the emergency access with the
+// time of the release of the requested access.
+func emergencyStatus(a *models.EmergencyAccess) string {
+	if a.Status == models.EmergencyRequested && a.RequestedAt != nil {
+		return fmt.Sprintf("%v, released at %v", a.Status,
+			a.RequestedAt.Add(a.Wait).Local().Format(time.RFC822))
+	}
+
+	return string(a.Status)
+}
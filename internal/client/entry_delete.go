@@ -6,15 +6,20 @@ import (
 	"github.com/fatih/color"
 	"github.com/iryzzh/y-gophkeeper/internal/services/api_client"
 	"github.com/iryzzh/y-gophkeeper/internal/services/item"
-	"github.com/iryzzh/y-gophkeeper/internal/services/token"
+	"github.com/iryzzh/y-gophkeeper/internal/tui"
 	"github.com/pkg/errors"
 	"github.com/urfave/cli/v2"
 )
 
+// entryDelete moves the entry to the trash locally and on the remote
+// server after a confirmation, unless the `--yes` flag is set.
 func (c *Client) entryDelete(cCtx *cli.Context) error {
 	name := cCtx.Args().First()
+	if name == "" {
+		return cli.Exit("usage: delete [--yes] <name>", 1)
+	}
 
-	userID, err := token.ParseUserIDFromToken(c.cfg.API.AT)
+	userID, err := c.vaultID()
 	if err != nil {
 		return err
 	}
@@ -27,6 +32,16 @@ func (c *Client) entryDelete(cCtx *cli.Context) error {
 		return err
 	}
 
+	if !cCtx.Bool("yes") {
+		var confirmed bool
+		if err = tui.AskConfirm(fmt.Sprintf("Move '%v' to the trash?", name), &confirmed); err != nil {
+			return err
+		}
+		if !confirmed {
+			return nil
+		}
+	}
+
 	if err = c.itemSvc.Delete(cCtx.Context, found); err != nil {
 		color.Red("❌ item deletion failed: %v", err)
 		return cli.Exit("", 1)
@@ -40,7 +55,7 @@ func (c *Client) entryDelete(cCtx *cli.Context) error {
 		return err
 	}
 
-	color.Green("✅ item was successfully deleted!")
+	color.Green("✅ item was moved to the trash!")
 
 	return nil
 }
@@ -0,0 +1,116 @@
+package client
+
+import (
+	"fmt"
+
+	"github.com/fatih/color"
+	"github.com/iryzzh/y-gophkeeper/internal/models"
+	"github.com/iryzzh/y-gophkeeper/internal/services/api_client"
+	"github.com/iryzzh/y-gophkeeper/internal/services/item"
+	"github.com/pkg/errors"
+	"github.com/urfave/cli/v2"
+)
+
+func (c *Client) entryEdit(cCtx *cli.Context) error {
+	name := cCtx.Args().First()
+	if name == "" {
+		return cli.Exit("usage: edit <name> [--name <new name>] [--value <value>] [--tag <tag>] [--field <name=value>] [--expires <date>]", 1)
+	}
+
+	userID, err := c.vaultID()
+	if err != nil {
+		return err
+	}
+
+	found, err := c.itemSvc.FindByMetaName(cCtx.Context, userID, name)
+	if errors.Is(err, item.ErrItemNotFound) {
+		return cli.Exit(fmt.Sprintf("entry '%v' not found", name), 1)
+	}
+	if err != nil {
+		return err
+	}
+
+	if cCtx.IsSet("name") {
+		found.Meta = cCtx.String("name")
+	}
+
+	if cCtx.IsSet("value") {
+		if found.DataType == models.EntryTypeCard {
+			return cli.Exit("the value of a card cannot be edited", 1)
+		}
+
+		var data []byte
+		data, err = encodeEntry(&models.Entry{Value: cCtx.String("value"), EntryType: found.DataType})
+		if err != nil {
+			return exitError(err)
+		}
+		found.ItemData.Data = data
+	}
+
+	found.Tags = append(found.Tags, cCtx.StringSlice("tag")...)
+	found.Tags = without(found.Tags, cCtx.StringSlice("untag")...)
+
+	fields, err := parseFields(cCtx)
+	if err != nil {
+		return cli.Exit(err.Error(), 1)
+	}
+	for _, f := range fields {
+		setField(found, f)
+	}
+	for _, removed := range cCtx.StringSlice("remove-field") {
+		for i, f := range found.Fields {
+			if f.Name == removed {
+				found.Fields = append(found.Fields[:i], found.Fields[i+1:]...)
+				break
+			}
+		}
+	}
+
+	if err = parseSchedule(cCtx, found); err != nil {
+		return cli.Exit(err.Error(), 1)
+	}
+
+	if cCtx.IsSet("value") || len(fields) > 0 {
+		c.warnBreached(found)
+	}
+
+	if err = c.itemSvc.Update(cCtx.Context, found); err != nil {
+		return exitError(err)
+	}
+
+	if err = c.clientSvc.RefreshToken(); err != nil {
+		return err
+	}
+
+	remote, err := c.encrypted(found)
+	if err != nil {
+		return err
+	}
+
+	if err = c.clientSvc.Item(remote, api_client.ActionUpdate); err != nil {
+		return err
+	}
+
+	color.Green("✅ item was successfully updated!")
+
+	return nil
+}
+
+// without returns the values except the excluded ones.
+func without(values []string, excluded ...string) []string {
+	var result []string
+	for _, v := range values {
+		keep := true
+		for _, e := range excluded {
+			if v == e {
+				keep = false
+				break
+			}
+		}
+		if keep {
+			result = append(result, v)
+		}
+	}
+
+	return result
+}
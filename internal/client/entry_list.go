@@ -2,39 +2,41 @@ package client
 
 import (
 	"fmt"
-	"strings"
+	"os"
 
-	"github.com/iryzzh/y-gophkeeper/internal/services/token"
+	"github.com/fatih/color"
+	"github.com/iryzzh/y-gophkeeper/internal/models"
+	"github.com/iryzzh/y-gophkeeper/internal/services/item"
+	"github.com/pkg/errors"
 	"github.com/urfave/cli/v2"
 )
 
+// entryList prints the tree of the entries. If a folder is specified,
+// only its subtree is printed.
 func (c *Client) entryList(cCtx *cli.Context) error {
-	userID, err := token.ParseUserIDFromToken(c.cfg.API.AT)
+	folder := cCtx.Args().First()
+
+	userID, err := c.vaultID()
 	if err != nil {
 		return err
 	}
 
-	items, err := c.itemSvc.FindByUserID(cCtx.Context, userID, "1000", "0")
+	items, err := c.itemSvc.Search(cCtx.Context, userID, &models.ItemFilter{
+		Tags:   cCtx.StringSlice("tag"),
+		Folder: folder,
+	})
+	if errors.Is(err, item.ErrItemNotFound) {
+		if folder != "" {
+			return cli.Exit(fmt.Sprintf("folder '%v' not found", folder), 1)
+		}
+		color.Yellow("no entries found")
+		return nil
+	}
 	if err != nil {
 		return err
 	}
 
-	var prev string
-	for _, v := range items.Data {
-		s := strings.Split(v.Meta, `/`)
-		if s[0] != prev {
-			fmt.Printf("%s\n", s[0])
-		}
-		for i := 1; i < len(s); i++ {
-			printListing(s[i], i-1)
-		}
-		prev = s[0]
-	}
+	buildTree(items.Data).print(os.Stdout)
 
 	return nil
 }
-
-func printListing(entry string, depth int) {
-	indent := strings.Repeat("│   ", depth)
-	fmt.Printf("%s└── %s\n", indent, entry)
-}
@@ -0,0 +1,52 @@
+package client
+
+import (
+	"fmt"
+
+	"github.com/fatih/color"
+	"github.com/iryzzh/y-gophkeeper/internal/models"
+	"github.com/iryzzh/y-gophkeeper/internal/services/item"
+	"github.com/pkg/errors"
+	"github.com/urfave/cli/v2"
+)
+
+// entryMove renames or moves an entry or a folder locally and on the
+// remote server. If the remote move fails, the local one is reverted.
+func (c *Client) entryMove(cCtx *cli.Context) error {
+	if cCtx.NArg() != 2 { //nolint:gomnd
+		return cli.Exit("usage: move <entry or folder> <new name>", 1)
+	}
+	move := &models.Move{From: cCtx.Args().Get(0), To: cCtx.Args().Get(1)}
+
+	userID, err := c.vaultID()
+	if err != nil {
+		return err
+	}
+
+	err = c.itemSvc.Move(cCtx.Context, userID, move)
+	if errors.Is(err, item.ErrItemNotFound) {
+		return cli.Exit(fmt.Sprintf("entry or folder '%v' not found", move.From), 1)
+	}
+	if errors.Is(err, item.ErrItemExists) {
+		return cli.Exit(fmt.Sprintf("entry '%v' already exists", move.To), 1)
+	}
+	if err != nil {
+		return exitError(err)
+	}
+
+	if err = c.clientSvc.RefreshToken(); err == nil {
+		err = c.clientSvc.Move(&models.Move{From: move.From, To: move.To})
+	}
+	if err != nil {
+		revert := &models.Move{From: move.To, To: move.From}
+		if revertErr := c.itemSvc.Move(cCtx.Context, userID, revert); revertErr != nil {
+			return errors.Wrap(err, revertErr.Error())
+		}
+
+		return err
+	}
+
+	color.Green("✅ %d item(s) successfully moved!", move.Moved)
+
+	return nil
+}
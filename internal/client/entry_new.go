@@ -3,9 +3,8 @@ package client
 import (
 	"github.com/fatih/color"
 	"github.com/iryzzh/y-gophkeeper/internal/file"
+	"github.com/iryzzh/y-gophkeeper/internal/generator"
 	"github.com/iryzzh/y-gophkeeper/internal/models"
-	"github.com/iryzzh/y-gophkeeper/internal/services/api_client"
-	"github.com/iryzzh/y-gophkeeper/internal/services/token"
 	"github.com/iryzzh/y-gophkeeper/internal/tui"
 	"github.com/urfave/cli/v2"
 )
@@ -18,26 +17,37 @@ func (c *Client) entryNew(cCtx *cli.Context) error {
 	}
 	color.Cyan("📝 creating a new entry")
 
+	var secret *generator.Secret
+	if cCtx.Bool("generate") {
+		if entry.Name == "" || cCtx.IsSet("value") || entry.EntryType != models.EntryTypeText {
+			return cli.Exit("usage: add --generate --name <name> [--length <n> | --passphrase]", 1)
+		}
+		var err error
+		if secret, err = generateSecret(cCtx); err != nil {
+			return err
+		}
+		entry.Value = secret.Value
+	}
+
 	if !cCtx.IsSet("name") && !cCtx.IsSet("value") && !cCtx.IsSet("type") {
 		if err := tui.AskEntry(entry); err != nil {
 			return err
 		}
 	}
 
-	userID, err := token.ParseUserIDFromToken(c.cfg.API.AT)
+	userID, err := c.vaultID()
 	if err != nil {
 		return err
 	}
 
-	var data []byte
-	if entry.EntryType == models.EntryTypeImage || entry.EntryType == models.EntryTypeFile {
-		data, err = file.Encode(entry.Value)
-		if err != nil {
-			color.Red("❌ %v", err)
-			return cli.Exit("", 1)
-		}
-	} else {
-		data = entry.EncodeBytes()
+	fields, err := parseFields(cCtx)
+	if err != nil {
+		return cli.Exit(err.Error(), 1)
+	}
+
+	data, err := encodeEntry(entry)
+	if err != nil {
+		return exitError(err)
 	}
 
 	item := &models.Item{
@@ -47,23 +57,42 @@ func (c *Client) entryNew(cCtx *cli.Context) error {
 		ItemData: &models.ItemData{
 			Data: data,
 		},
+		Tags:   cCtx.StringSlice("tag"),
+		Fields: fields,
+	}
+	if err = parseSchedule(cCtx, item); err != nil {
+		return cli.Exit(err.Error(), 1)
 	}
 
+	c.warnBreached(item)
+
 	err = c.itemSvc.Create(cCtx.Context, item)
 	if err != nil {
-		color.Red("❌ %v", err)
-		return cli.Exit("", 1)
+		return exitError(err)
 	}
 
 	if err = c.clientSvc.RefreshToken(); err != nil {
 		return err
 	}
 
-	if err = c.clientSvc.Item(item, api_client.ActionNew); err != nil {
+	if err = c.push(cCtx.Context, item); err != nil {
 		return err
 	}
 
 	color.Green("✅ item was successfully created!")
+	if secret != nil {
+		printEntropy(secret)
+	}
 
 	return nil
 }
+
+// encodeEntry returns the data of the entry: the content of the file
+// for files and images, the value for the text entries.
+func encodeEntry(entry *models.Entry) ([]byte, error) {
+	if entry.EntryType == models.EntryTypeImage || entry.EntryType == models.EntryTypeFile {
+		return file.Encode(entry.Value)
+	}
+
+	return entry.EncodeBytes(), nil
+}
@@ -5,8 +5,6 @@ import (
 
 	"github.com/fatih/color"
 	"github.com/iryzzh/y-gophkeeper/internal/models"
-	"github.com/iryzzh/y-gophkeeper/internal/services/api_client"
-	"github.com/iryzzh/y-gophkeeper/internal/services/token"
 	"github.com/iryzzh/y-gophkeeper/internal/tui"
 	"github.com/urfave/cli/v2"
 )
@@ -38,8 +36,7 @@ func (c *Client) entryNewCard(cCtx *cli.Context) (err error) {
 	}
 
 	if err = card.Validate(); err != nil {
-		color.Red("❌ %v", err)
-		return cli.Exit("", 1)
+		return exitError(err)
 	}
 
 	var data []byte
@@ -48,11 +45,16 @@ func (c *Client) entryNewCard(cCtx *cli.Context) (err error) {
 	}
 
 	var userID string
-	userID, err = token.ParseUserIDFromToken(c.cfg.API.AT)
+	userID, err = c.vaultID()
 	if err != nil {
 		return err
 	}
 
+	var fields []*models.Field
+	if fields, err = parseFields(cCtx); err != nil {
+		return cli.Exit(err.Error(), 1)
+	}
+
 	item := &models.Item{
 		UserID:   userID,
 		Meta:     meta,
@@ -60,19 +62,20 @@ func (c *Client) entryNewCard(cCtx *cli.Context) (err error) {
 		ItemData: &models.ItemData{
 			Data: data,
 		},
+		Tags:   cCtx.StringSlice("tag"),
+		Fields: fields,
 	}
 
 	err = c.itemSvc.Create(cCtx.Context, item)
 	if err != nil {
-		color.Red("❌ %v", err)
-		return cli.Exit("", 1)
+		return exitError(err)
 	}
 
 	if err = c.clientSvc.RefreshToken(); err != nil {
 		return err
 	}
 
-	if err = c.clientSvc.Item(item, api_client.ActionNew); err != nil {
+	if err = c.push(cCtx.Context, item); err != nil {
 		return err
 	}
 
@@ -0,0 +1,79 @@
+package client
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/iryzzh/y-gophkeeper/internal/models"
+	"github.com/iryzzh/y-gophkeeper/internal/services/item"
+	"github.com/pkg/errors"
+	"github.com/urfave/cli/v2"
+)
+
+// dateLayout is the layout of the dates accepted and printed by the client.
+const dateLayout = "2006-01-02"
+
+func (c *Client) entrySearch(cCtx *cli.Context) error {
+	filter := &models.ItemFilter{
+		Query:        strings.Join(cCtx.Args().Slice(), " "),
+		WithMetadata: cCtx.Bool("meta"),
+		Types:        cCtx.StringSlice("type"),
+		Since:        cCtx.Timestamp("since"),
+		Limit:        cCtx.Int("limit"),
+	}
+
+	for _, t := range filter.Types {
+		switch t {
+		case models.EntryTypeText, models.EntryTypeFile, models.EntryTypeImage, models.EntryTypeCard:
+		default:
+			return cli.Exit(fmt.Sprintf("unknown entry type '%v'", t), 1)
+		}
+	}
+
+	// the whole day is included.
+	if until := cCtx.Timestamp("until"); until != nil {
+		endOfDay := until.Add(24*time.Hour - time.Second)
+		filter.Until = &endOfDay
+	}
+
+	userID, err := c.vaultID()
+	if err != nil {
+		return err
+	}
+
+	items, err := c.itemSvc.Search(cCtx.Context, userID, filter)
+	if errors.Is(err, item.ErrItemNotFound) {
+		color.Yellow("no entries found")
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	printItems(items.Data)
+
+	return nil
+}
+
+// printItems prints the name, type and modification date of the items.
+func printItems(items []*models.Item) {
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0) //nolint:gomnd
+	for _, v := range items {
+		modified := v.CreatedAt
+		if v.UpdatedAt != nil {
+			modified = v.UpdatedAt
+		}
+
+		var date string
+		if modified != nil {
+			date = modified.Format(dateLayout)
+		}
+
+		_, _ = fmt.Fprintf(w, "%s\t%s\t%s\n", v.Meta, v.DataType, date)
+	}
+	_ = w.Flush()
+}
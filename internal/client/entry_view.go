@@ -7,7 +7,6 @@ import (
 	"github.com/iryzzh/y-gophkeeper/internal/config"
 	"github.com/iryzzh/y-gophkeeper/internal/models"
 	"github.com/iryzzh/y-gophkeeper/internal/services/item"
-	"github.com/iryzzh/y-gophkeeper/internal/services/token"
 	"github.com/iryzzh/y-gophkeeper/internal/tui"
 	"github.com/pkg/errors"
 	"github.com/urfave/cli/v2"
@@ -16,7 +15,7 @@ import (
 func (c *Client) entryView(cCtx *cli.Context) error {
 	name := cCtx.Args().First()
 
-	userID, err := token.ParseUserIDFromToken(c.cfg.API.AT)
+	userID, err := c.vaultID()
 	if err != nil {
 		return err
 	}
@@ -30,11 +29,15 @@ func (c *Client) entryView(cCtx *cli.Context) error {
 		return err
 	}
 
-	var data []byte
-	data, err = foundItem.ItemData.DecodeDataToBytes()
-	if err != nil {
-		return err
-	}
+	return viewItem(foundItem)
+}
+
+// viewItem prints the metadata and the value of the item. The content
+// of files and images is saved to the file chosen by the user.
+func viewItem(foundItem *models.Item) error {
+	var err error
+
+	printMetadata(foundItem)
 
 	switch foundItem.DataType {
 	case models.EntryTypeCard:
@@ -53,6 +56,12 @@ func (c *Client) entryView(cCtx *cli.Context) error {
 
 		return nil
 	case models.EntryTypeImage, models.EntryTypeFile:
+		var data []byte
+		data, err = foundItem.ItemData.DecodeDataToBytes()
+		if err != nil {
+			return err
+		}
+
 		var path string
 		path, err = tui.AskFile("save the file to:", false)
 		if err != nil {
@@ -61,7 +70,12 @@ func (c *Client) entryView(cCtx *cli.Context) error {
 
 		return os.WriteFile(path, data, config.FilePermission)
 	default:
-		fmt.Printf("value: %v\n", data)
+		var value string
+		value, err = foundItem.ItemData.DecodeDataToString()
+		if err != nil {
+			return err
+		}
+		fmt.Printf("value: %v\n", value)
 	}
 
 	return nil
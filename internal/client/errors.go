@@ -0,0 +1,105 @@
+package client
+
+import (
+	"fmt"
+	"net/url"
+
+	"github.com/fatih/color"
+	"github.com/iryzzh/y-gophkeeper/internal/agent"
+	"github.com/iryzzh/y-gophkeeper/internal/config"
+	"github.com/iryzzh/y-gophkeeper/internal/services/api_client"
+	"github.com/pkg/errors"
+	"github.com/urfave/cli/v2"
+)
+
+// Exit codes of the cli, following sysexits(3).
+const (
+	exitFailure     = 1
+	exitValidation  = 65 // EX_DATAERR
+	exitNotFound    = 66 // EX_NOINPUT
+	exitUnavailable = 69 // EX_UNAVAILABLE
+	exitConflict    = 73 // EX_CANTCREAT
+	exitTempFail    = 75 // EX_TEMPFAIL
+	exitNoPerm      = 77 // EX_NOPERM
+)
+
+// exitError prints the friendly message of the error and returns the
+// exit error with the exit code matching it.
+func exitError(err error) error {
+	color.Red("❌ %v", friendlyError(err))
+
+	return cli.Exit("", exitCode(err))
+}
+
+// friendlyError returns the message of the error for the user.
+func friendlyError(err error) string {
+	if errors.Is(err, agent.ErrLocked) {
+		return "the vault is locked, please run 'unlock'"
+	}
+
+	var urlErr *url.Error
+	if errors.As(err, &urlErr) {
+		return fmt.Sprintf("the remote server is unreachable: %v", urlErr.Err)
+	}
+
+	var e *api_client.Error
+	if !errors.As(err, &e) {
+		return err.Error()
+	}
+
+	switch {
+	case errors.Is(e, api_client.ErrUnauthorized) && e.Op == "login":
+		return "the login, the password or the one-time code is invalid"
+	case errors.Is(e, api_client.ErrOTPRequired):
+		return "the one-time code of the second factor is required"
+	case errors.Is(e, api_client.ErrUnauthorized):
+		return "you are not logged in or the session has expired, please run 'auth'"
+	case errors.Is(e, api_client.ErrForbidden) && e.Op == "login":
+		return fmt.Sprintf("the login is refused: %s", e.Message)
+	case errors.Is(e, api_client.ErrForbidden):
+		return fmt.Sprintf("you are not allowed to %s: %s", e.Op, e.Message)
+	case errors.Is(e, api_client.ErrRateLimited):
+		if e.RetryAfter > 0 {
+			return fmt.Sprintf("too many requests, please retry in %v", e.RetryAfter)
+		}
+		return "too many requests, please retry later"
+	case errors.Is(e, api_client.ErrQuotaExceeded):
+		return fmt.Sprintf("%s, see 'usage'", e.Message)
+	case errors.Is(e, api_client.ErrServer):
+		if e.RequestID != "" {
+			return fmt.Sprintf("the remote server failed to %s (request id: %s)", e.Op, e.RequestID)
+		}
+		return fmt.Sprintf("the remote server failed to %s", e.Op)
+	}
+
+	return e.Error()
+}
+
+// exitCode returns the exit code matching the error.
+func exitCode(err error) int {
+	var urlErr *url.Error
+	if errors.As(err, &urlErr) {
+		return exitUnavailable
+	}
+
+	switch {
+	case errors.Is(err, api_client.ErrUnauthorized), errors.Is(err, api_client.ErrOTPRequired),
+		errors.Is(err, api_client.ErrForbidden),
+		errors.Is(err, agent.ErrLocked), errors.Is(err, errWrongPassword):
+		return exitNoPerm
+	case errors.Is(err, api_client.ErrNotFound), errors.Is(err, config.ErrProfileNotFound):
+		return exitNotFound
+	case errors.Is(err, api_client.ErrConflict), errors.Is(err, api_client.ErrPublicKeyExists),
+		errors.Is(err, api_client.ErrQuotaExceeded), errors.Is(err, config.ErrProfileExists):
+		return exitConflict
+	case errors.Is(err, api_client.ErrValidation), errors.Is(err, config.ErrInvalidProfile),
+		errors.Is(err, config.ErrDefaultProfile), errors.Is(err, errSameMasterPassword):
+		return exitValidation
+	case errors.Is(err, api_client.ErrRateLimited):
+		return exitTempFail
+	case errors.Is(err, api_client.ErrServer):
+		return exitUnavailable
+	}
+
+	return exitFailure
+}
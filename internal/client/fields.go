@@ -0,0 +1,82 @@
+package client
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/iryzzh/y-gophkeeper/internal/models"
+	"github.com/urfave/cli/v2"
+)
+
+// metadataFlags returns the flags setting the tags and the custom
+// fields of an entry.
+func metadataFlags() []cli.Flag {
+	return []cli.Flag{
+		&cli.StringSliceFlag{
+			Name:  "tag",
+			Usage: "Tag of the entry, can be repeated",
+		},
+		&cli.StringSliceFlag{
+			Name:    "field",
+			Aliases: []string{"f"},
+			Usage:   "Custom field 'name=value', e.g. 'username=alice', can be repeated",
+		},
+		&cli.StringSliceFlag{
+			Name:  "secret-field",
+			Usage: "Custom secret field 'name=value', can be repeated",
+		},
+	}
+}
+
+// parseFields parses the custom fields set with `metadataFlags`.
+func parseFields(cCtx *cli.Context) ([]*models.Field, error) {
+	var fields []*models.Field
+	for _, flag := range []string{"field", "secret-field"} {
+		for _, v := range cCtx.StringSlice(flag) {
+			name, value, ok := strings.Cut(v, "=")
+			if !ok || strings.TrimSpace(name) == "" {
+				return nil, fmt.Errorf("invalid field '%v', expected 'name=value'", v)
+			}
+			fields = append(fields, &models.Field{
+				Name:   strings.TrimSpace(name),
+				Value:  value,
+				Secret: flag == "secret-field",
+			})
+		}
+	}
+
+	return fields, nil
+}
+
+// setField sets the value of the field with the same name or adds
+// the field to the item.
+func setField(it *models.Item, field *models.Field) {
+	for _, f := range it.Fields {
+		if f.Name == field.Name {
+			*f = *field
+			return
+		}
+	}
+
+	it.Fields = append(it.Fields, field)
+}
+
+// printMetadata prints the tags, the custom fields and the schedule
+// of the item.
+func printMetadata(it *models.Item) {
+	if len(it.Tags) > 0 {
+		fmt.Printf("tags: %s\n", strings.Join(it.Tags, ", "))
+	}
+	for _, f := range it.Fields {
+		fmt.Printf("%s: %s\n", f.Name, f.Value)
+	}
+	if it.ExpiresAt != nil {
+		fmt.Printf("expires: %s\n", it.ExpiresAt.Local().Format(dateLayout))
+	}
+	if it.RotateDays > 0 {
+		fmt.Printf("rotate every: %d days\n", it.RotateDays)
+	}
+	for _, a := range it.Attachments {
+		fmt.Printf("attachment: %s (%s)\n", a.Name, formatBytes(a.Size))
+	}
+}
@@ -0,0 +1,112 @@
+package client
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/fatih/color"
+	"github.com/iryzzh/y-gophkeeper/internal/generator"
+	"github.com/urfave/cli/v2"
+)
+
+// generatorFlags returns the flags of the generated passwords and
+// passphrases.
+func generatorFlags() []cli.Flag {
+	return []cli.Flag{
+		&cli.IntFlag{
+			Name:    "length",
+			Aliases: []string{"l"},
+			Usage:   "Length of the password",
+			Value:   generator.DefaultPassword.Length,
+		},
+		&cli.BoolFlag{
+			Name:  "no-lower",
+			Usage: "Exclude the lowercase letters",
+		},
+		&cli.BoolFlag{
+			Name:  "no-upper",
+			Usage: "Exclude the uppercase letters",
+		},
+		&cli.BoolFlag{
+			Name:  "no-digits",
+			Usage: "Exclude the digits",
+		},
+		&cli.BoolFlag{
+			Name:  "no-symbols",
+			Usage: "Exclude the symbols",
+		},
+		&cli.BoolFlag{
+			Name:  "no-ambiguous",
+			Usage: "Exclude the characters easily confused with others, such as 'l', '1' and 'I'",
+		},
+		&cli.BoolFlag{
+			Name:  "passphrase",
+			Usage: "Generate a passphrase of the words of a diceware wordlist instead of a password",
+		},
+		&cli.IntFlag{
+			Name:  "words",
+			Usage: "Number of the words of the passphrase",
+			Value: generator.DefaultPassphrase.Words,
+		},
+		&cli.StringFlag{
+			Name:  "separator",
+			Usage: "Separator of the words of the passphrase",
+			Value: generator.DefaultPassphrase.Separator,
+		},
+		&cli.BoolFlag{
+			Name:  "capitalize",
+			Usage: "Capitalize the words of the passphrase",
+		},
+	}
+}
+
+// generate prints a random password or passphrase. The entropy is
+// printed to stderr, so that the output can be piped.
+func (c *Client) generate(cCtx *cli.Context) error {
+	secret, err := generateSecret(cCtx)
+	if err != nil {
+		return err
+	}
+
+	fmt.Println(secret.Value)
+	printEntropy(secret)
+
+	return nil
+}
+
+// generateSecret generates the password or the passphrase set with
+// `generatorFlags`.
+func generateSecret(cCtx *cli.Context) (*generator.Secret, error) {
+	var secret *generator.Secret
+	var err error
+	if cCtx.Bool("passphrase") {
+		secret, err = generator.Passphrase(generator.PassphraseOptions{
+			Words:      cCtx.Int("words"),
+			Separator:  cCtx.String("separator"),
+			Capitalize: cCtx.Bool("capitalize"),
+		})
+	} else {
+		secret, err = generator.Password(generator.PasswordOptions{
+			Length:      cCtx.Int("length"),
+			Lower:       !cCtx.Bool("no-lower"),
+			Upper:       !cCtx.Bool("no-upper"),
+			Digits:      !cCtx.Bool("no-digits"),
+			Symbols:     !cCtx.Bool("no-symbols"),
+			NoAmbiguous: cCtx.Bool("no-ambiguous"),
+		})
+	}
+	if err != nil {
+		return nil, cli.Exit(err.Error(), exitValidation)
+	}
+
+	return secret, nil
+}
+
+// printEntropy prints the entropy of the secret to stderr.
+func printEntropy(secret *generator.Secret) {
+	c := color.New(color.FgCyan)
+	if secret.Entropy < 64 { //nolint:gomnd
+		c = color.New(color.FgYellow)
+	}
+	_, _ = c.Fprintf(os.Stderr, "🎲 %.1f bits of entropy\n", secret.Entropy)
+}
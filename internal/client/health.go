@@ -0,0 +1,120 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/iryzzh/y-gophkeeper/internal/health"
+	"github.com/iryzzh/y-gophkeeper/internal/models"
+	"github.com/iryzzh/y-gophkeeper/internal/services/item"
+	"github.com/pkg/errors"
+	"github.com/urfave/cli/v2"
+)
+
+// day is the unit of the thresholds of the health report.
+const day = 24 * time.Hour
+
+// healthKinds are the kinds of the issues in the order they are
+// printed.
+var healthKinds = []string{health.KindWeak, health.KindReused, health.KindOld, health.KindExpiring, health.KindExpired}
+
+// vaultHealth prints the health report of the local vault: the weak,
+// reused and old passwords and the cards expiring soon.
+func (c *Client) vaultHealth(cCtx *cli.Context) error {
+	userID, err := c.vaultID()
+	if err != nil {
+		return err
+	}
+
+	items, err := c.allItems(cCtx.Context, userID, &models.ItemFilter{
+		Types: []string{models.EntryTypeText, models.EntryTypeCard},
+	})
+	if err != nil {
+		return exitError(err)
+	}
+
+	report, err := health.Check(items, health.Options{
+		MinEntropy:   cCtx.Float64("min-entropy"),
+		MaxAge:       time.Duration(cCtx.Int("max-age")) * day,
+		ExpiryWindow: time.Duration(cCtx.Int("expiry-window")) * day,
+	})
+	if err != nil {
+		return err
+	}
+
+	if cCtx.Bool("json") {
+		data, err := c.json.MarshalIndent(report, "", "  ")
+		if err != nil {
+			return err
+		}
+		fmt.Println(string(data))
+
+		return nil
+	}
+
+	printHealth(report)
+
+	return nil
+}
+
+// printHealth prints the score and the issues of the report.
+func printHealth(report *health.Report) {
+	scoreColor := color.GreenString
+	switch {
+	case report.Score < 50: //nolint:gomnd
+		scoreColor = color.RedString
+	case report.Score < 80: //nolint:gomnd
+		scoreColor = color.YellowString
+	}
+	fmt.Printf("🩺 health score: %s (%d of %d entries are healthy)\n",
+		scoreColor("%d/100", report.Score), report.Healthy, report.Entries)
+
+	if len(report.Issues) == 0 {
+		color.Green("✅ no issues found")
+		return
+	}
+
+	summary := make([]string, 0, len(healthKinds))
+	for _, kind := range healthKinds {
+		summary = append(summary, fmt.Sprintf("%s: %d", kind, report.Summary[kind]))
+	}
+	fmt.Printf("%s\n\n", strings.Join(summary, "  "))
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0) //nolint:gomnd
+	_, _ = fmt.Fprintln(w, "ISSUE\tENTRY\tFIELD\tDETAIL")
+	for _, kind := range healthKinds {
+		for _, issue := range report.Issues {
+			if issue.Kind == kind {
+				_, _ = fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", issue.Kind, issue.Entry, issue.Field, issue.Detail)
+			}
+		}
+	}
+	_ = w.Flush()
+}
+
+// allItems returns all the items matching the filter, page by page.
+func (c *Client) allItems(ctx context.Context, userID string, filter *models.ItemFilter) ([]*models.Item, error) {
+	const limit = 1000
+
+	var all []*models.Item
+	filter.Limit = limit
+	for filter.Offset = 0; ; filter.Offset += limit {
+		items, err := c.itemSvc.Search(ctx, userID, filter)
+		if errors.Is(err, item.ErrItemNotFound) {
+			return all, nil
+		}
+		if err != nil {
+			return nil, err
+		}
+
+		all = append(all, items.Data...)
+		if len(items.Data) < limit {
+			return all, nil
+		}
+	}
+}
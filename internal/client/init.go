@@ -7,13 +7,24 @@ import (
 	"path/filepath"
 
 	"github.com/fatih/color"
+	"github.com/iryzzh/y-gophkeeper/internal/config"
 	"github.com/iryzzh/y-gophkeeper/internal/models"
 	"github.com/iryzzh/y-gophkeeper/internal/tui"
 	"github.com/urfave/cli/v2"
 )
 
-// isInitialized checks for existing configuration.
+// isInitialized checks for existing configuration and reads the
+// credentials, which needs the vault to be unlocked.
 func (c *Client) isInitialized(cCtx *cli.Context) error {
+	if err := c.isCreated(cCtx); err != nil {
+		return err
+	}
+
+	return c.cfg.LoadCredentials()
+}
+
+// isCreated checks for existing configuration.
+func (c *Client) isCreated(cCtx *cli.Context) error {
 	printMsg := func() {
 		executable, _ := os.Executable()
 		base := filepath.Base(executable)
@@ -24,7 +35,11 @@ func (c *Client) isInitialized(cCtx *cli.Context) error {
 			name = base
 		}
 		fmt.Printf("%v\n", logo)
-		fmt.Printf("Existing configuration not found.\n")
+		if c.cfg.Profile != config.DefaultProfile {
+			fmt.Printf("Existing configuration of profile '%s' not found.\n", c.cfg.Profile)
+		} else {
+			fmt.Printf("Existing configuration not found.\n")
+		}
 		fmt.Printf("☝ Please run '%s init'\n", name)
 	}
 
@@ -64,7 +79,12 @@ func (c *Client) init(cCtx *cli.Context) error {
 		return cli.Exit("usage: init <remote> <auth> <password>", 1)
 	}
 
-	if err := c.initStore(cCtx.Context, initModel); err != nil {
+	masterPassword, err := c.masterPassword(cCtx, initModel.User.Password)
+	if err != nil {
+		return exitError(err)
+	}
+
+	if err = c.initStore(cCtx.Context, initModel, masterPassword); err != nil {
 		return err
 	}
 
@@ -72,7 +92,9 @@ func (c *Client) init(cCtx *cli.Context) error {
 	return nil
 }
 
-func (c *Client) initStore(ctx context.Context, initModel *models.Init) error {
+// initStore signs up on the remote server and creates the local vault
+// locked with the master password.
+func (c *Client) initStore(ctx context.Context, initModel *models.Init, masterPassword string) error {
 	usersExist, err := c.store.IsUsersExist()
 	if err != nil {
 		return err
@@ -80,6 +102,7 @@ func (c *Client) initStore(ctx context.Context, initModel *models.Init) error {
 
 	if usersExist {
 		color.Red("❌ The store has already been initialized")
+		fmt.Printf("☝ Use 'profile add' to keep another vault side by side\n")
 		var confirm bool
 		if err = tui.AskConfirm("continue?", &confirm); err != nil {
 			return err
@@ -92,6 +115,12 @@ func (c *Client) initStore(ctx context.Context, initModel *models.Init) error {
 
 	c.clientSvc.SetBaseURL(initModel.Remote)
 
+	// the vault is unlocked first, so that the credentials are saved
+	// encrypted with its key.
+	if err = c.unlockVault(ctx, masterPassword, 0); err != nil {
+		return err
+	}
+
 	if err = c.clientSvc.Signup(initModel.User); err != nil {
 		return err
 	}
@@ -100,5 +129,9 @@ func (c *Client) initStore(ctx context.Context, initModel *models.Init) error {
 		return err
 	}
 
+	if err = c.registerKeys(); err != nil {
+		return err
+	}
+
 	return c.cfg.SaveConfig()
 }
@@ -0,0 +1,56 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"os"
+
+	"github.com/fatih/color"
+	"github.com/iryzzh/y-gophkeeper/internal/config"
+	"github.com/iryzzh/y-gophkeeper/internal/inject"
+	"github.com/urfave/cli/v2"
+)
+
+// inject renders the template with the secrets from the local
+// store and writes the result to the output file or to stdout.
+func (c *Client) inject(cCtx *cli.Context) error {
+	in, out := cCtx.String("in"), cCtx.String("out")
+
+	userID, err := c.vaultID()
+	if err != nil {
+		return err
+	}
+
+	src, err := os.Open(in)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = src.Close() }()
+
+	resolve := func(ctx context.Context, name string) (interface{}, error) {
+		it, err := c.itemSvc.FindByMetaName(ctx, userID, name)
+		if err != nil {
+			return nil, err
+		}
+
+		return secretValue(it)
+	}
+
+	var buf bytes.Buffer
+	if err = inject.Render(cCtx.Context, in, src, &buf, resolve); err != nil {
+		return exitError(err)
+	}
+
+	if out == "" {
+		_, err = buf.WriteTo(os.Stdout)
+		return err
+	}
+
+	if err = os.WriteFile(out, buf.Bytes(), config.FilePermission); err != nil {
+		return err
+	}
+
+	color.Green("✅ %v was successfully rendered to %v", in, out)
+
+	return nil
+}
@@ -0,0 +1,114 @@
+package client
+
+import (
+	"encoding/base64"
+
+	"github.com/fatih/color"
+	"github.com/iryzzh/y-gophkeeper/internal/config"
+	"github.com/iryzzh/y-gophkeeper/internal/keys"
+	"github.com/iryzzh/y-gophkeeper/internal/models"
+	"github.com/iryzzh/y-gophkeeper/internal/services/api_client"
+	"github.com/pkg/errors"
+)
+
+// errNoKeyPair is returned when the configuration has no keypair.
+var errNoKeyPair = errors.New("no keypair found, please run 'auth'")
+
+// keyPair returns the keypair of the user from the configuration.
+func (c *Client) keyPair() (*keys.KeyPair, error) {
+	if c.cfg.Keys.Public == "" || c.cfg.Keys.Private == "" {
+		return nil, errNoKeyPair
+	}
+
+	public, err := base64.StdEncoding.DecodeString(c.cfg.Keys.Public)
+	if err != nil {
+		return nil, err
+	}
+
+	private, err := base64.StdEncoding.DecodeString(c.cfg.Keys.Private)
+	if err != nil {
+		return nil, err
+	}
+
+	return keys.NewKeyPair(public, private)
+}
+
+// registerKeys generates the keypair of the user unless the
+// configuration has one and registers the public key on the remote
+// server. The configuration is expected to be saved by the caller.
+func (c *Client) registerKeys() error {
+	kp, err := c.keyPair()
+	if errors.Is(err, errNoKeyPair) {
+		if kp, err = keys.Generate(); err != nil {
+			return err
+		}
+		c.cfg.Keys = config.Keys{
+			Public:  base64.StdEncoding.EncodeToString(kp.Public[:]),
+			Private: base64.StdEncoding.EncodeToString(kp.Private[:]),
+		}
+	}
+	if err != nil {
+		return err
+	}
+
+	err = c.clientSvc.SetPublicKey(kp.Public[:])
+	if errors.Is(err, api_client.ErrPublicKeyExists) {
+		color.Yellow("⚠️ %v: the entries shared with you cannot be decrypted with the keypair of this device", err)
+		return nil
+	}
+
+	return err
+}
+
+// itemKey opens the item key of the shared item.
+func (c *Client) itemKey(it *models.Item) (*[keys.KeySize]byte, error) {
+	kp, err := c.keyPair()
+	if err != nil {
+		return nil, err
+	}
+
+	return kp.Open(it.Key)
+}
+
+// encrypted returns a copy of the shared item with the data encrypted
+// with the item key, or the item itself if it is not shared.
+func (c *Client) encrypted(it *models.Item) (*models.Item, error) {
+	if it.Key == nil || it.ItemData == nil {
+		return it, nil
+	}
+
+	itemKey, err := c.itemKey(it)
+	if err != nil {
+		return nil, err
+	}
+
+	data, err := keys.Encrypt(it.ItemData.Data, itemKey)
+	if err != nil {
+		return nil, err
+	}
+
+	encrypted := *it
+	encrypted.ItemData = &models.ItemData{ID: it.ItemData.ID, Data: data}
+
+	return &encrypted, nil
+}
+
+// decrypt decrypts the data of the shared item in place.
+func (c *Client) decrypt(it *models.Item) error {
+	if it.Key == nil || it.ItemData == nil {
+		return nil
+	}
+
+	itemKey, err := c.itemKey(it)
+	if err != nil {
+		return err
+	}
+
+	data, err := keys.Decrypt(it.ItemData.Data, itemKey)
+	if err != nil {
+		return err
+	}
+	it.ItemData.Data = data
+
+	return nil
+}
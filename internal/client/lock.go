@@ -0,0 +1,273 @@
+package client
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/iryzzh/y-gophkeeper/internal/agent"
+	"github.com/iryzzh/y-gophkeeper/internal/config"
+	"github.com/iryzzh/y-gophkeeper/internal/keys"
+	"github.com/iryzzh/y-gophkeeper/internal/services/token"
+	"github.com/iryzzh/y-gophkeeper/internal/tui"
+	"github.com/pkg/errors"
+	"github.com/urfave/cli/v2"
+)
+
+// lockCheck is the value encrypted with the key of the vault to verify
+// the master password.
+const lockCheck = "gophkeeper"
+
+// agentStartTimeout limits the time waiting for the started agent.
+const agentStartTimeout = 5 * time.Second
+
+// errWrongPassword is returned when the master password does not
+// match the one the vault is locked with.
+var errWrongPassword = errors.New("the master password is wrong")
+
+// errSameMasterPassword is returned when the master password of a new
+// vault is the password of the account, which the remote server
+// receives.
+var errSameMasterPassword = errors.New("the master password must differ from the account password")
+
+// masterPassword returns the master password of the vault set with the
+// `master-password` flag, or asks for it. The master password of a new
+// vault must differ from the password of the account, so that the
+// remote server never receives the secret the vault is encrypted with.
+func (c *Client) masterPassword(cCtx *cli.Context, accountPassword string) (string, error) {
+	password := cCtx.String("master-password")
+	if password == "" {
+		var err error
+		if password, err = tui.AskPassword("Master password:"); err != nil {
+			return "", err
+		}
+	}
+
+	if password == accountPassword {
+		if c.cfg.Lock.Salt == "" {
+			return "", errSameMasterPassword
+		}
+		color.Yellow("⚠️ the master password is the account password, the remote server receives it")
+	}
+
+	return password, nil
+}
+
+// unlock derives the key of the vault from the master password and
+// hands it over to the agent, starting the agent if it is not running.
+// The first unlock of a vault sets its master password.
+func (c *Client) unlock(cCtx *cli.Context) error {
+	password := cCtx.String("password")
+	if password == "" {
+		var err error
+		if password, err = tui.AskPassword("Master password:"); err != nil {
+			return err
+		}
+	}
+
+	if err := c.unlockVault(cCtx.Context, password, cCtx.Duration("timeout")); err != nil {
+		return exitError(err)
+	}
+
+	color.Green("✅ the vault is unlocked for %v of inactivity", c.lockTimeout(cCtx.Duration("timeout")))
+
+	return nil
+}
+
+// lock wipes the key of the vault held by the agent and stops it.
+func (c *Client) lock(_ *cli.Context) error {
+	err := agent.Lock(agentSocket(c.cfg))
+	if errors.Is(err, agent.ErrLocked) {
+		color.Yellow("the vault is already locked")
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	color.Green("✅ the vault is locked")
+
+	return nil
+}
+
+// agent runs the agent holding the key of the vault. It is started in
+// the background by `unlock`.
+func (c *Client) agent(cCtx *cli.Context) error {
+	l, err := agent.Listen(cCtx.String("socket"))
+	if err != nil {
+		return err
+	}
+
+	return agent.Serve(cCtx.Context, l, c.lockTimeout(cCtx.Duration("timeout")))
+}
+
+// unlockVault unlocks the vault with the master password and seals
+// the entries saved before the vault was locked.
+func (c *Client) unlockVault(ctx context.Context, password string, timeout time.Duration) error {
+	key, err := c.masterKey(password)
+	if err != nil {
+		return err
+	}
+
+	if err = c.startAgent(key, c.lockTimeout(timeout)); err != nil {
+		return err
+	}
+	c.key = key
+
+	// the credentials kept in the clear are moved to the credentials file
+	if err = c.cfg.LoadCredentials(); err != nil {
+		return err
+	}
+	if err = c.cfg.SaveConfig(); err != nil {
+		return err
+	}
+
+	vaults := map[string]struct{}{c.cfg.Vault.CollectionID: {}}
+	if userID, err := token.ParseUserIDFromToken(c.cfg.API.AT); err == nil {
+		vaults[userID] = struct{}{}
+	}
+	for vaultID := range vaults {
+		if vaultID == "" {
+			continue
+		}
+		sealed, err := c.sealed.SealAll(ctx, vaultID)
+		if err != nil {
+			return err
+		}
+		if sealed > 0 {
+			color.Yellow("🔒 %v existing entries were encrypted", sealed)
+		}
+	}
+
+	return nil
+}
+
+// masterKey derives the key of the vault from the master password and
+// verifies it. The salt and the check are created if the vault has no
+// master password yet, to be saved by the caller.
+func (c *Client) masterKey(password string) (*[keys.KeySize]byte, error) {
+	if c.cfg.Lock.Salt == "" {
+		salt, err := keys.NewSalt()
+		if err != nil {
+			return nil, err
+		}
+		key, err := keys.DeriveKey(password, salt)
+		if err != nil {
+			return nil, err
+		}
+		check, err := keys.Encrypt([]byte(lockCheck), key)
+		if err != nil {
+			return nil, err
+		}
+
+		c.cfg.Lock.Salt = base64.StdEncoding.EncodeToString(salt)
+		c.cfg.Lock.Check = base64.StdEncoding.EncodeToString(check)
+
+		return key, nil
+	}
+
+	salt, err := base64.StdEncoding.DecodeString(c.cfg.Lock.Salt)
+	if err != nil {
+		return nil, err
+	}
+	check, err := base64.StdEncoding.DecodeString(c.cfg.Lock.Check)
+	if err != nil {
+		return nil, err
+	}
+
+	key, err := keys.DeriveKey(password, salt)
+	if err != nil {
+		return nil, err
+	}
+	if value, err := keys.Decrypt(check, key); err != nil || string(value) != lockCheck {
+		return nil, errWrongPassword
+	}
+
+	return key, nil
+}
+
+// startAgent hands the key over to the agent, starting the agent in
+// the background if it is not running.
+func (c *Client) startAgent(key *[keys.KeySize]byte, timeout time.Duration) error {
+	socket := agentSocket(c.cfg)
+
+	err := agent.Set(socket, key, timeout)
+	if !errors.Is(err, agent.ErrLocked) {
+		return err
+	}
+
+	executable, err := os.Executable()
+	if err != nil {
+		return err
+	}
+
+	cmd := exec.Command(executable, "agent", "--socket", socket, "--timeout", timeout.String()) //nolint:gosec
+	cmd.SysProcAttr = detached()
+	if err = cmd.Start(); err != nil {
+		return errors.Wrap(err, "start the agent")
+	}
+	_ = cmd.Process.Release()
+
+	for deadline := time.Now().Add(agentStartTimeout); time.Now().Before(deadline); {
+		time.Sleep(100 * time.Millisecond) //nolint:gomnd
+		if err = agent.Set(socket, key, timeout); !errors.Is(err, agent.ErrLocked) {
+			return err
+		}
+	}
+
+	return errors.New("the agent did not start")
+}
+
+// vaultKey returns the key of the unlocked vault held by the agent.
+func (c *Client) vaultKey() (*[keys.KeySize]byte, error) {
+	if c.key != nil {
+		return c.key, nil
+	}
+
+	key, err := agent.Get(agentSocket(c.cfg))
+	if err != nil {
+		return nil, err
+	}
+	c.key = key
+
+	return key, nil
+}
+
+// agentSocket returns the path of the socket of the agent. Every local
+// vault has its own agent. The socket is placed in the runtime
+// directory of the user, or in the temporary directory if there is none,
+// whose predictable path the agent refuses unless the user owns it.
+func agentSocket(cfg *config.ClientCfg) string {
+	if cfg.Lock.Socket != "" {
+		return cfg.Lock.Socket
+	}
+
+	dsn, _ := filepath.Abs(cfg.DB.DSN)
+	sum := sha256.Sum256([]byte(dsn))
+	name := fmt.Sprintf("agent-%x.sock", sum[:4])
+
+	if dir := config.RuntimeDir(); dir != "" {
+		return filepath.Join(dir, name)
+	}
+
+	return filepath.Join(os.TempDir(), fmt.Sprintf("gophkeeper-%d", os.Getuid()), name)
+}
+
+// lockTimeout returns the given idle timeout of the agent, or the
+// configured one if it is zero.
+func (c *Client) lockTimeout(timeout time.Duration) time.Duration {
+	if timeout > 0 {
+		return timeout
+	}
+	if c.cfg.Lock.Timeout > 0 {
+		return c.cfg.Lock.Timeout
+	}
+
+	return 15 * time.Minute //nolint:gomnd
+}
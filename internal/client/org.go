@@ -0,0 +1,391 @@
+package client
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/fatih/color"
+	"github.com/iryzzh/y-gophkeeper/internal/config"
+	"github.com/iryzzh/y-gophkeeper/internal/models"
+	"github.com/iryzzh/y-gophkeeper/internal/services/token"
+	"github.com/iryzzh/y-gophkeeper/internal/tui"
+	"github.com/urfave/cli/v2"
+)
+
+// personalVault is the name of the personal vault of the user.
+const personalVault = "personal"
+
+// vaultID returns the id of the owner of the entries of the current
+// vault: the collection, or the user for the personal vault.
+func (c *Client) vaultID() (string, error) {
+	if c.cfg.Vault.CollectionID != "" {
+		return c.cfg.Vault.CollectionID, nil
+	}
+
+	return token.ParseUserIDFromToken(c.cfg.API.AT)
+}
+
+// vaultName returns the name of the current vault.
+func (c *Client) vaultName() string {
+	if c.cfg.Vault.CollectionID == "" {
+		return personalVault
+	}
+
+	return c.cfg.Vault.Org + models.FolderSeparator + c.cfg.Vault.Collection
+}
+
+// vaultShow prints the name of the current vault.
+func (c *Client) vaultShow(_ *cli.Context) error {
+	fmt.Println(c.vaultName())
+
+	return nil
+}
+
+// vaultUse switches to the personal vault or to the collection of the
+// organization given as `<org>/<collection>` and pulls its entries
+// from the remote server.
+func (c *Client) vaultUse(cCtx *cli.Context) error {
+	name := cCtx.Args().First()
+	if name == "" {
+		return cli.Exit("usage: vault use <personal | org/collection>", 1)
+	}
+
+	if err := c.clientSvc.RefreshToken(); err != nil {
+		return err
+	}
+
+	vault := config.Vault{}
+	if name != personalVault {
+		orgName, collectionName, ok := strings.Cut(name, models.FolderSeparator)
+		if !ok || orgName == "" || collectionName == "" {
+			return cli.Exit("usage: vault use <personal | org/collection>", 1)
+		}
+
+		o, err := c.findOrg(orgName)
+		if err != nil {
+			return err
+		}
+		collection, err := c.findCollection(o, collectionName)
+		if err != nil {
+			return err
+		}
+
+		vault = config.Vault{Org: o.Name, Collection: collection.Name, CollectionID: collection.ID}
+	}
+
+	c.cfg.Vault = vault
+	c.clientSvc.SetCollection(vault.CollectionID)
+
+	if err := c.pull(cCtx.Context); err != nil {
+		return exitError(err)
+	}
+
+	if err := c.cfg.SaveConfig(); err != nil {
+		return err
+	}
+
+	color.Green("✅ switched to the vault '%v'!", c.vaultName())
+
+	return nil
+}
+
+// orgList prints the organizations of the user with the role of the
+// user in them.
+func (c *Client) orgList(_ *cli.Context) error {
+	if err := c.clientSvc.RefreshToken(); err != nil {
+		return err
+	}
+
+	orgs, err := c.clientSvc.Orgs()
+	if err != nil {
+		return err
+	}
+	if len(orgs) == 0 {
+		color.Yellow("no organizations found")
+		return nil
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0) //nolint:gomnd
+	for _, o := range orgs {
+		_, _ = fmt.Fprintf(w, "%s\t%s\n", o.Name, o.Role)
+	}
+
+	return w.Flush()
+}
+
+// orgCreate creates an organization with the user as its owner.
+func (c *Client) orgCreate(cCtx *cli.Context) error {
+	name := cCtx.Args().First()
+	if name == "" {
+		return cli.Exit("usage: org create <name>", 1)
+	}
+
+	if err := c.clientSvc.RefreshToken(); err != nil {
+		return err
+	}
+
+	if _, err := c.clientSvc.CreateOrg(name); err != nil {
+		return exitError(err)
+	}
+
+	color.Green("✅ organization '%v' was created!", name)
+
+	return nil
+}
+
+// orgDelete deletes the organization after a confirmation, unless the
+// `--yes` flag is set.
+func (c *Client) orgDelete(cCtx *cli.Context) error {
+	name := cCtx.Args().First()
+	if name == "" {
+		return cli.Exit("usage: org delete [--yes] <name>", 1)
+	}
+
+	if err := c.clientSvc.RefreshToken(); err != nil {
+		return err
+	}
+
+	o, err := c.findOrg(name)
+	if err != nil {
+		return err
+	}
+
+	if !cCtx.Bool("yes") {
+		var confirmed bool
+		if err = tui.AskConfirm(fmt.Sprintf("Delete the organization '%v'?", name), &confirmed); err != nil {
+			return err
+		}
+		if !confirmed {
+			return nil
+		}
+	}
+
+	if err = c.clientSvc.DeleteOrg(o.ID); err != nil {
+		return exitError(err)
+	}
+
+	color.Green("✅ organization '%v' was deleted!", name)
+
+	return nil
+}
+
+// orgMembers prints the members of the organization with their roles.
+func (c *Client) orgMembers(cCtx *cli.Context) error {
+	name := cCtx.Args().First()
+	if name == "" {
+		return cli.Exit("usage: org members <org>", 1)
+	}
+
+	if err := c.clientSvc.RefreshToken(); err != nil {
+		return err
+	}
+
+	o, err := c.findOrg(name)
+	if err != nil {
+		return err
+	}
+
+	members, err := c.clientSvc.Members(o.ID)
+	if err != nil {
+		return err
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0) //nolint:gomnd
+	for _, m := range members {
+		_, _ = fmt.Fprintf(w, "%s\t%s\n", m.Login, m.Role)
+	}
+
+	return w.Flush()
+}
+
+// orgAdd adds the user to the organization or changes the role of the
+// member.
+func (c *Client) orgAdd(cCtx *cli.Context) error {
+	name, login := cCtx.Args().Get(0), cCtx.Args().Get(1)
+	if name == "" || login == "" {
+		return cli.Exit("usage: org add [--role member|admin|owner] <org> <login>", 1)
+	}
+
+	if err := c.clientSvc.RefreshToken(); err != nil {
+		return err
+	}
+
+	o, err := c.findOrg(name)
+	if err != nil {
+		return err
+	}
+
+	member := &models.Member{Login: login, Role: models.Role(cCtx.String("role"))}
+	if err = c.clientSvc.SetMember(o.ID, member); err != nil {
+		return exitError(err)
+	}
+
+	color.Green("✅ '%v' is now %v of '%v'!", login, member.Role, name)
+
+	return nil
+}
+
+// orgRemove removes the member from the organization.
+func (c *Client) orgRemove(cCtx *cli.Context) error {
+	name, login := cCtx.Args().Get(0), cCtx.Args().Get(1)
+	if name == "" || login == "" {
+		return cli.Exit("usage: org remove <org> <login>", 1)
+	}
+
+	if err := c.clientSvc.RefreshToken(); err != nil {
+		return err
+	}
+
+	o, err := c.findOrg(name)
+	if err != nil {
+		return err
+	}
+
+	if err = c.clientSvc.RemoveMember(o.ID, login); err != nil {
+		return exitError(err)
+	}
+
+	color.Green("✅ '%v' was removed from '%v'!", login, name)
+
+	return nil
+}
+
+// collectionList prints the collections of the organization.
+func (c *Client) collectionList(cCtx *cli.Context) error {
+	name := cCtx.Args().First()
+	if name == "" {
+		return cli.Exit("usage: collection list <org>", 1)
+	}
+
+	if err := c.clientSvc.RefreshToken(); err != nil {
+		return err
+	}
+
+	o, err := c.findOrg(name)
+	if err != nil {
+		return err
+	}
+
+	collections, err := c.clientSvc.Collections(o.ID)
+	if err != nil {
+		return err
+	}
+	if len(collections) == 0 {
+		color.Yellow("no collections found")
+		return nil
+	}
+
+	for _, collection := range collections {
+		fmt.Println(collection.Name)
+	}
+
+	return nil
+}
+
+// collectionCreate creates a collection in the organization.
+func (c *Client) collectionCreate(cCtx *cli.Context) error {
+	name, collectionName := cCtx.Args().Get(0), cCtx.Args().Get(1)
+	if name == "" || collectionName == "" {
+		return cli.Exit("usage: collection create <org> <name>", 1)
+	}
+
+	if err := c.clientSvc.RefreshToken(); err != nil {
+		return err
+	}
+
+	o, err := c.findOrg(name)
+	if err != nil {
+		return err
+	}
+
+	if _, err = c.clientSvc.CreateCollection(o.ID, collectionName); err != nil {
+		return exitError(err)
+	}
+
+	color.Green("✅ collection '%v' was created in '%v'!", collectionName, name)
+
+	return nil
+}
+
+// collectionDelete deletes the collection of the organization after a
+// confirmation, unless the `--yes` flag is set.
+func (c *Client) collectionDelete(cCtx *cli.Context) error {
+	name, collectionName := cCtx.Args().Get(0), cCtx.Args().Get(1)
+	if name == "" || collectionName == "" {
+		return cli.Exit("usage: collection delete [--yes] <org> <name>", 1)
+	}
+
+	if err := c.clientSvc.RefreshToken(); err != nil {
+		return err
+	}
+
+	o, err := c.findOrg(name)
+	if err != nil {
+		return err
+	}
+	collection, err := c.findCollection(o, collectionName)
+	if err != nil {
+		return err
+	}
+
+	if !cCtx.Bool("yes") {
+		var confirmed bool
+		if err = tui.AskConfirm(fmt.Sprintf("Delete the collection '%v'?", collectionName), &confirmed); err != nil {
+			return err
+		}
+		if !confirmed {
+			return nil
+		}
+	}
+
+	if err = c.clientSvc.DeleteCollection(o.ID, collection.ID); err != nil {
+		return exitError(err)
+	}
+
+	if c.cfg.Vault.CollectionID == collection.ID {
+		c.cfg.Vault = config.Vault{}
+		if err = c.cfg.SaveConfig(); err != nil {
+			return err
+		}
+		color.Yellow("switched to the personal vault")
+	}
+
+	color.Green("✅ collection '%v' was deleted!", collectionName)
+
+	return nil
+}
+
+// findOrg returns the organization of the user with the given name.
+func (c *Client) findOrg(name string) (*models.Org, error) {
+	orgs, err := c.clientSvc.Orgs()
+	if err != nil {
+		return nil, err
+	}
+
+	for _, o := range orgs {
+		if o.Name == name {
+			return o, nil
+		}
+	}
+
+	return nil, cli.Exit(fmt.Sprintf("organization '%v' not found", name), 1)
+}
+
+// findCollection returns the collection of the organization with the
+// given name.
+func (c *Client) findCollection(o *models.Org, name string) (*models.Collection, error) {
+	collections, err := c.clientSvc.Collections(o.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, collection := range collections {
+		if collection.Name == name {
+			return collection, nil
+		}
+	}
+
+	return nil, cli.Exit(fmt.Sprintf("collection '%v' not found in '%v'", name, o.Name), 1)
+}
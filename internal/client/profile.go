@@ -0,0 +1,151 @@
+package client
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"text/tabwriter"
+
+	"github.com/fatih/color"
+	"github.com/iryzzh/y-gophkeeper/internal/agent"
+	"github.com/iryzzh/y-gophkeeper/internal/config"
+	"github.com/iryzzh/y-gophkeeper/internal/tui"
+	"github.com/pkg/errors"
+	"github.com/urfave/cli/v2"
+)
+
+// profileList prints the profiles with their remote servers. The
+// profile in use is marked with an asterisk.
+func (c *Client) profileList(_ *cli.Context) error {
+	profiles, err := config.Profiles()
+	if err != nil {
+		return err
+	}
+	if len(profiles) == 0 {
+		color.Yellow("no profiles found")
+		return nil
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0) //nolint:gomnd
+	_, _ = fmt.Fprintln(w, "\tPROFILE\tREMOTE\tDATABASE")
+	for _, profile := range profiles {
+		cfg, err := config.NewClientConfig(profile)
+		if err != nil {
+			return err
+		}
+
+		remote := cfg.API.Remote
+		if remote == "" {
+			remote = config.NA
+		}
+		var current string
+		if profile == c.cfg.Profile {
+			current = "*"
+		}
+		_, _ = fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", current, profile, remote, cfg.DB.DSN)
+	}
+
+	return w.Flush()
+}
+
+// profileAdd creates a profile with its own configuration, credentials
+// and local database.
+func (c *Client) profileAdd(cCtx *cli.Context) error {
+	profile := cCtx.Args().First()
+	if profile == "" {
+		return cli.Exit("usage: profile add <name> [--remote <url>]", 1)
+	}
+	if config.ProfileExists(profile) {
+		return exitError(config.ErrProfileExists)
+	}
+
+	cfg, err := config.NewClientConfig(profile)
+	if err != nil {
+		return exitError(err)
+	}
+	cfg.API.Remote = cCtx.String("remote")
+	if err = cfg.SaveConfig(); err != nil {
+		return err
+	}
+
+	if cCtx.Bool("use") {
+		if err = config.UseProfile(profile); err != nil {
+			return exitError(err)
+		}
+	}
+
+	color.Green("✅ profile '%v' was added!", profile)
+	fmt.Printf("☝ Please run '%s --profile %s init' or '%s --profile %s auth'\n",
+		executableName(cCtx), profile, executableName(cCtx), profile)
+
+	return nil
+}
+
+// profileUse selects the profile used when none is set with the
+// `--profile` flag or the `GOPHKEEPER_PROFILE` environment variable.
+func (c *Client) profileUse(cCtx *cli.Context) error {
+	profile := cCtx.Args().First()
+	if profile == "" {
+		return cli.Exit("usage: profile use <name>", 1)
+	}
+
+	if err := config.UseProfile(profile); err != nil {
+		return exitError(err)
+	}
+
+	color.Green("✅ switched to profile '%v'", profile)
+
+	return nil
+}
+
+// profileRemove locks and removes a profile along with its local
+// database.
+func (c *Client) profileRemove(cCtx *cli.Context) error {
+	profile := cCtx.Args().First()
+	if profile == "" {
+		return cli.Exit("usage: profile remove <name> [--force]", 1)
+	}
+	if profile == config.DefaultProfile {
+		return exitError(config.ErrDefaultProfile)
+	}
+	if !config.ProfileExists(profile) {
+		return exitError(config.ErrProfileNotFound)
+	}
+
+	if !cCtx.Bool("force") {
+		confirm := false
+		msg := fmt.Sprintf("remove profile '%v' and its local database?", profile)
+		if err := tui.AskConfirm(msg, &confirm); err != nil {
+			return err
+		}
+		if !confirm {
+			color.Yellow("canceled")
+			return nil
+		}
+	}
+
+	cfg, err := config.NewClientConfig(profile)
+	if err != nil {
+		return exitError(err)
+	}
+	if err = agent.Lock(agentSocket(cfg)); err != nil && !errors.Is(err, agent.ErrLocked) {
+		return err
+	}
+
+	if err = config.RemoveProfile(profile); err != nil {
+		return exitError(err)
+	}
+
+	color.Green("✅ profile '%v' was removed!", profile)
+
+	return nil
+}
+
+// executableName returns the name the cli was run with.
+func executableName(cCtx *cli.Context) string {
+	if executable, err := os.Executable(); err == nil {
+		return filepath.Base(executable)
+	}
+
+	return cCtx.App.Name
+}
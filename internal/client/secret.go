@@ -0,0 +1,24 @@
+package client
+
+import (
+	"github.com/iryzzh/y-gophkeeper/internal/models"
+)
+
+// secretValue decodes the item data: cards are returned as
+// `*models.Card`, all other entry types as strings.
+func secretValue(it *models.Item) (interface{}, error) {
+	if it.ItemData == nil {
+		return "", nil
+	}
+
+	if it.DataType == models.EntryTypeCard {
+		card := &models.Card{}
+		if err := card.Decode(it.ItemData.Data); err != nil {
+			return nil, err
+		}
+
+		return card, nil
+	}
+
+	return it.ItemData.DecodeDataToString()
+}
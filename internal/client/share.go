@@ -0,0 +1,190 @@
+package client
+
+import (
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"github.com/fatih/color"
+	"github.com/iryzzh/y-gophkeeper/internal/keys"
+	"github.com/iryzzh/y-gophkeeper/internal/models"
+	"github.com/iryzzh/y-gophkeeper/internal/services/api_client"
+	"github.com/iryzzh/y-gophkeeper/internal/services/item"
+	"github.com/iryzzh/y-gophkeeper/internal/services/token"
+	"github.com/pkg/errors"
+	"github.com/urfave/cli/v2"
+)
+
+// share shares the entry with the user. On the first share the data of
+// the entry is encrypted on the remote server with a new item key, then
+// the item key is sealed with the public key of the recipient.
+func (c *Client) share(cCtx *cli.Context) error {
+	name, login := cCtx.Args().First(), cCtx.String("with")
+	if name == "" || login == "" {
+		return cli.Exit("usage: share --with <login> [--read-only] <name>", 1)
+	}
+	if c.cfg.Vault.CollectionID != "" {
+		return cli.Exit("only the entries of the personal vault can be shared", 1)
+	}
+
+	userID, err := token.ParseUserIDFromToken(c.cfg.API.AT)
+	if err != nil {
+		return err
+	}
+
+	found, err := c.itemSvc.FindByMetaName(cCtx.Context, userID, name)
+	if errors.Is(err, item.ErrItemNotFound) {
+		return cli.Exit(fmt.Sprintf("entry '%v' not found", name), 1)
+	}
+	if err != nil {
+		return err
+	}
+
+	if err = c.clientSvc.RefreshToken(); err != nil {
+		return err
+	}
+
+	if err = c.registerKeys(); err != nil {
+		return err
+	}
+	if err = c.cfg.SaveConfig(); err != nil {
+		return err
+	}
+
+	kp, err := c.keyPair()
+	if err != nil {
+		return err
+	}
+
+	recipientKey, err := c.clientSvc.PublicKey(login)
+	if err != nil {
+		return err
+	}
+
+	var itemKey *[keys.KeySize]byte
+	if found.Key == nil {
+		if itemKey, err = keys.NewItemKey(); err != nil {
+			return err
+		}
+		if found.Key, err = keys.Seal(itemKey, kp.Public[:]); err != nil {
+			return err
+		}
+
+		var remote *models.Item
+		if remote, err = c.encrypted(found); err != nil {
+			return err
+		}
+		if err = c.clientSvc.Item(remote, api_client.ActionUpdate); err != nil {
+			return err
+		}
+		if err = c.itemSvc.Update(cCtx.Context, found); err != nil {
+			return err
+		}
+	} else if itemKey, err = kp.Open(found.Key); err != nil {
+		return err
+	}
+
+	sealed, err := keys.Seal(itemKey, recipientKey)
+	if err != nil {
+		return err
+	}
+
+	if err = c.clientSvc.Share(found.ID, &models.Share{
+		Login:    login,
+		Key:      sealed,
+		ReadOnly: cCtx.Bool("read-only"),
+	}); err != nil {
+		return err
+	}
+
+	color.Green("✅ entry '%v' was shared with '%v'!", name, login)
+
+	return nil
+}
+
+// unshare revokes the access of the user to the entry. The item key is
+// not rotated, so the entry should be changed if the user may have
+// kept a copy of it.
+func (c *Client) unshare(cCtx *cli.Context) error {
+	name, login := cCtx.Args().First(), cCtx.String("with")
+	if name == "" || login == "" {
+		return cli.Exit("usage: unshare --with <login> <name>", 1)
+	}
+	if c.cfg.Vault.CollectionID != "" {
+		return cli.Exit("only the entries of the personal vault can be shared", 1)
+	}
+
+	userID, err := token.ParseUserIDFromToken(c.cfg.API.AT)
+	if err != nil {
+		return err
+	}
+
+	found, err := c.itemSvc.FindByMetaName(cCtx.Context, userID, name)
+	if errors.Is(err, item.ErrItemNotFound) {
+		return cli.Exit(fmt.Sprintf("entry '%v' not found", name), 1)
+	}
+	if err != nil {
+		return err
+	}
+
+	if err = c.clientSvc.RefreshToken(); err != nil {
+		return err
+	}
+
+	if err = c.clientSvc.Unshare(found.ID, login); err != nil {
+		return err
+	}
+
+	color.Green("✅ entry '%v' is no longer shared with '%v'!", name, login)
+
+	return nil
+}
+
+// shared lists the entries of the other users shared with the user or,
+// if the name is given, shows the shared entry.
+func (c *Client) shared(cCtx *cli.Context) error {
+	if err := c.clientSvc.RefreshToken(); err != nil {
+		return err
+	}
+
+	items, err := c.clientSvc.GetShared()
+	if err != nil {
+		return err
+	}
+
+	name := cCtx.Args().First()
+	if name == "" {
+		if len(items) == 0 {
+			fmt.Println("no entries are shared with you")
+			return nil
+		}
+
+		w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0) //nolint:gomnd
+		for _, v := range items {
+			mode := "read-write"
+			if v.ReadOnly {
+				mode = "read-only"
+			}
+			_, _ = fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", v.Meta, v.DataType, v.Owner, mode)
+		}
+
+		return w.Flush()
+	}
+
+	for _, v := range items {
+		if v.Meta != name || (cCtx.IsSet("from") && v.Owner != cCtx.String("from")) {
+			continue
+		}
+
+		if err = c.decrypt(v); err != nil {
+			color.Red("❌ entry '%v' cannot be decrypted: %v", name, err)
+			return cli.Exit("", 1)
+		}
+
+		fmt.Printf("owner: %v\n", v.Owner)
+
+		return viewItem(v)
+	}
+
+	return cli.Exit(fmt.Sprintf("entry '%v' not found", name), 1)
+}
@@ -0,0 +1,18 @@
+package client
+
+import (
+	"github.com/fatih/color"
+	"github.com/urfave/cli/v2"
+)
+
+// sync fetches the entries from the remote server and applies the
+// deletions made by the other clients.
+func (c *Client) sync(cCtx *cli.Context) error {
+	if err := c.pull(cCtx.Context); err != nil {
+		return exitError(err)
+	}
+
+	color.Green("✅ success!")
+
+	return nil
+}
@@ -0,0 +1,134 @@
+package client
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"text/tabwriter"
+
+	"github.com/fatih/color"
+	"github.com/iryzzh/y-gophkeeper/internal/models"
+	"github.com/iryzzh/y-gophkeeper/internal/services/item"
+	"github.com/iryzzh/y-gophkeeper/internal/tui"
+	"github.com/pkg/errors"
+	"github.com/urfave/cli/v2"
+)
+
+// trashList prints the entries in the trash, the most recently
+// deleted first.
+func (c *Client) trashList(cCtx *cli.Context) error {
+	userID, err := c.vaultID()
+	if err != nil {
+		return err
+	}
+
+	items, err := c.itemSvc.Trash(cCtx.Context, userID, "", "")
+	if errors.Is(err, item.ErrItemNotFound) {
+		fmt.Println("the trash is empty")
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0) //nolint:gomnd
+	for _, v := range items.Data {
+		var date string
+		if v.DeletedAt != nil {
+			date = v.DeletedAt.Local().Format(dateLayout + " 15:04")
+		}
+
+		_, _ = fmt.Fprintf(w, "%s\t%s\t%s\n", v.Meta, v.DataType, date)
+	}
+
+	return w.Flush()
+}
+
+// trashRestore moves the most recently deleted entry with the given
+// name out of the trash locally and on the remote server.
+func (c *Client) trashRestore(cCtx *cli.Context) error {
+	name := cCtx.Args().First()
+	if name == "" {
+		return cli.Exit("usage: trash restore <name>", 1)
+	}
+
+	userID, err := c.vaultID()
+	if err != nil {
+		return err
+	}
+
+	items, err := c.itemSvc.Trash(cCtx.Context, userID, "", "")
+	if err != nil && !errors.Is(err, item.ErrItemNotFound) {
+		return err
+	}
+
+	var found *models.Item
+	if items != nil {
+		for _, v := range items.Data {
+			if v.Meta == name {
+				found = v
+				break
+			}
+		}
+	}
+	if found == nil {
+		return cli.Exit(fmt.Sprintf("entry '%v' not found in the trash", name), 1)
+	}
+
+	_, err = c.itemSvc.Restore(cCtx.Context, userID, strconv.Itoa(found.ID))
+	if errors.Is(err, item.ErrItemExists) {
+		return cli.Exit(fmt.Sprintf("entry '%v' already exists", name), 1)
+	}
+	if err != nil {
+		return exitError(err)
+	}
+
+	if err = c.clientSvc.RefreshToken(); err != nil {
+		return err
+	}
+
+	if err = c.clientSvc.Restore(found.ID); err != nil {
+		return err
+	}
+
+	color.Green("✅ item was successfully restored!")
+
+	return nil
+}
+
+// trashEmpty permanently deletes the entries in the trash locally and
+// on the remote server after a confirmation, unless the `--yes` flag
+// is set.
+func (c *Client) trashEmpty(cCtx *cli.Context) error {
+	userID, err := c.vaultID()
+	if err != nil {
+		return err
+	}
+
+	if !cCtx.Bool("yes") {
+		var confirmed bool
+		if err = tui.AskConfirm("Permanently delete the entries in the trash?", &confirmed); err != nil {
+			return err
+		}
+		if !confirmed {
+			return nil
+		}
+	}
+
+	purged, err := c.itemSvc.EmptyTrash(cCtx.Context, userID)
+	if err != nil {
+		return exitError(err)
+	}
+
+	if err = c.clientSvc.RefreshToken(); err != nil {
+		return err
+	}
+
+	if _, err = c.clientSvc.EmptyTrash(); err != nil {
+		return err
+	}
+
+	color.Green("✅ %d item(s) permanently deleted!", purged)
+
+	return nil
+}
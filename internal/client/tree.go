@@ -0,0 +1,80 @@
+package client
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"github.com/iryzzh/y-gophkeeper/internal/models"
+)
+
+// node is a node of the tree of the entries. A node can be both an
+// entry and a folder, e.g. "google" and "google/mail".
+type node struct {
+	name     string
+	label    string
+	children []*node
+}
+
+// buildTree builds the tree of the entries from their names.
+func buildTree(items []*models.Item) *node {
+	root := &node{}
+	for _, it := range items {
+		n := root
+		for _, name := range strings.Split(it.Meta, models.FolderSeparator) {
+			if name != "" {
+				n = n.child(name)
+			}
+		}
+		if len(it.Tags) > 0 {
+			n.label = fmt.Sprintf(" [%s]", strings.Join(it.Tags, ", "))
+		}
+	}
+	root.sort()
+
+	return root
+}
+
+// child returns the child node with the given name, creating it if
+// necessary.
+func (n *node) child(name string) *node {
+	for _, c := range n.children {
+		if c.name == name {
+			return c
+		}
+	}
+
+	c := &node{name: name}
+	n.children = append(n.children, c)
+
+	return c
+}
+
+func (n *node) sort() {
+	sort.Slice(n.children, func(i, j int) bool {
+		return n.children[i].name < n.children[j].name
+	})
+	for _, c := range n.children {
+		c.sort()
+	}
+}
+
+// print prints the top-level nodes of the tree and their subtrees.
+func (n *node) print(w io.Writer) {
+	for _, c := range n.children {
+		_, _ = fmt.Fprintf(w, "%s%s\n", c.name, c.label)
+		c.printChildren(w, "")
+	}
+}
+
+func (n *node) printChildren(w io.Writer, indent string) {
+	for i, c := range n.children {
+		branch, next := "├── ", "│   "
+		if i == len(n.children)-1 {
+			branch, next = "└── ", "    "
+		}
+		_, _ = fmt.Fprintf(w, "%s%s%s%s\n", indent, branch, c.name, c.label)
+		c.printChildren(w, indent+next)
+	}
+}
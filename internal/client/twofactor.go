@@ -0,0 +1,71 @@
+package client
+
+import (
+	"fmt"
+
+	"github.com/fatih/color"
+	"github.com/iryzzh/y-gophkeeper/internal/tui"
+	"github.com/urfave/cli/v2"
+)
+
+// twoFactorEnable enables the second factor of the user. The generated
+// secret is printed to be added to an authenticator app, whose code
+// confirms it.
+func (c *Client) twoFactorEnable(cCtx *cli.Context) error {
+	if err := c.clientSvc.RefreshToken(); err != nil {
+		return err
+	}
+
+	tf, err := c.clientSvc.EnableTwoFactor()
+	if err != nil {
+		return exitError(err)
+	}
+
+	fmt.Printf("Add the secret to your authenticator app:\n\n  Secret: %v\n  URL:    %v\n\n", tf.Secret, tf.URL)
+
+	code, err := c.oneTimeCode(cCtx)
+	if err != nil {
+		return err
+	}
+	if err = c.clientSvc.ConfirmTwoFactor(code); err != nil {
+		return exitError(err)
+	}
+
+	color.Green("✅ two-factor authentication was enabled!")
+
+	return nil
+}
+
+// twoFactorDisable disables the second factor of the user.
+func (c *Client) twoFactorDisable(cCtx *cli.Context) error {
+	if err := c.clientSvc.RefreshToken(); err != nil {
+		return err
+	}
+
+	code, err := c.oneTimeCode(cCtx)
+	if err != nil {
+		return err
+	}
+	if err = c.clientSvc.DisableTwoFactor(code); err != nil {
+		return exitError(err)
+	}
+
+	color.Green("✅ two-factor authentication was disabled!")
+
+	return nil
+}
+
+// adminResetTwoFactor disables the second factor of a user who lost it.
+func (c *Client) adminResetTwoFactor(cCtx *cli.Context) error {
+	return c.adminAction(cCtx, c.clientSvc.AdminResetTwoFactor, "✅ two-factor authentication of '%v' was reset!")
+}
+
+// oneTimeCode returns the one-time code from the 'code' flag or asks
+// for it.
+func (c *Client) oneTimeCode(cCtx *cli.Context) (string, error) {
+	if code := cCtx.String("code"); code != "" {
+		return code, nil
+	}
+
+	return tui.AskPassword("One-time code:")
+}
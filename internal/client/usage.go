@@ -0,0 +1,78 @@
+package client
+
+import (
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"github.com/iryzzh/y-gophkeeper/internal/models"
+	"github.com/urfave/cli/v2"
+)
+
+// usage prints the storage usage and the quota of the user.
+func (c *Client) usage(_ *cli.Context) error {
+	if err := c.clientSvc.RefreshToken(); err != nil {
+		return err
+	}
+
+	u, err := c.clientSvc.Usage()
+	if err != nil {
+		return exitError(err)
+	}
+
+	return printAccount(u, false)
+}
+
+// printAccount prints the storage usage of the user against the quota,
+// with the role and the status of the user if full is set.
+func printAccount(u *models.User, full bool) error {
+	usage, quota := u.Usage, u.Quota
+	if usage == nil {
+		usage = &models.Usage{}
+	}
+	if quota == nil {
+		quota = &models.Quota{}
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0) //nolint:gomnd
+	_, _ = fmt.Fprintf(w, "Login:\t%s\n", u.Login)
+	if full {
+		_, _ = fmt.Fprintf(w, "Role:\t%s\n", userRole(u))
+		_, _ = fmt.Fprintf(w, "Status:\t%s\n", userStatus(u))
+		_, _ = fmt.Fprintf(w, "2FA:\t%s\n", twoFactorString(u))
+	}
+	_, _ = fmt.Fprintf(w, "Items:\t%d of %s\n", usage.Items, limitString(quota.MaxItems, false))
+	_, _ = fmt.Fprintf(w, "Storage:\t%s of %s\n", formatBytes(usage.Bytes), limitString(quota.MaxBytes, true))
+	_, _ = fmt.Fprintf(w, "Item size:\tup to %s\n", limitString(quota.MaxItemBytes, true))
+
+	return w.Flush()
+}
+
+// limitString returns the limit of the quota as a human-readable string.
+func limitString(n int64, bytes bool) string {
+	switch {
+	case n == 0:
+		return "unlimited"
+	case bytes:
+		return formatBytes(n)
+	}
+
+	return fmt.Sprint(n)
+}
+
+// formatBytes returns the size in bytes as a human-readable string,
+// such as "1.5 MiB".
+func formatBytes(n int64) string {
+	const unit = 1024
+	if n < unit {
+		return fmt.Sprintf("%d B", n)
+	}
+
+	div, exp := int64(unit), 0
+	for m := n / unit; m >= unit; m /= unit {
+		div *= unit
+		exp++
+	}
+
+	return fmt.Sprintf("%.1f %ciB", float64(n)/float64(div), "KMGTPE"[exp])
+}
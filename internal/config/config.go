@@ -5,8 +5,10 @@ import (
 	"os"
 	"path/filepath"
 	"strings"
+	"time"
 
 	"github.com/ilyakaznacheev/cleanenv"
+	"github.com/pkg/errors"
 	"gopkg.in/yaml.v2"
 )
 
@@ -33,6 +35,103 @@ type WebConfig struct {
 	TLSKeyPath    string `yaml:"tls_key_path" env:"TLS_KEY_PATH" env-default:"./config/server-key.pem"`
 	BasePath      string `yaml:"base_path" env-default:"/" env:"BASE_PATH"`
 	EnableHTTPS   bool   `yaml:"enable_https" env-default:"true" env:"ENABLE_HTTPS"`
+	// MetricsAddress is the address of the plain HTTP server exposing
+	// the Prometheus metrics at `/metrics`. The metrics are not served
+	// if it is empty.
+	MetricsAddress string `yaml:"metrics_address" env:"METRICS_ADDRESS" env-default:":9090"`
+}
+
+// TrashConfig contains the configuration of the trash.
+type TrashConfig struct {
+	// Retention is the period after which the items in the trash are
+	// deleted permanently, along with their tombstones.
+	Retention time.Duration `yaml:"retention" env:"TRASH_RETENTION" env-default:"720h"`
+	// PurgeInterval is the interval of the retention job.
+	PurgeInterval time.Duration `yaml:"purge_interval" env:"TRASH_PURGE_INTERVAL" env-default:"1h"`
+}
+
+// EmergencyConfig contains the configuration of the emergency access.
+type EmergencyConfig struct {
+	// Wait is the waiting period of the emergency access granted
+	// without one.
+	Wait time.Duration `yaml:"wait" env:"EMERGENCY_WAIT" env-default:"168h"`
+	// ReleaseInterval is the interval of the job releasing the
+	// emergency access after the waiting period.
+	ReleaseInterval time.Duration `yaml:"release_interval" env:"EMERGENCY_RELEASE_INTERVAL" env-default:"1m"`
+}
+
+// AuditConfig contains the configuration of the audit log.
+type AuditConfig struct {
+	// Retention is the period the events are kept in the audit log.
+	// The events are kept forever if it is zero.
+	Retention time.Duration `yaml:"retention" env:"AUDIT_RETENTION" env-default:"2160h"`
+	// PurgeInterval is the interval of the job removing the events
+	// older than the retention period.
+	PurgeInterval time.Duration `yaml:"purge_interval" env:"AUDIT_PURGE_INTERVAL" env-default:"1h"`
+}
+
+// ReminderConfig contains the configuration of the reminders of the
+// items expiring or due for rotation.
+type ReminderConfig struct {
+	// Interval is the interval of the job sending the reminders.
+	Interval time.Duration `yaml:"interval" env:"REMINDER_INTERVAL" env-default:"24h"`
+	// Window is the period before the due time from which the items
+	// are reminded.
+	Window time.Duration `yaml:"window" env:"REMINDER_WINDOW" env-default:"168h"`
+	// Output is the path of the file the reminders are appended to as
+	// JSON lines, or '-' for the standard output. The reminders are
+	// not sent if it is empty.
+	Output string `yaml:"output" env:"REMINDER_OUTPUT"`
+}
+
+// QuotaConfig contains the default quota of the vaults, in bytes of
+// the stored data. The quotas set for the users by the administrators
+// take precedence. Zero means no limit.
+type QuotaConfig struct {
+	// MaxBytes is the maximum total size of the items of a vault.
+	MaxBytes int64 `yaml:"max_bytes" env:"QUOTA_MAX_BYTES" env-default:"268435456"`
+	// MaxItems is the maximum number of the items of a vault.
+	MaxItems int64 `yaml:"max_items" env:"QUOTA_MAX_ITEMS" env-default:"10000"`
+	// MaxItemBytes is the maximum size of a single item.
+	MaxItemBytes int64 `yaml:"max_item_bytes" env:"QUOTA_MAX_ITEM_BYTES" env-default:"16777216"`
+}
+
+// BlobConfig contains the configuration of the storage of the item
+// data.
+type BlobConfig struct {
+	// Storage is the storage of the item data: 'db' to save it to the
+	// database, 'fs' to save it to the files of the directory at the
+	// path, or 's3' to save it to the bucket of an S3-compatible
+	// service. The data is deduplicated by content in every storage.
+	Storage string `yaml:"storage" env:"BLOB_STORAGE" env-default:"db"`
+	// Path is the directory of the 'fs' storage.
+	Path string   `yaml:"path" env:"BLOB_PATH" env-default:"blobs"`
+	S3   S3Config `yaml:"s3"`
+}
+
+// S3Config contains the configuration of the 's3' blob storage.
+type S3Config struct {
+	// Endpoint is the URL of the service, e.g. 'http://localhost:9000'.
+	Endpoint string `yaml:"endpoint" env:"BLOB_S3_ENDPOINT" env-default:"https://s3.amazonaws.com"`
+	Region   string `yaml:"region" env:"BLOB_S3_REGION" env-default:"us-east-1"`
+	Bucket   string `yaml:"bucket" env:"BLOB_S3_BUCKET"`
+	// Prefix is prepended to the names of the objects, e.g. 'blobs/'.
+	Prefix       string `yaml:"prefix" env:"BLOB_S3_PREFIX"`
+	AccessKey    string `yaml:"access_key" env:"BLOB_S3_ACCESS_KEY"`
+	SecretKey    string `yaml:"secret_key" env:"BLOB_S3_SECRET_KEY"`
+	SessionToken string `yaml:"session_token" env:"BLOB_S3_SESSION_TOKEN"`
+	// PathStyle addresses the bucket in the path of the URL instead of
+	// the host name, as MinIO and most self-hosted services require.
+	PathStyle bool `yaml:"path_style" env:"BLOB_S3_PATH_STYLE" env-default:"false"`
+}
+
+// LogConfig contains the configuration of the server log.
+type LogConfig struct {
+	// Level is the minimal level of the logged messages: debug, info,
+	// warn or error.
+	Level string `yaml:"level" env:"LOG_LEVEL" env-default:"info"`
+	// Format is the format of the log: json or text (logfmt).
+	Format string `yaml:"format" env:"LOG_FORMAT" env-default:"json"`
 }
 
 // DBConfig contains the database configuration.
@@ -44,17 +143,24 @@ type DBConfig struct {
 
 // Version contains the Version information.
 type Version struct {
-	Version   string
-	Commit    string
-	BuildDate string
+	Version   string `json:"version"`
+	Commit    string `json:"commit"`
+	BuildDate string `json:"build_date"`
 }
 
 // ServerCfg contains the configuration of the application.
 type ServerCfg struct {
-	Web      WebConfig
-	DB       DBConfig
-	Version  Version
-	Security SecurityConfig
+	Web       WebConfig
+	DB        DBConfig
+	Trash     TrashConfig
+	Emergency EmergencyConfig
+	Audit     AuditConfig
+	Reminder  ReminderConfig
+	Quota     QuotaConfig
+	Blob      BlobConfig
+	Log       LogConfig
+	Version   Version
+	Security  SecurityConfig
 }
 
 // String returns version, build date and commit id.
@@ -67,8 +173,12 @@ func (v Version) String() string {
 }
 
 const (
-	srvConfigPath                = "./config/config.yml"
+	srvConfigPath = "./config/config.yml"
+	// ClientConfigPath is the path of the configuration of the cli
+	// before it moved to the configuration directory. It is migrated
+	// automatically.
 	ClientConfigPath             = "./config.yml"
+	clientConfigFile             = "config.yml"
 	NA                           = "N/A"
 	FilePermission   os.FileMode = 0o600
 	MaxFileSize                  = 100 * 1024 * 1024
@@ -107,41 +217,136 @@ type ClientCfg struct {
 	Security   SecurityConfig `yaml:"security,omitempty"`
 	SkipVerify bool           `yaml:"skip_verify" ENV:"skip_verify" env-default:"1"`
 	API        API            `yaml:"api_client"`
+	Keys       Keys           `yaml:"keys,omitempty"`
+	Vault      Vault          `yaml:"vault,omitempty"`
+	Lock       Lock           `yaml:"lock,omitempty"`
+	// Synced is the deletion time of the last item deleted on the
+	// remote server pulled to each vault: the next sync pulls the items
+	// deleted since then only.
+	Synced map[string]time.Time `yaml:"synced,omitempty"`
+	// BreachList is the path of the local list of the SHA-1 hashes of
+	// the breached passwords, a file or a directory of the files by
+	// prefix. The added passwords are checked against it if it is set.
+	BreachList string `yaml:"breach_list,omitempty" env:"BREACH_LIST"`
+	// KeyFile is the path of the file with the key of the credentials
+	// file, relative to the configuration directory. The credentials
+	// are encrypted with the key of the vault if it is empty. The file
+	// is created with a random key if it does not exist.
+	KeyFile string `yaml:"credentials_key_file,omitempty" env:"CREDENTIALS_KEY_FILE"`
+	// Profile is the name of the profile of the configuration.
+	Profile string `yaml:"-"`
+	// Notices are the messages about the migration of the configuration
+	// to report to the user.
+	Notices []string `yaml:"-"`
+
+	path            string
+	dataDir         string
+	credentialsPath string
+	key             KeyFunc
+	// stored are the credentials in the credentials file.
+	stored Credentials
+	// inClear reports whether the credentials were read from the
+	// configuration, as there is no credentials file yet.
+	inClear bool
 }
 
-// SaveConfig saves the current configuration to a file.
+// SaveConfig saves the current configuration to a file. The credentials
+// are saved to the credentials file, never to the configuration.
 func (c *ClientCfg) SaveConfig() error {
-	configToStore := &c
+	if err := c.saveCredentials(); err != nil {
+		return err
+	}
 
-	(*configToStore).Security = SecurityConfig{}
+	configToStore := *c
+	configToStore.Security = SecurityConfig{}
+	configToStore.setCredentials(Credentials{})
 
 	data, err := yaml.Marshal(&configToStore)
 	if err != nil {
 		return err
 	}
 
-	return os.WriteFile(ClientConfigPath, data, FilePermission)
+	return writeFile(c.path, data)
+}
+
+// Path returns the path of the configuration file.
+func (c *ClientCfg) Path() string {
+	return c.path
 }
 
 // API contains the configuration for communicating with the
 // remote server.
 type API struct {
 	Remote string `yaml:"remote" ENV:"REMOTE"`
-	AT     string `yaml:"at" ENV:"ACCESS_TOKEN"`
-	RT     string `yaml:"rt" ENV:"REFRESH_TOKEN"`
+	AT     string `yaml:"at,omitempty" ENV:"ACCESS_TOKEN"`
+	RT     string `yaml:"rt,omitempty" ENV:"REFRESH_TOKEN"`
 }
 
-// NewClientConfig creates a new ClientConfig.
-func NewClientConfig() (*ClientCfg, error) {
-	cfg := ClientCfg{}
+// Keys contains the keypair of the user, base64 encoded. The public
+// key is registered on the remote server, so that the other users can
+// share items with the user.
+type Keys struct {
+	Public  string `yaml:"public,omitempty"`
+	Private string `yaml:"private,omitempty"`
+}
 
-	err := cleanenv.ReadEnv(&cfg)
+// Vault is the vault the cli works with: a collection of an
+// organization, or the personal vault of the user if empty.
+type Vault struct {
+	Org          string `yaml:"org,omitempty"`
+	Collection   string `yaml:"collection,omitempty"`
+	CollectionID string `yaml:"collection_id,omitempty"`
+}
+
+// Lock contains the configuration of the lock of the local vault. The
+// key of the vault is derived from the master password with the salt,
+// the check is a known value encrypted with the key to verify it.
+type Lock struct {
+	Salt  string `yaml:"salt,omitempty"`
+	Check string `yaml:"check,omitempty"`
+	// Timeout is the idle timeout of the agent holding the key of the
+	// unlocked vault.
+	Timeout time.Duration `yaml:"timeout,omitempty" env:"LOCK_TIMEOUT" env-default:"15m"`
+	// Socket is the path of the Unix socket of the agent. A path in the
+	// temporary directory of the user is used if it is empty.
+	Socket string `yaml:"socket,omitempty" env:"AGENT_SOCKET"`
+}
+
+// NewClientConfig creates a new ClientConfig of the profile, see
+// `ResolveProfile`. The configuration is read from the configuration
+// directory of the profile. The configuration in the working directory
+// is moved to the default profile along with the database if it has
+// none.
+func NewClientConfig(profile string) (*ClientCfg, error) {
+	profile, err := ResolveProfile(profile)
+	if err != nil {
+		return nil, err
+	}
+	configDir, dataDir, err := profileDirs(profile)
 	if err != nil {
 		return nil, err
 	}
 
-	if _, err = os.ReadFile(ClientConfigPath); err == nil {
-		err = cleanenv.ReadConfig(ClientConfigPath, &cfg)
+	cfg := ClientCfg{
+		Profile:         profile,
+		path:            filepath.Join(configDir, clientConfigFile),
+		dataDir:         dataDir,
+		credentialsPath: filepath.Join(configDir, credentialsFile),
+	}
+
+	err = cleanenv.ReadEnv(&cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	path := cfg.path
+	legacy := profile == DefaultProfile && !exists(path) && exists(ClientConfigPath)
+	if legacy {
+		path = ClientConfigPath
+	}
+
+	if exists(path) {
+		err = cleanenv.ReadConfig(path, &cfg)
 		if err != nil {
 			if !strings.Contains(err.Error(), "EOF") {
 				return nil, err
@@ -149,11 +354,108 @@ func NewClientConfig() (*ClientCfg, error) {
 		}
 	}
 
+	cfg.inClear = !cfg.HasCredentials()
+	cfg.stored = cfg.credentials()
+	if cfg.KeyFile != "" && !filepath.IsAbs(cfg.KeyFile) {
+		cfg.KeyFile = filepath.Join(configDir, cfg.KeyFile)
+	}
+
+	if legacy {
+		if err = cfg.migrate(); err != nil {
+			return nil, errors.Wrapf(err, "migrate %v", ClientConfigPath)
+		}
+	} else if err = cfg.resolveDSN(); err != nil {
+		return nil, err
+	}
+
 	buildVersion(&cfg.Version)
 
 	return &cfg, nil
 }
 
+// resolveDSN places the database with a relative path in the data
+// directory of the profile.
+func (c *ClientCfg) resolveDSN() error {
+	if !isFile(c.DB.DSN) || filepath.IsAbs(c.DB.DSN) {
+		return nil
+	}
+
+	if err := os.MkdirAll(c.dataDir, dirPermission); err != nil {
+		return err
+	}
+	c.DB.DSN = filepath.Join(c.dataDir, c.DB.DSN)
+
+	return nil
+}
+
+// migrate moves the configuration read from the working directory to
+// the configuration directory, and the database with a relative path
+// to the data directory. The database is left in place, with its
+// absolute path in the configuration, if it cannot be moved. The
+// credentials in the clear are moved to the credentials file encrypted
+// with the key file, which is created if none is configured, since the
+// key of the vault is not available yet: they are never written in the
+// clear to the configuration directory.
+func (c *ClientCfg) migrate() error {
+	legacyDSN := c.DB.DSN
+	if err := c.resolveDSN(); err != nil {
+		return err
+	}
+
+	if legacyDSN != c.DB.DSN && exists(legacyDSN) {
+		if exists(c.DB.DSN) || os.Rename(legacyDSN, c.DB.DSN) != nil {
+			c.DB.DSN, _ = filepath.Abs(legacyDSN)
+		} else {
+			for _, suffix := range []string{"-journal", "-wal", "-shm"} {
+				_ = os.Rename(legacyDSN+suffix, c.DB.DSN+suffix)
+			}
+			c.Notices = append(c.Notices, fmt.Sprintf("the database was moved to %v", c.DB.DSN))
+		}
+	}
+
+	if c.inClear && c.credentials() != (Credentials{}) {
+		if c.KeyFile == "" {
+			c.KeyFile = filepath.Join(filepath.Dir(c.path), keyFile)
+			c.Notices = append(c.Notices, fmt.Sprintf("the credentials were encrypted with the key file %v", c.KeyFile))
+		}
+		if _, err := c.readKeyFile(); err != nil {
+			return err
+		}
+	}
+
+	if err := c.SaveConfig(); err != nil {
+		return err
+	}
+	if err := os.Remove(ClientConfigPath); err != nil {
+		return err
+	}
+	c.Notices = append(c.Notices, fmt.Sprintf("the configuration was moved to %v", c.path))
+
+	return nil
+}
+
+// isFile reports whether the sqlite DSN is the path of a file.
+func isFile(dsn string) bool {
+	return dsn != "" && dsn != ":memory:" && !strings.HasPrefix(dsn, "file:")
+}
+
+// exists reports whether the file exists.
+func exists(path string) bool {
+	_, err := os.Stat(path)
+
+	return err == nil
+}
+
+// writeFile writes the file accessible to the user only, creating its
+// directory if needed.
+func writeFile(path string, data []byte) error {
+	if err := os.MkdirAll(filepath.Dir(path), dirPermission); err != nil {
+		return err
+	}
+
+	return os.WriteFile(path, data, FilePermission)
+}
+
 func buildVersion(cfg *Version) {
 	if cfg.Version == "" {
 		cfg.Version = "N/A"
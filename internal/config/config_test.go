@@ -0,0 +1,188 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/iryzzh/y-gophkeeper/internal/keys"
+	"github.com/stretchr/testify/require"
+)
+
+// setDirs points the base directories to the temporary directory and
+// makes it the working directory.
+func setDirs(t *testing.T) string {
+	dir := t.TempDir()
+	t.Setenv("XDG_CONFIG_HOME", filepath.Join(dir, "config"))
+	t.Setenv("XDG_DATA_HOME", filepath.Join(dir, "data"))
+	t.Setenv(ProfileEnv, "")
+
+	wd, err := os.Getwd()
+	require.NoError(t, err)
+	require.NoError(t, os.Chdir(dir))
+	t.Cleanup(func() { _ = os.Chdir(wd) })
+
+	return dir
+}
+
+func TestNewClientConfig_migrate(t *testing.T) {
+	dir := setDirs(t)
+
+	legacy := "db:\n  type: sqlite3\n  dsn: db.sqlite3\napi_client:\n  remote: https://localhost:8080\n  at: access\n  rt: refresh\n"
+	require.NoError(t, os.WriteFile(ClientConfigPath, []byte(legacy), FilePermission))
+	require.NoError(t, os.WriteFile("db.sqlite3", []byte("database"), FilePermission))
+
+	cfg, err := NewClientConfig("")
+	require.NoError(t, err)
+	require.Len(t, cfg.Notices, 3)
+	require.Equal(t, filepath.Join(dir, "config", appName, clientConfigFile), cfg.Path())
+	require.Equal(t, filepath.Join(dir, "data", appName, "db.sqlite3"), cfg.DB.DSN)
+	require.Equal(t, "access", cfg.API.AT)
+
+	require.NoFileExists(t, ClientConfigPath)
+	require.NoFileExists(t, "db.sqlite3")
+	data, err := os.ReadFile(cfg.DB.DSN)
+	require.NoError(t, err)
+	require.Equal(t, "database", string(data))
+
+	// the credentials are never written in the clear to the new location
+	data, err = os.ReadFile(cfg.Path())
+	require.NoError(t, err)
+	require.NotContains(t, string(data), "access")
+	require.NotContains(t, string(data), "refresh")
+	require.True(t, cfg.HasCredentials())
+	require.FileExists(t, filepath.Join(dir, "config", appName, keyFile))
+
+	cfg, err = NewClientConfig("")
+	require.NoError(t, err)
+	require.Empty(t, cfg.Notices)
+	require.Empty(t, cfg.API.RT)
+	require.NoError(t, cfg.LoadCredentials())
+	require.Equal(t, "refresh", cfg.API.RT)
+}
+
+func TestNewClientConfig_migrateWithoutCredentials(t *testing.T) {
+	dir := setDirs(t)
+
+	legacy := "api_client:\n  remote: https://localhost:8080\n"
+	require.NoError(t, os.WriteFile(ClientConfigPath, []byte(legacy), FilePermission))
+
+	cfg, err := NewClientConfig("")
+	require.NoError(t, err)
+	require.Len(t, cfg.Notices, 1)
+	require.Empty(t, cfg.KeyFile)
+	require.NoFileExists(t, filepath.Join(dir, "config", appName, keyFile))
+	require.NoFileExists(t, ClientConfigPath)
+}
+
+func TestClientCfg_credentials(t *testing.T) {
+	setDirs(t)
+
+	key, err := keys.NewItemKey()
+	require.NoError(t, err)
+	keyFunc := func() (*[keys.KeySize]byte, error) { return key, nil }
+
+	cfg, err := NewClientConfig("")
+	require.NoError(t, err)
+	cfg.SetKey(keyFunc)
+	cfg.API.AT, cfg.API.RT, cfg.Keys.Private = "access", "refresh", "private"
+	require.NoError(t, cfg.SaveConfig())
+	require.True(t, cfg.HasCredentials())
+
+	data, err := os.ReadFile(cfg.Path())
+	require.NoError(t, err)
+	require.NotContains(t, string(data), "access")
+	require.NotContains(t, string(data), "private")
+	data, err = os.ReadFile(cfg.credentialsPath)
+	require.NoError(t, err)
+	require.NotContains(t, string(data), "access")
+
+	cfg, err = NewClientConfig("")
+	require.NoError(t, err)
+	require.Empty(t, cfg.API.AT)
+	cfg.SetKey(keyFunc)
+	require.NoError(t, cfg.LoadCredentials())
+	require.Equal(t, "access", cfg.API.AT)
+	require.Equal(t, "refresh", cfg.API.RT)
+	require.Equal(t, "private", cfg.Keys.Private)
+
+	wrong, err := keys.NewItemKey()
+	require.NoError(t, err)
+	cfg.SetKey(func() (*[keys.KeySize]byte, error) { return wrong, nil })
+	require.Error(t, cfg.LoadCredentials())
+}
+
+func TestClientCfg_credentialsWithoutKey(t *testing.T) {
+	setDirs(t)
+
+	cfg, err := NewClientConfig("")
+	require.NoError(t, err)
+	require.NoError(t, cfg.SaveConfig())
+
+	cfg.API.AT, cfg.Keys.Private = "access", "private"
+	require.Error(t, cfg.SaveConfig())
+	require.False(t, cfg.HasCredentials())
+
+	data, err := os.ReadFile(cfg.Path())
+	require.NoError(t, err)
+	require.NotContains(t, string(data), "access")
+	require.NotContains(t, string(data), "private")
+}
+
+func TestClientCfg_keyFile(t *testing.T) {
+	setDirs(t)
+	t.Setenv("CREDENTIALS_KEY_FILE", "key")
+
+	cfg, err := NewClientConfig("")
+	require.NoError(t, err)
+	cfg.API.AT = "access"
+	require.NoError(t, cfg.SaveConfig())
+	require.FileExists(t, filepath.Join(filepath.Dir(cfg.Path()), "key"))
+
+	cfg, err = NewClientConfig("")
+	require.NoError(t, err)
+	require.NoError(t, cfg.LoadCredentials())
+	require.Equal(t, "access", cfg.API.AT)
+}
+
+func TestProfiles(t *testing.T) {
+	dir := setDirs(t)
+
+	cfg, err := NewClientConfig("")
+	require.NoError(t, err)
+	require.Equal(t, DefaultProfile, cfg.Profile)
+	require.NoError(t, cfg.SaveConfig())
+
+	_, err = NewClientConfig("../work")
+	require.ErrorIs(t, err, ErrInvalidProfile)
+
+	cfg, err = NewClientConfig("work")
+	require.NoError(t, err)
+	require.Equal(t, filepath.Join(dir, "data", appName, profilesDir, "work", "db.sqlite3"), cfg.DB.DSN)
+	cfg.API.Remote = "https://work"
+	require.NoError(t, cfg.SaveConfig())
+
+	profiles, err := Profiles()
+	require.NoError(t, err)
+	require.Equal(t, []string{DefaultProfile, "work"}, profiles)
+
+	require.ErrorIs(t, UseProfile("home"), ErrProfileNotFound)
+	require.NoError(t, UseProfile("work"))
+	cfg, err = NewClientConfig("")
+	require.NoError(t, err)
+	require.Equal(t, "work", cfg.Profile)
+	require.Equal(t, "https://work", cfg.API.Remote)
+
+	t.Setenv(ProfileEnv, DefaultProfile)
+	cfg, err = NewClientConfig("")
+	require.NoError(t, err)
+	require.Equal(t, DefaultProfile, cfg.Profile)
+	require.Empty(t, cfg.API.Remote)
+
+	require.ErrorIs(t, RemoveProfile(DefaultProfile), ErrDefaultProfile)
+	require.NoError(t, RemoveProfile("work"))
+	require.False(t, ProfileExists("work"))
+	current, err := CurrentProfile()
+	require.NoError(t, err)
+	require.Equal(t, DefaultProfile, current)
+}
@@ -0,0 +1,156 @@
+package config
+
+import (
+	"crypto/rand"
+	"io"
+	"os"
+
+	"github.com/iryzzh/y-gophkeeper/internal/keys"
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v2"
+)
+
+const (
+	credentialsFile = "credentials"
+	// keyFile is the key file created to encrypt the credentials moved
+	// from the configuration in the working directory.
+	keyFile       = "credentials.key"
+	dirPermission = 0o700
+)
+
+// KeyFunc returns the key of the credentials file.
+type KeyFunc func() (*[keys.KeySize]byte, error)
+
+// Credentials are the secrets of the cli: the tokens and the private
+// key of the user. They are stored in the credentials file encrypted
+// with the key of the vault, or with the key of the key file if it is
+// configured.
+type Credentials struct {
+	AT         string `yaml:"at,omitempty"`
+	RT         string `yaml:"rt,omitempty"`
+	PrivateKey string `yaml:"private_key,omitempty"`
+}
+
+// credentials returns the credentials of the configuration.
+func (c *ClientCfg) credentials() Credentials {
+	return Credentials{AT: c.API.AT, RT: c.API.RT, PrivateKey: c.Keys.Private}
+}
+
+// setCredentials sets the credentials of the configuration.
+func (c *ClientCfg) setCredentials(creds Credentials) {
+	c.API.AT, c.API.RT, c.Keys.Private = creds.AT, creds.RT, creds.PrivateKey
+}
+
+// SetKey sets the function returning the key of the credentials file,
+// unless the key file is configured.
+func (c *ClientCfg) SetKey(key KeyFunc) {
+	c.key = key
+}
+
+// HasCredentials reports whether the credentials file exists.
+func (c *ClientCfg) HasCredentials() bool {
+	_, err := os.Stat(c.credentialsPath)
+
+	return err == nil
+}
+
+// LoadCredentials reads the credentials file. The credentials read from
+// a configuration written before the credentials file existed are kept.
+func (c *ClientCfg) LoadCredentials() error {
+	data, err := os.ReadFile(c.credentialsPath)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+
+	key, err := c.credentialsKey()
+	if err != nil {
+		return err
+	}
+	data, err = keys.Decrypt(data, key)
+	if err != nil {
+		return errors.Wrap(err, "credentials")
+	}
+
+	var creds Credentials
+	if err = yaml.Unmarshal(data, &creds); err != nil {
+		return errors.Wrap(err, "credentials")
+	}
+	c.setCredentials(creds)
+	c.stored, c.inClear = creds, false
+
+	return nil
+}
+
+// saveCredentials writes the credentials file if the credentials have
+// changed since they were read. The credentials read in the clear from
+// the configuration are moved to the credentials file. It fails if
+// there are credentials but no key, as they are never saved in the
+// clear.
+func (c *ClientCfg) saveCredentials() error {
+	creds := c.credentials()
+	if creds == c.stored && !c.inClear {
+		return nil
+	}
+
+	key, err := c.credentialsKey()
+	if err != nil {
+		if creds == (Credentials{}) {
+			return nil
+		}
+		return errors.Wrap(err, "credentials")
+	}
+
+	data, err := yaml.Marshal(&creds)
+	if err != nil {
+		return err
+	}
+	if data, err = keys.Encrypt(data, key); err != nil {
+		return err
+	}
+	if err = writeFile(c.credentialsPath, data); err != nil {
+		return err
+	}
+	c.stored, c.inClear = creds, false
+
+	return nil
+}
+
+// credentialsKey returns the key of the credentials file: the key of
+// the key file if it is configured, otherwise the key of the vault.
+func (c *ClientCfg) credentialsKey() (*[keys.KeySize]byte, error) {
+	if c.KeyFile != "" {
+		return c.readKeyFile()
+	}
+	if c.key == nil {
+		return nil, errors.New("the key of the credentials is not set")
+	}
+
+	return c.key()
+}
+
+// readKeyFile reads the key file, creating it with a random key if it
+// does not exist.
+func (c *ClientCfg) readKeyFile() (*[keys.KeySize]byte, error) {
+	data, err := os.ReadFile(c.KeyFile)
+	if errors.Is(err, os.ErrNotExist) {
+		data = make([]byte, keys.KeySize)
+		if _, err = io.ReadFull(rand.Reader, data); err != nil {
+			return nil, err
+		}
+		err = writeFile(c.KeyFile, data)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if len(data) != keys.KeySize {
+		return nil, errors.Wrapf(keys.ErrInvalidKey, "key file %v", c.KeyFile)
+	}
+
+	key := new([keys.KeySize]byte)
+	copy(key[:], data)
+
+	return key, nil
+}
@@ -0,0 +1,49 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+)
+
+// appName is the name of the directories of the cli.
+const appName = "gophkeeper"
+
+// ConfigDir returns the directory of the configuration of the cli,
+// following the XDG Base Directory Specification.
+func ConfigDir() (string, error) {
+	return xdgDir("XDG_CONFIG_HOME", ".config")
+}
+
+// DataDir returns the directory of the data of the cli, such as the
+// local database, following the XDG Base Directory Specification.
+func DataDir() (string, error) {
+	return xdgDir("XDG_DATA_HOME", filepath.Join(".local", "share"))
+}
+
+// RuntimeDir returns the directory of the runtime files of the cli,
+// such as the sockets, or an empty string if `XDG_RUNTIME_DIR` is not
+// set.
+func RuntimeDir() string {
+	if dir := os.Getenv("XDG_RUNTIME_DIR"); filepath.IsAbs(dir) {
+		return filepath.Join(dir, appName)
+	}
+
+	return ""
+}
+
+// xdgDir returns the directory of the cli in the base directory set
+// with the environment variable, or in the given directory of the home
+// directory. Relative paths in the variable are ignored, as the
+// specification requires.
+func xdgDir(env, home string) (string, error) {
+	if dir := os.Getenv(env); filepath.IsAbs(dir) {
+		return filepath.Join(dir, appName), nil
+	}
+
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.Join(homeDir, home, appName), nil
+}
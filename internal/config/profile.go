@@ -0,0 +1,170 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	// DefaultProfile is the profile used when none is selected. Its
+	// files are placed in the directories of the cli directly.
+	DefaultProfile = "default"
+	// ProfileEnv is the environment variable selecting the profile.
+	ProfileEnv = "GOPHKEEPER_PROFILE"
+
+	profilesDir = "profiles"
+	currentFile = "profile"
+)
+
+var (
+	// ErrProfileNotFound is returned when the profile does not exist.
+	ErrProfileNotFound = errors.New("profile not found")
+	// ErrProfileExists is returned when the profile already exists.
+	ErrProfileExists = errors.New("profile already exists")
+	// ErrDefaultProfile is returned when the default profile is removed.
+	ErrDefaultProfile = errors.New("the default profile cannot be removed")
+	// ErrInvalidProfile is returned when the name of the profile is
+	// not valid.
+	ErrInvalidProfile = errors.New("the name of a profile must consist of letters, digits, '.', '_' and '-'")
+
+	profileName = regexp.MustCompile(`^[a-zA-Z0-9][a-zA-Z0-9._-]*$`)
+)
+
+// ResolveProfile returns the profile to use: the given one, the one set
+// with `GOPHKEEPER_PROFILE`, the one selected with `UseProfile`, or the
+// default profile.
+func ResolveProfile(profile string) (string, error) {
+	if profile != "" {
+		return profile, nil
+	}
+	if profile = os.Getenv(ProfileEnv); profile != "" {
+		return profile, nil
+	}
+
+	return CurrentProfile()
+}
+
+// CurrentProfile returns the profile selected with `UseProfile`, or the
+// default profile.
+func CurrentProfile() (string, error) {
+	configDir, err := ConfigDir()
+	if err != nil {
+		return "", err
+	}
+
+	data, err := os.ReadFile(filepath.Join(configDir, currentFile))
+	if errors.Is(err, os.ErrNotExist) {
+		return DefaultProfile, nil
+	}
+	if err != nil {
+		return "", err
+	}
+	if profile := strings.TrimSpace(string(data)); profile != "" {
+		return profile, nil
+	}
+
+	return DefaultProfile, nil
+}
+
+// UseProfile selects the existing profile used when none is set.
+func UseProfile(profile string) error {
+	if profile != DefaultProfile && !ProfileExists(profile) {
+		return ErrProfileNotFound
+	}
+
+	configDir, err := ConfigDir()
+	if err != nil {
+		return err
+	}
+
+	return writeFile(filepath.Join(configDir, currentFile), []byte(profile+"\n"))
+}
+
+// ProfileExists reports whether the configuration of the profile exists.
+func ProfileExists(profile string) bool {
+	configDir, _, err := profileDirs(profile)
+	if err != nil {
+		return false
+	}
+
+	return exists(filepath.Join(configDir, clientConfigFile))
+}
+
+// Profiles returns the names of the existing profiles, sorted.
+func Profiles() ([]string, error) {
+	configDir, err := ConfigDir()
+	if err != nil {
+		return nil, err
+	}
+
+	var profiles []string
+	if ProfileExists(DefaultProfile) {
+		profiles = append(profiles, DefaultProfile)
+	}
+
+	entries, err := os.ReadDir(filepath.Join(configDir, profilesDir))
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return nil, err
+	}
+	for _, entry := range entries {
+		if entry.IsDir() && ProfileExists(entry.Name()) {
+			profiles = append(profiles, entry.Name())
+		}
+	}
+	sort.Strings(profiles)
+
+	return profiles, nil
+}
+
+// RemoveProfile removes the configuration, the credentials and the
+// local database of the profile. The default profile cannot be removed.
+func RemoveProfile(profile string) error {
+	if profile == DefaultProfile {
+		return ErrDefaultProfile
+	}
+	if !ProfileExists(profile) {
+		return ErrProfileNotFound
+	}
+
+	configDir, dataDir, err := profileDirs(profile)
+	if err != nil {
+		return err
+	}
+	if err = os.RemoveAll(configDir); err != nil {
+		return err
+	}
+	if err = os.RemoveAll(dataDir); err != nil {
+		return err
+	}
+
+	if current, err := CurrentProfile(); err != nil || current != profile {
+		return err
+	}
+
+	return UseProfile(DefaultProfile)
+}
+
+// profileDirs returns the configuration and the data directories of
+// the profile.
+func profileDirs(profile string) (configDir, dataDir string, err error) {
+	if !profileName.MatchString(profile) {
+		return "", "", ErrInvalidProfile
+	}
+
+	if configDir, err = ConfigDir(); err != nil {
+		return "", "", err
+	}
+	if dataDir, err = DataDir(); err != nil {
+		return "", "", err
+	}
+	if profile == DefaultProfile {
+		return configDir, dataDir, nil
+	}
+
+	return filepath.Join(configDir, profilesDir, profile), filepath.Join(dataDir, profilesDir, profile), nil
+}
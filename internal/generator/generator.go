@@ -0,0 +1,222 @@
+// Package generator implements the generator of the random passwords
+// and of the diceware passphrases, and reports their entropy.
+package generator
+
+import (
+	_ "embed" // the wordlist of the passphrases
+	"math"
+	"math/big"
+	"strings"
+
+	"github.com/iryzzh/y-gophkeeper/internal/rand"
+	"github.com/pkg/errors"
+)
+
+const (
+	lowerChars  = "abcdefghijklmnopqrstuvwxyz"
+	upperChars  = "ABCDEFGHIJKLMNOPQRSTUVWXYZ"
+	digitChars  = "0123456789"
+	symbolChars = "!#$%&*+-./:=?@^_~"
+	// ambiguousChars are the characters easily confused with others.
+	ambiguousChars = "0O1Il"
+
+	// MaxLength is the maximum length of a password.
+	MaxLength = 1024
+	// MaxWords is the maximum number of words of a passphrase.
+	MaxWords = 64
+)
+
+var (
+	// ErrLength is returned when the length of the password is out of
+	// range or shorter than the number of the character classes.
+	ErrLength = errors.New("invalid length of the password")
+	// ErrNoClasses is returned when no character class is selected.
+	ErrNoClasses = errors.New("at least one character class is required")
+	// ErrWords is returned when the number of words is out of range.
+	ErrWords = errors.New("invalid number of words of the passphrase")
+)
+
+//go:embed wordlist.txt
+var wordlistData string
+
+// wordlist is the diceware wordlist: 6^4 short words, one for every
+// roll of four dice.
+var wordlist = parseWordlist(wordlistData)
+
+// PasswordOptions are the options of a password.
+type PasswordOptions struct {
+	Length  int
+	Lower   bool
+	Upper   bool
+	Digits  bool
+	Symbols bool
+	// NoAmbiguous excludes the characters easily confused with others,
+	// such as 'l', '1' and 'I'.
+	NoAmbiguous bool
+}
+
+// DefaultPassword are the default options of a password.
+var DefaultPassword = PasswordOptions{Length: 20, Lower: true, Upper: true, Digits: true, Symbols: true}
+
+// PassphraseOptions are the options of a passphrase.
+type PassphraseOptions struct {
+	Words     int
+	Separator string
+	// Capitalize capitalizes the first letter of every word. It does
+	// not add to the entropy.
+	Capitalize bool
+}
+
+// DefaultPassphrase are the default options of a passphrase.
+var DefaultPassphrase = PassphraseOptions{Words: 6, Separator: "-"}
+
+// Secret is a generated password or passphrase.
+type Secret struct {
+	Value string
+	// Entropy is the entropy of the secret in bits.
+	Entropy float64
+}
+
+// Password returns a random password containing at least one
+// character of every selected class. The passwords missing a class are
+// discarded, so that the password is uniformly random among the ones
+// containing all the classes.
+func Password(opts PasswordOptions) (*Secret, error) {
+	classes := opts.classes()
+	if len(classes) == 0 {
+		return nil, ErrNoClasses
+	}
+	if opts.Length < len(classes) || opts.Length > MaxLength {
+		return nil, errors.Wrapf(ErrLength, "expected %d to %d characters", len(classes), MaxLength)
+	}
+
+	charset := strings.Join(classes, "")
+	password := make([]byte, opts.Length)
+	for {
+		for i := range password {
+			password[i] = charset[rand.Intn(len(charset))]
+		}
+		if hasAll(password, classes) {
+			break
+		}
+	}
+
+	return &Secret{Value: string(password), Entropy: passwordEntropy(opts.Length, classes)}, nil
+}
+
+// Passphrase returns a passphrase of the words picked at random from
+// the diceware wordlist.
+func Passphrase(opts PassphraseOptions) (*Secret, error) {
+	if opts.Words < 1 || opts.Words > MaxWords {
+		return nil, errors.Wrapf(ErrWords, "expected 1 to %d words", MaxWords)
+	}
+
+	words := make([]string, opts.Words)
+	for i := range words {
+		words[i] = wordlist[rand.Intn(len(wordlist))]
+		if opts.Capitalize {
+			words[i] = strings.ToUpper(words[i][:1]) + words[i][1:]
+		}
+	}
+
+	return &Secret{
+		Value:   strings.Join(words, opts.Separator),
+		Entropy: float64(opts.Words) * math.Log2(float64(len(wordlist))),
+	}, nil
+}
+
+// classes returns the character sets of the selected classes.
+func (o PasswordOptions) classes() []string {
+	var classes []string
+	for _, class := range []struct {
+		selected bool
+		chars    string
+	}{
+		{o.Lower, lowerChars},
+		{o.Upper, upperChars},
+		{o.Digits, digitChars},
+		{o.Symbols, symbolChars},
+	} {
+		if !class.selected {
+			continue
+		}
+		chars := class.chars
+		if o.NoAmbiguous {
+			chars = strings.Map(func(r rune) rune {
+				if strings.ContainsRune(ambiguousChars, r) {
+					return -1
+				}
+				return r
+			}, chars)
+		}
+		classes = append(classes, chars)
+	}
+
+	return classes
+}
+
+// hasAll reports whether the password contains a character of every
+// class.
+func hasAll(password []byte, classes []string) bool {
+	for _, class := range classes {
+		if !strings.ContainsAny(string(password), class) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// passwordEntropy returns the entropy of the password of the length
+// containing all the classes: the logarithm of the number of such
+// passwords, counted with the inclusion-exclusion principle.
+func passwordEntropy(length int, classes []string) float64 {
+	total := 0
+	for _, class := range classes {
+		total += len(class)
+	}
+
+	count := new(big.Int)
+	for subset := 0; subset < 1<<len(classes); subset++ {
+		// the passwords missing the classes of the subset
+		size, missing := total, 0
+		for i, class := range classes {
+			if subset&(1<<i) != 0 {
+				size -= len(class)
+				missing++
+			}
+		}
+		term := new(big.Int).Exp(big.NewInt(int64(size)), big.NewInt(int64(length)), nil)
+		if missing%2 == 0 {
+			count.Add(count, term)
+		} else {
+			count.Sub(count, term)
+		}
+	}
+
+	return log2(count)
+}
+
+// log2 returns the binary logarithm of the positive number.
+func log2(n *big.Int) float64 {
+	shift := n.BitLen() - 64 //nolint:gomnd
+	if shift < 0 {
+		shift = 0
+	}
+	top := new(big.Int).Rsh(n, uint(shift))
+
+	return math.Log2(float64(top.Uint64())) + float64(shift)
+}
+
+// parseWordlist returns the words of the diceware wordlist, one per
+// line after the roll of the dice.
+func parseWordlist(data string) []string {
+	lines := strings.Split(strings.TrimSpace(data), "\n")
+	words := make([]string, 0, len(lines))
+	for _, line := range lines {
+		fields := strings.Fields(line)
+		words = append(words, fields[len(fields)-1])
+	}
+
+	return words
+}
@@ -0,0 +1,55 @@
+package generator
+
+import (
+	"math"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestPassword(t *testing.T) {
+	for i := 0; i < 100; i++ {
+		secret, err := Password(PasswordOptions{Length: 4, Lower: true, Upper: true, Digits: true, Symbols: true})
+		require.NoError(t, err)
+		require.Len(t, secret.Value, 4)
+		for _, class := range []string{lowerChars, upperChars, digitChars, symbolChars} {
+			require.True(t, strings.ContainsAny(secret.Value, class), secret.Value)
+		}
+	}
+
+	secret, err := Password(PasswordOptions{Length: 200, Lower: true, Upper: true, Digits: true, NoAmbiguous: true})
+	require.NoError(t, err)
+	require.False(t, strings.ContainsAny(secret.Value, ambiguousChars+symbolChars), secret.Value)
+
+	secret, err = Password(PasswordOptions{Length: 10, Digits: true})
+	require.NoError(t, err)
+	require.InDelta(t, 10*math.Log2(10), secret.Entropy, 1e-9)
+
+	// 26^2 - 2 * 13^2 passwords of two letters contain both halves
+	require.InDelta(t, math.Log2(26*26-2*13*13), passwordEntropy(2, []string{lowerChars[:13], lowerChars[13:]}), 1e-9)
+
+	_, err = Password(PasswordOptions{Length: 10})
+	require.ErrorIs(t, err, ErrNoClasses)
+	_, err = Password(PasswordOptions{Length: 3, Lower: true, Upper: true, Digits: true, Symbols: true})
+	require.ErrorIs(t, err, ErrLength)
+	_, err = Password(PasswordOptions{Length: MaxLength + 1, Lower: true})
+	require.ErrorIs(t, err, ErrLength)
+}
+
+func TestPassphrase(t *testing.T) {
+	require.Len(t, wordlist, 6*6*6*6)
+
+	secret, err := Passphrase(PassphraseOptions{Words: 5, Separator: " ", Capitalize: true})
+	require.NoError(t, err)
+	words := strings.Split(secret.Value, " ")
+	require.Len(t, words, 5)
+	for _, word := range words {
+		require.Contains(t, wordlist, strings.ToLower(word))
+		require.Equal(t, strings.ToUpper(word[:1]), word[:1])
+	}
+	require.InDelta(t, 5*math.Log2(1296), secret.Entropy, 1e-9)
+
+	_, err = Passphrase(PassphraseOptions{})
+	require.ErrorIs(t, err, ErrWords)
+}
@@ -0,0 +1,114 @@
+package health
+
+import (
+	"math"
+	"strings"
+	"unicode"
+)
+
+// commonPasswords are the most common passwords, guessed first.
+var commonPasswords = []string{
+	"123456", "password", "12345678", "qwerty", "123456789", "12345", "1234", "111111",
+	"1234567", "dragon", "123123", "baseball", "abc123", "football", "monkey", "letmein",
+	"696969", "shadow", "master", "666666", "qwertyuiop", "123321", "mustang", "1234567890",
+	"michael", "654321", "superman", "1qaz2wsx", "7777777", "121212", "000000", "qazwsx",
+	"123qwe", "killer", "trustno1", "jordan", "jennifer", "zxcvbnm", "asdfgh", "hunter",
+	"buster", "soccer", "harley", "batman", "andrew", "tigger", "sunshine", "iloveyou",
+	"2000", "charlie", "robert", "thomas", "hockey", "ranger", "daniel", "starwars",
+	"112233", "george", "computer", "michelle", "jessica", "pepper", "1111",
+	"zxcvbn", "555555", "11111111", "131313", "freedom", "777777", "pass", "maggie",
+	"159753", "aaaaaa", "ginger", "princess", "joshua", "cheese", "amanda", "summer",
+	"love", "ashley", "nicole", "chelsea", "biteme", "matthew", "access", "yankees",
+	"987654321", "dallas", "austin", "thunder", "taylor", "matrix", "admin", "welcome",
+	"passw0rd", "p@ssw0rd", "changeme", "secret",
+}
+
+// keyboardRows are the rows of the keyboard. The neighbour keys typed
+// in a row are guessed as a sequence.
+var keyboardRows = []string{"1234567890", "qwertyuiop", "asdfghjkl", "zxcvbnm"}
+
+// Entropy returns an estimate of the entropy of the password in bits:
+// the logarithm of the size of the pool of its characters times its
+// length. The repeated characters, the alphabetical sequences and the
+// keys typed along a keyboard row add nothing, and the common
+// passwords, with digits appended or not, are guessed at once.
+func Entropy(password string) float64 {
+	if password == "" {
+		return 0
+	}
+
+	lower := strings.ToLower(password)
+	stem := strings.TrimRightFunc(lower, unicode.IsDigit)
+	for _, common := range commonPasswords {
+		switch common {
+		case lower:
+			return math.Log2(float64(len(commonPasswords)))
+		case stem:
+			return math.Log2(float64(len(commonPasswords))) + float64(len(lower)-len(stem))*math.Log2(10) //nolint:gomnd
+		}
+	}
+
+	return float64(effectiveLength(lower)) * math.Log2(float64(poolSize(password)))
+}
+
+// poolSize returns the size of the pool of the characters of the
+// password.
+func poolSize(password string) int {
+	var lower, upper, digit, symbol, other bool
+	for _, r := range password {
+		switch {
+		case r >= 'a' && r <= 'z':
+			lower = true
+		case r >= 'A' && r <= 'Z':
+			upper = true
+		case r >= '0' && r <= '9':
+			digit = true
+		case r < unicode.MaxASCII && unicode.IsPrint(r):
+			symbol = true
+		default:
+			other = true
+		}
+	}
+
+	size := 0
+	for _, class := range []struct {
+		present bool
+		size    int
+	}{{lower, 26}, {upper, 26}, {digit, 10}, {symbol, 33}, {other, 100}} { //nolint:gomnd
+		if class.present {
+			size += class.size
+		}
+	}
+
+	return size
+}
+
+// effectiveLength returns the number of the characters of the password
+// which are not guessed from the previous one: repeated, next or
+// previous in the alphabet, or neighbour on the keyboard.
+func effectiveLength(password string) int {
+	length := 0
+	var prev rune = -1
+	for _, r := range password {
+		if prev < 0 || !(r == prev || r == prev+1 || r == prev-1 || neighbours(prev, r)) {
+			length++
+		}
+		prev = r
+	}
+
+	return length
+}
+
+// neighbours reports whether the keys are next to each other in a row
+// of the keyboard.
+func neighbours(a, b rune) bool {
+	for _, row := range keyboardRows {
+		i := strings.IndexRune(row, a)
+		j := strings.IndexRune(row, b)
+		if i >= 0 && j >= 0 && (i-j == 1 || j-i == 1) {
+			return true
+		}
+	}
+
+	return false
+}
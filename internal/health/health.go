@@ -0,0 +1,265 @@
+// Package health implements the health report of a vault: the weak,
+// reused and old passwords and the bank cards expiring soon. The
+// report is built from the decrypted items and never contains the
+// secrets.
+package health
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/iryzzh/y-gophkeeper/internal/models"
+)
+
+// The kinds of the issues.
+const (
+	KindWeak     = "weak"
+	KindReused   = "reused"
+	KindOld      = "old"
+	KindExpiring = "expiring"
+	KindExpired  = "expired"
+)
+
+// Options are the thresholds of the report.
+type Options struct {
+	// MinEntropy is the estimated entropy in bits below which a
+	// password is weak.
+	MinEntropy float64
+	// MaxAge is the age of the last modification of an entry after
+	// which its secrets need to be rotated.
+	MaxAge time.Duration
+	// ExpiryWindow is the time before the expiration of a card from
+	// which it expires soon.
+	ExpiryWindow time.Duration
+	// Now is the time of the report, the current time if zero.
+	Now time.Time
+}
+
+// DefaultOptions are the default thresholds of the report.
+var DefaultOptions = Options{
+	MinEntropy:   60,                   //nolint:gomnd
+	MaxAge:       365 * 24 * time.Hour, //nolint:gomnd
+	ExpiryWindow: 60 * 24 * time.Hour,  //nolint:gomnd
+}
+
+// Issue is an issue of an entry.
+type Issue struct {
+	Entry string `json:"entry"`
+	// Field is the name of the secret field with the issue, empty for
+	// the value of the entry.
+	Field  string `json:"field,omitempty"`
+	Kind   string `json:"kind"`
+	Detail string `json:"detail"`
+}
+
+// Report is the health report of a vault.
+type Report struct {
+	// Score is the percentage of the checked entries without issues.
+	Score int `json:"score"`
+	// Entries is the number of the checked entries: the text entries
+	// and the entries with secret fields, and the cards.
+	Entries int `json:"entries"`
+	// Healthy is the number of the checked entries without issues.
+	Healthy int `json:"healthy"`
+	// Summary is the number of the issues of every kind.
+	Summary map[string]int `json:"summary"`
+	Issues  []*Issue       `json:"issues"`
+}
+
+// secret is a password of an entry.
+type secret struct {
+	entry, field, value string
+}
+
+// Check returns the health report of the decrypted items. The items
+// other than the text entries and the cards are skipped.
+func Check(items []*models.Item, opts Options) (*Report, error) {
+	if opts.Now.IsZero() {
+		opts.Now = time.Now()
+	}
+
+	report := &Report{
+		Summary: map[string]int{KindWeak: 0, KindReused: 0, KindOld: 0, KindExpiring: 0, KindExpired: 0},
+		Issues:  []*Issue{},
+	}
+	unhealthy := map[string]struct{}{}
+	add := func(issue *Issue) {
+		report.Issues = append(report.Issues, issue)
+		report.Summary[issue.Kind]++
+		unhealthy[issue.Entry] = struct{}{}
+	}
+
+	var secrets []*secret
+	for _, item := range items {
+		switch item.DataType {
+		case models.EntryTypeCard:
+			issue, err := checkCard(item, opts)
+			if err != nil {
+				return nil, err
+			}
+			if issue != nil {
+				add(issue)
+			}
+		case models.EntryTypeText:
+			found, err := itemSecrets(item)
+			if err != nil {
+				return nil, err
+			}
+			if len(found) == 0 {
+				continue
+			}
+			secrets = append(secrets, found...)
+		default:
+			continue
+		}
+		report.Entries++
+
+		if modified := lastModified(item); modified != nil && opts.MaxAge > 0 && opts.Now.Sub(*modified) > opts.MaxAge {
+			add(&Issue{
+				Entry:  item.Meta,
+				Kind:   KindOld,
+				Detail: fmt.Sprintf("not changed for %d days", int(opts.Now.Sub(*modified).Hours()/24)), //nolint:gomnd
+			})
+		}
+	}
+
+	for _, s := range secrets {
+		if entropy := Entropy(s.value); entropy < opts.MinEntropy {
+			add(&Issue{Entry: s.entry, Field: s.field, Kind: KindWeak, Detail: fmt.Sprintf("%.1f bits of entropy", entropy)})
+		}
+	}
+	for _, issue := range reused(secrets) {
+		add(issue)
+	}
+
+	report.Healthy = report.Entries - len(unhealthy)
+	report.Score = 100 //nolint:gomnd
+	if report.Entries > 0 {
+		report.Score = report.Healthy * 100 / report.Entries //nolint:gomnd
+	}
+
+	return report, nil
+}
+
+// itemSecrets returns the value of the text entry and its secret
+// fields.
+func itemSecrets(item *models.Item) ([]*secret, error) {
+	var secrets []*secret
+	if item.ItemData != nil {
+		value, err := item.ItemData.DecodeDataToString()
+		if err != nil {
+			return nil, err
+		}
+		if value != "" {
+			secrets = append(secrets, &secret{entry: item.Meta, value: value})
+		}
+	}
+	for _, field := range item.Fields {
+		if field.Secret && field.Value != "" {
+			secrets = append(secrets, &secret{entry: item.Meta, field: field.Name, value: field.Value})
+		}
+	}
+
+	return secrets, nil
+}
+
+// reused returns the issues of the secrets used more than once.
+func reused(secrets []*secret) []*Issue {
+	byValue := map[string][]*secret{}
+	for _, s := range secrets {
+		byValue[s.value] = append(byValue[s.value], s)
+	}
+
+	var issues []*Issue
+	for _, s := range secrets {
+		same := byValue[s.value]
+		if len(same) < 2 { //nolint:gomnd
+			continue
+		}
+
+		var others []string
+		for _, other := range same {
+			if other != s {
+				others = append(others, other.name())
+			}
+		}
+		sort.Strings(others)
+		issues = append(issues, &Issue{
+			Entry:  s.entry,
+			Field:  s.field,
+			Kind:   KindReused,
+			Detail: "also used by " + strings.Join(others, ", "),
+		})
+	}
+
+	return issues
+}
+
+// name returns the name of the entry of the secret with its field.
+func (s *secret) name() string {
+	if s.field == "" {
+		return s.entry
+	}
+
+	return s.entry + "." + s.field
+}
+
+// checkCard returns the issue of the card expired or expiring soon.
+func checkCard(item *models.Item, opts Options) (*Issue, error) {
+	if item.ItemData == nil {
+		return nil, nil
+	}
+
+	card := &models.Card{}
+	if err := card.Decode(item.ItemData.Data); err != nil {
+		return nil, err
+	}
+	expiry, ok := CardExpiry(card)
+	if !ok {
+		return nil, nil
+	}
+
+	switch {
+	case !opts.Now.Before(expiry):
+		return &Issue{Entry: item.Meta, Kind: KindExpired, Detail: "expired " + expiry.AddDate(0, -1, 0).Format("01/2006")}, nil
+	case expiry.Sub(opts.Now) <= opts.ExpiryWindow:
+		return &Issue{
+			Entry:  item.Meta,
+			Kind:   KindExpiring,
+			Detail: fmt.Sprintf("expires in %d days", int(expiry.Sub(opts.Now).Hours()/24)), //nolint:gomnd
+		}, nil
+	}
+
+	return nil, nil
+}
+
+// CardExpiry returns the time the card expires: the beginning of the
+// month following its expiration month. The year may be two digits.
+// It returns false if the expiration date of the card is not valid.
+func CardExpiry(card *models.Card) (time.Time, bool) {
+	month, err := strconv.Atoi(strings.TrimSpace(card.Month))
+	if err != nil || month < 1 || month > 12 {
+		return time.Time{}, false
+	}
+	year, err := strconv.Atoi(strings.TrimSpace(card.Year))
+	if err != nil || year < 0 {
+		return time.Time{}, false
+	}
+	if year < 100 { //nolint:gomnd
+		year += 2000
+	}
+
+	return time.Date(year, time.Month(month)+1, 1, 0, 0, 0, 0, time.Local), true
+}
+
+// lastModified returns the time of the last modification of the item.
+func lastModified(item *models.Item) *time.Time {
+	if item.UpdatedAt != nil {
+		return item.UpdatedAt
+	}
+
+	return item.CreatedAt
+}
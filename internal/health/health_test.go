@@ -0,0 +1,70 @@
+package health
+
+import (
+	"encoding/base64"
+	"testing"
+	"time"
+
+	"github.com/iryzzh/y-gophkeeper/internal/models"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEntropy(t *testing.T) {
+	require.Zero(t, Entropy(""))
+	require.Less(t, Entropy("password"), 10.0)
+	require.Less(t, Entropy("Password2024"), 30.0)
+	require.Less(t, Entropy("aaaaaaaaaaaaaaaa"), 10.0)
+	require.Less(t, Entropy("abcdefghijklmnop"), 10.0)
+	require.Less(t, Entropy("qwertyuiop"), 10.0)
+	require.Greater(t, Entropy("_2P58V1wZnnv/QC7FF&S"), 100.0)
+}
+
+func TestCheck(t *testing.T) {
+	now := time.Date(2024, 5, 15, 12, 0, 0, 0, time.Local)
+	recent := now.AddDate(0, -1, 0)
+	old := now.AddDate(-2, 0, 0)
+
+	text := func(name, value string, modified time.Time, fields ...*models.Field) *models.Item {
+		return &models.Item{
+			Meta:      name,
+			DataType:  models.EntryTypeText,
+			ItemData:  &models.ItemData{Data: []byte(base64.StdEncoding.EncodeToString([]byte(value)))},
+			Fields:    fields,
+			CreatedAt: &modified,
+		}
+	}
+	card := func(name, month, year string) *models.Item {
+		data, err := (&models.Card{Number: "4242424242424242", Month: month, Year: year, CVV: "123"}).Encode()
+		require.NoError(t, err)
+		return &models.Item{Meta: name, DataType: models.EntryTypeCard, ItemData: &models.ItemData{Data: data}, CreatedAt: &recent}
+	}
+
+	report, err := Check([]*models.Item{
+		text("strong", "_2P58V1wZnnv/QC7FF&S", recent),
+		text("weak", "letmein", recent),
+		text("mail", "ewCsV4gDzzVRn2iiDldqyGCpOugkK18c", recent),
+		text("bank", "note", recent, &models.Field{Name: "pin", Value: "ewCsV4gDzzVRn2iiDldqyGCpOugkK18c", Secret: true}),
+		text("archive", "town-hover-blush-pear-pouch", old),
+		card("visa", "6", "24"),
+		card("master", "4", "24"),
+		card("amex", "12", "2030"),
+		{Meta: "photo", DataType: models.EntryTypeImage, CreatedAt: &old},
+	}, Options{MinEntropy: 60, MaxAge: 365 * 24 * time.Hour, ExpiryWindow: 60 * 24 * time.Hour, Now: now})
+	require.NoError(t, err)
+
+	require.Equal(t, 8, report.Entries)
+	require.Equal(t, 2, report.Healthy)
+	require.Equal(t, 25, report.Score)
+	require.Equal(t, map[string]int{KindWeak: 2, KindReused: 2, KindOld: 1, KindExpiring: 1, KindExpired: 1}, report.Summary)
+
+	issues := map[string][]string{}
+	for _, issue := range report.Issues {
+		issues[issue.Kind] = append(issues[issue.Kind], issue.Entry+"/"+issue.Field+": "+issue.Detail)
+		require.NotContains(t, issue.Detail, "ewCsV4gD")
+	}
+	require.Equal(t, []string{"mail/: also used by bank.pin", "bank/pin: also used by mail"}, issues[KindReused])
+	require.Equal(t, []string{"archive/: not changed for 731 days"}, issues[KindOld])
+	require.Equal(t, []string{"visa/: expires in 46 days"}, issues[KindExpiring])
+	require.Equal(t, []string{"master/: expired 04/2024"}, issues[KindExpired])
+	require.Len(t, issues[KindWeak], 2)
+}
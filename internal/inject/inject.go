@@ -0,0 +1,53 @@
+package inject
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"text/template"
+)
+
+// Resolver returns the value of the secret stored under the given
+// name. Text entries are expected to be returned as strings,
+// structured entries (e.g. `models.Card`) as values whose fields
+// can be accessed from the template.
+type Resolver func(ctx context.Context, name string) (interface{}, error)
+
+// Render parses the template read from src, resolves each
+// `secret "path/name"` call with the resolver and writes the
+// result to dst. Every secret is resolved at most once.
+//
+// Example:
+//
+//	password = {{ secret "db/password" }}
+//	card     = {{ (secret "cards/visa").Number }}
+func Render(ctx context.Context, name string, src io.Reader, dst io.Writer, resolve Resolver) error {
+	text, err := io.ReadAll(src)
+	if err != nil {
+		return err
+	}
+
+	resolved := make(map[string]interface{})
+	funcs := template.FuncMap{
+		"secret": func(name string) (interface{}, error) {
+			if v, ok := resolved[name]; ok {
+				return v, nil
+			}
+
+			v, err := resolve(ctx, name)
+			if err != nil {
+				return nil, fmt.Errorf("secret %q: %w", name, err)
+			}
+			resolved[name] = v
+
+			return v, nil
+		},
+	}
+
+	tmpl, err := template.New(name).Funcs(funcs).Option("missingkey=error").Parse(string(text))
+	if err != nil {
+		return err
+	}
+
+	return tmpl.Execute(dst, nil)
+}
@@ -0,0 +1,78 @@
+package inject
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/iryzzh/y-gophkeeper/internal/models"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRender(t *testing.T) {
+	errNotFound := errors.New("not found")
+	secrets := map[string]interface{}{
+		"db/password": "s3cr3t",
+		"cards/visa": &models.Card{
+			Type:   "Visa",
+			Number: "4111111111111111",
+			Month:  "12",
+			Year:   "25",
+			CVV:    "123",
+		},
+	}
+
+	tests := []struct {
+		name     string
+		template string
+		want     string
+		wantErr  error
+	}{
+		{
+			name:     "text secret",
+			template: `password = {{ secret "db/password" }}`,
+			want:     "password = s3cr3t",
+		},
+		{
+			name:     "card fields",
+			template: `{{ with secret "cards/visa" }}{{ .Number }} {{ .Month }}/{{ .Year }}{{ end }}`,
+			want:     "4111111111111111 12/25",
+		},
+		{
+			name:     "repeated secret",
+			template: `{{ secret "db/password" }}:{{ secret "db/password" }}`,
+			want:     "s3cr3t:s3cr3t",
+		},
+		{
+			name:     "unknown secret",
+			template: `{{ secret "db/unknown" }}`,
+			wantErr:  errNotFound,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			calls := make(map[string]int)
+			resolve := func(_ context.Context, name string) (interface{}, error) {
+				calls[name]++
+				if v, ok := secrets[name]; ok {
+					return v, nil
+				}
+				return nil, errNotFound
+			}
+
+			var out bytes.Buffer
+			err := Render(context.Background(), tt.name, strings.NewReader(tt.template), &out, resolve)
+			if tt.wantErr != nil {
+				require.ErrorIs(t, err, tt.wantErr)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.want, out.String())
+			for name, n := range calls {
+				require.Equalf(t, 1, n, "secret %q resolved %d times", name, n)
+			}
+		})
+	}
+}
@@ -0,0 +1,163 @@
+// Package keys implements the public-key encryption used to share
+// items between users. Every user has a Curve25519 keypair, the data
+// of a shared item is encrypted with a random item key and the item
+// key is sealed for every user having access to the item, so that the
+// server never sees the data or the keys in the clear.
+package keys
+
+import (
+	"crypto/rand"
+	"io"
+
+	"github.com/pkg/errors"
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/nacl/box"
+	"golang.org/x/crypto/nacl/secretbox"
+)
+
+// KeySize is the size of the public, private and item keys.
+const KeySize = 32
+
+// SaltSize is the size of the salt of `DeriveKey`.
+const SaltSize = 16
+
+const nonceSize = 24
+
+// Overhead is the number of bytes `Encrypt` adds to the data.
+const Overhead = nonceSize + secretbox.Overhead
+
+// The parameters of `DeriveKey`. They must never change, since the
+// local vaults are encrypted with the derived keys.
+const (
+	kdfTime    = 3
+	kdfMemory  = 64 * 1024 // KiB
+	kdfThreads = 2
+)
+
+var (
+	// ErrInvalidKey is returned when the key has an invalid size.
+	ErrInvalidKey = errors.New("invalid key")
+	// ErrDecryptionFailed is returned when the data or the item key
+	// cannot be decrypted with the given key.
+	ErrDecryptionFailed = errors.New("decryption failed")
+)
+
+// KeyPair is the keypair of a user.
+type KeyPair struct {
+	Public  *[KeySize]byte
+	Private *[KeySize]byte
+}
+
+// Generate generates a new keypair.
+func Generate() (*KeyPair, error) {
+	public, private, err := box.GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+
+	return &KeyPair{Public: public, Private: private}, nil
+}
+
+// NewKeyPair returns the keypair made of the given keys.
+func NewKeyPair(public, private []byte) (*KeyPair, error) {
+	pub, err := toKey(public)
+	if err != nil {
+		return nil, err
+	}
+
+	priv, err := toKey(private)
+	if err != nil {
+		return nil, err
+	}
+
+	return &KeyPair{Public: pub, Private: priv}, nil
+}
+
+// NewItemKey generates a new random item key.
+func NewItemKey() (*[KeySize]byte, error) {
+	key := new([KeySize]byte)
+	if _, err := io.ReadFull(rand.Reader, key[:]); err != nil {
+		return nil, err
+	}
+
+	return key, nil
+}
+
+// NewSalt generates a new random salt for `DeriveKey`.
+func NewSalt() ([]byte, error) {
+	salt := make([]byte, SaltSize)
+	if _, err := io.ReadFull(rand.Reader, salt); err != nil {
+		return nil, err
+	}
+
+	return salt, nil
+}
+
+// DeriveKey derives the key of the local vault from the master
+// password with Argon2id.
+func DeriveKey(password string, salt []byte) (*[KeySize]byte, error) {
+	if len(salt) != SaltSize {
+		return nil, ErrInvalidKey
+	}
+
+	return toKey(argon2.IDKey([]byte(password), salt, kdfTime, kdfMemory, kdfThreads, KeySize))
+}
+
+// Seal encrypts the item key for the owner of the public key.
+func Seal(itemKey *[KeySize]byte, public []byte) ([]byte, error) {
+	pub, err := toKey(public)
+	if err != nil {
+		return nil, err
+	}
+
+	return box.SealAnonymous(nil, itemKey[:], pub, rand.Reader)
+}
+
+// Open decrypts the item key sealed for the owner of the keypair.
+func (kp *KeyPair) Open(sealed []byte) (*[KeySize]byte, error) {
+	key, ok := box.OpenAnonymous(nil, sealed, kp.Public, kp.Private)
+	if !ok {
+		return nil, ErrDecryptionFailed
+	}
+
+	return toKey(key)
+}
+
+// Encrypt encrypts the data with the item key. The random nonce is
+// prepended to the result.
+func Encrypt(data []byte, itemKey *[KeySize]byte) ([]byte, error) {
+	var nonce [nonceSize]byte
+	if _, err := io.ReadFull(rand.Reader, nonce[:]); err != nil {
+		return nil, err
+	}
+
+	return secretbox.Seal(nonce[:], data, &nonce, itemKey), nil
+}
+
+// Decrypt decrypts the data encrypted with `Encrypt`.
+func Decrypt(data []byte, itemKey *[KeySize]byte) ([]byte, error) {
+	if len(data) < nonceSize {
+		return nil, ErrDecryptionFailed
+	}
+
+	var nonce [nonceSize]byte
+	copy(nonce[:], data[:nonceSize])
+
+	decrypted, ok := secretbox.Open(nil, data[nonceSize:], &nonce, itemKey)
+	if !ok {
+		return nil, ErrDecryptionFailed
+	}
+
+	return decrypted, nil
+}
+
+func toKey(b []byte) (*[KeySize]byte, error) {
+	if len(b) != KeySize {
+		return nil, ErrInvalidKey
+	}
+
+	key := new([KeySize]byte)
+	copy(key[:], b)
+
+	return key, nil
+}
@@ -0,0 +1,88 @@
+package keys
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestShare(t *testing.T) {
+	owner, err := Generate()
+	require.NoError(t, err)
+	recipient, err := Generate()
+	require.NoError(t, err)
+	stranger, err := Generate()
+	require.NoError(t, err)
+
+	itemKey, err := NewItemKey()
+	require.NoError(t, err)
+
+	encrypted, err := Encrypt([]byte("secret"), itemKey)
+	require.NoError(t, err)
+	require.NotContains(t, string(encrypted), "secret")
+
+	sealed, err := Seal(itemKey, recipient.Public[:])
+	require.NoError(t, err)
+
+	opened, err := recipient.Open(sealed)
+	require.NoError(t, err)
+	require.Equal(t, itemKey, opened)
+
+	decrypted, err := Decrypt(encrypted, opened)
+	require.NoError(t, err)
+	require.Equal(t, []byte("secret"), decrypted)
+
+	_, err = stranger.Open(sealed)
+	require.ErrorIs(t, err, ErrDecryptionFailed)
+
+	_, err = owner.Open(sealed)
+	require.ErrorIs(t, err, ErrDecryptionFailed)
+
+	otherKey, err := NewItemKey()
+	require.NoError(t, err)
+	_, err = Decrypt(encrypted, otherKey)
+	require.ErrorIs(t, err, ErrDecryptionFailed)
+
+	_, err = Decrypt([]byte("short"), itemKey)
+	require.ErrorIs(t, err, ErrDecryptionFailed)
+}
+
+func TestNewKeyPair(t *testing.T) {
+	kp, err := Generate()
+	require.NoError(t, err)
+
+	restored, err := NewKeyPair(kp.Public[:], kp.Private[:])
+	require.NoError(t, err)
+	require.Equal(t, kp, restored)
+
+	_, err = NewKeyPair(kp.Public[:16], kp.Private[:])
+	require.ErrorIs(t, err, ErrInvalidKey)
+
+	_, err = Seal(new([KeySize]byte), []byte("short"))
+	require.ErrorIs(t, err, ErrInvalidKey)
+}
+
+func TestDeriveKey(t *testing.T) {
+	salt, err := NewSalt()
+	require.NoError(t, err)
+
+	key, err := DeriveKey("password", salt)
+	require.NoError(t, err)
+
+	same, err := DeriveKey("password", salt)
+	require.NoError(t, err)
+	require.Equal(t, key, same)
+
+	other, err := DeriveKey("other", salt)
+	require.NoError(t, err)
+	require.NotEqual(t, key, other)
+
+	otherSalt, err := NewSalt()
+	require.NoError(t, err)
+	other, err = DeriveKey("password", otherSalt)
+	require.NoError(t, err)
+	require.NotEqual(t, key, other)
+
+	_, err = DeriveKey("password", salt[1:])
+	require.ErrorIs(t, err, ErrInvalidKey)
+}
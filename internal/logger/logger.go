@@ -0,0 +1,119 @@
+// Package logger provides the structured leveled logger of the server
+// and carries the request scoped fields, such as the request id and
+// the user id, in the context.
+package logger
+
+import (
+	"context"
+	"io"
+	"sync"
+
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+)
+
+// Formats of the log.
+const (
+	FormatJSON = "json"
+	FormatText = "text"
+)
+
+// ErrInvalidFormat is returned when the format of the log is unknown.
+var ErrInvalidFormat = errors.New("invalid log format")
+
+type ctxKey struct{}
+
+// holder keeps the entry of the request, so that the fields added by
+// the inner handlers are seen by the outer ones.
+type holder struct {
+	mu    sync.RWMutex
+	entry *logrus.Entry
+}
+
+// New creates a logger writing to the writer with the given format and
+// the minimal level.
+func New(w io.Writer, format, level string) (*logrus.Logger, error) {
+	l := logrus.New()
+	l.SetOutput(w)
+
+	lvl, err := logrus.ParseLevel(level)
+	if err != nil {
+		return nil, err
+	}
+	l.SetLevel(lvl)
+
+	switch format {
+	case FormatJSON:
+		l.SetFormatter(&logrus.JSONFormatter{})
+	case FormatText:
+		l.SetFormatter(&logrus.TextFormatter{DisableColors: true, FullTimestamp: true})
+	default:
+		return nil, errors.Wrap(ErrInvalidFormat, format)
+	}
+
+	return l, nil
+}
+
+// NewContext returns a copy of the context carrying the entry.
+func NewContext(ctx context.Context, entry *logrus.Entry) context.Context {
+	return context.WithValue(ctx, ctxKey{}, &holder{entry: entry})
+}
+
+// FromContext returns the entry carried by the context or the entry of
+// the standard logger if there is none.
+func FromContext(ctx context.Context) *logrus.Entry {
+	if h, ok := ctx.Value(ctxKey{}).(*holder); ok {
+		h.mu.RLock()
+		defer h.mu.RUnlock()
+
+		return h.entry
+	}
+
+	return logrus.NewEntry(logrus.StandardLogger())
+}
+
+// AddFields adds the fields to the entry carried by the context. It
+// does nothing if the context carries no entry.
+func AddFields(ctx context.Context, fields logrus.Fields) {
+	if h, ok := ctx.Value(ctxKey{}).(*holder); ok {
+		h.mu.Lock()
+		h.entry = h.entry.WithFields(fields)
+		h.mu.Unlock()
+	}
+}
+
+// Failure logs the error, unless it is nil or already logged, with the
+// fields and the entry carried by the context, and returns it marked as
+// logged. The services and the store log their unexpected failures with
+// it, so that the failures carry the request id and the user id.
+func Failure(ctx context.Context, err error, msg string, fields logrus.Fields) error {
+	if err == nil || Logged(err) {
+		return err
+	}
+	FromContext(ctx).WithFields(fields).WithError(err).Error(msg)
+
+	return &logged{error: err}
+}
+
+// Logged reports whether the error was logged by `Failure`, so that the
+// handlers of the error do not log it again.
+func Logged(err error) bool {
+	var l *logged
+
+	return errors.As(err, &l)
+}
+
+// logged is the error logged by `Failure`.
+type logged struct {
+	error
+}
+
+// Unwrap returns the logged error.
+func (e *logged) Unwrap() error {
+	return e.error
+}
+
+// Cause returns the logged error, for `errors.Cause`.
+func (e *logged) Cause() error {
+	return e.error
+}
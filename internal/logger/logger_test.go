@@ -0,0 +1,87 @@
+package logger
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNew(t *testing.T) {
+	var buf bytes.Buffer
+
+	l, err := New(&buf, FormatJSON, "warn")
+	require.NoError(t, err)
+
+	l.Info("skipped")
+	l.WithField("request_id", "abc").Warn("logged")
+
+	var line map[string]interface{}
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &line))
+	require.Equal(t, "logged", line["msg"])
+	require.Equal(t, "warning", line["level"])
+	require.Equal(t, "abc", line["request_id"])
+
+	buf.Reset()
+	l, err = New(&buf, FormatText, "debug")
+	require.NoError(t, err)
+	l.WithField("user_id", "u1").Debug("text")
+	require.True(t, strings.Contains(buf.String(), `msg=text user_id=u1`), buf.String())
+
+	_, err = New(&buf, "xml", "info")
+	require.ErrorIs(t, err, ErrInvalidFormat)
+
+	_, err = New(&buf, FormatJSON, "loud")
+	require.Error(t, err)
+}
+
+func TestContext(t *testing.T) {
+	var buf bytes.Buffer
+
+	l, err := New(&buf, FormatJSON, "info")
+	require.NoError(t, err)
+
+	require.NotNil(t, FromContext(context.Background()))
+	AddFields(context.Background(), logrus.Fields{"user_id": "u1"})
+
+	ctx := NewContext(context.Background(), l.WithField("request_id", "abc"))
+	AddFields(ctx, logrus.Fields{"user_id": "u1"})
+	FromContext(ctx).Info("request")
+
+	var line map[string]interface{}
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &line))
+	require.Equal(t, "abc", line["request_id"])
+	require.Equal(t, "u1", line["user_id"])
+}
+
+func TestFailure(t *testing.T) {
+	var buf bytes.Buffer
+
+	l, err := New(&buf, FormatJSON, "info")
+	require.NoError(t, err)
+	ctx := NewContext(context.Background(), l.WithField("request_id", "abc"))
+
+	require.NoError(t, Failure(ctx, nil, "skipped", nil))
+	require.Zero(t, buf.Len())
+
+	failure := errors.New("disk I/O error")
+	require.False(t, Logged(failure))
+	err = Failure(ctx, failure, "item update failed", logrus.Fields{"item_id": 1})
+	require.ErrorIs(t, err, failure)
+	require.Equal(t, failure, errors.Cause(err))
+	require.True(t, Logged(errors.Wrap(err, "update")))
+	require.Equal(t, err, Failure(ctx, err, "logged again", nil))
+
+	var line map[string]interface{}
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &line))
+	require.Equal(t, "item update failed", line["msg"])
+	require.Equal(t, "error", line["level"])
+	require.Equal(t, "abc", line["request_id"])
+	require.Equal(t, float64(1), line["item_id"])
+	require.Equal(t, "disk I/O error", line["error"])
+}
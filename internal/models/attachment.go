@@ -0,0 +1,15 @@
+package models
+
+import "time"
+
+// Attachment is a named file attached to an item in addition to its
+// data. The content of the attachments is transferred apart from the
+// items, as is, and only their descriptions are listed in
+// 'Item.Attachments'.
+type Attachment struct {
+	ID        int        `json:"id,omitempty"`
+	ItemID    int        `json:"item_id,omitempty"`
+	Name      string     `json:"name"`
+	Size      int64      `json:"size"`
+	CreatedAt *time.Time `json:"created_at,omitempty"`
+}
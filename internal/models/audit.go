@@ -0,0 +1,70 @@
+package models
+
+import "time"
+
+// AuditAction is the security-relevant action recorded in the audit
+// log.
+type AuditAction string
+
+const (
+	AuditSignup           AuditAction = "signup"
+	AuditLogin            AuditAction = "login"
+	AuditLoginFailed      AuditAction = "login_failed"
+	AuditTokenRefresh     AuditAction = "token_refresh"
+	AuditKeySet           AuditAction = "key_set"
+	AuditItemCreate       AuditAction = "item_create"
+	AuditItemRead         AuditAction = "item_read"
+	AuditItemUpdate       AuditAction = "item_update"
+	AuditItemDelete       AuditAction = "item_delete"
+	AuditItemMove         AuditAction = "item_move"
+	AuditItemRestore      AuditAction = "item_restore"
+	AuditTrashEmpty       AuditAction = "trash_empty"
+	AuditItemShare        AuditAction = "item_share"
+	AuditItemUnshare      AuditAction = "item_unshare"
+	AuditItemAttach       AuditAction = "item_attach"
+	AuditItemDetach       AuditAction = "item_detach"
+	AuditOrgCreate        AuditAction = "org_create"
+	AuditOrgDelete        AuditAction = "org_delete"
+	AuditMemberSet        AuditAction = "member_set"
+	AuditMemberRemove     AuditAction = "member_remove"
+	AuditCollectionCreate AuditAction = "collection_create"
+	AuditCollectionDelete AuditAction = "collection_delete"
+	AuditEmergencyGrant   AuditAction = "emergency_grant"
+	AuditEmergencyRevoke  AuditAction = "emergency_revoke"
+	AuditEmergencyRequest AuditAction = "emergency_request"
+	AuditEmergencyApprove AuditAction = "emergency_approve"
+	AuditEmergencyDeny    AuditAction = "emergency_deny"
+	AuditEmergencyAccess  AuditAction = "emergency_access"
+	AuditAdminDisable     AuditAction = "admin_disable"
+	AuditAdminEnable      AuditAction = "admin_enable"
+	AuditAdminLogout      AuditAction = "admin_logout"
+	AuditAdminQuota       AuditAction = "admin_quota"
+	AuditAdminReset2FA    AuditAction = "admin_reset_2fa"
+	Audit2FAEnable        AuditAction = "2fa_enable"
+	Audit2FADisable       AuditAction = "2fa_disable"
+)
+
+// AuditEvent is the record of the audit log. `AuditEvent.Target` is
+// the login, the organization or the collection the action was
+// applied to, if any.
+type AuditEvent struct {
+	ID        int64       `json:"id"`
+	UserID    string      `json:"-"`
+	SessionID string      `json:"session_id,omitempty"`
+	IP        string      `json:"ip,omitempty"`
+	Action    AuditAction `json:"action"`
+	ItemID    int         `json:"item_id,omitempty"`
+	Target    string      `json:"target,omitempty"`
+	CreatedAt *time.Time  `json:"created_at,omitempty"`
+}
+
+// AuditFilter describes the criteria for querying the audit log. Empty
+// fields are not used for filtering.
+type AuditFilter struct {
+	// Action limits the result to the given action.
+	Action AuditAction
+	// Since limits the result to the events recorded after the time.
+	Since  *time.Time
+	Limit  int
+	Offset int
+}
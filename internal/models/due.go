@@ -0,0 +1,23 @@
+package models
+
+import "time"
+
+// The reasons an item is due.
+const (
+	// DueExpires is the reason of an item expiring.
+	DueExpires = "expires"
+	// DueRotate is the reason of an item due for rotation.
+	DueRotate = "rotate"
+)
+
+// Due is an item expiring or due for rotation. An item due for both
+// reasons is reported twice.
+type Due struct {
+	ItemID   int       `json:"item_id"`
+	UserID   string    `json:"user_id,omitempty"`
+	Meta     string    `json:"meta"`
+	DataType string    `json:"data_type,omitempty"`
+	Reason   string    `json:"reason"`
+	DueAt    time.Time `json:"due_at"`
+	Overdue  bool      `json:"overdue"`
+}
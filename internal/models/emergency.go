@@ -0,0 +1,37 @@
+package models
+
+import "time"
+
+// EmergencyStatus is the status of an emergency access.
+type EmergencyStatus string
+
+const (
+	// EmergencyIdle is the status of the granted emergency access
+	// which has not been requested by the contact.
+	EmergencyIdle EmergencyStatus = "idle"
+	// EmergencyRequested is the status of the emergency access
+	// requested by the contact and waiting for the release.
+	EmergencyRequested EmergencyStatus = "requested"
+	// EmergencyApproved is the status of the emergency access approved
+	// by the grantor or released after the waiting period.
+	EmergencyApproved EmergencyStatus = "approved"
+)
+
+// EmergencyAccess is the access to the vault of the grantor granted
+// to a trusted contact. `EmergencyAccess.Key` is the vault key, that
+// is the private key of the grantor, sealed with the public key of the
+// contact. The server releases it to the contact once the access is
+// approved by the grantor or the waiting period after the request
+// passes without the grantor denying it.
+type EmergencyAccess struct {
+	GrantorID   string          `json:"-"`
+	GranteeID   string          `json:"-"`
+	Grantor     string          `json:"grantor,omitempty"`
+	Grantee     string          `json:"grantee,omitempty"`
+	Key         []byte          `json:"key,omitempty"`
+	Wait        time.Duration   `json:"wait,omitempty"`
+	Status      EmergencyStatus `json:"status,omitempty"`
+	RequestedAt *time.Time      `json:"requested_at,omitempty"`
+	ReleasedAt  *time.Time      `json:"released_at,omitempty"`
+	CreatedAt   *time.Time      `json:"created_at,omitempty"`
+}
@@ -0,0 +1,48 @@
+package models
+
+// ErrorCode is the machine-readable code of an error returned by the
+// api. The codes are stable, unlike the messages.
+type ErrorCode string
+
+const (
+	// CodeBadRequest is the code of a malformed request.
+	CodeBadRequest ErrorCode = "bad_request"
+	// CodeValidation is the code of a request with invalid values,
+	// detailed in `Error.Fields` where possible.
+	CodeValidation ErrorCode = "validation_failed"
+	// CodeUnauthorized is the code of a request with missing or invalid
+	// credentials.
+	CodeUnauthorized ErrorCode = "unauthorized"
+	// CodeOTPRequired is the code of a login of a user with the second
+	// factor enabled without the one-time code.
+	CodeOTPRequired ErrorCode = "otp_required"
+	// CodeForbidden is the code of a request the user is not allowed to make.
+	CodeForbidden ErrorCode = "forbidden"
+	// CodeNotFound is the code of a request for a missing resource.
+	CodeNotFound ErrorCode = "not_found"
+	// CodeConflict is the code of a request conflicting with the
+	// current state of the resource.
+	CodeConflict ErrorCode = "conflict"
+	// CodeRateLimited is the code of a request rejected because of too
+	// many requests.
+	CodeRateLimited ErrorCode = "rate_limited"
+	// CodeQuotaExceeded is the code of a request which would exceed the
+	// storage quota of the user.
+	CodeQuotaExceeded ErrorCode = "quota_exceeded"
+	// CodeInternal is the code of a request the server failed to process.
+	CodeInternal ErrorCode = "internal_error"
+)
+
+// Error is the body of every error response of the api. `Error.Fields`
+// maps the names of the invalid fields to the reasons.
+type Error struct {
+	Code      ErrorCode         `json:"code"`
+	Message   string            `json:"message"`
+	RequestID string            `json:"request_id,omitempty"`
+	Fields    map[string]string `json:"fields,omitempty"`
+}
+
+// Error returns the message of the error.
+func (e *Error) Error() string {
+	return e.Message
+}
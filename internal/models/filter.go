@@ -0,0 +1,27 @@
+package models
+
+import "time"
+
+// ItemFilter describes the criteria for searching items. Empty
+// fields are not used for filtering.
+type ItemFilter struct {
+	// Query is matched against the item name: full words and
+	// prefixes first, then characters in the same order (fuzzy).
+	Query string
+	// WithMetadata extends the query match to the non-secret
+	// metadata of the item.
+	WithMetadata bool
+	// Types limits the result to the given data types.
+	Types []string
+	// Tags limits the result to the items having all the given tags.
+	Tags []string
+	// Folder limits the result to the item with the given name and
+	// the items in the folder with this name, including subfolders.
+	Folder string
+	// Since and Until limit the result by the date of the last
+	// modification of the item.
+	Since  *time.Time
+	Until  *time.Time
+	Limit  int
+	Offset int
+}
@@ -0,0 +1,29 @@
+package models
+
+import "strings"
+
+// FolderSeparator separates the folders in the item name.
+const FolderSeparator = "/"
+
+// Move is a request to rename or move an item or a folder with all
+// its items. The field `Move.Moved` is filled in with the number of
+// moved items.
+type Move struct {
+	From  string `json:"from"`
+	To    string `json:"to"`
+	Moved int    `json:"moved,omitempty"`
+}
+
+// CleanPath removes the leading, trailing and duplicate separators
+// from the item or folder name.
+func CleanPath(path string) string {
+	parts := strings.Split(path, FolderSeparator)
+	result := parts[:0]
+	for _, p := range parts {
+		if p = strings.TrimSpace(p); p != "" {
+			result = append(result, p)
+		}
+	}
+
+	return strings.Join(result, FolderSeparator)
+}
@@ -9,18 +9,43 @@ import (
 
 // Item is the model of the item. The fields `Item.ID`,
 // 'Item.DataID', 'Item.CreatedAt', 'Item.UpdatedAt' are filled
-// by the database service after creating or updating. Field
+// by the database service after creating or updating, the field
+// 'Item.DeletedAt' is set for the items in the trash. The fields
+// 'Item.Key', 'Item.Owner' and 'Item.ReadOnly' describe the access of
+// the requesting user to a shared item, see `models.Share`. Field
 // 'Item.DataID' must correspond to field 'Item.ID' of
 // `models.ItemData` struct.
 type Item struct {
-	ID        int        `json:"id,omitempty"`
-	UserID    string     `json:"user_id,omitempty"`
-	Meta      string     `json:"meta"`
-	DataID    int        `json:"data_id,omitempty"`
-	DataType  string     `json:"data_type,omitempty"`
-	ItemData  *ItemData  `json:"item_data,omitempty"`
-	CreatedAt *time.Time `json:"created_at,omitempty"`
-	UpdatedAt *time.Time `json:"updated_at,omitempty"`
+	ID       int       `json:"id,omitempty"`
+	UserID   string    `json:"user_id,omitempty"`
+	Meta     string    `json:"meta"`
+	DataID   int       `json:"data_id,omitempty"`
+	DataType string    `json:"data_type,omitempty"`
+	ItemData *ItemData `json:"item_data,omitempty"`
+	Tags     []string  `json:"tags,omitempty"`
+	Fields   []*Field  `json:"fields,omitempty"`
+	// ExpiresAt is the time the secret of the item expires, if any.
+	ExpiresAt *time.Time `json:"expires_at,omitempty"`
+	// RotateDays is the number of days after the last change of the
+	// item in which its secret is due for rotation, zero if never.
+	RotateDays int `json:"rotate_days,omitempty"`
+	// Attachments are the descriptions of the files attached to the
+	// item, they are neither saved nor updated with the item.
+	Attachments []*Attachment `json:"attachments,omitempty"`
+	CreatedAt   *time.Time    `json:"created_at,omitempty"`
+	UpdatedAt   *time.Time    `json:"updated_at,omitempty"`
+	DeletedAt   *time.Time    `json:"deleted_at,omitempty"`
+	Key         []byte        `json:"key,omitempty"`
+	Owner       string        `json:"owner,omitempty"`
+	ReadOnly    bool          `json:"read_only,omitempty"`
+}
+
+// Field is a custom key/value field of the item, e.g. a username or
+// an URL. Secret fields are neither listed nor indexed for search.
+type Field struct {
+	Name   string `json:"name"`
+	Value  string `json:"value"`
+	Secret bool   `json:"secret,omitempty"`
 }
 
 // ItemData is a data model. The field `ItemData.ID` are
@@ -47,20 +72,20 @@ func (i *Item) Marshal() ([]byte, error) {
 
 func (id *ItemData) DecodeDataToString() (string, error) {
 	buf := make([]byte, base64.StdEncoding.DecodedLen(len(id.Data)))
-	_, err := base64.StdEncoding.Decode(buf, id.Data)
+	n, err := base64.StdEncoding.Decode(buf, id.Data)
 	if err != nil {
 		return "", err
 	}
 
-	return string(buf), nil
+	return string(buf[:n]), nil
 }
 
 func (id *ItemData) DecodeDataToBytes() ([]byte, error) {
 	buf := make([]byte, base64.StdEncoding.DecodedLen(len(id.Data)))
-	_, err := base64.StdEncoding.Decode(buf, id.Data)
+	n, err := base64.StdEncoding.Decode(buf, id.Data)
 	if err != nil {
 		return nil, err
 	}
 
-	return buf, nil
+	return buf[:n], nil
 }
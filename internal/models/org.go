@@ -0,0 +1,61 @@
+package models
+
+import "time"
+
+// Role is the role of a member of an organization.
+type Role string
+
+const (
+	// RoleMember can view, create and update the items of the
+	// collections of the organization.
+	RoleMember Role = "member"
+	// RoleAdmin can also delete and restore the items, and manage
+	// the collections and the members of the organization.
+	RoleAdmin Role = "admin"
+	// RoleOwner can also manage the owners and delete the organization.
+	RoleOwner Role = "owner"
+)
+
+// roleRanks orders the roles by their permissions.
+var roleRanks = map[Role]int{
+	RoleMember: 1,
+	RoleAdmin:  2,
+	RoleOwner:  3,
+}
+
+// Valid reports whether the role is known.
+func (r Role) Valid() bool {
+	_, ok := roleRanks[r]
+	return ok
+}
+
+// Includes reports whether the role has all the permissions of the
+// other role.
+func (r Role) Includes(other Role) bool {
+	return r.Valid() && roleRanks[r] >= roleRanks[other]
+}
+
+// Org is an organization owning collections of items shared by its
+// members. `Org.Role` is the role of the requesting user.
+type Org struct {
+	ID        string     `json:"id"`
+	Name      string     `json:"name"`
+	Role      Role       `json:"role,omitempty"`
+	CreatedAt *time.Time `json:"created_at,omitempty"`
+}
+
+// Member is a member of an organization.
+type Member struct {
+	UserID string `json:"-"`
+	Login  string `json:"login"`
+	Role   Role   `json:"role"`
+}
+
+// Collection is a vault of an organization. The items of a collection
+// are owned by the collection rather than by a user.
+type Collection struct {
+	ID        string     `json:"id"`
+	OrgID     string     `json:"org_id"`
+	Name      string     `json:"name"`
+	CreatedAt *time.Time `json:"created_at,omitempty"`
+}
@@ -0,0 +1,17 @@
+package models
+
+// Share grants the user with the given login access to an item. The
+// data of a shared item is encrypted with the item key, and `Key` is
+// the item key sealed with the public key of the recipient.
+type Share struct {
+	Login    string `json:"login"`
+	Key      []byte `json:"key"`
+	ReadOnly bool   `json:"read_only,omitempty"`
+}
+
+// PublicKey is the public key of the user used to seal the item keys
+// of the items shared with the user.
+type PublicKey struct {
+	Login string `json:"login,omitempty"`
+	Key   []byte `json:"key"`
+}
@@ -0,0 +1,25 @@
+package models
+
+// Stats contains the counters of the data in the store.
+type Stats struct {
+	Users        int64
+	ActiveUsers  int64
+	Items        int64
+	TrashedItems int64
+	Blobs        int64
+	BlobBytes    int64
+}
+
+// MigrationStatus is the status of the schema migrations of the store.
+// The store is up to date if `MigrationStatus.Version` is
+// `MigrationStatus.Latest` and the migration is not dirty.
+type MigrationStatus struct {
+	Version uint `json:"version"`
+	Latest  uint `json:"latest"`
+	Dirty   bool `json:"dirty"`
+}
+
+// UpToDate reports whether all the migrations are applied.
+func (s *MigrationStatus) UpToDate() bool {
+	return s.Version == s.Latest && !s.Dirty
+}
@@ -9,6 +9,7 @@ type Token struct {
 	RefreshToken string `json:"refresh_token"`
 	Login        string `json:"-"`
 	UserID       string `json:"-"`
+	SessionID    string `json:"-"`
 }
 
 func (t *Token) UnmarshalFromString(payload string) error {
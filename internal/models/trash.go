@@ -0,0 +1,16 @@
+package models
+
+import "time"
+
+// Tombstone records the deletion of an item, so that the other
+// clients of the user can delete their copy of the item on sync.
+type Tombstone struct {
+	ItemID    int       `json:"item_id"`
+	Meta      string    `json:"meta"`
+	DeletedAt time.Time `json:"deleted_at"`
+}
+
+// Purge is the result of emptying the trash.
+type Purge struct {
+	Purged int `json:"purged"`
+}
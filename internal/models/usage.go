@@ -0,0 +1,17 @@
+package models
+
+// Usage is the storage used by the personal vault of the user. The
+// items in the trash are counted until they are purged.
+type Usage struct {
+	Items int64 `json:"items"`
+	Bytes int64 `json:"bytes"`
+}
+
+// Quota limits the storage of the personal vault of the user: the total
+// size of the items, the number of the items and the size of a single
+// item in bytes. Zero means no limit.
+type Quota struct {
+	MaxBytes     int64 `json:"max_bytes"`
+	MaxItems     int64 `json:"max_items"`
+	MaxItemBytes int64 `json:"max_item_bytes"`
+}
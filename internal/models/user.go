@@ -1,18 +1,46 @@
 package models
 
-import jsoniter "github.com/json-iterator/go"
+import (
+	"time"
 
-// User contains information about the user.
+	jsoniter "github.com/json-iterator/go"
+)
+
+// User contains information about the user. The disabled user cannot
+// log in or refresh the tokens. The tokens issued with another
+// `User.TokenVersion` are rejected, so that the sessions of the user
+// are revoked by incrementing it. The user with `User.TwoFactor` logs
+// in with the one-time code of the TOTP secret as `User.OTP`. The
+// administrator manages the other users with the admin api.
 type User struct {
-	ID           string `json:"id"`
-	Login        string `json:"login"`
-	Password     string `json:"password,omitempty"`
-	PasswordHash string `json:"-"`
+	ID           string     `json:"id"`
+	Login        string     `json:"login"`
+	Password     string     `json:"password,omitempty"`
+	OTP          string     `json:"otp,omitempty"`
+	PasswordHash string     `json:"-"`
+	Admin        bool       `json:"admin,omitempty"`
+	TwoFactor    bool       `json:"two_factor,omitempty"`
+	TOTPSecret   string     `json:"-"`
+	TOTPPending  string     `json:"-"`
+	DisabledAt   *time.Time `json:"disabled_at,omitempty"`
+	TokenVersion int        `json:"-"`
+	Usage        *Usage     `json:"usage,omitempty"`
+	Quota        *Quota     `json:"quota,omitempty"`
 }
 
-// Sanitize clears the password field.
+// Sanitize clears the password and the one-time code fields.
 func (u *User) Sanitize() {
 	u.Password = ""
+	u.OTP = ""
+}
+
+// TwoFactor is the TOTP secret of the second factor being enabled,
+// with its 'otpauth' URL, or the one-time code confirming or disabling
+// the second factor.
+type TwoFactor struct {
+	Secret string `json:"secret,omitempty"`
+	URL    string `json:"url,omitempty"`
+	Code   string `json:"code,omitempty"`
 }
 
 // Marshal returns the JSON encoding of user.
@@ -0,0 +1,81 @@
+// Package notify implements the notifiers sending the reminders of the
+// items expiring or due for rotation to the users. The server only
+// depends on the `Notifier` interface, so that the reminders can be
+// sent by email or any other channel; the `Writer` writes them as JSON
+// lines to a file or the standard output.
+package notify
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"os"
+	"sync"
+
+	"github.com/iryzzh/y-gophkeeper/internal/models"
+)
+
+// Stdout is the path of the standard output.
+const Stdout = "-"
+
+// Reminder is the reminder of the items of a vault expiring or due for
+// rotation.
+type Reminder struct {
+	UserID string `json:"user_id"`
+	// Login is the login of the owner of the vault, empty for the
+	// vaults of the collections.
+	Login string        `json:"login,omitempty"`
+	Items []*models.Due `json:"items"`
+}
+
+// Notifier sends the reminders.
+type Notifier interface {
+	Notify(ctx context.Context, reminder *Reminder) error
+}
+
+// Writer is the notifier writing every reminder as a line of JSON.
+type Writer struct {
+	mu  sync.Mutex
+	w   io.Writer
+	enc *json.Encoder
+}
+
+var _ Notifier = (*Writer)(nil)
+
+// NewWriter returns the notifier writing the reminders to the writer.
+func NewWriter(w io.Writer) *Writer {
+	return &Writer{w: w, enc: json.NewEncoder(w)}
+}
+
+// Open returns the notifier appending the reminders to the file at
+// the path, created if needed, or writing them to the standard output
+// if the path is `Stdout`.
+func Open(path string) (*Writer, error) {
+	if path == Stdout {
+		return NewWriter(os.Stdout), nil
+	}
+
+	f, err := os.OpenFile(path, os.O_WRONLY|os.O_APPEND|os.O_CREATE, 0o600) //nolint:gomnd
+	if err != nil {
+		return nil, err
+	}
+
+	return NewWriter(f), nil
+}
+
+// Notify writes the reminder.
+func (w *Writer) Notify(_ context.Context, reminder *Reminder) error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	return w.enc.Encode(reminder)
+}
+
+// Close closes the file of the notifier, if any.
+func (w *Writer) Close() error {
+	if c, ok := w.w.(io.Closer); ok && w.w != os.Stdout {
+		return c.Close()
+	}
+
+	return nil
+}
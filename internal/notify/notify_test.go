@@ -0,0 +1,51 @@
+package notify
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/iryzzh/y-gophkeeper/internal/models"
+	"github.com/stretchr/testify/require"
+)
+
+func TestWriter(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "reminders.jsonl")
+
+	for i := 0; i < 2; i++ {
+		w, err := Open(path)
+		require.NoError(t, err)
+		require.NoError(t, w.Notify(context.Background(), &Reminder{
+			UserID: "id",
+			Login:  "alice",
+			Items: []*models.Due{
+				{ItemID: i, Meta: "mail", Reason: models.DueExpires, DueAt: time.Now()},
+			},
+		}))
+		require.NoError(t, w.Close())
+	}
+
+	f, err := os.Open(path)
+	require.NoError(t, err)
+	defer func() { _ = f.Close() }()
+
+	var lines int
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		var reminder Reminder
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), &reminder))
+		require.Equal(t, "alice", reminder.Login)
+		require.Len(t, reminder.Items, 1)
+		require.Equal(t, lines, reminder.Items[0].ItemID)
+		lines++
+	}
+	require.NoError(t, scanner.Err())
+	require.Equal(t, 2, lines)
+
+	_, err = Open(filepath.Join(t.TempDir(), "missing", "reminders.jsonl"))
+	require.Error(t, err)
+}
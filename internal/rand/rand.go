@@ -31,3 +31,21 @@ func String(n int) string {
 func Uint64() uint64 {
 	return defaultSecureSource.Uint64()
 }
+
+// Intn returns a cryptographically secure uniformly random number in
+// [0, n). It panics if n <= 0.
+func Intn(n int) int {
+	if n <= 0 {
+		panic("invalid argument to Intn")
+	}
+
+	// the values below the threshold are rejected, so that the rest
+	// are evenly divisible by n and the result has no modulo bias
+	max := uint64(n)
+	threshold := -max % max
+	for {
+		if v := Uint64(); v >= threshold {
+			return int(v % max)
+		}
+	}
+}
@@ -0,0 +1,214 @@
+// Package metrics implements the Prometheus metrics of the server:
+// the requests processed by the API, the authentication events, the
+// data in the store, the connection pool of the database and the Go
+// runtime.
+package metrics
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/go-chi/chi/v5/middleware"
+	"github.com/iryzzh/y-gophkeeper/internal/store"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "gophkeeper"
+
+// activePeriod is the period in which the user must have an event in
+// the audit log to be counted as active.
+const activePeriod = 24 * time.Hour
+
+// collectTimeout limits the time of the queries collecting the
+// metrics of the store.
+const collectTimeout = 5 * time.Second
+
+// Metrics is the registry of the metrics of the server. The nil
+// `*Metrics` is valid and records nothing.
+type Metrics struct {
+	registry        *prometheus.Registry
+	requests        *prometheus.CounterVec
+	requestDuration *prometheus.HistogramVec
+	authFailures    *prometheus.CounterVec
+	tokensIssued    *prometheus.CounterVec
+}
+
+// New creates the metrics of the server collecting the data of the
+// store on every scrape.
+func New(st store.Store) *Metrics {
+	m := &Metrics{
+		registry: prometheus.NewRegistry(),
+		requests: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "http_requests_total",
+			Help:      "Number of the processed HTTP requests.",
+		}, []string{"method", "route", "status"}),
+		requestDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: namespace,
+			Name:      "http_request_duration_seconds",
+			Help:      "Latency of the processed HTTP requests.",
+			Buckets:   prometheus.DefBuckets,
+		}, []string{"method", "route", "status"}),
+		authFailures: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "auth_failures_total",
+			Help:      "Number of the failed authentications.",
+		}, []string{"reason"}),
+		tokensIssued: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: namespace,
+			Name:      "tokens_issued_total",
+			Help:      "Number of the issued tokens.",
+		}, []string{"kind"}),
+	}
+
+	m.registry.MustRegister(
+		m.requests,
+		m.requestDuration,
+		m.authFailures,
+		m.tokensIssued,
+		newStoreCollector(st),
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+	)
+
+	return m
+}
+
+// Handler returns the handler exposing the metrics.
+func (m *Metrics) Handler() http.Handler {
+	return promhttp.HandlerFor(m.registry, promhttp.HandlerOpts{})
+}
+
+// Middleware counts the requests and measures their latency per route
+// pattern, so that the ids in the path do not produce new series.
+func (m *Metrics) Middleware(next http.Handler) http.Handler {
+	if m == nil {
+		return next
+	}
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		ww := middleware.NewWrapResponseWriter(w, r.ProtoMajor)
+
+		next.ServeHTTP(ww, r)
+
+		route := "unknown"
+		if rctx := chi.RouteContext(r.Context()); rctx != nil && rctx.RoutePattern() != "" {
+			route = rctx.RoutePattern()
+		}
+		status := ww.Status()
+		if status == 0 {
+			status = http.StatusOK
+		}
+
+		labels := prometheus.Labels{"method": r.Method, "route": route, "status": strconv.Itoa(status)}
+		m.requests.With(labels).Inc()
+		m.requestDuration.With(labels).Observe(time.Since(start).Seconds())
+	})
+}
+
+// AuthFailure counts the failed authentication with the given reason.
+func (m *Metrics) AuthFailure(reason string) {
+	if m == nil {
+		return
+	}
+
+	m.authFailures.WithLabelValues(reason).Inc()
+}
+
+// TokenIssued counts the token issued on the signup, the login or the
+// refresh.
+func (m *Metrics) TokenIssued(kind string) {
+	if m == nil {
+		return
+	}
+
+	m.tokensIssued.WithLabelValues(kind).Inc()
+}
+
+// storeCollector collects the counters of the data and the statistics
+// of the connection pool of the store.
+type storeCollector struct {
+	store       store.Store
+	users       *prometheus.Desc
+	activeUsers *prometheus.Desc
+	items       *prometheus.Desc
+	blobs       *prometheus.Desc
+	blobBytes   *prometheus.Desc
+	dbOpen      *prometheus.Desc
+	dbInUse     *prometheus.Desc
+	dbIdle      *prometheus.Desc
+	dbWaits     *prometheus.Desc
+	dbWaitTime  *prometheus.Desc
+}
+
+func newStoreCollector(st store.Store) *storeCollector {
+	return &storeCollector{
+		store: st,
+		users: prometheus.NewDesc(prometheus.BuildFQName(namespace, "", "users"),
+			"Number of the registered users.", nil, nil),
+		activeUsers: prometheus.NewDesc(prometheus.BuildFQName(namespace, "", "active_users"),
+			"Number of the users active in the last 24 hours.", nil, nil),
+		items: prometheus.NewDesc(prometheus.BuildFQName(namespace, "", "items"),
+			"Number of the stored items.", []string{"state"}, nil),
+		blobs: prometheus.NewDesc(prometheus.BuildFQName(namespace, "", "blobs"),
+			"Number of the stored blobs.", nil, nil),
+		blobBytes: prometheus.NewDesc(prometheus.BuildFQName(namespace, "", "blob_bytes"),
+			"Total size of the stored blobs.", nil, nil),
+		dbOpen: prometheus.NewDesc(prometheus.BuildFQName(namespace, "db", "open_connections"),
+			"Number of the established connections to the database.", nil, nil),
+		dbInUse: prometheus.NewDesc(prometheus.BuildFQName(namespace, "db", "in_use_connections"),
+			"Number of the connections currently in use.", nil, nil),
+		dbIdle: prometheus.NewDesc(prometheus.BuildFQName(namespace, "db", "idle_connections"),
+			"Number of the idle connections.", nil, nil),
+		dbWaits: prometheus.NewDesc(prometheus.BuildFQName(namespace, "db", "wait_count_total"),
+			"Number of the connections waited for.", nil, nil),
+		dbWaitTime: prometheus.NewDesc(prometheus.BuildFQName(namespace, "db", "wait_duration_seconds_total"),
+			"Total time blocked waiting for a new connection.", nil, nil),
+	}
+}
+
+// Describe implements `prometheus.Collector`.
+func (c *storeCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- c.users
+	ch <- c.activeUsers
+	ch <- c.items
+	ch <- c.blobs
+	ch <- c.blobBytes
+	ch <- c.dbOpen
+	ch <- c.dbInUse
+	ch <- c.dbIdle
+	ch <- c.dbWaits
+	ch <- c.dbWaitTime
+}
+
+// Collect implements `prometheus.Collector`.
+func (c *storeCollector) Collect(ch chan<- prometheus.Metric) {
+	db := c.store.DBStats()
+	ch <- prometheus.MustNewConstMetric(c.dbOpen, prometheus.GaugeValue, float64(db.OpenConnections))
+	ch <- prometheus.MustNewConstMetric(c.dbInUse, prometheus.GaugeValue, float64(db.InUse))
+	ch <- prometheus.MustNewConstMetric(c.dbIdle, prometheus.GaugeValue, float64(db.Idle))
+	ch <- prometheus.MustNewConstMetric(c.dbWaits, prometheus.CounterValue, float64(db.WaitCount))
+	ch <- prometheus.MustNewConstMetric(c.dbWaitTime, prometheus.CounterValue, db.WaitDuration.Seconds())
+
+	ctx, cancel := context.WithTimeout(context.Background(), collectTimeout)
+	defer cancel()
+
+	stats, err := c.store.Stats(ctx, time.Now().Add(-activePeriod))
+	if err != nil {
+		ch <- prometheus.NewInvalidMetric(c.users, err)
+		return
+	}
+
+	ch <- prometheus.MustNewConstMetric(c.users, prometheus.GaugeValue, float64(stats.Users))
+	ch <- prometheus.MustNewConstMetric(c.activeUsers, prometheus.GaugeValue, float64(stats.ActiveUsers))
+	ch <- prometheus.MustNewConstMetric(c.items, prometheus.GaugeValue, float64(stats.Items), "active")
+	ch <- prometheus.MustNewConstMetric(c.items, prometheus.GaugeValue, float64(stats.TrashedItems), "trash")
+	ch <- prometheus.MustNewConstMetric(c.blobs, prometheus.GaugeValue, float64(stats.Blobs))
+	ch <- prometheus.MustNewConstMetric(c.blobBytes, prometheus.GaugeValue, float64(stats.BlobBytes))
+}
@@ -0,0 +1,67 @@
+package metrics
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/iryzzh/y-gophkeeper/internal/store/sqlite"
+	"github.com/iryzzh/y-gophkeeper/internal/utils"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMetrics(t *testing.T) {
+	cfg, err := utils.TestConfig(t)
+	require.NoError(t, err)
+	st, err := sqlite.NewStore(cfg.DB.DSN, "../../../migrations")
+	require.NoError(t, err)
+	defer func() { _ = st.Close() }()
+
+	item := utils.TestItem(t, "user")
+	require.NoError(t, st.Item().Create(context.Background(), item))
+
+	m := New(st)
+	h := chi.NewMux()
+	h.Use(m.Middleware)
+	h.Get("/item/{id}", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+	})
+
+	for _, id := range []string{"1", "2"} {
+		h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/item/"+id, nil))
+	}
+	m.AuthFailure("invalid_token")
+	m.TokenIssued("login")
+
+	rec := httptest.NewRecorder()
+	m.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	require.Equal(t, http.StatusOK, rec.Code)
+	body, err := io.ReadAll(rec.Body)
+	require.NoError(t, err)
+
+	for _, want := range []string{
+		`gophkeeper_http_requests_total{method="GET",route="/item/{id}",status="404"} 2`,
+		`gophkeeper_http_request_duration_seconds_count{method="GET",route="/item/{id}",status="404"} 2`,
+		`gophkeeper_auth_failures_total{reason="invalid_token"} 1`,
+		`gophkeeper_tokens_issued_total{kind="login"} 1`,
+		`gophkeeper_items{state="active"} 1`,
+		`gophkeeper_blobs 1`,
+		`gophkeeper_users 0`,
+		`gophkeeper_db_open_connections`,
+		`go_goroutines`,
+	} {
+		require.Contains(t, string(body), want)
+	}
+}
+
+func TestMetrics_nil(t *testing.T) {
+	var m *Metrics
+	m.AuthFailure("invalid_token")
+	m.TokenIssued("login")
+
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {})
+	require.NotNil(t, m.Middleware(next))
+}
@@ -2,50 +2,262 @@ package server
 
 import (
 	"context"
+	"time"
 
 	"github.com/iryzzh/y-gophkeeper/internal/config"
+	"github.com/iryzzh/y-gophkeeper/internal/logger"
+	"github.com/iryzzh/y-gophkeeper/internal/models"
+	"github.com/iryzzh/y-gophkeeper/internal/notify"
+	"github.com/iryzzh/y-gophkeeper/internal/server/metrics"
 	"github.com/iryzzh/y-gophkeeper/internal/server/web"
+	"github.com/iryzzh/y-gophkeeper/internal/services/audit"
+	"github.com/iryzzh/y-gophkeeper/internal/services/emergency"
 	"github.com/iryzzh/y-gophkeeper/internal/services/item"
+	"github.com/iryzzh/y-gophkeeper/internal/services/org"
 	"github.com/iryzzh/y-gophkeeper/internal/services/token"
 	"github.com/iryzzh/y-gophkeeper/internal/services/user"
+	"github.com/iryzzh/y-gophkeeper/internal/store"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
 )
 
 type Server struct {
 	webServerConfig *config.WebConfig
+	trashConfig     *config.TrashConfig
+	emergencyConfig *config.EmergencyConfig
+	auditConfig     *config.AuditConfig
+	reminderConfig  *config.ReminderConfig
 	debug           bool
 	tokenSvc        *token.Service
 	userSvc         *user.Service
 	itemSvc         *item.Service
+	orgSvc          *org.Service
+	emergencySvc    *emergency.Service
+	auditSvc        *audit.Service
+	notifier        notify.Notifier
+	metrics         *metrics.Metrics
+	log             *logrus.Logger
+	status          store.Status
+	version         config.Version
 }
 
 func NewServer(
 	webServerConfig *config.WebConfig,
+	trashConfig *config.TrashConfig,
+	emergencyConfig *config.EmergencyConfig,
+	auditConfig *config.AuditConfig,
+	reminderConfig *config.ReminderConfig,
 	tokenSvc *token.Service,
 	userSvc *user.Service,
 	itemSvc *item.Service,
+	orgSvc *org.Service,
+	emergencySvc *emergency.Service,
+	auditSvc *audit.Service,
+	notifier notify.Notifier,
+	m *metrics.Metrics,
+	log *logrus.Logger,
+	status store.Status,
+	version config.Version,
 	debug bool,
 ) *Server {
 	return &Server{
 		webServerConfig: webServerConfig,
+		trashConfig:     trashConfig,
+		emergencyConfig: emergencyConfig,
+		auditConfig:     auditConfig,
+		reminderConfig:  reminderConfig,
 		tokenSvc:        tokenSvc,
 		userSvc:         userSvc,
 		itemSvc:         itemSvc,
+		orgSvc:          orgSvc,
+		emergencySvc:    emergencySvc,
+		auditSvc:        auditSvc,
+		notifier:        notifier,
+		metrics:         m,
+		log:             log,
+		status:          status,
+		version:         version,
 		debug:           debug,
 	}
 }
 
 func (s *Server) Run(ctx context.Context) error {
+	ctx = logger.NewContext(ctx, logrus.NewEntry(s.log))
+
 	apiSrv := web.NewServer(
 		s.webServerConfig.Network,
 		s.webServerConfig.ServerAddress,
 		s.webServerConfig.TLSCertPath,
 		s.webServerConfig.TLSKeyPath,
 		s.webServerConfig.EnableHTTPS,
+		s.webServerConfig.MetricsAddress,
+		s.metrics,
+		s.log,
+		s.status,
+		s.version,
 		s.tokenSvc,
 		s.userSvc,
 		s.itemSvc,
+		s.orgSvc,
+		s.emergencySvc,
+		s.auditSvc,
 		s.debug,
 	)
 
+	go s.purgeTrash(ctx)
+	go s.releaseEmergencyAccess(ctx)
+	go s.purgeAudit(ctx)
+	go s.sendReminders(ctx)
+
 	return apiSrv.Run(ctx)
 }
+
+// purgeTrash periodically deletes the items which have been in the
+// trash longer than the retention period, then the blobs no longer
+// referenced, until the context is done.
+func (s *Server) purgeTrash(ctx context.Context) {
+	if s.trashConfig.PurgeInterval <= 0 {
+		return
+	}
+
+	ticker := time.NewTicker(s.trashConfig.PurgeInterval)
+	defer ticker.Stop()
+
+	for {
+		// The failures are logged by the services.
+		purged, err := s.itemSvc.PurgeTrash(ctx, s.trashConfig.Retention)
+		if err == nil && purged > 0 {
+			s.log.WithField("items", purged).Info("trash purged")
+		}
+
+		collected, err := s.itemSvc.CollectBlobs(ctx)
+		if err == nil && collected > 0 {
+			s.log.WithField("blobs", collected).Info("blobs collected")
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// releaseEmergencyAccess periodically releases the emergency access
+// requested longer than the waiting period ago until the context is
+// done.
+func (s *Server) releaseEmergencyAccess(ctx context.Context) {
+	if s.emergencyConfig.ReleaseInterval <= 0 {
+		return
+	}
+
+	ticker := time.NewTicker(s.emergencyConfig.ReleaseInterval)
+	defer ticker.Stop()
+
+	for {
+		// The failures are logged by the service.
+		released, err := s.emergencySvc.Release(ctx)
+		if err == nil && released > 0 {
+			s.log.WithField("requests", released).Info("emergency access released")
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// purgeAudit periodically removes the events older than the retention
+// period from the audit log until the context is done.
+func (s *Server) purgeAudit(ctx context.Context) {
+	if s.auditConfig.Retention <= 0 || s.auditConfig.PurgeInterval <= 0 {
+		return
+	}
+
+	ticker := time.NewTicker(s.auditConfig.PurgeInterval)
+	defer ticker.Stop()
+
+	for {
+		// The failures are logged by the service.
+		purged, err := s.auditSvc.Purge(ctx, s.auditConfig.Retention)
+		if err == nil && purged > 0 {
+			s.log.WithField("events", purged).Info("audit log purged")
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// sendReminders periodically notifies the users of their items
+// expiring or due for rotation within the window until the context is
+// done. The reminders are not sent without a notifier.
+func (s *Server) sendReminders(ctx context.Context) {
+	if s.notifier == nil || s.reminderConfig.Interval <= 0 {
+		return
+	}
+
+	ticker := time.NewTicker(s.reminderConfig.Interval)
+	defer ticker.Stop()
+
+	for {
+		sent, err := s.remind(ctx)
+		if err != nil {
+			s.log.WithError(err).Error("reminders failed")
+		} else if sent > 0 {
+			s.log.WithField("reminders", sent).Info("reminders sent")
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// remind sends a reminder of the due items to every vault with some,
+// except for the vaults of the disabled users, and returns the number
+// of the sent reminders.
+func (s *Server) remind(ctx context.Context) (int, error) {
+	due, err := s.itemSvc.DueAll(ctx, s.reminderConfig.Window)
+	if err != nil {
+		return 0, err
+	}
+
+	var vaults []string
+	byVault := map[string][]*models.Due{}
+	for _, d := range due {
+		if _, ok := byVault[d.UserID]; !ok {
+			vaults = append(vaults, d.UserID)
+		}
+		byVault[d.UserID] = append(byVault[d.UserID], d)
+	}
+
+	var sent int
+	for _, vault := range vaults {
+		reminder := &notify.Reminder{UserID: vault, Items: byVault[vault]}
+		u, err := s.userSvc.FindByID(ctx, vault)
+		switch {
+		case errors.Is(err, user.ErrUserNotFound):
+		case err != nil:
+			return sent, err
+		case u.DisabledAt != nil:
+			continue
+		default:
+			reminder.Login = u.Login
+		}
+
+		if err = s.notifier.Notify(ctx, reminder); err != nil {
+			return sent, err
+		}
+		sent++
+	}
+
+	return sent, nil
+}
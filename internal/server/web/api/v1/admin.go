@@ -0,0 +1,204 @@
+package v1
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/iryzzh/y-gophkeeper/internal/models"
+	"github.com/iryzzh/y-gophkeeper/internal/services/user"
+	"github.com/pkg/errors"
+)
+
+var (
+	// errNotAdmin is returned when the user is not an administrator.
+	errNotAdmin = errors.New("the admin role is required")
+	// errAdminSelf is returned when the administrator disables or logs
+	// out the own account.
+	errAdminSelf = errors.New("cannot apply to your own account")
+)
+
+// Admin is an authorization middleware letting through the requests of
+// the administrators only. It must be used after `API.Auth`.
+func (a *API) Admin(h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		userID, _ := r.Context().Value(ctxUserID).(string)
+
+		u, err := a.userSvc.FindByID(r.Context(), userID)
+		if errors.Is(err, user.ErrUserNotFound) {
+			WriteError(w, r, http.StatusUnauthorized, errNotAuthenticated)
+			return
+		}
+		if err != nil {
+			internalError(w, r, err)
+			return
+		}
+		if !u.Admin {
+			a.metrics.AuthFailure("not_admin")
+			WriteError(w, r, http.StatusForbidden, errNotAdmin)
+			return
+		}
+
+		h.ServeHTTP(w, r)
+	})
+}
+
+// registerAdmin registers the routes of the admin api.
+func (a *API) registerAdmin(r chi.Router) {
+	r.Use(a.Admin)
+	r.Get("/users", a.adminUsers)
+	r.Get("/users/{login}", a.adminUser)
+	r.Post("/users/{login}/disable", a.adminDisable)
+	r.Post("/users/{login}/enable", a.adminEnable)
+	r.Post("/users/{login}/logout", a.adminLogout)
+	r.Put("/users/{login}/quota", a.adminQuota)
+	r.Delete("/users/{login}/quota", a.adminResetQuota)
+	r.Delete("/users/{login}/2fa", a.adminResetTwoFactor)
+}
+
+// adminUsers returns all the users with their usage and quotas.
+func (a *API) adminUsers(w http.ResponseWriter, r *http.Request) {
+	users, err := a.userSvc.List(r.Context())
+	if err != nil {
+		internalError(w, r, err)
+		return
+	}
+	for _, u := range users {
+		if err = a.itemSvc.Usage(r.Context(), u); err != nil {
+			internalError(w, r, err)
+			return
+		}
+	}
+
+	WriteJSON(w, r, users, http.StatusOK)
+}
+
+// adminUser returns the user with the given login with the usage and
+// the quota.
+func (a *API) adminUser(w http.ResponseWriter, r *http.Request) {
+	u, err := a.userSvc.FindByLogin(r.Context(), chi.URLParam(r, "login"))
+	if err == nil {
+		err = a.itemSvc.Usage(r.Context(), u)
+	}
+	if writeAdminError(w, r, err) {
+		return
+	}
+
+	WriteJSON(w, r, u, http.StatusOK)
+}
+
+// adminDisable disables the user with the given login and revokes the
+// sessions of the user.
+func (a *API) adminDisable(w http.ResponseWriter, r *http.Request) {
+	login := chi.URLParam(r, "login")
+	if a.isSelf(r, login) {
+		validationError(w, r, errAdminSelf, "login")
+		return
+	}
+
+	err := a.userSvc.SetDisabled(r.Context(), login, true)
+	if err == nil {
+		err = a.userSvc.RevokeSessions(r.Context(), login)
+	}
+	if writeAdminError(w, r, err) {
+		return
+	}
+
+	a.audit(r, models.AuditAdminDisable, 0, login)
+
+	w.WriteHeader(http.StatusOK)
+}
+
+// adminEnable enables the user with the given login.
+func (a *API) adminEnable(w http.ResponseWriter, r *http.Request) {
+	login := chi.URLParam(r, "login")
+
+	if writeAdminError(w, r, a.userSvc.SetDisabled(r.Context(), login, false)) {
+		return
+	}
+
+	a.audit(r, models.AuditAdminEnable, 0, login)
+
+	w.WriteHeader(http.StatusOK)
+}
+
+// adminLogout revokes all the sessions of the user with the given
+// login.
+func (a *API) adminLogout(w http.ResponseWriter, r *http.Request) {
+	login := chi.URLParam(r, "login")
+	if a.isSelf(r, login) {
+		validationError(w, r, errAdminSelf, "login")
+		return
+	}
+
+	if writeAdminError(w, r, a.userSvc.RevokeSessions(r.Context(), login)) {
+		return
+	}
+
+	a.audit(r, models.AuditAdminLogout, 0, login)
+
+	w.WriteHeader(http.StatusOK)
+}
+
+// adminQuota sets the received `models.Quota` of the user with the
+// given login.
+func (a *API) adminQuota(w http.ResponseWriter, r *http.Request) {
+	login := chi.URLParam(r, "login")
+
+	var quota models.Quota
+	if err := json.NewDecoder(r.Body).Decode(&quota); err != nil {
+		WriteError(w, r, http.StatusBadRequest, err)
+		return
+	}
+
+	err := a.userSvc.SetQuota(r.Context(), login, &quota)
+	if errors.Is(err, user.ErrInvalidQuota) {
+		validationError(w, r, err, "quota")
+		return
+	}
+	if writeAdminError(w, r, err) {
+		return
+	}
+
+	a.audit(r, models.AuditAdminQuota, 0, login)
+
+	WriteJSON(w, r, quota, http.StatusOK)
+}
+
+// adminResetQuota deletes the quota set for the user with the given
+// login, so that the default quota applies.
+func (a *API) adminResetQuota(w http.ResponseWriter, r *http.Request) {
+	login := chi.URLParam(r, "login")
+
+	if writeAdminError(w, r, a.userSvc.ResetQuota(r.Context(), login)) {
+		return
+	}
+
+	a.audit(r, models.AuditAdminQuota, 0, login)
+
+	w.WriteHeader(http.StatusOK)
+}
+
+// isSelf reports whether the login is the login of the administrator.
+func (a *API) isSelf(r *http.Request, login string) bool {
+	userID, _ := r.Context().Value(ctxUserID).(string)
+
+	u, err := a.userSvc.Find(r.Context(), login)
+
+	return err == nil && u.ID == userID
+}
+
+// writeAdminError writes the response for the errors common to the
+// admin handlers and reports whether the error was written.
+func writeAdminError(w http.ResponseWriter, r *http.Request, err error) bool {
+	switch {
+	case err == nil:
+		return false
+	case errors.Is(err, user.ErrUserNotFound):
+		WriteError(w, r, http.StatusNotFound, err)
+	default:
+		internalError(w, r, err)
+	}
+
+	return true
+}
@@ -3,8 +3,13 @@ package v1
 import (
 	"encoding/json"
 	"net/http"
+	"strconv"
 
+	"github.com/iryzzh/y-gophkeeper/internal/server/metrics"
+	"github.com/iryzzh/y-gophkeeper/internal/services/audit"
+	"github.com/iryzzh/y-gophkeeper/internal/services/emergency"
 	"github.com/iryzzh/y-gophkeeper/internal/services/item"
+	"github.com/iryzzh/y-gophkeeper/internal/services/org"
 	"golang.org/x/net/context"
 
 	"github.com/go-chi/chi/v5"
@@ -17,31 +22,57 @@ import (
 type contextKey int
 type empty struct{}
 
+var (
+	// errNotAuthenticated is returned when the request has no valid access token.
+	errNotAuthenticated = errors.New("not authenticated")
+	// errNoRoute is returned when no route matches the request.
+	errNoRoute = errors.New("no such endpoint")
+	// errMethodNotAllowed is returned when the route does not allow the method.
+	errMethodNotAllowed = errors.New("method not allowed")
+)
+
 const (
 	ctxUserID contextKey = iota
 	ctxPageID
 	ctxItem
+	ctxSessionID
 )
 
 // API is a http api service.
 type API struct {
-	tokenSvc *token.Service
-	userSvc  *user.Service
-	itemSvc  *item.Service
+	tokenSvc     *token.Service
+	userSvc      *user.Service
+	itemSvc      *item.Service
+	orgSvc       *org.Service
+	emergencySvc *emergency.Service
+	auditSvc     *audit.Service
+	metrics      *metrics.Metrics
 }
 
 // NewAPI creates a new API.
-func NewAPI(tokenSvc *token.Service, userSvc *user.Service, itemSvc *item.Service) *API {
+func NewAPI(tokenSvc *token.Service, userSvc *user.Service, itemSvc *item.Service, orgSvc *org.Service,
+	emergencySvc *emergency.Service, auditSvc *audit.Service, m *metrics.Metrics) *API {
 	return &API{
-		tokenSvc: tokenSvc,
-		userSvc:  userSvc,
-		itemSvc:  itemSvc,
+		tokenSvc:     tokenSvc,
+		userSvc:      userSvc,
+		itemSvc:      itemSvc,
+		orgSvc:       orgSvc,
+		emergencySvc: emergencySvc,
+		auditSvc:     auditSvc,
+		metrics:      m,
 	}
 }
 
 // Register registers the routes.
 func (a *API) Register(r *chi.Mux) {
 	r.Route("/api/v1/", func(r chi.Router) {
+		r.NotFound(func(w http.ResponseWriter, r *http.Request) {
+			WriteError(w, r, http.StatusNotFound, errNoRoute)
+		})
+		r.MethodNotAllowed(func(w http.ResponseWriter, r *http.Request) {
+			WriteError(w, r, http.StatusMethodNotAllowed, errMethodNotAllowed)
+		})
+
 		r.Post("/signup", a.signup)
 		r.Post("/login", a.login)
 		r.Post("/token/refresh", a.tokenRefresh)
@@ -53,22 +84,72 @@ func (a *API) Register(r *chi.Mux) {
 		// protected
 		r.Group(func(r chi.Router) {
 			r.Use(a.Auth)
+			r.Get("/audit", a.auditGet)
+			r.Get("/usage", a.usageGet)
+			r.Put("/keys", a.keySet)
+			r.Get("/keys/{login}", a.keyGet)
+			r.Post("/2fa", a.twoFactorEnable)
+			r.Post("/2fa/confirm", a.twoFactorConfirm)
+			r.Post("/2fa/disable", a.twoFactorDisable)
 			r.Route("/item", func(r chi.Router) {
-				r.Get("/", a.itemGet)
-				r.Get("/{id}", a.itemGet)
-				r.With(itemCtx).Put("/", a.itemNew)
-				r.With(itemCtx).Post("/{id}", a.itemSet)
-				r.With(itemCtx).Delete("/{id}", a.itemSet)
+				a.registerItems(r)
+				r.Get("/shared", a.itemShared)
+				r.Post("/{id}/share", a.itemShare)
+				r.Delete("/{id}/share/{login}", a.itemUnshare)
+			})
+			r.Route("/orgs", func(r chi.Router) {
+				r.Get("/", a.orgList)
+				r.Put("/", a.orgNew)
+				r.Delete("/{org}", a.orgDelete)
+				r.Get("/{org}/members", a.memberList)
+				r.Put("/{org}/members", a.memberSet)
+				r.Delete("/{org}/members/{login}", a.memberRemove)
+				r.Get("/{org}/collections", a.collectionList)
+				r.Put("/{org}/collections", a.collectionNew)
+				r.Delete("/{org}/collections/{collection}", a.collectionDelete)
+			})
+			r.With(a.collectionCtx).Route("/collections/{collection}/item", a.registerItems)
+			r.Route("/admin", a.registerAdmin)
+			r.Route("/emergency", func(r chi.Router) {
+				r.Get("/granted", a.emergencyGranted)
+				r.Get("/trusted", a.emergencyTrusted)
+				r.Put("/granted", a.emergencyGrant)
+				r.Delete("/granted/{login}", a.emergencyRevoke)
+				r.Post("/granted/{login}/approve", a.emergencyApprove)
+				r.Post("/granted/{login}/deny", a.emergencyDeny)
+				r.Post("/trusted/{login}/request", a.emergencyRequest)
+				r.Get("/trusted/{login}", a.emergencyAccess)
+				r.Get("/trusted/{login}/item", a.emergencyItems)
 			})
 		})
 	})
 }
 
+// registerItems registers the routes processing the items of the
+// user or, with `API.collectionCtx`, of the collection.
+func (a *API) registerItems(r chi.Router) {
+	r.Get("/", a.itemGet)
+	r.Get("/{id}", a.itemGet)
+	r.Post("/move", a.itemMove)
+	r.Get("/deleted", a.itemDeleted)
+	r.Get("/due", a.itemDue)
+	r.Get("/trash", a.trashGet)
+	r.Delete("/trash", a.trashEmpty)
+	r.Post("/trash/{id}/restore", a.trashRestore)
+	r.Get("/{id}/attachments", a.attachmentList)
+	r.Get("/{id}/attachments/{name}", a.attachmentGet)
+	r.Put("/{id}/attachments/{name}", a.attachmentPut)
+	r.Delete("/{id}/attachments/{name}", a.attachmentDelete)
+	r.With(itemCtx).Put("/", a.itemNew)
+	r.With(itemCtx).Post("/{id}", a.itemSet)
+	r.With(itemCtx).Delete("/{id}", a.itemSet)
+}
+
 func itemCtx(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		var it *models.Item
 		if err := json.NewDecoder(r.Body).Decode(&it); err != nil {
-			http.Error(w, err.Error(), http.StatusBadRequest)
+			WriteError(w, r, http.StatusBadRequest, err)
 			return
 		}
 
@@ -88,31 +169,34 @@ func itemCtx(next http.Handler) http.Handler {
 func (a *API) signup(w http.ResponseWriter, r *http.Request) {
 	var newUser *models.User
 	if err := json.NewDecoder(r.Body).Decode(&newUser); err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
+		WriteError(w, r, http.StatusBadRequest, err)
 		return
 	}
 
 	err := a.userSvc.Create(r.Context(), newUser)
 	if errors.Is(err, user.ErrInvalidUser) || errors.Is(err, user.ErrPasswordCannotBeEmpty) {
-		http.Error(w, err.Error(), http.StatusBadRequest)
+		validationError(w, r, err, userField(err))
 		return
 	}
 	if errors.Is(err, user.ErrUserExists) {
-		http.Error(w, err.Error(), http.StatusConflict)
+		WriteError(w, r, http.StatusConflict, err)
 		return
 	}
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		internalError(w, r, err)
 		return
 	}
 
 	t, err := a.tokenSvc.Create(r.Context(), newUser)
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		internalError(w, r, err)
 		return
 	}
 
-	WriteJSON(w, t, http.StatusCreated)
+	a.metrics.TokenIssued("signup")
+	a.record(r, &models.AuditEvent{UserID: t.UserID, SessionID: t.SessionID, Action: models.AuditSignup})
+
+	WriteJSON(w, r, t, http.StatusCreated)
 }
 
 // login matches the received login/password pair with the
@@ -121,27 +205,70 @@ func (a *API) signup(w http.ResponseWriter, r *http.Request) {
 func (a *API) login(w http.ResponseWriter, r *http.Request) {
 	var u models.User
 	if err := json.NewDecoder(r.Body).Decode(&u); err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
+		WriteError(w, r, http.StatusBadRequest, err)
 		return
 	}
 
-	login, err := a.userSvc.Login(r.Context(), u.Login, u.Password)
+	login, err := a.userSvc.Login(r.Context(), u.Login, u.Password, u.OTP)
 	if errors.Is(err, user.ErrInvalidUser) || errors.Is(err, user.ErrPasswordCannotBeEmpty) {
-		http.Error(w, err.Error(), http.StatusBadRequest)
+		validationError(w, r, err, userField(err))
 		return
 	}
-	if errors.Is(err, user.ErrLoginOrPasswordIsInvalid) || err != nil {
-		http.Error(w, err.Error(), http.StatusUnauthorized)
+	if errors.Is(err, user.ErrUserDisabled) {
+		a.loginFailed(r, u.Login, "user_disabled")
+		WriteError(w, r, http.StatusForbidden, err)
+		return
+	}
+	if errors.Is(err, user.ErrLoginOrPasswordIsInvalid) {
+		a.loginFailed(r, u.Login, "invalid_credentials")
+		WriteError(w, r, http.StatusUnauthorized, err)
+		return
+	}
+	if errors.Is(err, user.ErrOTPRequired) {
+		writeError(w, r, http.StatusUnauthorized, &models.Error{Code: models.CodeOTPRequired, Message: err.Error()})
+		return
+	}
+	if errors.Is(err, user.ErrInvalidOTP) {
+		a.loginFailed(r, u.Login, "invalid_otp")
+		WriteError(w, r, http.StatusUnauthorized, err)
+		return
+	}
+	if err != nil {
+		internalError(w, r, err)
 		return
 	}
 
 	t, createErr := a.tokenSvc.Create(r.Context(), login)
 	if createErr != nil {
-		http.Error(w, createErr.Error(), http.StatusInternalServerError)
+		internalError(w, r, createErr)
 		return
 	}
 
-	WriteJSON(w, t, http.StatusOK)
+	a.metrics.TokenIssued("login")
+	a.record(r, &models.AuditEvent{UserID: t.UserID, SessionID: t.SessionID, Action: models.AuditLogin})
+
+	WriteJSON(w, r, t, http.StatusOK)
+}
+
+// loginFailed records the failed login of the user with the given
+// login in the audit log and the metrics.
+func (a *API) loginFailed(r *http.Request, login, reason string) {
+	event := &models.AuditEvent{Action: models.AuditLoginFailed, Target: login}
+	if found, err := a.userSvc.Find(r.Context(), login); err == nil {
+		event.UserID = found.ID
+	}
+	a.record(r, event)
+	a.metrics.AuthFailure(reason)
+}
+
+// userField returns the field of `models.User` the validation error
+// of the user service is about.
+func userField(err error) string {
+	if errors.Is(err, user.ErrPasswordCannotBeEmpty) {
+		return "password"
+	}
+
+	return "login"
 }
 
 // tokenRefresh matches the received token in `models.Token` format,
@@ -149,21 +276,29 @@ func (a *API) login(w http.ResponseWriter, r *http.Request) {
 func (a *API) tokenRefresh(w http.ResponseWriter, r *http.Request) {
 	var t models.Token
 	if err := json.NewDecoder(r.Body).Decode(&t); err != nil {
-		http.Error(w, err.Error(), http.StatusBadRequest)
+		WriteError(w, r, http.StatusBadRequest, err)
 		return
 	}
 
 	newToken, err := a.tokenSvc.Refresh(r.Context(), t.RefreshToken)
 	if errors.Is(err, token.ErrTokenExpired) || errors.Is(err, token.ErrInvalidToken) {
-		http.Error(w, err.Error(), http.StatusUnauthorized)
+		a.metrics.AuthFailure("invalid_refresh_token")
+		WriteError(w, r, http.StatusUnauthorized, err)
 		return
 	}
 	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		internalError(w, r, err)
 		return
 	}
 
-	WriteJSON(w, newToken, http.StatusCreated)
+	a.metrics.TokenIssued("refresh")
+	a.record(r, &models.AuditEvent{
+		UserID:    newToken.UserID,
+		SessionID: newToken.SessionID,
+		Action:    models.AuditTokenRefresh,
+	})
+
+	WriteJSON(w, r, newToken, http.StatusCreated)
 }
 
 // itemGet is a handler for incoming 'GET' requests to retrieve user
@@ -173,53 +308,99 @@ func (a *API) tokenRefresh(w http.ResponseWriter, r *http.Request) {
 // returned if it exists.
 //
 // If a page number is specified as the query `?limit=n&offset=n`, the
-// `models.Items` is returned.
+// `models.Items` is returned. The items can be filtered by tags with
+// the query `?tag=a&tag=b`.
 func (a *API) itemGet(w http.ResponseWriter, r *http.Request) {
 	var userID string
 	var ok bool
 	if userID, ok = r.Context().Value(ctxUserID).(string); !ok {
-		http.Error(w, "", http.StatusBadRequest)
+		WriteError(w, r, http.StatusUnauthorized, errNotAuthenticated)
+		return
 	}
 	if chi.URLParam(r, "id") != "" {
 		foundItem, err := a.itemSvc.FindByID(r.Context(), userID, chi.URLParam(r, "id"))
 		if err != nil {
+			if errors.Is(err, item.ErrForbidden) {
+				WriteError(w, r, http.StatusForbidden, err)
+				return
+			}
 			if errors.Is(err, item.ErrItemNotFound) {
-				http.Error(w, err.Error(), http.StatusNotFound)
+				WriteError(w, r, http.StatusNotFound, err)
 				return
 			}
-			http.Error(w, err.Error(), http.StatusInternalServerError)
+			internalError(w, r, err)
 			return
 		}
-		WriteJSON(w, foundItem, http.StatusOK)
+		a.audit(r, models.AuditItemRead, foundItem.ID, "")
+
+		WriteJSON(w, r, foundItem, http.StatusOK)
 		return
 	}
 
-	items, err := a.itemSvc.FindByUserID(
-		r.Context(),
-		userID,
-		r.URL.Query().Get("limit"),
-		r.URL.Query().Get("offset"),
-	)
+	var items *models.Items
+	var err error
+	if tags := r.URL.Query()["tag"]; len(tags) > 0 {
+		limit, _ := strconv.Atoi(r.URL.Query().Get("limit"))
+		offset, _ := strconv.Atoi(r.URL.Query().Get("offset"))
+		items, err = a.itemSvc.Search(r.Context(), userID, &models.ItemFilter{
+			Tags:   tags,
+			Limit:  limit,
+			Offset: offset,
+		})
+	} else {
+		items, err = a.itemSvc.FindByUserID(
+			r.Context(),
+			userID,
+			r.URL.Query().Get("limit"),
+			r.URL.Query().Get("offset"),
+		)
+	}
 	if err == nil {
-		WriteJSON(w, items, http.StatusOK)
+		WriteJSON(w, r, items, http.StatusOK)
 		return
 	}
 	if errors.Is(err, item.ErrItemNotFound) {
-		http.Error(w, err.Error(), http.StatusNoContent)
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+	if errors.Is(err, item.ErrForbidden) {
+		WriteError(w, r, http.StatusForbidden, err)
 		return
 	}
 
-	http.Error(w, err.Error(), http.StatusBadRequest)
+	internalError(w, r, err)
 }
 
 func (a *API) itemNew(w http.ResponseWriter, r *http.Request) {
 	it, _ := r.Context().Value(ctxItem).(*models.Item)
 	if err := a.itemSvc.Create(r.Context(), it); err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
+		if errors.Is(err, item.ErrInvalidField) {
+			validationError(w, r, err, "fields")
+			return
+		}
+		if errors.Is(err, item.ErrInvalidRotation) {
+			validationError(w, r, err, "rotate_days")
+			return
+		}
+		if errors.Is(err, item.ErrItemExists) {
+			WriteError(w, r, http.StatusConflict, err)
+			return
+		}
+		if errors.Is(err, item.ErrForbidden) {
+			WriteError(w, r, http.StatusForbidden, err)
+			return
+		}
+		if errors.Is(err, item.ErrQuotaExceeded) {
+			quotaError(w, r, err)
+			return
+		}
+		internalError(w, r, err)
 		return
 	}
 
-	WriteJSON(w, it, http.StatusCreated)
+	a.audit(r, models.AuditItemCreate, it.ID, "")
+
+	WriteJSON(w, r, it, http.StatusCreated)
 }
 
 func (a *API) itemSet(w http.ResponseWriter, r *http.Request) {
@@ -227,20 +408,91 @@ func (a *API) itemSet(w http.ResponseWriter, r *http.Request) {
 	switch r.Method {
 	case http.MethodPost:
 		err := a.itemSvc.Update(r.Context(), it)
+		if errors.Is(err, item.ErrInvalidField) {
+			validationError(w, r, err, "fields")
+			return
+		}
+		if errors.Is(err, item.ErrInvalidRotation) {
+			validationError(w, r, err, "rotate_days")
+			return
+		}
+		if errors.Is(err, item.ErrItemNotFound) {
+			WriteError(w, r, http.StatusNotFound, err)
+			return
+		}
+		if errors.Is(err, item.ErrItemReadOnly) || errors.Is(err, item.ErrForbidden) {
+			WriteError(w, r, http.StatusForbidden, err)
+			return
+		}
+		if errors.Is(err, item.ErrQuotaExceeded) {
+			quotaError(w, r, err)
+			return
+		}
 		if err != nil {
-			http.Error(w, err.Error(), http.StatusInternalServerError)
+			internalError(w, r, err)
 			return
 		}
 
-		WriteJSON(w, it, http.StatusOK)
+		a.audit(r, models.AuditItemUpdate, it.ID, "")
+
+		WriteJSON(w, r, it, http.StatusOK)
 		return
 	case http.MethodDelete:
 		err := a.itemSvc.Delete(r.Context(), it)
+		if errors.Is(err, item.ErrItemNotFound) {
+			WriteError(w, r, http.StatusNotFound, err)
+			return
+		}
+		if errors.Is(err, item.ErrForbidden) {
+			WriteError(w, r, http.StatusForbidden, err)
+			return
+		}
 		if err != nil {
-			http.Error(w, err.Error(), http.StatusInternalServerError)
+			internalError(w, r, err)
 			return
 		}
+
+		a.audit(r, models.AuditItemDelete, it.ID, "")
 	}
 
 	w.WriteHeader(http.StatusOK)
 }
+
+// itemMove renames or moves the item or the folder with all its
+// items as specified in the received `models.Move` and returns it
+// with the number of moved items.
+func (a *API) itemMove(w http.ResponseWriter, r *http.Request) {
+	userID, _ := r.Context().Value(ctxUserID).(string)
+
+	var m models.Move
+	if err := json.NewDecoder(r.Body).Decode(&m); err != nil {
+		WriteError(w, r, http.StatusBadRequest, err)
+		return
+	}
+
+	err := a.itemSvc.Move(r.Context(), userID, &m)
+	if errors.Is(err, item.ErrInvalidMove) {
+		validationError(w, r, err, "")
+		return
+	}
+	if errors.Is(err, item.ErrForbidden) {
+		WriteError(w, r, http.StatusForbidden, err)
+		return
+	}
+	if errors.Is(err, item.ErrItemNotFound) {
+		WriteError(w, r, http.StatusNotFound, err)
+		return
+	}
+	if errors.Is(err, item.ErrItemExists) {
+		WriteError(w, r, http.StatusConflict, err)
+		return
+	}
+	if err != nil {
+		internalError(w, r, err)
+		return
+	}
+
+	a.audit(r, models.AuditItemMove, 0, "")
+
+	WriteJSON(w, r, m, http.StatusOK)
+}
@@ -9,19 +9,26 @@ import (
 	"net/http/httptest"
 	"regexp"
 	"testing"
+	"time"
 
 	"github.com/iryzzh/y-gophkeeper/internal/rand"
 	"github.com/stretchr/testify/assert"
 
+	"github.com/iryzzh/y-gophkeeper/internal/server/metrics"
+	"github.com/iryzzh/y-gophkeeper/internal/services/audit"
+	"github.com/iryzzh/y-gophkeeper/internal/services/emergency"
 	"github.com/iryzzh/y-gophkeeper/internal/services/item"
+	"github.com/iryzzh/y-gophkeeper/internal/services/org"
 
 	"github.com/iryzzh/y-gophkeeper/internal/store/sqlite"
+	"github.com/iryzzh/y-gophkeeper/internal/totp"
 
 	"github.com/iryzzh/y-gophkeeper/internal/store"
 
 	"github.com/iryzzh/y-gophkeeper/internal/services/token"
 	"github.com/iryzzh/y-gophkeeper/internal/services/user"
 
+	"github.com/go-chi/chi/middleware"
 	"github.com/go-chi/chi/v5"
 	"github.com/go-resty/resty/v2"
 	"github.com/iryzzh/y-gophkeeper/internal/models"
@@ -43,7 +50,8 @@ func testStore(t *testing.T) *sqlite.Store {
 	return st
 }
 
-func newTestServer(t *testing.T, tokenSvc *token.Service, userSvc *user.Service, itemSvc *item.Service) (*httptest.Server, error) {
+func newTestServer(t *testing.T, tokenSvc *token.Service, userSvc *user.Service, itemSvc *item.Service,
+	orgSvc *org.Service, emergencySvc *emergency.Service, auditSvc *audit.Service, m *metrics.Metrics) (*httptest.Server, error) {
 	t.Helper()
 
 	l, err := net.Listen("tcp", "localhost:8080")
@@ -52,7 +60,8 @@ func newTestServer(t *testing.T, tokenSvc *token.Service, userSvc *user.Service,
 	}
 
 	h := chi.NewMux()
-	apiV1 := NewAPI(tokenSvc, userSvc, itemSvc)
+	h.Use(m.Middleware)
+	apiV1 := NewAPI(tokenSvc, userSvc, itemSvc, orgSvc, emergencySvc, auditSvc, m)
 	apiV1.Register(h)
 
 	ts := httptest.NewUnstartedServer(h)
@@ -91,7 +100,7 @@ func testService(t *testing.T) (tokenSvc *token.Service, userSvc *user.Service,
 		cfg.Security.KeyLength,
 	)
 
-	itemSvc = item.NewService(st)
+	itemSvc = item.NewService(st, nil)
 
 	return tokenSvc, userSvc, itemSvc, st
 }
@@ -122,7 +131,7 @@ func TestAPI_SignUp(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			tSvc, uSvc, iSvc, st := testService(t)
-			ts, err := newTestServer(t, tSvc, uSvc, iSvc)
+			ts, err := newTestServer(t, tSvc, uSvc, iSvc, org.NewService(st), emergency.NewService(st, time.Hour), audit.NewService(st), metrics.New(st))
 			require.NoError(t, err)
 			defer func() {
 				ts.Close()
@@ -171,11 +180,34 @@ func TestAPI_login(t *testing.T) {
 			},
 			want: http.StatusOK,
 		},
+		{
+			name: "wrong password",
+			user: &models.User{
+				Login:    "test",
+				Password: "wrong",
+			},
+			runBefore: func(svc *user.Service) error {
+				return svc.Create(context.Background(), &models.User{
+					Login:    "test",
+					Password: "test",
+				})
+			},
+			want: http.StatusUnauthorized,
+		},
+		{
+			name: "unknown user",
+			user: &models.User{
+				Login:    "unknown",
+				Password: "test",
+			},
+			runBefore: func(svc *user.Service) error { return nil },
+			want:      http.StatusUnauthorized,
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			tSvc, uSvc, iSvc, st := testService(t)
-			ts, err := newTestServer(t, tSvc, uSvc, iSvc)
+			ts, err := newTestServer(t, tSvc, uSvc, iSvc, org.NewService(st), emergency.NewService(st, time.Hour), audit.NewService(st), metrics.New(st))
 			require.NoError(t, err)
 			defer func() {
 				ts.Close()
@@ -227,7 +259,7 @@ func TestAPI_tokenRefresh(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			tSvc, uSvc, iSvc, st := testService(t)
-			ts, err := newTestServer(t, tSvc, uSvc, iSvc)
+			ts, err := newTestServer(t, tSvc, uSvc, iSvc, org.NewService(st), emergency.NewService(st, time.Hour), audit.NewService(st), metrics.New(st))
 			require.NoError(t, err)
 			defer func() {
 				ts.Close()
@@ -250,10 +282,13 @@ func TestAPI_tokenRefresh(t *testing.T) {
 	}
 }
 
-func setupTestUserWithToken(t *testing.T, uSvc *user.Service, tSvc *token.Service) *models.Token {
+func setupTestUserWithToken(t *testing.T, uSvc *user.Service, tSvc *token.Service, login ...string) *models.Token {
 	t.Helper()
 
 	u := &models.User{Login: "test", Password: "test"}
+	if len(login) > 0 {
+		u.Login = login[0]
+	}
 
 	err := uSvc.Create(context.Background(), u)
 	if err != nil {
@@ -317,7 +352,7 @@ func TestAPI_itemGet(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			tSvc, uSvc, iSvc, st := testService(t)
-			ts, err := newTestServer(t, tSvc, uSvc, iSvc)
+			ts, err := newTestServer(t, tSvc, uSvc, iSvc, org.NewService(st), emergency.NewService(st, time.Hour), audit.NewService(st), metrics.New(st))
 			require.NoError(t, err)
 			defer func() {
 				ts.Close()
@@ -394,7 +429,7 @@ func TestAPI_itemGetWithID(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			tSvc, uSvc, iSvc, st := testService(t)
-			ts, err := newTestServer(t, tSvc, uSvc, iSvc)
+			ts, err := newTestServer(t, tSvc, uSvc, iSvc, org.NewService(st), emergency.NewService(st, time.Hour), audit.NewService(st), metrics.New(st))
 			require.NoError(t, err)
 			defer func() {
 				ts.Close()
@@ -438,7 +473,7 @@ func TestAPI_itemNew(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			tSvc, uSvc, iSvc, st := testService(t)
-			ts, err := newTestServer(t, tSvc, uSvc, iSvc)
+			ts, err := newTestServer(t, tSvc, uSvc, iSvc, org.NewService(st), emergency.NewService(st, time.Hour), audit.NewService(st), metrics.New(st))
 			require.NoError(t, err)
 			defer func() {
 				ts.Close()
@@ -497,7 +532,7 @@ func TestAPI_itemSetPost(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			tSvc, uSvc, iSvc, st := testService(t)
-			ts, err := newTestServer(t, tSvc, uSvc, iSvc)
+			ts, err := newTestServer(t, tSvc, uSvc, iSvc, org.NewService(st), emergency.NewService(st, time.Hour), audit.NewService(st), metrics.New(st))
 			require.NoError(t, err)
 			defer func() {
 				ts.Close()
@@ -546,7 +581,7 @@ func TestAPI_itemSetDelete(t *testing.T) {
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			tSvc, uSvc, iSvc, st := testService(t)
-			ts, err := newTestServer(t, tSvc, uSvc, iSvc)
+			ts, err := newTestServer(t, tSvc, uSvc, iSvc, org.NewService(st), emergency.NewService(st, time.Hour), audit.NewService(st), metrics.New(st))
 			require.NoError(t, err)
 			defer func() {
 				ts.Close()
@@ -568,3 +603,1133 @@ func TestAPI_itemSetDelete(t *testing.T) {
 		})
 	}
 }
+
+func TestAPI_itemTags(t *testing.T) {
+	tests := []struct {
+		name      string
+		tags      []string
+		wantCode  int
+		wantItems int
+	}{
+		{
+			name:      "tag",
+			tags:      []string{"work"},
+			wantCode:  http.StatusOK,
+			wantItems: 2,
+		},
+		{
+			name:      "all tags",
+			tags:      []string{"work", "email"},
+			wantCode:  http.StatusOK,
+			wantItems: 1,
+		},
+		{
+			name:     "not found",
+			tags:     []string{"personal"},
+			wantCode: http.StatusNoContent,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tSvc, uSvc, iSvc, st := testService(t)
+			ts, err := newTestServer(t, tSvc, uSvc, iSvc, org.NewService(st), emergency.NewService(st, time.Hour), audit.NewService(st), metrics.New(st))
+			require.NoError(t, err)
+			defer func() {
+				ts.Close()
+				_ = st.Close()
+			}()
+
+			accessToken := setupTestUserWithToken(t, uSvc, tSvc).AccessToken
+			client := resty.New()
+			client.SetHeader("Accept", "application/json")
+			client.SetAuthToken(accessToken)
+
+			for _, it := range []*models.Item{
+				{
+					Meta:   "work/mail",
+					Tags:   []string{"work", "email"},
+					Fields: []*models.Field{{Name: "username", Value: "alice"}},
+				},
+				{
+					Meta: "work/gitlab",
+					Tags: []string{"work"},
+				},
+			} {
+				b, err := it.Marshal()
+				require.NoError(t, err)
+				resp, err := client.R().SetBody(b).Put(fmt.Sprintf("%v/api/v1/item", ts.URL))
+				require.NoError(t, err)
+				require.Equal(t, http.StatusCreated, resp.StatusCode())
+			}
+
+			resp, err := client.R().
+				SetQueryParamsFromValues(map[string][]string{"tag": tt.tags}).
+				Get(fmt.Sprintf("%v/api/v1/item", ts.URL))
+			require.NoError(t, err)
+			require.Equal(t, tt.wantCode, resp.StatusCode())
+			if tt.wantCode != http.StatusOK {
+				return
+			}
+
+			got := &models.Items{}
+			require.NoError(t, json.Unmarshal(resp.Body(), &got))
+			require.Len(t, got.Data, tt.wantItems)
+			for _, it := range got.Data {
+				require.Subset(t, it.Tags, tt.tags)
+				if it.Meta == "work/mail" {
+					require.Equal(t, []*models.Field{{Name: "username", Value: "alice"}}, it.Fields)
+				}
+			}
+		})
+	}
+}
+
+func TestAPI_itemInvalidField(t *testing.T) {
+	tSvc, uSvc, iSvc, st := testService(t)
+	ts, err := newTestServer(t, tSvc, uSvc, iSvc, org.NewService(st), emergency.NewService(st, time.Hour), audit.NewService(st), metrics.New(st))
+	require.NoError(t, err)
+	defer func() {
+		ts.Close()
+		_ = st.Close()
+	}()
+
+	it := &models.Item{
+		Meta:   rand.String(10),
+		Fields: []*models.Field{{Name: "url", Value: "a"}, {Name: "url", Value: "b"}},
+	}
+	b, err := it.Marshal()
+	require.NoError(t, err)
+
+	resp, err := resty.New().R().
+		SetHeader("Accept", "application/json").
+		SetAuthToken(setupTestUserWithToken(t, uSvc, tSvc).AccessToken).
+		SetBody(b).
+		Put(fmt.Sprintf("%v/api/v1/item", ts.URL))
+	require.NoError(t, err)
+	require.Equal(t, http.StatusBadRequest, resp.StatusCode())
+}
+
+func TestAPI_itemMove(t *testing.T) {
+	tests := []struct {
+		name     string
+		move     *models.Move
+		wantCode int
+		want     int
+	}{
+		{
+			name:     "ok",
+			move:     &models.Move{From: "work", To: "archive/work"},
+			wantCode: http.StatusOK,
+			want:     2,
+		},
+		{
+			name:     "conflict",
+			move:     &models.Move{From: "work/mail", To: "work/gitlab"},
+			wantCode: http.StatusConflict,
+		},
+		{
+			name:     "not found",
+			move:     &models.Move{From: "personal", To: "private"},
+			wantCode: http.StatusNotFound,
+		},
+		{
+			name:     "invalid",
+			move:     &models.Move{From: "work", To: "/"},
+			wantCode: http.StatusBadRequest,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tSvc, uSvc, iSvc, st := testService(t)
+			ts, err := newTestServer(t, tSvc, uSvc, iSvc, org.NewService(st), emergency.NewService(st, time.Hour), audit.NewService(st), metrics.New(st))
+			require.NoError(t, err)
+			defer func() {
+				ts.Close()
+				_ = st.Close()
+			}()
+
+			withToken := setupTestUserWithToken(t, uSvc, tSvc)
+			for _, meta := range []string{"work/mail", "work/gitlab"} {
+				require.NoError(t, st.Item().Create(context.Background(), &models.Item{
+					UserID: withToken.UserID,
+					Meta:   meta,
+				}))
+			}
+
+			resp, err := resty.New().R().
+				SetHeader("Accept", "application/json").
+				SetAuthToken(withToken.AccessToken).
+				SetBody(tt.move).
+				Post(fmt.Sprintf("%v/api/v1/item/move", ts.URL))
+			require.NoError(t, err)
+			require.Equal(t, tt.wantCode, resp.StatusCode())
+			if tt.wantCode == http.StatusOK {
+				var got models.Move
+				require.NoError(t, json.Unmarshal(resp.Body(), &got))
+				require.Equal(t, tt.want, got.Moved)
+			}
+		})
+	}
+}
+
+func TestAPI_itemTrash(t *testing.T) {
+	tSvc, uSvc, iSvc, st := testService(t)
+	ts, err := newTestServer(t, tSvc, uSvc, iSvc, org.NewService(st), emergency.NewService(st, time.Hour), audit.NewService(st), metrics.New(st))
+	require.NoError(t, err)
+	defer func() {
+		ts.Close()
+		_ = st.Close()
+	}()
+
+	withToken := setupTestUserWithToken(t, uSvc, tSvc)
+	testItem := &models.Item{
+		UserID: withToken.UserID,
+		Meta:   rand.String(10),
+	}
+	require.NoError(t, st.Item().Create(context.Background(), testItem))
+
+	client := resty.New().
+		SetHeader("Accept", "application/json").
+		SetAuthToken(withToken.AccessToken)
+
+	resp, err := client.R().
+		SetBody(&models.Item{ID: testItem.ID}).
+		Delete(fmt.Sprintf("%v/api/v1/item/%v", ts.URL, testItem.ID))
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, resp.StatusCode())
+
+	resp, err = client.R().
+		SetBody(&models.Item{ID: testItem.ID}).
+		Delete(fmt.Sprintf("%v/api/v1/item/%v", ts.URL, testItem.ID))
+	require.NoError(t, err)
+	require.Equal(t, http.StatusNotFound, resp.StatusCode())
+
+	resp, err = client.R().Get(fmt.Sprintf("%v/api/v1/item/trash", ts.URL))
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, resp.StatusCode())
+	var trashed models.Items
+	require.NoError(t, json.Unmarshal(resp.Body(), &trashed))
+	require.Len(t, trashed.Data, 1)
+	require.Equal(t, testItem.Meta, trashed.Data[0].Meta)
+
+	resp, err = client.R().Get(fmt.Sprintf("%v/api/v1/item/deleted", ts.URL))
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, resp.StatusCode())
+	var tombstones []*models.Tombstone
+	require.NoError(t, json.Unmarshal(resp.Body(), &tombstones))
+	require.Len(t, tombstones, 1)
+	require.Equal(t, testItem.Meta, tombstones[0].Meta)
+
+	resp, err = client.R().Get(fmt.Sprintf("%v/api/v1/item/deleted?since=yesterday", ts.URL))
+	require.NoError(t, err)
+	require.Equal(t, http.StatusBadRequest, resp.StatusCode())
+
+	resp, err = client.R().Post(fmt.Sprintf("%v/api/v1/item/trash/%v/restore", ts.URL, testItem.ID))
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, resp.StatusCode())
+
+	resp, err = client.R().Post(fmt.Sprintf("%v/api/v1/item/trash/%v/restore", ts.URL, testItem.ID))
+	require.NoError(t, err)
+	require.Equal(t, http.StatusNotFound, resp.StatusCode())
+
+	resp, err = client.R().
+		SetBody(&models.Item{ID: testItem.ID}).
+		Delete(fmt.Sprintf("%v/api/v1/item/%v", ts.URL, testItem.ID))
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, resp.StatusCode())
+
+	resp, err = client.R().Delete(fmt.Sprintf("%v/api/v1/item/trash", ts.URL))
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, resp.StatusCode())
+	var purge models.Purge
+	require.NoError(t, json.Unmarshal(resp.Body(), &purge))
+	require.Equal(t, 1, purge.Purged)
+
+	resp, err = client.R().Get(fmt.Sprintf("%v/api/v1/item/trash", ts.URL))
+	require.NoError(t, err)
+	require.Equal(t, http.StatusNoContent, resp.StatusCode())
+}
+
+func TestAPI_itemDue(t *testing.T) {
+	tSvc, uSvc, iSvc, st := testService(t)
+	ts, err := newTestServer(t, tSvc, uSvc, iSvc, org.NewService(st), emergency.NewService(st, time.Hour), audit.NewService(st), metrics.New(st))
+	require.NoError(t, err)
+	defer func() {
+		ts.Close()
+		_ = st.Close()
+	}()
+
+	withToken := setupTestUserWithToken(t, uSvc, tSvc)
+	soon, later := time.Now().Add(72*time.Hour), time.Now().Add(90*24*time.Hour)
+	for _, it := range []*models.Item{
+		{UserID: withToken.UserID, Meta: "soon", ExpiresAt: &soon},
+		{UserID: withToken.UserID, Meta: "later", ExpiresAt: &later},
+		{UserID: withToken.UserID, Meta: "rotate", RotateDays: 10},
+		{UserID: withToken.UserID, Meta: "never"},
+	} {
+		require.NoError(t, st.Item().Create(context.Background(), it))
+	}
+
+	client := resty.New().
+		SetHeader("Accept", "application/json").
+		SetAuthToken(withToken.AccessToken)
+
+	resp, err := client.R().Get(fmt.Sprintf("%v/api/v1/item/due", ts.URL))
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, resp.StatusCode())
+	var due []*models.Due
+	require.NoError(t, json.Unmarshal(resp.Body(), &due))
+	require.Len(t, due, 2)
+	require.Equal(t, "soon", due[0].Meta)
+	require.Equal(t, models.DueExpires, due[0].Reason)
+	require.Equal(t, "rotate", due[1].Meta)
+	require.Equal(t, models.DueRotate, due[1].Reason)
+
+	resp, err = client.R().Get(fmt.Sprintf("%v/api/v1/item/due?within=100", ts.URL))
+	require.NoError(t, err)
+	require.NoError(t, json.Unmarshal(resp.Body(), &due))
+	require.Len(t, due, 3)
+
+	resp, err = client.R().Get(fmt.Sprintf("%v/api/v1/item/due?within=-1", ts.URL))
+	require.NoError(t, err)
+	require.Equal(t, http.StatusBadRequest, resp.StatusCode())
+
+	resp, err = client.R().
+		SetBody(&models.Item{Meta: "invalid", RotateDays: -1}).
+		Put(fmt.Sprintf("%v/api/v1/item", ts.URL))
+	require.NoError(t, err)
+	require.Equal(t, http.StatusBadRequest, resp.StatusCode())
+}
+
+func TestAPI_itemAttachments(t *testing.T) {
+	tSvc, uSvc, _, st := testService(t)
+	iSvc := item.NewService(st, &models.Quota{MaxBytes: 24, MaxItemBytes: 16})
+	ts, err := newTestServer(t, tSvc, uSvc, iSvc, org.NewService(st), emergency.NewService(st, time.Hour), audit.NewService(st), metrics.New(st))
+	require.NoError(t, err)
+	defer func() {
+		ts.Close()
+		_ = st.Close()
+	}()
+
+	withToken := setupTestUserWithToken(t, uSvc, tSvc)
+	it := &models.Item{UserID: withToken.UserID, Meta: "login", ItemData: &models.ItemData{Data: []byte("pass")}}
+	require.NoError(t, st.Item().Create(context.Background(), it))
+
+	attachments := fmt.Sprintf("/api/v1/item/%d/attachments", it.ID)
+	client := resty.New().
+		SetBaseURL(ts.URL).
+		SetHeader("Accept", "application/json").
+		SetAuthToken(withToken.AccessToken)
+
+	put := func(name, content string) *resty.Response {
+		t.Helper()
+		resp, err := client.R().
+			SetHeader("Content-Type", "application/octet-stream").
+			SetBody([]byte(content)).
+			Put(attachments + "/" + name)
+		require.NoError(t, err)
+		return resp
+	}
+
+	resp := put("key.pem", "0123456789")
+	require.Equal(t, http.StatusCreated, resp.StatusCode(), resp.String())
+	var attachment models.Attachment
+	require.NoError(t, json.Unmarshal(resp.Body(), &attachment))
+	require.Equal(t, "key.pem", attachment.Name)
+	require.Equal(t, int64(10), attachment.Size)
+
+	require.Equal(t, http.StatusCreated, put("key.pem", "012345").StatusCode())
+	require.Equal(t, http.StatusCreated, put("notes.txt", "notes").StatusCode())
+	require.Equal(t, http.StatusRequestEntityTooLarge, put("big.bin", "0123456789abcdefg").StatusCode())
+	require.Equal(t, http.StatusRequestEntityTooLarge, put("rest.bin", "0123456789abcdef").StatusCode())
+	require.Equal(t, http.StatusBadRequest, put("%20", "x").StatusCode())
+
+	resp, err = client.R().Get(attachments)
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, resp.StatusCode(), resp.String())
+	var list []*models.Attachment
+	require.NoError(t, json.Unmarshal(resp.Body(), &list))
+	require.Len(t, list, 2)
+	require.Equal(t, "key.pem", list[0].Name)
+	require.Equal(t, int64(6), list[0].Size)
+
+	resp, err = client.R().Get(attachments + "/key.pem")
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, resp.StatusCode())
+	require.Equal(t, "012345", resp.String())
+	require.Equal(t, `attachment; filename=key.pem`, resp.Header().Get("Content-Disposition"))
+
+	found, err := iSvc.FindByID(context.Background(), withToken.UserID, fmt.Sprint(it.ID))
+	require.NoError(t, err)
+	require.Len(t, found.Attachments, 2)
+
+	resp, err = client.R().Delete(attachments + "/key.pem")
+	require.NoError(t, err)
+	require.Equal(t, http.StatusNoContent, resp.StatusCode())
+
+	resp, err = client.R().Get(attachments + "/key.pem")
+	require.NoError(t, err)
+	require.Equal(t, http.StatusNotFound, resp.StatusCode())
+	resp, err = client.R().Delete(attachments + "/key.pem")
+	require.NoError(t, err)
+	require.Equal(t, http.StatusNotFound, resp.StatusCode())
+
+	other := setupTestUserWithToken(t, uSvc, tSvc, "other")
+	resp, err = client.R().SetAuthToken(other.AccessToken).Get(attachments + "/notes.txt")
+	require.NoError(t, err)
+	require.Equal(t, http.StatusNotFound, resp.StatusCode())
+}
+
+func TestAPI_itemShare(t *testing.T) {
+	tSvc, uSvc, iSvc, st := testService(t)
+	ts, err := newTestServer(t, tSvc, uSvc, iSvc, org.NewService(st), emergency.NewService(st, time.Hour), audit.NewService(st), metrics.New(st))
+	require.NoError(t, err)
+	defer func() {
+		ts.Close()
+		_ = st.Close()
+	}()
+
+	ownerToken := setupTestUserWithToken(t, uSvc, tSvc)
+	recipientToken := setupTestUserWithToken(t, uSvc, tSvc, "recipient")
+	owner := resty.New().SetHeader("Accept", "application/json").SetAuthToken(ownerToken.AccessToken)
+	recipient := resty.New().SetHeader("Accept", "application/json").SetAuthToken(recipientToken.AccessToken)
+
+	plain := &models.Item{UserID: ownerToken.UserID, Meta: "plain"}
+	require.NoError(t, st.Item().Create(context.Background(), plain))
+	encrypted := &models.Item{
+		UserID:   ownerToken.UserID,
+		Meta:     "encrypted",
+		ItemData: &models.ItemData{Data: []byte("ciphertext")},
+		Key:      []byte("owner key"),
+	}
+	require.NoError(t, st.Item().Create(context.Background(), encrypted))
+
+	publicKey := []byte("0123456789abcdef0123456789abcdef")
+	tests := []struct {
+		name     string
+		client   *resty.Client
+		method   string
+		url      string
+		body     interface{}
+		wantCode int
+	}{
+		{"set public key", recipient, http.MethodPut, "/api/v1/keys", models.PublicKey{Key: publicKey}, http.StatusOK},
+		{"set same public key", recipient, http.MethodPut, "/api/v1/keys", models.PublicKey{Key: publicKey}, http.StatusOK},
+		{"replace public key", recipient, http.MethodPut, "/api/v1/keys", models.PublicKey{Key: []byte("fedcba9876543210fedcba9876543210")}, http.StatusConflict},
+		{"invalid public key", owner, http.MethodPut, "/api/v1/keys", models.PublicKey{Key: []byte("short")}, http.StatusBadRequest},
+		{"get public key", owner, http.MethodGet, "/api/v1/keys/recipient", nil, http.StatusOK},
+		{"no public key", recipient, http.MethodGet, "/api/v1/keys/test", nil, http.StatusNotFound},
+		{"share not encrypted", owner, http.MethodPost, fmt.Sprintf("/api/v1/item/%v/share", plain.ID), models.Share{Login: "recipient", Key: []byte("key")}, http.StatusBadRequest},
+		{"share with unknown", owner, http.MethodPost, fmt.Sprintf("/api/v1/item/%v/share", encrypted.ID), models.Share{Login: "unknown", Key: []byte("key")}, http.StatusNotFound},
+		{"share with yourself", owner, http.MethodPost, fmt.Sprintf("/api/v1/item/%v/share", encrypted.ID), models.Share{Login: "test", Key: []byte("key")}, http.StatusBadRequest},
+		{"nothing shared", recipient, http.MethodGet, "/api/v1/item/shared", nil, http.StatusNoContent},
+		{"not shared", recipient, http.MethodGet, fmt.Sprintf("/api/v1/item/%v", encrypted.ID), nil, http.StatusNotFound},
+		{"share", owner, http.MethodPost, fmt.Sprintf("/api/v1/item/%v/share", encrypted.ID), models.Share{Login: "recipient", Key: []byte("recipient key"), ReadOnly: true}, http.StatusOK},
+		{"reshare by recipient", recipient, http.MethodPost, fmt.Sprintf("/api/v1/item/%v/share", encrypted.ID), models.Share{Login: "test", Key: []byte("key")}, http.StatusNotFound},
+		{"shared", recipient, http.MethodGet, "/api/v1/item/shared", nil, http.StatusOK},
+		{"get shared", recipient, http.MethodGet, fmt.Sprintf("/api/v1/item/%v", encrypted.ID), nil, http.StatusOK},
+		{"update read-only", recipient, http.MethodPost, fmt.Sprintf("/api/v1/item/%v", encrypted.ID), &models.Item{ID: encrypted.ID, Meta: "changed", DataID: encrypted.DataID, ItemData: &models.ItemData{ID: encrypted.DataID, Data: []byte("changed")}}, http.StatusForbidden},
+		{"delete shared", recipient, http.MethodDelete, fmt.Sprintf("/api/v1/item/%v", encrypted.ID), &models.Item{ID: encrypted.ID}, http.StatusNotFound},
+		{"share read-write", owner, http.MethodPost, fmt.Sprintf("/api/v1/item/%v/share", encrypted.ID), models.Share{Login: "recipient", Key: []byte("recipient key")}, http.StatusOK},
+		{"update read-write", recipient, http.MethodPost, fmt.Sprintf("/api/v1/item/%v", encrypted.ID), &models.Item{ID: encrypted.ID, Meta: "encrypted", DataID: encrypted.DataID, ItemData: &models.ItemData{ID: encrypted.DataID, Data: []byte("changed")}}, http.StatusOK},
+		{"unshare", owner, http.MethodDelete, fmt.Sprintf("/api/v1/item/%v/share/recipient", encrypted.ID), nil, http.StatusOK},
+		{"unshare again", owner, http.MethodDelete, fmt.Sprintf("/api/v1/item/%v/share/recipient", encrypted.ID), nil, http.StatusNotFound},
+		{"get unshared", recipient, http.MethodGet, fmt.Sprintf("/api/v1/item/%v", encrypted.ID), nil, http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := tt.client.R()
+			if tt.body != nil {
+				req.SetBody(tt.body)
+			}
+
+			resp, err := req.Execute(tt.method, ts.URL+tt.url)
+			require.NoError(t, err)
+			require.Equal(t, tt.wantCode, resp.StatusCode(), resp.String())
+
+			switch tt.name {
+			case "get public key":
+				var key models.PublicKey
+				require.NoError(t, json.Unmarshal(resp.Body(), &key))
+				require.Equal(t, publicKey, key.Key)
+			case "get shared":
+				var got models.Item
+				require.NoError(t, json.Unmarshal(resp.Body(), &got))
+				require.Equal(t, []byte("recipient key"), got.Key)
+				require.Equal(t, "test", got.Owner)
+				require.True(t, got.ReadOnly)
+			}
+		})
+	}
+
+	found, err := st.Item().FindByID(context.Background(), ownerToken.UserID, encrypted.ID)
+	require.NoError(t, err)
+	require.Equal(t, []byte("changed"), found.ItemData.Data)
+	require.Equal(t, []byte("owner key"), found.Key)
+}
+
+func TestAPI_org(t *testing.T) {
+	tSvc, uSvc, iSvc, st := testService(t)
+	ts, err := newTestServer(t, tSvc, uSvc, iSvc, org.NewService(st), emergency.NewService(st, time.Hour), audit.NewService(st), metrics.New(st))
+	require.NoError(t, err)
+	defer func() {
+		ts.Close()
+		_ = st.Close()
+	}()
+
+	clients := make(map[string]*resty.Client)
+	for _, login := range []string{"test", "admin", "member", "stranger"} {
+		tk := setupTestUserWithToken(t, uSvc, tSvc, login)
+		clients[login] = resty.New().SetBaseURL(ts.URL).SetHeader("Accept", "application/json").
+			SetAuthToken(tk.AccessToken)
+	}
+	do := func(login, method, url string, body interface{}, wantCode int) *resty.Response {
+		t.Helper()
+		req := clients[login].R()
+		if body != nil {
+			req.SetBody(body)
+		}
+		resp, err := req.Execute(method, url)
+		require.NoError(t, err)
+		require.Equal(t, wantCode, resp.StatusCode(), "%v %v %v: %v", login, method, url, resp.String())
+
+		return resp
+	}
+
+	var o models.Org
+	resp := do("test", http.MethodPut, "/api/v1/orgs", models.Org{Name: "acme"}, http.StatusCreated)
+	require.NoError(t, json.Unmarshal(resp.Body(), &o))
+	require.Equal(t, models.RoleOwner, o.Role)
+	do("admin", http.MethodPut, "/api/v1/orgs", models.Org{Name: "acme"}, http.StatusConflict)
+	do("admin", http.MethodPut, "/api/v1/orgs", models.Org{Name: " "}, http.StatusBadRequest)
+
+	orgURL := "/api/v1/orgs/" + o.ID
+	do("test", http.MethodPut, orgURL+"/members", models.Member{Login: "admin", Role: models.RoleAdmin}, http.StatusOK)
+	do("test", http.MethodPut, orgURL+"/members", models.Member{Login: "member"}, http.StatusOK)
+	do("test", http.MethodPut, orgURL+"/members", models.Member{Login: "unknown"}, http.StatusNotFound)
+	do("test", http.MethodPut, orgURL+"/members", models.Member{Login: "stranger", Role: "boss"}, http.StatusBadRequest)
+	do("admin", http.MethodPut, orgURL+"/members", models.Member{Login: "member", Role: models.RoleOwner}, http.StatusForbidden)
+	do("member", http.MethodPut, orgURL+"/members", models.Member{Login: "stranger"}, http.StatusForbidden)
+	do("stranger", http.MethodGet, orgURL+"/members", nil, http.StatusNotFound)
+
+	var members []*models.Member
+	resp = do("member", http.MethodGet, orgURL+"/members", nil, http.StatusOK)
+	require.NoError(t, json.Unmarshal(resp.Body(), &members))
+	require.Len(t, members, 3)
+
+	var c models.Collection
+	do("member", http.MethodPut, orgURL+"/collections", models.Collection{Name: "infra"}, http.StatusForbidden)
+	do("admin", http.MethodGet, orgURL+"/collections", nil, http.StatusNoContent)
+	resp = do("admin", http.MethodPut, orgURL+"/collections", models.Collection{Name: "infra"}, http.StatusCreated)
+	require.NoError(t, json.Unmarshal(resp.Body(), &c))
+	do("admin", http.MethodPut, orgURL+"/collections", models.Collection{Name: "infra"}, http.StatusConflict)
+
+	itemURL := "/api/v1/collections/" + c.ID + "/item"
+	var it models.Item
+	resp = do("member", http.MethodPut, itemURL, models.Item{Meta: "db"}, http.StatusCreated)
+	require.NoError(t, json.Unmarshal(resp.Body(), &it))
+	require.Equal(t, c.ID, it.UserID)
+	do("stranger", http.MethodGet, itemURL, nil, http.StatusNotFound)
+	do("member", http.MethodGet, "/api/v1/collections/unknown/item", nil, http.StatusNotFound)
+	do("member", http.MethodGet, fmt.Sprintf("%v/%v", itemURL, it.ID), nil, http.StatusOK)
+	do("test", http.MethodGet, "/api/v1/item", nil, http.StatusNoContent)
+	do("test", http.MethodGet, fmt.Sprintf("/api/v1/item/%v", it.ID), nil, http.StatusNotFound)
+
+	it.Meta = "db/main"
+	do("member", http.MethodPost, fmt.Sprintf("%v/%v", itemURL, it.ID), it, http.StatusOK)
+	do("member", http.MethodDelete, fmt.Sprintf("%v/%v", itemURL, it.ID), it, http.StatusForbidden)
+	do("admin", http.MethodDelete, fmt.Sprintf("%v/%v", itemURL, it.ID), it, http.StatusOK)
+	do("member", http.MethodGet, itemURL+"/trash", nil, http.StatusOK)
+	do("member", http.MethodPost, fmt.Sprintf("%v/trash/%v/restore", itemURL, it.ID), nil, http.StatusForbidden)
+	do("admin", http.MethodPost, fmt.Sprintf("%v/trash/%v/restore", itemURL, it.ID), nil, http.StatusOK)
+
+	do("admin", http.MethodDelete, orgURL+"/collections/"+c.ID, nil, http.StatusConflict)
+	do("admin", http.MethodDelete, orgURL, nil, http.StatusForbidden)
+	do("admin", http.MethodDelete, orgURL+"/members/test", nil, http.StatusForbidden)
+	do("test", http.MethodDelete, orgURL+"/members/test", nil, http.StatusConflict)
+	do("member", http.MethodDelete, orgURL+"/members/member", nil, http.StatusOK)
+	do("member", http.MethodGet, itemURL, nil, http.StatusNotFound)
+	do("test", http.MethodDelete, orgURL, nil, http.StatusConflict)
+
+	var orgs []*models.Org
+	resp = do("admin", http.MethodGet, "/api/v1/orgs", nil, http.StatusOK)
+	require.NoError(t, json.Unmarshal(resp.Body(), &orgs))
+	require.Len(t, orgs, 1)
+	require.Equal(t, models.RoleAdmin, orgs[0].Role)
+	do("member", http.MethodGet, "/api/v1/orgs", nil, http.StatusNoContent)
+}
+
+func TestAPI_emergency(t *testing.T) {
+	tSvc, uSvc, iSvc, st := testService(t)
+	ts, err := newTestServer(t, tSvc, uSvc, iSvc, org.NewService(st), emergency.NewService(st, time.Hour), audit.NewService(st), metrics.New(st))
+	require.NoError(t, err)
+	defer func() {
+		ts.Close()
+		_ = st.Close()
+	}()
+
+	grantorToken := setupTestUserWithToken(t, uSvc, tSvc)
+	grantee := resty.New().SetHeader("Accept", "application/json").
+		SetAuthToken(setupTestUserWithToken(t, uSvc, tSvc, "contact").AccessToken)
+	grantor := resty.New().SetHeader("Accept", "application/json").SetAuthToken(grantorToken.AccessToken)
+
+	require.NoError(t, st.Item().Create(context.Background(), &models.Item{UserID: grantorToken.UserID, Meta: "secret"}))
+
+	tests := []struct {
+		name     string
+		client   *resty.Client
+		method   string
+		url      string
+		body     interface{}
+		wantCode int
+	}{
+		{"nothing granted", grantor, http.MethodGet, "/api/v1/emergency/granted", nil, http.StatusNoContent},
+		{"grant without key", grantor, http.MethodPut, "/api/v1/emergency/granted", models.EmergencyAccess{Grantee: "contact"}, http.StatusBadRequest},
+		{"grant to yourself", grantor, http.MethodPut, "/api/v1/emergency/granted", models.EmergencyAccess{Grantee: "test", Key: []byte("key")}, http.StatusBadRequest},
+		{"grant to unknown", grantor, http.MethodPut, "/api/v1/emergency/granted", models.EmergencyAccess{Grantee: "unknown", Key: []byte("key")}, http.StatusNotFound},
+		{"grant", grantor, http.MethodPut, "/api/v1/emergency/granted", models.EmergencyAccess{Grantee: "contact", Key: []byte("vault key")}, http.StatusOK},
+		{"granted", grantor, http.MethodGet, "/api/v1/emergency/granted", nil, http.StatusOK},
+		{"trusted", grantee, http.MethodGet, "/api/v1/emergency/trusted", nil, http.StatusOK},
+		{"access idle", grantee, http.MethodGet, "/api/v1/emergency/trusted/test", nil, http.StatusForbidden},
+		{"approve idle", grantor, http.MethodPost, "/api/v1/emergency/granted/contact/approve", nil, http.StatusConflict},
+		{"request unknown", grantee, http.MethodPost, "/api/v1/emergency/trusted/unknown/request", nil, http.StatusNotFound},
+		{"request not granted", grantor, http.MethodPost, "/api/v1/emergency/trusted/contact/request", nil, http.StatusNotFound},
+		{"request", grantee, http.MethodPost, "/api/v1/emergency/trusted/test/request", nil, http.StatusOK},
+		{"request again", grantee, http.MethodPost, "/api/v1/emergency/trusted/test/request", nil, http.StatusConflict},
+		{"access requested", grantee, http.MethodGet, "/api/v1/emergency/trusted/test", nil, http.StatusForbidden},
+		{"items requested", grantee, http.MethodGet, "/api/v1/emergency/trusted/test/item", nil, http.StatusForbidden},
+		{"deny", grantor, http.MethodPost, "/api/v1/emergency/granted/contact/deny", nil, http.StatusOK},
+		{"deny again", grantor, http.MethodPost, "/api/v1/emergency/granted/contact/deny", nil, http.StatusConflict},
+		{"request after deny", grantee, http.MethodPost, "/api/v1/emergency/trusted/test/request", nil, http.StatusOK},
+		{"approve", grantor, http.MethodPost, "/api/v1/emergency/granted/contact/approve", nil, http.StatusOK},
+		{"access", grantee, http.MethodGet, "/api/v1/emergency/trusted/test", nil, http.StatusOK},
+		{"items", grantee, http.MethodGet, "/api/v1/emergency/trusted/test/item", nil, http.StatusOK},
+		{"revoke", grantor, http.MethodDelete, "/api/v1/emergency/granted/contact", nil, http.StatusOK},
+		{"revoke again", grantor, http.MethodDelete, "/api/v1/emergency/granted/contact", nil, http.StatusNotFound},
+		{"access revoked", grantee, http.MethodGet, "/api/v1/emergency/trusted/test", nil, http.StatusNotFound},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := tt.client.R()
+			if tt.body != nil {
+				req.SetBody(tt.body)
+			}
+
+			resp, err := req.Execute(tt.method, ts.URL+tt.url)
+			require.NoError(t, err)
+			require.Equal(t, tt.wantCode, resp.StatusCode(), resp.String())
+
+			switch tt.name {
+			case "granted", "trusted":
+				var got []*models.EmergencyAccess
+				require.NoError(t, json.Unmarshal(resp.Body(), &got))
+				require.Len(t, got, 1)
+				require.Equal(t, "test", got[0].Grantor)
+				require.Equal(t, "contact", got[0].Grantee)
+				require.Equal(t, time.Hour, got[0].Wait)
+				require.Equal(t, models.EmergencyIdle, got[0].Status)
+				require.Empty(t, got[0].Key)
+			case "access":
+				var got models.EmergencyAccess
+				require.NoError(t, json.Unmarshal(resp.Body(), &got))
+				require.Equal(t, models.EmergencyApproved, got.Status)
+				require.Equal(t, []byte("vault key"), got.Key)
+			case "items":
+				var got models.Items
+				require.NoError(t, json.Unmarshal(resp.Body(), &got))
+				require.Len(t, got.Data, 1)
+				require.Equal(t, "secret", got.Data[0].Meta)
+			}
+		})
+	}
+}
+
+func TestAPI_audit(t *testing.T) {
+	tSvc, uSvc, iSvc, st := testService(t)
+	ts, err := newTestServer(t, tSvc, uSvc, iSvc, org.NewService(st), emergency.NewService(st, time.Hour), audit.NewService(st), metrics.New(st))
+	require.NoError(t, err)
+	defer func() {
+		ts.Close()
+		_ = st.Close()
+	}()
+
+	client := resty.New().SetBaseURL(ts.URL).SetHeader("Accept", "application/json")
+
+	resp, err := client.R().SetBody(models.User{Login: "test", Password: "test"}).Post("/api/v1/signup")
+	require.NoError(t, err)
+	require.Equal(t, http.StatusCreated, resp.StatusCode(), resp.String())
+
+	resp, err = client.R().SetBody(models.User{Login: "test", Password: "wrong"}).Post("/api/v1/login")
+	require.NoError(t, err)
+	require.Equal(t, http.StatusUnauthorized, resp.StatusCode(), resp.String())
+
+	var tk models.Token
+	resp, err = client.R().SetBody(models.User{Login: "test", Password: "test"}).Post("/api/v1/login")
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, resp.StatusCode(), resp.String())
+	require.NoError(t, json.Unmarshal(resp.Body(), &tk))
+	client.SetAuthToken(tk.AccessToken)
+
+	var it models.Item
+	resp, err = client.R().SetBody(&models.Item{Meta: "secret", DataType: "text",
+		ItemData: &models.ItemData{Data: []byte("data")}}).Put("/api/v1/item")
+	require.NoError(t, err)
+	require.Equal(t, http.StatusCreated, resp.StatusCode(), resp.String())
+	require.NoError(t, json.Unmarshal(resp.Body(), &it))
+
+	resp, err = client.R().Get(fmt.Sprintf("/api/v1/item/%v", it.ID))
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, resp.StatusCode(), resp.String())
+
+	resp, err = client.R().SetBody(&models.Item{ID: it.ID}).Delete(fmt.Sprintf("/api/v1/item/%v", it.ID))
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, resp.StatusCode(), resp.String())
+
+	var events []*models.AuditEvent
+	resp, err = client.R().Get("/api/v1/audit")
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, resp.StatusCode(), resp.String())
+	require.NoError(t, json.Unmarshal(resp.Body(), &events))
+
+	var actions []models.AuditAction
+	for _, e := range events {
+		actions = append(actions, e.Action)
+		require.Equal(t, "127.0.0.1", e.IP)
+	}
+	require.Equal(t, []models.AuditAction{
+		models.AuditItemDelete,
+		models.AuditItemRead,
+		models.AuditItemCreate,
+		models.AuditLogin,
+		models.AuditLoginFailed,
+		models.AuditSignup,
+	}, actions)
+	require.Equal(t, it.ID, events[0].ItemID)
+	require.Equal(t, events[0].SessionID, events[3].SessionID)
+	require.NotEqual(t, events[3].SessionID, events[5].SessionID)
+	require.Equal(t, "test", events[4].Target)
+
+	resp, err = client.R().SetQueryParam("action", string(models.AuditLoginFailed)).Get("/api/v1/audit")
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, resp.StatusCode(), resp.String())
+	require.NoError(t, json.Unmarshal(resp.Body(), &events))
+	require.Len(t, events, 1)
+
+	resp, err = client.R().SetQueryParam("since", "yesterday").Get("/api/v1/audit")
+	require.NoError(t, err)
+	require.Equal(t, http.StatusBadRequest, resp.StatusCode(), resp.String())
+
+	resp, err = client.R().SetQueryParam("since", time.Now().Add(time.Hour).UTC().Format(time.RFC3339)).
+		Get("/api/v1/audit")
+	require.NoError(t, err)
+	require.Equal(t, http.StatusNoContent, resp.StatusCode(), resp.String())
+}
+
+func TestErrorResponse(t *testing.T) {
+	decode := func(rec *httptest.ResponseRecorder) *models.Error {
+		t.Helper()
+		require.Equal(t, "application/json", rec.Header().Get("Content-Type"))
+		e := &models.Error{}
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), e))
+		return e
+	}
+
+	h := middleware.RequestID(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		internalError(w, r, fmt.Errorf("database is locked"))
+	}))
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil))
+
+	require.Equal(t, http.StatusInternalServerError, rec.Code)
+	e := decode(rec)
+	require.Equal(t, models.CodeInternal, e.Code)
+	require.NotContains(t, e.Message, "database is locked")
+	require.NotEmpty(t, e.RequestID)
+
+	rec = httptest.NewRecorder()
+	WriteJSON(rec, httptest.NewRequest(http.MethodGet, "/", nil), func() {}, http.StatusOK)
+	require.Equal(t, http.StatusInternalServerError, rec.Code)
+
+	rec = httptest.NewRecorder()
+	validationError(rec, httptest.NewRequest(http.MethodGet, "/", nil), user.ErrPasswordCannotBeEmpty, "password")
+	require.Equal(t, http.StatusBadRequest, rec.Code)
+	e = decode(rec)
+	require.Equal(t, models.CodeValidation, e.Code)
+	require.Equal(t, map[string]string{"password": user.ErrPasswordCannotBeEmpty.Error()}, e.Fields)
+
+	for status, code := range map[int]models.ErrorCode{
+		http.StatusUnauthorized:    models.CodeUnauthorized,
+		http.StatusForbidden:       models.CodeForbidden,
+		http.StatusNotFound:        models.CodeNotFound,
+		http.StatusConflict:        models.CodeConflict,
+		http.StatusTooManyRequests: models.CodeRateLimited,
+		http.StatusBadRequest:      models.CodeBadRequest,
+	} {
+		rec = httptest.NewRecorder()
+		WriteError(rec, httptest.NewRequest(http.MethodGet, "/", nil), status, errNotAuthenticated)
+		require.Equal(t, status, rec.Code)
+		require.Equal(t, code, decode(rec).Code)
+	}
+}
+
+func TestAPI_errors(t *testing.T) {
+	tSvc, uSvc, iSvc, st := testService(t)
+	ts, err := newTestServer(t, tSvc, uSvc, iSvc, org.NewService(st), emergency.NewService(st, time.Hour), audit.NewService(st), metrics.New(st))
+	require.NoError(t, err)
+	defer func() {
+		ts.Close()
+		_ = st.Close()
+	}()
+
+	client := resty.New().SetBaseURL(ts.URL).SetHeader("Accept", "application/json")
+
+	tests := []struct {
+		name   string
+		req    func() (*resty.Response, error)
+		status int
+		code   models.ErrorCode
+		fields map[string]string
+	}{
+		{
+			name: "validation",
+			req: func() (*resty.Response, error) {
+				return client.R().SetBody(models.User{Login: "test"}).Post("/api/v1/signup")
+			},
+			status: http.StatusBadRequest,
+			code:   models.CodeValidation,
+			fields: map[string]string{"password": user.ErrPasswordCannotBeEmpty.Error()},
+		},
+		{
+			name:   "malformed body",
+			req:    func() (*resty.Response, error) { return client.R().SetBody("{").Post("/api/v1/login") },
+			status: http.StatusBadRequest,
+			code:   models.CodeBadRequest,
+		},
+		{
+			name:   "unauthorized",
+			req:    func() (*resty.Response, error) { return client.R().Get("/api/v1/item") },
+			status: http.StatusUnauthorized,
+			code:   models.CodeUnauthorized,
+		},
+		{
+			name:   "no route",
+			req:    func() (*resty.Response, error) { return client.R().Get("/api/v1/nothing") },
+			status: http.StatusNotFound,
+			code:   models.CodeNotFound,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			resp, err := tt.req()
+			require.NoError(t, err)
+			require.Equal(t, tt.status, resp.StatusCode(), resp.String())
+
+			e := &models.Error{}
+			require.NoError(t, json.Unmarshal(resp.Body(), e), resp.String())
+			require.Equal(t, tt.code, e.Code)
+			require.NotEmpty(t, e.Message)
+			require.Equal(t, tt.fields, e.Fields)
+		})
+	}
+}
+
+func TestAPI_disabledUser(t *testing.T) {
+	tSvc, uSvc, iSvc, st := testService(t)
+	ts, err := newTestServer(t, tSvc, uSvc, iSvc, org.NewService(st), emergency.NewService(st, time.Hour), audit.NewService(st), metrics.New(st))
+	require.NoError(t, err)
+	defer func() {
+		ts.Close()
+		_ = st.Close()
+	}()
+
+	tk := setupTestUserWithToken(t, uSvc, tSvc)
+	require.NoError(t, uSvc.SetDisabled(context.Background(), "test", true))
+
+	client := resty.New().SetBaseURL(ts.URL).SetHeader("Accept", "application/json")
+
+	resp, err := client.R().SetBody(models.User{Login: "test", Password: "test"}).Post("/api/v1/login")
+	require.NoError(t, err)
+	require.Equal(t, http.StatusForbidden, resp.StatusCode(), resp.String())
+
+	resp, err = client.R().SetBody(models.Token{RefreshToken: tk.RefreshToken}).Post("/api/v1/token/refresh")
+	require.NoError(t, err)
+	require.Equal(t, http.StatusUnauthorized, resp.StatusCode(), resp.String())
+
+	require.NoError(t, uSvc.SetDisabled(context.Background(), "test", false))
+
+	resp, err = client.R().SetBody(models.User{Login: "test", Password: "test"}).Post("/api/v1/login")
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, resp.StatusCode(), resp.String())
+}
+
+func TestAPI_admin(t *testing.T) {
+	tSvc, uSvc, iSvc, st := testService(t)
+	ts, err := newTestServer(t, tSvc, uSvc, iSvc, org.NewService(st), emergency.NewService(st, time.Hour), audit.NewService(st), metrics.New(st))
+	require.NoError(t, err)
+	defer func() {
+		ts.Close()
+		_ = st.Close()
+	}()
+
+	adminToken := setupTestUserWithToken(t, uSvc, tSvc, "admin")
+	require.NoError(t, uSvc.SetAdmin(context.Background(), "admin", true))
+	bobToken := setupTestUserWithToken(t, uSvc, tSvc, "bob")
+
+	admin := resty.New().SetBaseURL(ts.URL).SetHeader("Accept", "application/json").SetAuthToken(adminToken.AccessToken)
+	bob := resty.New().SetBaseURL(ts.URL).SetHeader("Accept", "application/json").SetAuthToken(bobToken.AccessToken)
+
+	resp, err := bob.R().Get("/api/v1/admin/users")
+	require.NoError(t, err)
+	require.Equal(t, http.StatusForbidden, resp.StatusCode(), resp.String())
+
+	resp, err = bob.R().SetBody(&models.Item{Meta: "secret", DataType: "text",
+		ItemData: &models.ItemData{Data: []byte("data")}}).Put("/api/v1/item")
+	require.NoError(t, err)
+	require.Equal(t, http.StatusCreated, resp.StatusCode(), resp.String())
+
+	var users []*models.User
+	resp, err = admin.R().SetResult(&users).Get("/api/v1/admin/users")
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, resp.StatusCode(), resp.String())
+	require.Len(t, users, 2)
+	require.Equal(t, "admin", users[0].Login)
+	require.True(t, users[0].Admin)
+	require.Equal(t, &models.Usage{Items: 1, Bytes: 4}, users[1].Usage)
+
+	quota := &models.Quota{MaxBytes: 1024, MaxItems: 10}
+	resp, err = admin.R().SetBody(quota).Put("/api/v1/admin/users/bob/quota")
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, resp.StatusCode(), resp.String())
+
+	resp, err = admin.R().SetBody(&models.Quota{MaxItems: -1}).Put("/api/v1/admin/users/bob/quota")
+	require.NoError(t, err)
+	require.Equal(t, http.StatusBadRequest, resp.StatusCode(), resp.String())
+
+	var got models.User
+	resp, err = admin.R().SetResult(&got).Get("/api/v1/admin/users/bob")
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, resp.StatusCode(), resp.String())
+	require.Equal(t, quota, got.Quota)
+
+	resp, err = admin.R().Get("/api/v1/admin/users/nobody")
+	require.NoError(t, err)
+	require.Equal(t, http.StatusNotFound, resp.StatusCode(), resp.String())
+
+	resp, err = admin.R().Post("/api/v1/admin/users/admin/disable")
+	require.NoError(t, err)
+	require.Equal(t, http.StatusBadRequest, resp.StatusCode(), resp.String())
+
+	resp, err = admin.R().Post("/api/v1/admin/users/bob/logout")
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, resp.StatusCode(), resp.String())
+
+	resp, err = bob.R().Get("/api/v1/item")
+	require.NoError(t, err)
+	require.Equal(t, http.StatusUnauthorized, resp.StatusCode(), resp.String())
+
+	resp, err = bob.R().SetBody(models.Token{RefreshToken: bobToken.RefreshToken}).Post("/api/v1/token/refresh")
+	require.NoError(t, err)
+	require.Equal(t, http.StatusUnauthorized, resp.StatusCode(), resp.String())
+
+	resp, err = admin.R().Post("/api/v1/admin/users/bob/disable")
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, resp.StatusCode(), resp.String())
+
+	resp, err = bob.R().SetBody(models.User{Login: "bob", Password: "test"}).Post("/api/v1/login")
+	require.NoError(t, err)
+	require.Equal(t, http.StatusForbidden, resp.StatusCode(), resp.String())
+
+	resp, err = admin.R().Post("/api/v1/admin/users/bob/enable")
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, resp.StatusCode(), resp.String())
+
+	var tk models.Token
+	resp, err = bob.R().SetResult(&tk).SetBody(models.User{Login: "bob", Password: "test"}).Post("/api/v1/login")
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, resp.StatusCode(), resp.String())
+
+	resp, err = bob.SetAuthToken(tk.AccessToken).R().Get("/api/v1/item")
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, resp.StatusCode(), resp.String())
+}
+
+func TestAPI_twoFactor(t *testing.T) {
+	tSvc, uSvc, iSvc, st := testService(t)
+	ts, err := newTestServer(t, tSvc, uSvc, iSvc, org.NewService(st), emergency.NewService(st, time.Hour), audit.NewService(st), metrics.New(st))
+	require.NoError(t, err)
+	defer func() {
+		ts.Close()
+		_ = st.Close()
+	}()
+
+	adminToken := setupTestUserWithToken(t, uSvc, tSvc, "admin")
+	require.NoError(t, uSvc.SetAdmin(context.Background(), "admin", true))
+	bobToken := setupTestUserWithToken(t, uSvc, tSvc, "bob")
+
+	admin := resty.New().SetBaseURL(ts.URL).SetHeader("Accept", "application/json").SetAuthToken(adminToken.AccessToken)
+	bob := resty.New().SetBaseURL(ts.URL).SetHeader("Accept", "application/json").SetAuthToken(bobToken.AccessToken)
+
+	resp, err := bob.R().SetBody(models.TwoFactor{Code: "123456"}).Post("/api/v1/2fa/confirm")
+	require.NoError(t, err)
+	require.Equal(t, http.StatusConflict, resp.StatusCode(), resp.String())
+
+	var tf models.TwoFactor
+	resp, err = bob.R().SetResult(&tf).Post("/api/v1/2fa")
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, resp.StatusCode(), resp.String())
+	require.NotEmpty(t, tf.Secret)
+	require.Contains(t, tf.URL, "otpauth://totp/")
+
+	now := time.Now()
+	stale, err := totp.Code(tf.Secret, now.Add(-time.Hour))
+	require.NoError(t, err)
+	resp, err = bob.R().SetBody(models.TwoFactor{Code: stale}).Post("/api/v1/2fa/confirm")
+	require.NoError(t, err)
+	require.Equal(t, http.StatusBadRequest, resp.StatusCode(), resp.String())
+
+	code, err := totp.Code(tf.Secret, now)
+	require.NoError(t, err)
+	resp, err = bob.R().SetBody(models.TwoFactor{Code: code}).Post("/api/v1/2fa/confirm")
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, resp.StatusCode(), resp.String())
+
+	resp, err = bob.R().Post("/api/v1/2fa")
+	require.NoError(t, err)
+	require.Equal(t, http.StatusConflict, resp.StatusCode(), resp.String())
+
+	var e models.Error
+	resp, err = bob.R().SetError(&e).SetBody(models.User{Login: "bob", Password: "test"}).Post("/api/v1/login")
+	require.NoError(t, err)
+	require.Equal(t, http.StatusUnauthorized, resp.StatusCode(), resp.String())
+	require.Equal(t, models.CodeOTPRequired, e.Code)
+
+	// the code confirming the secret is used already.
+	resp, err = bob.R().SetBody(models.User{Login: "bob", Password: "test", OTP: code}).Post("/api/v1/login")
+	require.NoError(t, err)
+	require.Equal(t, http.StatusUnauthorized, resp.StatusCode(), resp.String())
+
+	next, err := totp.Code(tf.Secret, now.Add(totp.Period))
+	require.NoError(t, err)
+	resp, err = bob.R().SetBody(models.User{Login: "bob", Password: "test", OTP: next}).Post("/api/v1/login")
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, resp.StatusCode(), resp.String())
+
+	resp, err = bob.R().SetBody(models.TwoFactor{Code: stale}).Post("/api/v1/2fa/disable")
+	require.NoError(t, err)
+	require.Equal(t, http.StatusBadRequest, resp.StatusCode(), resp.String())
+
+	var got models.User
+	resp, err = admin.R().SetResult(&got).Get("/api/v1/admin/users/bob")
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, resp.StatusCode(), resp.String())
+	require.True(t, got.TwoFactor)
+
+	resp, err = bob.R().Delete("/api/v1/admin/users/bob/2fa")
+	require.NoError(t, err)
+	require.Equal(t, http.StatusForbidden, resp.StatusCode(), resp.String())
+
+	resp, err = admin.R().Delete("/api/v1/admin/users/bob/2fa")
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, resp.StatusCode(), resp.String())
+
+	resp, err = bob.R().SetBody(models.User{Login: "bob", Password: "test"}).Post("/api/v1/login")
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, resp.StatusCode(), resp.String())
+
+	resp, err = bob.R().SetBody(models.TwoFactor{Code: next}).Post("/api/v1/2fa/disable")
+	require.NoError(t, err)
+	require.Equal(t, http.StatusConflict, resp.StatusCode(), resp.String())
+
+	var events []*models.AuditEvent
+	resp, err = admin.R().SetResult(&events).SetQueryParam("action", string(models.AuditAdminReset2FA)).Get("/api/v1/audit")
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, resp.StatusCode(), resp.String())
+	require.Len(t, events, 1)
+	require.Equal(t, "bob", events[0].Target)
+}
+
+func TestAPI_quota(t *testing.T) {
+	tSvc, uSvc, _, st := testService(t)
+	quota := &models.Quota{MaxBytes: 12, MaxItems: 2, MaxItemBytes: 8}
+	iSvc := item.NewService(st, quota)
+	ts, err := newTestServer(t, tSvc, uSvc, iSvc, org.NewService(st), emergency.NewService(st, time.Hour), audit.NewService(st), metrics.New(st))
+	require.NoError(t, err)
+	defer func() {
+		ts.Close()
+		_ = st.Close()
+	}()
+
+	adminToken := setupTestUserWithToken(t, uSvc, tSvc, "admin")
+	require.NoError(t, uSvc.SetAdmin(context.Background(), "admin", true))
+	bobToken := setupTestUserWithToken(t, uSvc, tSvc, "bob")
+
+	admin := resty.New().SetBaseURL(ts.URL).SetHeader("Accept", "application/json").SetAuthToken(adminToken.AccessToken)
+	bob := resty.New().SetBaseURL(ts.URL).SetHeader("Accept", "application/json").SetAuthToken(bobToken.AccessToken)
+
+	create := func(meta, data string) (*models.Item, *resty.Response) {
+		t.Helper()
+		it := &models.Item{}
+		resp, err := bob.R().SetResult(it).SetBody(&models.Item{Meta: meta, DataType: "text",
+			ItemData: &models.ItemData{Data: []byte(data)}}).Put("/api/v1/item")
+		require.NoError(t, err)
+		return it, resp
+	}
+	requireQuotaExceeded := func(resp *resty.Response) {
+		t.Helper()
+		require.Equal(t, http.StatusRequestEntityTooLarge, resp.StatusCode(), resp.String())
+		var e models.Error
+		require.NoError(t, json.Unmarshal(resp.Body(), &e))
+		require.Equal(t, models.CodeQuotaExceeded, e.Code)
+	}
+
+	a, resp := create("a", "1234")
+	require.Equal(t, http.StatusCreated, resp.StatusCode(), resp.String())
+
+	_, resp = create("big", "123456789")
+	requireQuotaExceeded(resp)
+
+	_, resp = create("b", "12345678")
+	require.Equal(t, http.StatusCreated, resp.StatusCode(), resp.String())
+
+	_, resp = create("c", "1")
+	requireQuotaExceeded(resp)
+
+	var got models.User
+	resp, err = bob.R().SetResult(&got).Get("/api/v1/usage")
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, resp.StatusCode(), resp.String())
+	require.Equal(t, "bob", got.Login)
+	require.Equal(t, &models.Usage{Items: 2, Bytes: 12}, got.Usage)
+	require.Equal(t, quota, got.Quota)
+
+	update := func(data string) *resty.Response {
+		t.Helper()
+		resp, err := bob.R().SetBody(&models.Item{ID: a.ID, Meta: "a", DataType: "text", DataID: a.DataID,
+			ItemData: &models.ItemData{ID: a.DataID, Data: []byte(data)}}).Post(fmt.Sprintf("/api/v1/item/%d", a.ID))
+		require.NoError(t, err)
+		return resp
+	}
+
+	requireQuotaExceeded(update("12345"))
+
+	resp = update("12")
+	require.Equal(t, http.StatusOK, resp.StatusCode(), resp.String())
+
+	resp, err = admin.R().SetBody(&models.Quota{MaxBytes: 12, MaxItems: 3}).Put("/api/v1/admin/users/bob/quota")
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, resp.StatusCode(), resp.String())
+
+	_, resp = create("c", "1")
+	require.Equal(t, http.StatusCreated, resp.StatusCode(), resp.String())
+
+	resp, err = admin.R().Delete("/api/v1/admin/users/bob/quota")
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, resp.StatusCode(), resp.String())
+
+	resp, err = admin.R().SetResult(&got).Get("/api/v1/admin/users/bob")
+	require.NoError(t, err)
+	require.Equal(t, http.StatusOK, resp.StatusCode(), resp.String())
+	require.Equal(t, quota, got.Quota)
+	require.Equal(t, &models.Usage{Items: 3, Bytes: 11}, got.Usage)
+}
@@ -0,0 +1,111 @@
+package v1
+
+import (
+	"io"
+	"mime"
+	"net/http"
+	"strconv"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/iryzzh/y-gophkeeper/internal/models"
+	"github.com/iryzzh/y-gophkeeper/internal/services/item"
+	"github.com/pkg/errors"
+)
+
+// attachmentList returns the `models.Attachment` of the item with the
+// given id.
+func (a *API) attachmentList(w http.ResponseWriter, r *http.Request) {
+	userID, _ := r.Context().Value(ctxUserID).(string)
+
+	attachments, err := a.itemSvc.Attachments(r.Context(), userID, chi.URLParam(r, "id"))
+	if err != nil {
+		attachmentError(w, r, err)
+		return
+	}
+
+	WriteJSON(w, r, attachments, http.StatusOK)
+}
+
+// attachmentGet streams the content of the attachment with the given
+// name of the item with the given id as is.
+func (a *API) attachmentGet(w http.ResponseWriter, r *http.Request) {
+	userID, _ := r.Context().Value(ctxUserID).(string)
+
+	attachment, content, err := a.itemSvc.OpenAttachment(r.Context(), userID, chi.URLParam(r, "id"),
+		chi.URLParam(r, "name"))
+	if err != nil {
+		attachmentError(w, r, err)
+		return
+	}
+	defer func() { _ = content.Close() }()
+
+	a.audit(r, models.AuditItemRead, attachment.ItemID, "")
+
+	w.Header().Set("Content-Type", "application/octet-stream")
+	w.Header().Set("Content-Length", strconv.FormatInt(attachment.Size, 10))
+	w.Header().Set("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": attachment.Name}))
+	w.WriteHeader(http.StatusOK)
+	_, _ = io.Copy(w, content)
+}
+
+// attachmentPut saves the body of the request as the attachment with
+// the given name of the item with the given id, replacing the
+// attachment with the same name, and returns the `models.Attachment`.
+func (a *API) attachmentPut(w http.ResponseWriter, r *http.Request) {
+	userID, _ := r.Context().Value(ctxUserID).(string)
+
+	limit, err := a.itemSvc.MaxAttachmentSize(r.Context(), userID, chi.URLParam(r, "id"))
+	if err != nil {
+		attachmentError(w, r, err)
+		return
+	}
+	// the body is streamed to the storage: it is cut at the limit, one
+	// more byte is let through for the service to fail with the quota.
+	if limit > 0 {
+		r.Body = http.MaxBytesReader(w, r.Body, limit+1)
+	}
+
+	attachment := &models.Attachment{Name: chi.URLParam(r, "name")}
+	if err = a.itemSvc.Attach(r.Context(), userID, chi.URLParam(r, "id"), attachment, r.Body); err != nil {
+		attachmentError(w, r, err)
+		return
+	}
+
+	a.audit(r, models.AuditItemAttach, attachment.ItemID, "")
+
+	WriteJSON(w, r, attachment, http.StatusCreated)
+}
+
+// attachmentDelete deletes the attachment with the given name of the
+// item with the given id.
+func (a *API) attachmentDelete(w http.ResponseWriter, r *http.Request) {
+	userID, _ := r.Context().Value(ctxUserID).(string)
+
+	if err := a.itemSvc.Detach(r.Context(), userID, chi.URLParam(r, "id"), chi.URLParam(r, "name")); err != nil {
+		attachmentError(w, r, err)
+		return
+	}
+
+	id, _ := strconv.Atoi(chi.URLParam(r, "id"))
+	a.audit(r, models.AuditItemDetach, id, "")
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// attachmentError writes the error of the attachment operation.
+func attachmentError(w http.ResponseWriter, r *http.Request, err error) {
+	switch {
+	case errors.Is(err, item.ErrIncorrectItemID):
+		validationError(w, r, err, "id")
+	case errors.Is(err, item.ErrInvalidAttachment):
+		validationError(w, r, err, "name")
+	case errors.Is(err, item.ErrItemNotFound), errors.Is(err, item.ErrAttachmentNotFound):
+		WriteError(w, r, http.StatusNotFound, err)
+	case errors.Is(err, item.ErrItemReadOnly), errors.Is(err, item.ErrForbidden):
+		WriteError(w, r, http.StatusForbidden, err)
+	case errors.Is(err, item.ErrQuotaExceeded):
+		quotaError(w, r, err)
+	default:
+		internalError(w, r, err)
+	}
+}
@@ -0,0 +1,81 @@
+package v1
+
+import (
+	"net"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/iryzzh/y-gophkeeper/internal/models"
+)
+
+// auditGet returns the `models.AuditEvent` of the user, the most recent
+// first. The events can be filtered with the query `?action=login`
+// and `?since=2006-01-02T15:04:05Z`, the page can be specified as the
+// query `?limit=n&offset=n`.
+func (a *API) auditGet(w http.ResponseWriter, r *http.Request) {
+	userID, _ := r.Context().Value(ctxUserID).(string)
+
+	filter := &models.AuditFilter{Action: models.AuditAction(r.URL.Query().Get("action"))}
+	filter.Limit, _ = strconv.Atoi(r.URL.Query().Get("limit"))
+	filter.Offset, _ = strconv.Atoi(r.URL.Query().Get("offset"))
+	if v := r.URL.Query().Get("since"); v != "" {
+		since, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			validationError(w, r, err, "since")
+			return
+		}
+		filter.Since = &since
+	}
+
+	events, err := a.auditSvc.Events(r.Context(), userID, filter)
+	if err != nil {
+		internalError(w, r, err)
+		return
+	}
+	if len(events) == 0 {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+
+	WriteJSON(w, r, events, http.StatusOK)
+}
+
+// audit records the action of the authenticated user on the item with
+// the given id, if any, in the audit log. The collection of the
+// request is used as the target unless the target is given.
+func (a *API) audit(r *http.Request, action models.AuditAction, itemID int, target string) {
+	userID, _ := r.Context().Value(ctxUserID).(string)
+	sessionID, _ := r.Context().Value(ctxSessionID).(string)
+	if target == "" {
+		target = chi.URLParam(r, "collection")
+	}
+
+	a.record(r, &models.AuditEvent{
+		UserID:    userID,
+		SessionID: sessionID,
+		Action:    action,
+		ItemID:    itemID,
+		Target:    target,
+	})
+}
+
+// record appends the event to the audit log with the address of the
+// client. The failure to record the event is logged by the service and
+// does not fail the request.
+func (a *API) record(r *http.Request, event *models.AuditEvent) {
+	event.IP = r.RemoteAddr
+	if host, _, err := net.SplitHostPort(r.RemoteAddr); err == nil {
+		event.IP = host
+	}
+
+	_ = a.auditSvc.Record(r.Context(), event)
+}
+
+// urlItemID returns the item id from the path or 0 if it is invalid.
+func urlItemID(r *http.Request) int {
+	id, _ := strconv.Atoi(chi.URLParam(r, "id"))
+
+	return id
+}
@@ -5,7 +5,9 @@ import (
 	"net/http"
 	"strings"
 
+	"github.com/iryzzh/y-gophkeeper/internal/logger"
 	"github.com/iryzzh/y-gophkeeper/internal/models"
+	"github.com/sirupsen/logrus"
 	"golang.org/x/net/context"
 )
 
@@ -15,11 +17,14 @@ func (a *API) Auth(h http.Handler) http.Handler {
 		ctx := r.Context()
 		token, err := a.verifyRequest(r, tokenFromHeader)
 		if err != nil || token == nil {
-			w.WriteHeader(http.StatusUnauthorized)
+			a.metrics.AuthFailure("invalid_token")
+			WriteError(w, r, http.StatusUnauthorized, errNotAuthenticated)
 			return
 		}
 
 		ctx = context.WithValue(ctx, ctxUserID, token.UserID)
+		ctx = context.WithValue(ctx, ctxSessionID, token.SessionID)
+		logger.AddFields(ctx, logrus.Fields{"user_id": token.UserID})
 
 		h.ServeHTTP(w, r.WithContext(ctx))
 	})
@@ -0,0 +1,46 @@
+package v1
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/iryzzh/y-gophkeeper/internal/services/item"
+	"github.com/pkg/errors"
+)
+
+// defaultDueDays is the period in days of the due items when it is
+// not specified.
+const defaultDueDays = 30
+
+// errInvalidDays is returned when the period of the due items is not a
+// non-negative number of days.
+var errInvalidDays = errors.New("invalid number of days")
+
+// itemDue returns the `models.Due` of the items expiring or due for
+// rotation within the number of days specified as the query
+// `?within=n`, 30 by default. The overdue items are always returned.
+func (a *API) itemDue(w http.ResponseWriter, r *http.Request) {
+	userID, _ := r.Context().Value(ctxUserID).(string)
+
+	days := defaultDueDays
+	if v := r.URL.Query().Get("within"); v != "" {
+		var err error
+		if days, err = strconv.Atoi(v); err != nil || days < 0 {
+			validationError(w, r, errInvalidDays, "within")
+			return
+		}
+	}
+
+	due, err := a.itemSvc.Due(r.Context(), userID, time.Duration(days)*24*time.Hour)
+	if errors.Is(err, item.ErrForbidden) {
+		WriteError(w, r, http.StatusForbidden, err)
+		return
+	}
+	if err != nil {
+		internalError(w, r, err)
+		return
+	}
+
+	WriteJSON(w, r, due, http.StatusOK)
+}
@@ -0,0 +1,201 @@
+package v1
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/iryzzh/y-gophkeeper/internal/models"
+	"github.com/iryzzh/y-gophkeeper/internal/services/emergency"
+	"github.com/iryzzh/y-gophkeeper/internal/services/item"
+	"github.com/pkg/errors"
+)
+
+// emergencyGranted returns the `models.EmergencyAccess` granted by the
+// user to the trusted contacts.
+func (a *API) emergencyGranted(w http.ResponseWriter, r *http.Request) {
+	userID, _ := r.Context().Value(ctxUserID).(string)
+
+	access, err := a.emergencySvc.Granted(r.Context(), userID)
+	if err != nil {
+		internalError(w, r, err)
+		return
+	}
+	if len(access) == 0 {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+
+	WriteJSON(w, r, access, http.StatusOK)
+}
+
+// emergencyTrusted returns the `models.EmergencyAccess` granted to the
+// user by the other users.
+func (a *API) emergencyTrusted(w http.ResponseWriter, r *http.Request) {
+	userID, _ := r.Context().Value(ctxUserID).(string)
+
+	access, err := a.emergencySvc.Trusted(r.Context(), userID)
+	if err != nil {
+		internalError(w, r, err)
+		return
+	}
+	if len(access) == 0 {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+
+	WriteJSON(w, r, access, http.StatusOK)
+}
+
+// emergencyGrant grants the emergency access to the vault of the user
+// to the grantee of the received `models.EmergencyAccess`.
+func (a *API) emergencyGrant(w http.ResponseWriter, r *http.Request) {
+	userID, _ := r.Context().Value(ctxUserID).(string)
+
+	var access models.EmergencyAccess
+	if err := json.NewDecoder(r.Body).Decode(&access); err != nil {
+		WriteError(w, r, http.StatusBadRequest, err)
+		return
+	}
+
+	err := a.emergencySvc.Grant(r.Context(), userID, &access)
+	if errors.Is(err, emergency.ErrInvalidGrant) {
+		validationError(w, r, err, "")
+		return
+	}
+	if writeEmergencyError(w, r, err) {
+		return
+	}
+
+	a.audit(r, models.AuditEmergencyGrant, 0, access.Grantee)
+
+	access.Key = nil
+	WriteJSON(w, r, access, http.StatusOK)
+}
+
+// emergencyRevoke revokes the emergency access granted to the user
+// with the given login.
+func (a *API) emergencyRevoke(w http.ResponseWriter, r *http.Request) {
+	userID, _ := r.Context().Value(ctxUserID).(string)
+
+	err := a.emergencySvc.Revoke(r.Context(), userID, chi.URLParam(r, "login"))
+	if writeEmergencyError(w, r, err) {
+		return
+	}
+
+	a.audit(r, models.AuditEmergencyRevoke, 0, chi.URLParam(r, "login"))
+
+	w.WriteHeader(http.StatusOK)
+}
+
+// emergencyApprove releases the emergency access requested by the
+// user with the given login.
+func (a *API) emergencyApprove(w http.ResponseWriter, r *http.Request) {
+	userID, _ := r.Context().Value(ctxUserID).(string)
+
+	err := a.emergencySvc.Approve(r.Context(), userID, chi.URLParam(r, "login"))
+	if writeEmergencyError(w, r, err) {
+		return
+	}
+
+	a.audit(r, models.AuditEmergencyApprove, 0, chi.URLParam(r, "login"))
+
+	w.WriteHeader(http.StatusOK)
+}
+
+// emergencyDeny denies the emergency access requested by the user with
+// the given login.
+func (a *API) emergencyDeny(w http.ResponseWriter, r *http.Request) {
+	userID, _ := r.Context().Value(ctxUserID).(string)
+
+	err := a.emergencySvc.Deny(r.Context(), userID, chi.URLParam(r, "login"))
+	if writeEmergencyError(w, r, err) {
+		return
+	}
+
+	a.audit(r, models.AuditEmergencyDeny, 0, chi.URLParam(r, "login"))
+
+	w.WriteHeader(http.StatusOK)
+}
+
+// emergencyRequest requests the emergency access to the vault of the
+// user with the given login and returns the requested
+// `models.EmergencyAccess`.
+func (a *API) emergencyRequest(w http.ResponseWriter, r *http.Request) {
+	userID, _ := r.Context().Value(ctxUserID).(string)
+
+	access, err := a.emergencySvc.Request(r.Context(), userID, chi.URLParam(r, "login"))
+	if writeEmergencyError(w, r, err) {
+		return
+	}
+
+	a.audit(r, models.AuditEmergencyRequest, 0, chi.URLParam(r, "login"))
+
+	access.Key = nil
+	WriteJSON(w, r, access, http.StatusOK)
+}
+
+// emergencyAccess returns the approved `models.EmergencyAccess` to the
+// vault of the user with the given login with the sealed vault key.
+func (a *API) emergencyAccess(w http.ResponseWriter, r *http.Request) {
+	userID, _ := r.Context().Value(ctxUserID).(string)
+
+	access, err := a.emergencySvc.Access(r.Context(), userID, chi.URLParam(r, "login"))
+	if writeEmergencyError(w, r, err) {
+		return
+	}
+
+	a.audit(r, models.AuditEmergencyAccess, 0, chi.URLParam(r, "login"))
+
+	WriteJSON(w, r, access, http.StatusOK)
+}
+
+// emergencyItems returns the `models.Items` of the vault of the user
+// with the given login once the emergency access is approved. The
+// page can be specified as the query `?limit=n&offset=n`.
+func (a *API) emergencyItems(w http.ResponseWriter, r *http.Request) {
+	userID, _ := r.Context().Value(ctxUserID).(string)
+
+	access, err := a.emergencySvc.Access(r.Context(), userID, chi.URLParam(r, "login"))
+	if writeEmergencyError(w, r, err) {
+		return
+	}
+
+	items, err := a.itemSvc.FindByUserID(
+		r.Context(),
+		access.GrantorID,
+		r.URL.Query().Get("limit"),
+		r.URL.Query().Get("offset"),
+	)
+	if errors.Is(err, item.ErrItemNotFound) {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+	if err != nil {
+		internalError(w, r, err)
+		return
+	}
+
+	a.audit(r, models.AuditEmergencyAccess, 0, chi.URLParam(r, "login"))
+
+	WriteJSON(w, r, items, http.StatusOK)
+}
+
+// writeEmergencyError writes the response for the errors common to the
+// emergency access handlers and reports whether the error was written.
+func writeEmergencyError(w http.ResponseWriter, r *http.Request, err error) bool {
+	switch {
+	case err == nil:
+		return false
+	case errors.Is(err, emergency.ErrNotFound), errors.Is(err, emergency.ErrContactNotFound):
+		WriteError(w, r, http.StatusNotFound, err)
+	case errors.Is(err, emergency.ErrInvalidStatus):
+		WriteError(w, r, http.StatusConflict, err)
+	case errors.Is(err, emergency.ErrNotApproved):
+		WriteError(w, r, http.StatusForbidden, err)
+	default:
+		internalError(w, r, err)
+	}
+
+	return true
+}
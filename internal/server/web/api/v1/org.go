@@ -0,0 +1,251 @@
+package v1
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/iryzzh/y-gophkeeper/internal/models"
+	"github.com/iryzzh/y-gophkeeper/internal/services/item"
+	"github.com/iryzzh/y-gophkeeper/internal/services/org"
+	"github.com/pkg/errors"
+)
+
+// collectionCtx checks that the user is a member of the organization
+// of the collection in the path, so that the item handlers process the
+// items of the collection. The role of the user is checked by the item
+// service for every operation.
+func (a *API) collectionCtx(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		userID, _ := r.Context().Value(ctxUserID).(string)
+
+		c, err := a.orgSvc.Collection(r.Context(), userID, chi.URLParam(r, "collection"))
+		if errors.Is(err, org.ErrCollectionNotFound) {
+			WriteError(w, r, http.StatusNotFound, err)
+			return
+		}
+		if err != nil {
+			internalError(w, r, err)
+			return
+		}
+
+		next.ServeHTTP(w, r.WithContext(item.WithCollection(r.Context(), c.ID)))
+	})
+}
+
+// orgList returns the organizations the user is a member of.
+func (a *API) orgList(w http.ResponseWriter, r *http.Request) {
+	userID, _ := r.Context().Value(ctxUserID).(string)
+
+	orgs, err := a.orgSvc.List(r.Context(), userID)
+	if err != nil {
+		internalError(w, r, err)
+		return
+	}
+	if len(orgs) == 0 {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+
+	WriteJSON(w, r, orgs, http.StatusOK)
+}
+
+// orgNew creates the received `models.Org` with the user as its owner.
+func (a *API) orgNew(w http.ResponseWriter, r *http.Request) {
+	userID, _ := r.Context().Value(ctxUserID).(string)
+
+	var o models.Org
+	if err := json.NewDecoder(r.Body).Decode(&o); err != nil {
+		WriteError(w, r, http.StatusBadRequest, err)
+		return
+	}
+
+	err := a.orgSvc.Create(r.Context(), userID, &o)
+	if errors.Is(err, org.ErrInvalidOrg) {
+		validationError(w, r, err, "name")
+		return
+	}
+	if errors.Is(err, org.ErrOrgExists) {
+		WriteError(w, r, http.StatusConflict, err)
+		return
+	}
+	if err != nil {
+		internalError(w, r, err)
+		return
+	}
+
+	a.audit(r, models.AuditOrgCreate, 0, o.ID)
+
+	WriteJSON(w, r, o, http.StatusCreated)
+}
+
+// orgDelete deletes the organization.
+func (a *API) orgDelete(w http.ResponseWriter, r *http.Request) {
+	userID, _ := r.Context().Value(ctxUserID).(string)
+
+	err := a.orgSvc.Delete(r.Context(), userID, chi.URLParam(r, "org"))
+	if errors.Is(err, org.ErrOrgNotEmpty) {
+		WriteError(w, r, http.StatusConflict, err)
+		return
+	}
+	if writeOrgError(w, r, err) {
+		return
+	}
+
+	a.audit(r, models.AuditOrgDelete, 0, chi.URLParam(r, "org"))
+
+	w.WriteHeader(http.StatusOK)
+}
+
+// memberList returns the `models.Member` list of the organization.
+func (a *API) memberList(w http.ResponseWriter, r *http.Request) {
+	userID, _ := r.Context().Value(ctxUserID).(string)
+
+	members, err := a.orgSvc.Members(r.Context(), userID, chi.URLParam(r, "org"))
+	if writeOrgError(w, r, err) {
+		return
+	}
+
+	WriteJSON(w, r, members, http.StatusOK)
+}
+
+// memberSet adds the received `models.Member` to the organization or
+// changes the role of the member.
+func (a *API) memberSet(w http.ResponseWriter, r *http.Request) {
+	userID, _ := r.Context().Value(ctxUserID).(string)
+
+	var m models.Member
+	if err := json.NewDecoder(r.Body).Decode(&m); err != nil {
+		WriteError(w, r, http.StatusBadRequest, err)
+		return
+	}
+
+	err := a.orgSvc.SetMember(r.Context(), userID, chi.URLParam(r, "org"), &m)
+	if errors.Is(err, org.ErrInvalidRole) {
+		validationError(w, r, err, "role")
+		return
+	}
+	if errors.Is(err, org.ErrUserNotFound) {
+		WriteError(w, r, http.StatusNotFound, err)
+		return
+	}
+	if errors.Is(err, org.ErrLastOwner) {
+		WriteError(w, r, http.StatusConflict, err)
+		return
+	}
+	if writeOrgError(w, r, err) {
+		return
+	}
+
+	a.audit(r, models.AuditMemberSet, 0, chi.URLParam(r, "org")+"/"+m.Login)
+
+	WriteJSON(w, r, m, http.StatusOK)
+}
+
+// memberRemove removes the member with the given login from the
+// organization.
+func (a *API) memberRemove(w http.ResponseWriter, r *http.Request) {
+	userID, _ := r.Context().Value(ctxUserID).(string)
+
+	err := a.orgSvc.RemoveMember(r.Context(), userID, chi.URLParam(r, "org"), chi.URLParam(r, "login"))
+	if errors.Is(err, org.ErrMemberNotFound) {
+		WriteError(w, r, http.StatusNotFound, err)
+		return
+	}
+	if errors.Is(err, org.ErrLastOwner) {
+		WriteError(w, r, http.StatusConflict, err)
+		return
+	}
+	if writeOrgError(w, r, err) {
+		return
+	}
+
+	a.audit(r, models.AuditMemberRemove, 0, chi.URLParam(r, "org")+"/"+chi.URLParam(r, "login"))
+
+	w.WriteHeader(http.StatusOK)
+}
+
+// collectionList returns the `models.Collection` list of the
+// organization.
+func (a *API) collectionList(w http.ResponseWriter, r *http.Request) {
+	userID, _ := r.Context().Value(ctxUserID).(string)
+
+	collections, err := a.orgSvc.Collections(r.Context(), userID, chi.URLParam(r, "org"))
+	if writeOrgError(w, r, err) {
+		return
+	}
+	if len(collections) == 0 {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+
+	WriteJSON(w, r, collections, http.StatusOK)
+}
+
+// collectionNew creates the received `models.Collection` in the
+// organization.
+func (a *API) collectionNew(w http.ResponseWriter, r *http.Request) {
+	userID, _ := r.Context().Value(ctxUserID).(string)
+
+	var c models.Collection
+	if err := json.NewDecoder(r.Body).Decode(&c); err != nil {
+		WriteError(w, r, http.StatusBadRequest, err)
+		return
+	}
+
+	err := a.orgSvc.CreateCollection(r.Context(), userID, chi.URLParam(r, "org"), &c)
+	if errors.Is(err, org.ErrInvalidCollection) {
+		validationError(w, r, err, "name")
+		return
+	}
+	if errors.Is(err, org.ErrCollectionExists) {
+		WriteError(w, r, http.StatusConflict, err)
+		return
+	}
+	if writeOrgError(w, r, err) {
+		return
+	}
+
+	a.audit(r, models.AuditCollectionCreate, 0, c.ID)
+
+	WriteJSON(w, r, c, http.StatusCreated)
+}
+
+// collectionDelete deletes the collection of the organization.
+func (a *API) collectionDelete(w http.ResponseWriter, r *http.Request) {
+	userID, _ := r.Context().Value(ctxUserID).(string)
+
+	err := a.orgSvc.DeleteCollection(r.Context(), userID, chi.URLParam(r, "org"), chi.URLParam(r, "collection"))
+	if errors.Is(err, org.ErrCollectionNotFound) {
+		WriteError(w, r, http.StatusNotFound, err)
+		return
+	}
+	if errors.Is(err, org.ErrCollectionNotEmpty) {
+		WriteError(w, r, http.StatusConflict, err)
+		return
+	}
+	if writeOrgError(w, r, err) {
+		return
+	}
+
+	a.audit(r, models.AuditCollectionDelete, 0, "")
+
+	w.WriteHeader(http.StatusOK)
+}
+
+// writeOrgError writes the response for the errors common to the
+// organization handlers and reports whether the error was written.
+func writeOrgError(w http.ResponseWriter, r *http.Request, err error) bool {
+	switch {
+	case err == nil:
+		return false
+	case errors.Is(err, org.ErrOrgNotFound):
+		WriteError(w, r, http.StatusNotFound, err)
+	case errors.Is(err, org.ErrForbidden):
+		WriteError(w, r, http.StatusForbidden, err)
+	default:
+		internalError(w, r, err)
+	}
+
+	return true
+}
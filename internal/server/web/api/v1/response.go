@@ -3,15 +3,98 @@ package v1
 import (
 	"net/http"
 
+	"github.com/go-chi/chi/middleware"
+	"github.com/iryzzh/y-gophkeeper/internal/logger"
+	"github.com/iryzzh/y-gophkeeper/internal/models"
 	jsoniter "github.com/json-iterator/go"
 )
 
-func WriteJSON(w http.ResponseWriter, data interface{}, status int) {
+// WriteJSON writes the data encoded as json with the given status. The
+// data is encoded before anything is written, so that the failure is
+// reported as an internal error.
+func WriteJSON(w http.ResponseWriter, r *http.Request, data interface{}, status int) {
+	json := jsoniter.ConfigCompatibleWithStandardLibrary
+	body, err := json.Marshal(data)
+	if err != nil {
+		internalError(w, r, err)
+		return
+	}
+
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(status)
+	_, _ = w.Write(append(body, '\n'))
+}
+
+// WriteError writes the error as `models.Error` with the code matching
+// the status.
+func WriteError(w http.ResponseWriter, r *http.Request, status int, err error) {
+	writeError(w, r, status, &models.Error{Code: errorCode(status), Message: err.Error()})
+}
+
+// validationError writes the error as `models.Error` with
+// `models.CodeValidation`, detailing the invalid field if it is known.
+func validationError(w http.ResponseWriter, r *http.Request, err error, field string) {
+	e := &models.Error{Code: models.CodeValidation, Message: err.Error()}
+	if field != "" {
+		e.Fields = map[string]string{field: err.Error()}
+	}
+
+	writeError(w, r, http.StatusBadRequest, e)
+}
+
+// quotaError writes the error as `models.Error` with
+// `models.CodeQuotaExceeded`.
+func quotaError(w http.ResponseWriter, r *http.Request, err error) {
+	writeError(w, r, http.StatusRequestEntityTooLarge, &models.Error{Code: models.CodeQuotaExceeded, Message: err.Error()})
+}
+
+// internalError logs the error with the fields of the request, unless
+// the service logged it already, and writes the generic message with
+// the request id, so that the details of the error are not exposed to
+// the client.
+func internalError(w http.ResponseWriter, r *http.Request, err error) {
+	if !logger.Logged(err) {
+		logger.FromContext(r.Context()).WithError(err).Error("internal server error")
+	}
+
+	writeError(w, r, http.StatusInternalServerError, &models.Error{
+		Code:    models.CodeInternal,
+		Message: http.StatusText(http.StatusInternalServerError),
+	})
+}
+
+// writeError writes the error with the id of the request.
+func writeError(w http.ResponseWriter, r *http.Request, status int, e *models.Error) {
+	e.RequestID = middleware.GetReqID(r.Context())
 
 	json := jsoniter.ConfigCompatibleWithStandardLibrary
-	if err := json.NewEncoder(w).Encode(data); err != nil {
-		panic("WriteJSON: " + err.Error())
+	body, _ := json.Marshal(e)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.WriteHeader(status)
+	_, _ = w.Write(append(body, '\n'))
+}
+
+// errorCode returns the code of the error responded with the status.
+func errorCode(status int) models.ErrorCode {
+	switch status {
+	case http.StatusUnauthorized:
+		return models.CodeUnauthorized
+	case http.StatusForbidden:
+		return models.CodeForbidden
+	case http.StatusNotFound:
+		return models.CodeNotFound
+	case http.StatusConflict:
+		return models.CodeConflict
+	case http.StatusTooManyRequests:
+		return models.CodeRateLimited
+	case http.StatusBadRequest, http.StatusRequestEntityTooLarge:
+		return models.CodeBadRequest
+	}
+	if status >= http.StatusInternalServerError {
+		return models.CodeInternal
 	}
+
+	return models.CodeBadRequest
 }
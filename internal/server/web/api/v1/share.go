@@ -0,0 +1,129 @@
+package v1
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/iryzzh/y-gophkeeper/internal/models"
+	"github.com/iryzzh/y-gophkeeper/internal/services/item"
+	"github.com/iryzzh/y-gophkeeper/internal/services/user"
+	"github.com/pkg/errors"
+)
+
+// keySet registers the received `models.PublicKey` as the public key
+// of the user. The key cannot be replaced once registered.
+func (a *API) keySet(w http.ResponseWriter, r *http.Request) {
+	userID, _ := r.Context().Value(ctxUserID).(string)
+
+	var key models.PublicKey
+	if err := json.NewDecoder(r.Body).Decode(&key); err != nil {
+		WriteError(w, r, http.StatusBadRequest, err)
+		return
+	}
+
+	err := a.userSvc.SetPublicKey(r.Context(), userID, key.Key)
+	if errors.Is(err, user.ErrInvalidPublicKey) {
+		validationError(w, r, err, "key")
+		return
+	}
+	if errors.Is(err, user.ErrPublicKeyExists) {
+		WriteError(w, r, http.StatusConflict, err)
+		return
+	}
+	if err != nil {
+		internalError(w, r, err)
+		return
+	}
+
+	a.audit(r, models.AuditKeySet, 0, "")
+
+	w.WriteHeader(http.StatusOK)
+}
+
+// keyGet returns the `models.PublicKey` of the user with the given login.
+func (a *API) keyGet(w http.ResponseWriter, r *http.Request) {
+	key, err := a.userSvc.PublicKey(r.Context(), chi.URLParam(r, "login"))
+	if errors.Is(err, user.ErrUserNotFound) || errors.Is(err, user.ErrPublicKeyNotFound) {
+		WriteError(w, r, http.StatusNotFound, err)
+		return
+	}
+	if err != nil {
+		internalError(w, r, err)
+		return
+	}
+
+	WriteJSON(w, r, key, http.StatusOK)
+}
+
+// itemShare grants the user specified in the received `models.Share`
+// access to the item with the given id.
+func (a *API) itemShare(w http.ResponseWriter, r *http.Request) {
+	userID, _ := r.Context().Value(ctxUserID).(string)
+
+	var share models.Share
+	if err := json.NewDecoder(r.Body).Decode(&share); err != nil {
+		WriteError(w, r, http.StatusBadRequest, err)
+		return
+	}
+
+	err := a.itemSvc.Share(r.Context(), userID, chi.URLParam(r, "id"), &share)
+	if errors.Is(err, item.ErrIncorrectItemID) || errors.Is(err, item.ErrInvalidShare) {
+		validationError(w, r, err, "")
+		return
+	}
+	if errors.Is(err, item.ErrItemNotFound) || errors.Is(err, item.ErrRecipientNotFound) {
+		WriteError(w, r, http.StatusNotFound, err)
+		return
+	}
+	if err != nil {
+		internalError(w, r, err)
+		return
+	}
+
+	a.audit(r, models.AuditItemShare, urlItemID(r), share.Login)
+
+	w.WriteHeader(http.StatusOK)
+}
+
+// itemUnshare revokes the access of the user with the given login to
+// the item with the given id.
+func (a *API) itemUnshare(w http.ResponseWriter, r *http.Request) {
+	userID, _ := r.Context().Value(ctxUserID).(string)
+
+	err := a.itemSvc.Unshare(r.Context(), userID, chi.URLParam(r, "id"), chi.URLParam(r, "login"))
+	if errors.Is(err, item.ErrIncorrectItemID) || errors.Is(err, item.ErrInvalidShare) {
+		validationError(w, r, err, "")
+		return
+	}
+	if errors.Is(err, item.ErrItemNotFound) || errors.Is(err, item.ErrRecipientNotFound) {
+		WriteError(w, r, http.StatusNotFound, err)
+		return
+	}
+	if err != nil {
+		internalError(w, r, err)
+		return
+	}
+
+	a.audit(r, models.AuditItemUnshare, urlItemID(r), chi.URLParam(r, "login"))
+
+	w.WriteHeader(http.StatusOK)
+}
+
+// itemShared returns the `models.Items` of the other users shared
+// with the user.
+func (a *API) itemShared(w http.ResponseWriter, r *http.Request) {
+	userID, _ := r.Context().Value(ctxUserID).(string)
+
+	items, err := a.itemSvc.Shared(r.Context(), userID)
+	if errors.Is(err, item.ErrItemNotFound) {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+	if err != nil {
+		internalError(w, r, err)
+		return
+	}
+
+	WriteJSON(w, r, items, http.StatusOK)
+}
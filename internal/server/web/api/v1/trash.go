@@ -0,0 +1,119 @@
+package v1
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/iryzzh/y-gophkeeper/internal/models"
+	"github.com/iryzzh/y-gophkeeper/internal/services/item"
+	"github.com/pkg/errors"
+)
+
+// trashGet returns the `models.Items` in the trash of the user. The
+// page can be specified as the query `?limit=n&offset=n`.
+func (a *API) trashGet(w http.ResponseWriter, r *http.Request) {
+	userID, _ := r.Context().Value(ctxUserID).(string)
+
+	items, err := a.itemSvc.Trash(
+		r.Context(),
+		userID,
+		r.URL.Query().Get("limit"),
+		r.URL.Query().Get("offset"),
+	)
+	if errors.Is(err, item.ErrItemNotFound) {
+		w.WriteHeader(http.StatusNoContent)
+		return
+	}
+	if errors.Is(err, item.ErrForbidden) {
+		WriteError(w, r, http.StatusForbidden, err)
+		return
+	}
+	if err != nil {
+		internalError(w, r, err)
+		return
+	}
+
+	WriteJSON(w, r, items, http.StatusOK)
+}
+
+// trashRestore moves the item with the given id out of the trash and
+// returns the restored `models.Item`.
+func (a *API) trashRestore(w http.ResponseWriter, r *http.Request) {
+	userID, _ := r.Context().Value(ctxUserID).(string)
+
+	restored, err := a.itemSvc.Restore(r.Context(), userID, chi.URLParam(r, "id"))
+	if errors.Is(err, item.ErrIncorrectItemID) {
+		validationError(w, r, err, "id")
+		return
+	}
+	if errors.Is(err, item.ErrItemNotFound) {
+		WriteError(w, r, http.StatusNotFound, err)
+		return
+	}
+	if errors.Is(err, item.ErrItemExists) {
+		WriteError(w, r, http.StatusConflict, err)
+		return
+	}
+	if errors.Is(err, item.ErrForbidden) {
+		WriteError(w, r, http.StatusForbidden, err)
+		return
+	}
+	if err != nil {
+		internalError(w, r, err)
+		return
+	}
+
+	a.audit(r, models.AuditItemRestore, restored.ID, "")
+
+	WriteJSON(w, r, restored, http.StatusOK)
+}
+
+// trashEmpty permanently deletes the items in the trash of the user
+// and returns the number of deleted items as `models.Purge`.
+func (a *API) trashEmpty(w http.ResponseWriter, r *http.Request) {
+	userID, _ := r.Context().Value(ctxUserID).(string)
+
+	purged, err := a.itemSvc.EmptyTrash(r.Context(), userID)
+	if errors.Is(err, item.ErrForbidden) {
+		WriteError(w, r, http.StatusForbidden, err)
+		return
+	}
+	if err != nil {
+		internalError(w, r, err)
+		return
+	}
+
+	a.audit(r, models.AuditTrashEmpty, 0, "")
+
+	WriteJSON(w, r, models.Purge{Purged: purged}, http.StatusOK)
+}
+
+// itemDeleted returns the `models.Tombstone` of the items deleted
+// since the time specified in RFC 3339 format as the query
+// `?since=2006-01-02T15:04:05Z`. All the known tombstones are
+// returned if the time is not specified.
+func (a *API) itemDeleted(w http.ResponseWriter, r *http.Request) {
+	userID, _ := r.Context().Value(ctxUserID).(string)
+
+	var since time.Time
+	if v := r.URL.Query().Get("since"); v != "" {
+		var err error
+		if since, err = time.Parse(time.RFC3339, v); err != nil {
+			validationError(w, r, err, "since")
+			return
+		}
+	}
+
+	tombstones, err := a.itemSvc.Tombstones(r.Context(), userID, since)
+	if errors.Is(err, item.ErrForbidden) {
+		WriteError(w, r, http.StatusForbidden, err)
+		return
+	}
+	if err != nil {
+		internalError(w, r, err)
+		return
+	}
+
+	WriteJSON(w, r, tombstones, http.StatusOK)
+}
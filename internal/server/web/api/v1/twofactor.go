@@ -0,0 +1,98 @@
+package v1
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/go-chi/chi/v5"
+	"github.com/iryzzh/y-gophkeeper/internal/models"
+	"github.com/iryzzh/y-gophkeeper/internal/services/user"
+	"github.com/pkg/errors"
+)
+
+// twoFactorEnable generates the TOTP secret of the second factor of the
+// user and returns it as `models.TwoFactor`. The second factor is
+// enabled once `API.twoFactorConfirm` receives a code of it.
+func (a *API) twoFactorEnable(w http.ResponseWriter, r *http.Request) {
+	userID, _ := r.Context().Value(ctxUserID).(string)
+
+	tf, err := a.userSvc.EnableTwoFactor(r.Context(), userID)
+	if writeTwoFactorError(w, r, err) {
+		return
+	}
+
+	WriteJSON(w, r, tf, http.StatusOK)
+}
+
+// twoFactorConfirm enables the second factor of the user if the code of
+// the received `models.TwoFactor` matches the generated secret.
+func (a *API) twoFactorConfirm(w http.ResponseWriter, r *http.Request) {
+	userID, _ := r.Context().Value(ctxUserID).(string)
+
+	var tf models.TwoFactor
+	if err := json.NewDecoder(r.Body).Decode(&tf); err != nil {
+		WriteError(w, r, http.StatusBadRequest, err)
+		return
+	}
+
+	if writeTwoFactorError(w, r, a.userSvc.ConfirmTwoFactor(r.Context(), userID, tf.Code)) {
+		return
+	}
+
+	a.audit(r, models.Audit2FAEnable, 0, "")
+
+	w.WriteHeader(http.StatusOK)
+}
+
+// twoFactorDisable disables the second factor of the user if the code
+// of the received `models.TwoFactor` matches its secret.
+func (a *API) twoFactorDisable(w http.ResponseWriter, r *http.Request) {
+	userID, _ := r.Context().Value(ctxUserID).(string)
+
+	var tf models.TwoFactor
+	if err := json.NewDecoder(r.Body).Decode(&tf); err != nil {
+		WriteError(w, r, http.StatusBadRequest, err)
+		return
+	}
+
+	if writeTwoFactorError(w, r, a.userSvc.DisableTwoFactor(r.Context(), userID, tf.Code)) {
+		return
+	}
+
+	a.audit(r, models.Audit2FADisable, 0, "")
+
+	w.WriteHeader(http.StatusOK)
+}
+
+// adminResetTwoFactor disables the second factor of the user with the
+// given login, who lost it.
+func (a *API) adminResetTwoFactor(w http.ResponseWriter, r *http.Request) {
+	login := chi.URLParam(r, "login")
+
+	if writeAdminError(w, r, a.userSvc.ResetTwoFactor(r.Context(), login)) {
+		return
+	}
+
+	a.audit(r, models.AuditAdminReset2FA, 0, login)
+
+	w.WriteHeader(http.StatusOK)
+}
+
+// writeTwoFactorError writes the response for the errors of the second
+// factor handlers and reports whether the error was written.
+func writeTwoFactorError(w http.ResponseWriter, r *http.Request, err error) bool {
+	switch {
+	case err == nil:
+		return false
+	case errors.Is(err, user.ErrInvalidOTP):
+		validationError(w, r, err, "code")
+	case errors.Is(err, user.ErrTwoFactorEnabled), errors.Is(err, user.ErrTwoFactorDisabled):
+		WriteError(w, r, http.StatusConflict, err)
+	case errors.Is(err, user.ErrUserNotFound):
+		WriteError(w, r, http.StatusUnauthorized, errNotAuthenticated)
+	default:
+		internalError(w, r, err)
+	}
+
+	return true
+}
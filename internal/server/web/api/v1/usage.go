@@ -0,0 +1,30 @@
+package v1
+
+import (
+	"net/http"
+
+	"github.com/iryzzh/y-gophkeeper/internal/models"
+	"github.com/iryzzh/y-gophkeeper/internal/services/user"
+	"github.com/pkg/errors"
+)
+
+// usageGet returns the user with the storage usage and the quota of
+// the personal vault.
+func (a *API) usageGet(w http.ResponseWriter, r *http.Request) {
+	userID, _ := r.Context().Value(ctxUserID).(string)
+
+	u, err := a.userSvc.FindByID(r.Context(), userID)
+	if errors.Is(err, user.ErrUserNotFound) {
+		WriteError(w, r, http.StatusUnauthorized, errNotAuthenticated)
+		return
+	}
+	if err == nil {
+		err = a.itemSvc.Usage(r.Context(), u)
+	}
+	if err != nil {
+		internalError(w, r, err)
+		return
+	}
+
+	WriteJSON(w, r, &models.User{Login: u.Login, Usage: u.Usage, Quota: u.Quota}, http.StatusOK)
+}
@@ -0,0 +1,72 @@
+package web
+
+import (
+	"net/http"
+
+	"github.com/iryzzh/y-gophkeeper/internal/logger"
+	v1 "github.com/iryzzh/y-gophkeeper/internal/server/web/api/v1"
+	"github.com/pkg/errors"
+)
+
+const (
+	// checkOK is the result of the passed readiness check.
+	checkOK = "ok"
+	// checkUnavailable is the result of the failed readiness check. The
+	// error itself is only logged, as the endpoint is unauthenticated.
+	checkUnavailable = "unavailable"
+)
+
+// errNotMigrated is reported when the migrations of the store are not
+// applied.
+var errNotMigrated = errors.New("the migrations are not applied")
+
+// health is the body of the health responses.
+type health struct {
+	Status string            `json:"status"`
+	Checks map[string]string `json:"checks,omitempty"`
+}
+
+// registerHealth registers the unauthenticated liveness, readiness and
+// version endpoints.
+func (s *Server) registerHealth() {
+	s.Mux.Get("/healthz", s.healthz)
+	s.Mux.Get("/readyz", s.readyz)
+	s.Mux.Get("/version", s.versionInfo)
+}
+
+// healthz reports that the process is alive.
+func (s *Server) healthz(w http.ResponseWriter, r *http.Request) {
+	v1.WriteJSON(w, r, health{Status: checkOK}, http.StatusOK)
+}
+
+// readyz reports whether the server is ready to serve the requests:
+// the store is reachable and its migrations are applied.
+func (s *Server) readyz(w http.ResponseWriter, r *http.Request) {
+	res := health{Status: checkOK, Checks: map[string]string{"store": checkOK, "migrations": checkOK}}
+	log := logger.FromContext(r.Context())
+
+	if err := s.status.Ping(); err != nil {
+		log.WithError(err).Error("readiness: store ping")
+		res.Checks["store"] = checkUnavailable
+		res.Checks["migrations"] = "unknown"
+	} else if migrations, err := s.status.Migrations(); err != nil {
+		log.WithError(err).Error("readiness: store migrations")
+		res.Checks["migrations"] = checkUnavailable
+	} else if !migrations.UpToDate() {
+		res.Checks["migrations"] = errNotMigrated.Error()
+	}
+
+	status := http.StatusOK
+	for _, v := range res.Checks {
+		if v != checkOK {
+			res.Status, status = checkUnavailable, http.StatusServiceUnavailable
+		}
+	}
+
+	v1.WriteJSON(w, r, res, status)
+}
+
+// versionInfo returns the build version of the server.
+func (s *Server) versionInfo(w http.ResponseWriter, r *http.Request) {
+	v1.WriteJSON(w, r, s.version, http.StatusOK)
+}
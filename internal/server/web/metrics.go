@@ -0,0 +1,37 @@
+package web
+
+import (
+	"net/http"
+	"time"
+
+	"github.com/pkg/errors"
+	"golang.org/x/net/context"
+)
+
+// serveMetrics serves the metrics at `/metrics` on the separate
+// address until the context is done.
+func (s *Server) serveMetrics(ctx context.Context) {
+	mux := http.NewServeMux()
+	mux.Handle("/metrics", s.metrics.Handler())
+
+	srv := &http.Server{
+		Addr:         s.metricsAddr,
+		Handler:      mux,
+		ReadTimeout:  srvTimeout,
+		WriteTimeout: srvTimeout,
+	}
+
+	go func() {
+		<-ctx.Done()
+
+		timeout, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+
+		_ = srv.Shutdown(timeout) //nolint:contextcheck
+	}()
+
+	s.log.WithField("addr", s.metricsAddr).Info("starting the metrics server")
+	if err := srv.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+		s.log.WithError(err).Error("metrics server failed")
+	}
+}
@@ -3,8 +3,12 @@ package web
 import (
 	"compress/gzip"
 	"net/http"
+	"time"
 
 	"github.com/go-chi/chi/middleware"
+	"github.com/iryzzh/y-gophkeeper/internal/logger"
+	v1 "github.com/iryzzh/y-gophkeeper/internal/server/web/api/v1"
+	"github.com/sirupsen/logrus"
 )
 
 func (s *Server) registerMiddlewares() {
@@ -12,18 +16,57 @@ func (s *Server) registerMiddlewares() {
 
 	h.Use(middleware.RequestID)
 	h.Use(middleware.RealIP)
-	h.Use(middleware.Logger)
+	h.Use(s.metrics.Middleware)
+	h.Use(s.requestLogger)
 	h.Use(middleware.Recoverer)
 	h.Use(middleware.Compress(5)) //nolint:gomnd
 	h.Use(gzipHandler)
 }
 
+// requestLogger puts the entry with the request id into the context of
+// the request and logs the request once it is served, with the fields
+// added by the handlers, such as the user id.
+func (s *Server) requestLogger(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := logger.NewContext(r.Context(), s.log.WithFields(logrus.Fields{
+			"request_id": middleware.GetReqID(r.Context()),
+			"method":     r.Method,
+			"path":       r.URL.Path,
+			"ip":         r.RemoteAddr,
+		}))
+
+		ww := middleware.NewWrapResponseWriter(w, r.ProtoMajor)
+		start := time.Now()
+
+		defer func() {
+			status := ww.Status()
+			if status == 0 {
+				status = http.StatusOK
+			}
+
+			entry := logger.FromContext(ctx).WithFields(logrus.Fields{
+				"status":   status,
+				"bytes":    ww.BytesWritten(),
+				"duration": time.Since(start).String(),
+			})
+			if status >= http.StatusInternalServerError {
+				entry.Warn("request served")
+			} else {
+				entry.Info("request served")
+			}
+		}()
+
+		next.ServeHTTP(ww, r.WithContext(ctx))
+	})
+}
+
 func gzipHandler(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		if r.Header.Get("content-encoding") == "gzip" {
 			gz, err := gzip.NewReader(r.Body)
 			if err != nil {
-				panic(err)
+				v1.WriteError(w, r, http.StatusBadRequest, err)
+				return
 			}
 			r.Body = gz
 			_ = gz.Close()
@@ -3,52 +3,78 @@ package web
 import (
 	"crypto/tls"
 	"fmt"
+	"log"
 	"net"
 	"net/http"
 	"time"
 
+	"github.com/iryzzh/y-gophkeeper/internal/config"
+	"github.com/iryzzh/y-gophkeeper/internal/server/metrics"
+	"github.com/iryzzh/y-gophkeeper/internal/services/audit"
+	"github.com/iryzzh/y-gophkeeper/internal/services/emergency"
 	"github.com/iryzzh/y-gophkeeper/internal/services/item"
+	"github.com/iryzzh/y-gophkeeper/internal/services/org"
 
 	"github.com/iryzzh/y-gophkeeper/internal/services/user"
+	"github.com/iryzzh/y-gophkeeper/internal/store"
 
 	"github.com/iryzzh/y-gophkeeper/internal/services/token"
 
 	"github.com/go-chi/chi/v5"
 	v1 "github.com/iryzzh/y-gophkeeper/internal/server/web/api/v1"
 	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
 	"golang.org/x/net/context"
 )
 
 // Server is an http server.
 type Server struct {
 	*chi.Mux
-	network     string
-	serverAddr  string
-	tlsCertPath string
-	tlsKeyPath  string
-	enableHTTPS bool
-	debug       bool
-	tokenSvc    *token.Service
-	userSvc     *user.Service
-	itemSvc     *item.Service
+	network      string
+	serverAddr   string
+	tlsCertPath  string
+	tlsKeyPath   string
+	enableHTTPS  bool
+	metricsAddr  string
+	debug        bool
+	tokenSvc     *token.Service
+	userSvc      *user.Service
+	itemSvc      *item.Service
+	orgSvc       *org.Service
+	emergencySvc *emergency.Service
+	auditSvc     *audit.Service
+	metrics      *metrics.Metrics
+	log          *logrus.Logger
+	status       store.Status
+	version      config.Version
 }
 
 // srvTimeout is the read and write timeout for the http server.
 const srvTimeout = time.Second * 30
 
 // NewServer returns a Server.
-func NewServer(network, serverAddr, tlsCertPath, tlsKeyPath string, enableHTTPS bool, tokenSvc *token.Service,
-	userSvc *user.Service, itemSvc *item.Service, debug bool) *Server {
+func NewServer(network, serverAddr, tlsCertPath, tlsKeyPath string, enableHTTPS bool, metricsAddr string,
+	m *metrics.Metrics, log *logrus.Logger, status store.Status, version config.Version, tokenSvc *token.Service,
+	userSvc *user.Service, itemSvc *item.Service, orgSvc *org.Service, emergencySvc *emergency.Service,
+	auditSvc *audit.Service, debug bool) *Server {
 	return &Server{
-		network:     network,
-		serverAddr:  serverAddr,
-		enableHTTPS: enableHTTPS,
-		tlsCertPath: tlsCertPath,
-		tlsKeyPath:  tlsKeyPath,
-		tokenSvc:    tokenSvc,
-		userSvc:     userSvc,
-		itemSvc:     itemSvc,
-		debug:       debug,
+		network:      network,
+		serverAddr:   serverAddr,
+		enableHTTPS:  enableHTTPS,
+		tlsCertPath:  tlsCertPath,
+		tlsKeyPath:   tlsKeyPath,
+		metricsAddr:  metricsAddr,
+		metrics:      m,
+		log:          log,
+		status:       status,
+		version:      version,
+		tokenSvc:     tokenSvc,
+		userSvc:      userSvc,
+		itemSvc:      itemSvc,
+		orgSvc:       orgSvc,
+		emergencySvc: emergencySvc,
+		auditSvc:     auditSvc,
+		debug:        debug,
 	}
 }
 
@@ -64,12 +90,19 @@ func (s *Server) Run(ctx context.Context) error {
 
 	s.Mux = chi.NewMux()
 	s.registerMiddlewares()
+	s.registerHealth()
 
-	apiV1 := v1.NewAPI(s.tokenSvc, s.userSvc, s.itemSvc)
+	apiV1 := v1.NewAPI(s.tokenSvc, s.userSvc, s.itemSvc, s.orgSvc, s.emergencySvc, s.auditSvc, s.metrics)
 	apiV1.Register(s.Mux)
 
+	errorLog := s.log.WriterLevel(logrus.ErrorLevel)
+	defer func() {
+		_ = errorLog.Close()
+	}()
+
 	srv := &http.Server{
 		Handler:      s.Mux,
+		ErrorLog:     log.New(errorLog, "", 0),
 		ReadTimeout:  srvTimeout,
 		WriteTimeout: srvTimeout,
 	}
@@ -78,7 +111,11 @@ func (s *Server) Run(ctx context.Context) error {
 	if s.enableHTTPS {
 		srvType = "HTTPS"
 	}
-	fmt.Printf("Starting the %s server on %s\n", srvType, listener.Addr())
+	s.log.WithField("addr", listener.Addr().String()).Infof("starting the %s server", srvType)
+
+	if s.metricsAddr != "" && s.metrics != nil {
+		go s.serveMetrics(ctx)
+	}
 
 	// serve in the background.
 	serveError := make(chan error, 1)
@@ -92,9 +129,9 @@ func (s *Server) Run(ctx context.Context) error {
 	// wait for stop or error signals.
 	select {
 	case <-ctx.Done():
-		fmt.Printf("Shutting down %s server\n", srvType)
+		s.log.Infof("shutting down the %s server", srvType)
 	case err = <-serveError:
-		fmt.Printf("%s server error: %v\n", srvType, err)
+		s.log.WithError(err).Errorf("%s server failed", srvType)
 		return err
 	}
 
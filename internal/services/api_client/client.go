@@ -3,8 +3,13 @@ package api_client
 import (
 	"crypto/tls"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"net/http"
+	"net/url"
+	"strings"
+	"time"
 
 	"github.com/go-resty/resty/v2"
 	"github.com/iryzzh/y-gophkeeper/internal/config"
@@ -17,12 +22,30 @@ const (
 	apiLoginEndpoint        = "/api/v1/login"
 	apiRefreshTokenEndpoint = "/api/v1/token/refresh" //nolint:gosec
 	apiItemEndpoint         = "/api/v1/item"
+	apiItemMoveEndpoint     = "/api/v1/item/move"
+	apiItemDeletedEndpoint  = "/api/v1/item/deleted"
+	apiTrashEndpoint        = "/api/v1/item/trash"
+	apiItemSharedEndpoint   = "/api/v1/item/shared"
+	apiKeysEndpoint         = "/api/v1/keys"
+	apiOrgsEndpoint         = "/api/v1/orgs"
+	apiCollectionsEndpoint  = "/api/v1/collections"
+	apiGrantedEndpoint      = "/api/v1/emergency/granted"
+	apiTrustedEndpoint      = "/api/v1/emergency/trusted"
+	apiAuditEndpoint        = "/api/v1/audit"
+	apiAdminUsersEndpoint   = "/api/v1/admin/users"
+	apiUsageEndpoint        = "/api/v1/usage"
+	apiTwoFactorEndpoint    = "/api/v1/2fa"
 )
 
+// ErrPublicKeyExists is returned when another public key is registered
+// for the user on the remote server.
+var ErrPublicKeyExists = errors.New("another public key is registered for the user")
+
 // ApiClient is a rest client.
 type ApiClient struct {
-	cfg   *config.API
-	resty *resty.Client
+	cfg        *config.API
+	resty      *resty.Client
+	collection string
 }
 
 // NewApiClient creates a new API client.
@@ -52,12 +75,28 @@ func (ac *ApiClient) Ping() error {
 	}
 
 	if get.StatusCode() != http.StatusOK {
-		return fmt.Errorf("remote ping failed")
+		return responseError("ping", get)
 	}
 
 	return nil
 }
 
+// SetCollection makes the client process the items of the collection
+// with the given id instead of the personal items of the user. The
+// empty id switches back to the personal items.
+func (ac *ApiClient) SetCollection(id string) {
+	ac.collection = id
+}
+
+// endpoint returns the item endpoint for the current collection.
+func (ac *ApiClient) endpoint(path string) string {
+	if ac.collection == "" {
+		return path
+	}
+
+	return apiCollectionsEndpoint + "/" + url.PathEscape(ac.collection) + strings.TrimPrefix(path, "/api/v1")
+}
+
 // SetBaseURL sets the remote server address.
 func (ac *ApiClient) SetBaseURL(url string) {
 	ac.cfg.Remote = url
@@ -77,7 +116,7 @@ func (ac *ApiClient) Signup(user *models.User) error {
 	}
 
 	if resp.StatusCode() != http.StatusCreated {
-		return fmt.Errorf("remote signup failed: %v", resp.String())
+		return responseError("signup", resp)
 	}
 
 	return ac.parseToken(resp.String())
@@ -103,7 +142,7 @@ func (ac *ApiClient) RefreshToken() error {
 		return err
 	}
 	if resp.StatusCode() != http.StatusCreated {
-		return fmt.Errorf("remote refresh token failed: %v", resp.String())
+		return responseError("refresh token", resp)
 	}
 
 	return ac.parseToken(resp.String())
@@ -122,7 +161,7 @@ func (ac *ApiClient) Login(user *models.User) error {
 	}
 
 	if resp.StatusCode() != http.StatusOK {
-		return fmt.Errorf("remote login failed: %v", resp.String())
+		return responseError("login", resp)
 	}
 
 	return ac.parseToken(resp.String())
@@ -136,7 +175,9 @@ const (
 	ActionDelete ActionKey = "delete"
 )
 
-func (ac *ApiClient) GetItems() ([]*models.Item, error) {
+// GetItems returns the items of the user. If tags are specified,
+// only the items having all of them are returned.
+func (ac *ApiClient) GetItems(tags ...string) ([]*models.Item, error) {
 	var itemsTotal []*models.Item
 	for i := 0; ; i++ {
 		if i%10 == 0 {
@@ -144,7 +185,7 @@ func (ac *ApiClient) GetItems() ([]*models.Item, error) {
 				"limit":  "10",
 				"offset": fmt.Sprintf("%d", i),
 			})
-			resp, err := ac.resty.R().Get(apiItemEndpoint)
+			resp, err := ac.resty.R().SetQueryParamsFromValues(url.Values{"tag": tags}).Get(ac.endpoint(apiItemEndpoint))
 			if err != nil {
 				return nil, err
 			}
@@ -152,7 +193,7 @@ func (ac *ApiClient) GetItems() ([]*models.Item, error) {
 				return itemsTotal, nil
 			}
 			if resp.StatusCode() != http.StatusOK {
-				return nil, fmt.Errorf("remote get items failed: %v", resp.String())
+				return nil, responseError("get items", resp)
 			}
 			got := &models.Items{}
 			if err := json.Unmarshal(resp.Body(), &got); err != nil {
@@ -169,37 +210,826 @@ func (ac *ApiClient) GetItems() ([]*models.Item, error) {
 }
 
 func (ac *ApiClient) Item(item *models.Item, action ActionKey) error {
+	if action == ActionNew {
+		_, err := ac.CreateItem(item)
+		return err
+	}
+
 	body, err := item.Marshal()
 	if err != nil {
 		return err
 	}
 
 	switch action {
-	case ActionNew:
-		resp, err := ac.resty.R().SetBody(body).Put(apiItemEndpoint)
-		if err != nil {
-			return err
-		}
-		if resp.StatusCode() != http.StatusCreated {
-			return fmt.Errorf("remote add item failed: %v", resp.String())
-		}
 	case ActionUpdate:
-		resp, err := ac.resty.R().SetBody(body).Post(fmt.Sprintf("%v/%v", apiItemEndpoint, item.ID))
+		resp, err := ac.resty.R().SetBody(body).Post(fmt.Sprintf("%v/%v", ac.endpoint(apiItemEndpoint), item.ID))
 		if err != nil {
 			return err
 		}
 		if resp.StatusCode() != http.StatusOK {
-			return fmt.Errorf("remote update item failed: %v", resp.String())
+			return responseError("update item", resp)
 		}
 	case ActionDelete:
-		resp, err := ac.resty.R().SetBody(body).Delete(fmt.Sprintf("%v/%v", apiItemEndpoint, item.ID))
+		resp, err := ac.resty.R().SetBody(body).Delete(fmt.Sprintf("%v/%v", ac.endpoint(apiItemEndpoint), item.ID))
 		if err != nil {
 			return err
 		}
 		if resp.StatusCode() != http.StatusOK {
-			return fmt.Errorf("remote delete item failed: %v", resp.String())
+			return responseError("delete item", resp)
 		}
 	}
 
 	return nil
 }
+
+// CreateItem creates the item on the remote server and returns its id
+// there.
+func (ac *ApiClient) CreateItem(item *models.Item) (int, error) {
+	body, err := item.Marshal()
+	if err != nil {
+		return 0, err
+	}
+
+	resp, err := ac.resty.R().SetBody(body).Put(ac.endpoint(apiItemEndpoint))
+	if err != nil {
+		return 0, err
+	}
+	if resp.StatusCode() != http.StatusCreated {
+		return 0, responseError("add item", resp)
+	}
+
+	created := &models.Item{}
+	if err = json.Unmarshal(resp.Body(), created); err != nil {
+		return 0, err
+	}
+
+	return created.ID, nil
+}
+
+// Move renames or moves the item or the folder on the remote server.
+func (ac *ApiClient) Move(move *models.Move) error {
+	resp, err := ac.resty.R().SetBody(move).Post(ac.endpoint(apiItemMoveEndpoint))
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode() != http.StatusOK {
+		return responseError("move item", resp)
+	}
+
+	return json.Unmarshal(resp.Body(), move)
+}
+
+// GetTrash returns the items in the trash of the user.
+func (ac *ApiClient) GetTrash() ([]*models.Item, error) {
+	resp, err := ac.resty.R().
+		SetQueryParams(map[string]string{"limit": "1000", "offset": "0"}).
+		Get(ac.endpoint(apiTrashEndpoint))
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode() == http.StatusNoContent {
+		return nil, nil
+	}
+	if resp.StatusCode() != http.StatusOK {
+		return nil, responseError("get trash", resp)
+	}
+
+	got := &models.Items{}
+	if err = json.Unmarshal(resp.Body(), got); err != nil {
+		return nil, err
+	}
+
+	return got.Data, nil
+}
+
+// Restore moves the item with the given id out of the trash on the
+// remote server.
+func (ac *ApiClient) Restore(id int) error {
+	resp, err := ac.resty.R().Post(fmt.Sprintf("%v/%v/restore", ac.endpoint(apiTrashEndpoint), id))
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode() != http.StatusOK {
+		return responseError("restore item", resp)
+	}
+
+	return nil
+}
+
+// EmptyTrash permanently deletes the items in the trash on the remote
+// server and returns their number.
+func (ac *ApiClient) EmptyTrash() (int, error) {
+	resp, err := ac.resty.R().Delete(ac.endpoint(apiTrashEndpoint))
+	if err != nil {
+		return 0, err
+	}
+	if resp.StatusCode() != http.StatusOK {
+		return 0, responseError("empty trash", resp)
+	}
+
+	var purge models.Purge
+	if err = json.Unmarshal(resp.Body(), &purge); err != nil {
+		return 0, err
+	}
+
+	return purge.Purged, nil
+}
+
+// Tombstones returns the items deleted on the remote server since the
+// given time. All the known tombstones are returned for the zero time.
+func (ac *ApiClient) Tombstones(since time.Time) ([]*models.Tombstone, error) {
+	req := ac.resty.R()
+	if !since.IsZero() {
+		req.SetQueryParam("since", since.UTC().Format(time.RFC3339))
+	}
+
+	resp, err := req.Get(ac.endpoint(apiItemDeletedEndpoint))
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode() != http.StatusOK {
+		return nil, responseError("get deleted items", resp)
+	}
+
+	var tombstones []*models.Tombstone
+	if err = json.Unmarshal(resp.Body(), &tombstones); err != nil {
+		return nil, err
+	}
+
+	return tombstones, nil
+}
+
+// SetPublicKey registers the public key of the user on the remote server.
+func (ac *ApiClient) SetPublicKey(key []byte) error {
+	resp, err := ac.resty.R().SetBody(models.PublicKey{Key: key}).Put(apiKeysEndpoint)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode() == http.StatusConflict {
+		return ErrPublicKeyExists
+	}
+	if resp.StatusCode() != http.StatusOK {
+		return responseError("set public key", resp)
+	}
+
+	return nil
+}
+
+// PublicKey returns the public key of the user with the given login.
+func (ac *ApiClient) PublicKey(login string) ([]byte, error) {
+	resp, err := ac.resty.R().Get(fmt.Sprintf("%v/%v", apiKeysEndpoint, url.PathEscape(login)))
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode() != http.StatusOK {
+		return nil, responseError("get public key", resp)
+	}
+
+	var key models.PublicKey
+	if err = json.Unmarshal(resp.Body(), &key); err != nil {
+		return nil, err
+	}
+
+	return key.Key, nil
+}
+
+// Share grants the user specified in the share access to the item
+// with the given id.
+func (ac *ApiClient) Share(id int, share *models.Share) error {
+	resp, err := ac.resty.R().SetBody(share).Post(fmt.Sprintf("%v/%v/share", apiItemEndpoint, id))
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode() != http.StatusOK {
+		return responseError("share item", resp)
+	}
+
+	return nil
+}
+
+// Unshare revokes the access of the user with the given login to the
+// item with the given id.
+func (ac *ApiClient) Unshare(id int, login string) error {
+	resp, err := ac.resty.R().Delete(fmt.Sprintf("%v/%v/share/%v", apiItemEndpoint, id, url.PathEscape(login)))
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode() != http.StatusOK {
+		return responseError("unshare item", resp)
+	}
+
+	return nil
+}
+
+// Attach uploads the content read from the reader as the attachment
+// with the given name of the item with the given id.
+func (ac *ApiClient) Attach(id int, name string, r io.Reader) (*models.Attachment, error) {
+	attachment := &models.Attachment{}
+	resp, err := ac.resty.R().
+		SetHeader("Content-Type", "application/octet-stream").
+		SetBody(r).
+		SetResult(attachment).
+		Put(ac.attachmentEndpoint(id, name))
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode() != http.StatusCreated {
+		return nil, responseError("attach file", resp)
+	}
+
+	return attachment, nil
+}
+
+// Attachments returns the attachments of the item with the given id.
+func (ac *ApiClient) Attachments(id int) ([]*models.Attachment, error) {
+	var attachments []*models.Attachment
+	resp, err := ac.resty.R().SetResult(&attachments).Get(ac.attachmentEndpoint(id, ""))
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode() != http.StatusOK {
+		return nil, responseError("get attachments", resp)
+	}
+
+	return attachments, nil
+}
+
+// OpenAttachment returns the content of the attachment with the given
+// name of the item with the given id as it is downloaded. It must be
+// closed by the caller.
+func (ac *ApiClient) OpenAttachment(id int, name string) (io.ReadCloser, error) {
+	resp, err := ac.resty.R().
+		SetHeader("Accept", "application/octet-stream").
+		SetDoNotParseResponse(true).
+		Get(ac.attachmentEndpoint(id, name))
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode() != http.StatusOK {
+		_ = resp.RawBody().Close()
+		return nil, responseError("download attachment", resp)
+	}
+
+	return resp.RawBody(), nil
+}
+
+// Detach deletes the attachment with the given name of the item with
+// the given id.
+func (ac *ApiClient) Detach(id int, name string) error {
+	resp, err := ac.resty.R().Delete(ac.attachmentEndpoint(id, name))
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode() != http.StatusNoContent {
+		return responseError("detach file", resp)
+	}
+
+	return nil
+}
+
+// attachmentEndpoint returns the endpoint of the attachment with the
+// given name of the item, or of the attachments of the item if the
+// name is empty.
+func (ac *ApiClient) attachmentEndpoint(id int, name string) string {
+	path := fmt.Sprintf("%v/%v/attachments", ac.endpoint(apiItemEndpoint), id)
+	if name == "" {
+		return path
+	}
+
+	return path + "/" + url.PathEscape(name)
+}
+
+// GetShared returns the items of the other users shared with the user.
+func (ac *ApiClient) GetShared() ([]*models.Item, error) {
+	resp, err := ac.resty.R().Get(apiItemSharedEndpoint)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode() == http.StatusNoContent {
+		return nil, nil
+	}
+	if resp.StatusCode() != http.StatusOK {
+		return nil, responseError("get shared items", resp)
+	}
+
+	got := &models.Items{}
+	if err = json.Unmarshal(resp.Body(), got); err != nil {
+		return nil, err
+	}
+
+	return got.Data, nil
+}
+
+// Orgs returns the organizations the user is a member of.
+func (ac *ApiClient) Orgs() ([]*models.Org, error) {
+	resp, err := ac.resty.R().Get(apiOrgsEndpoint)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode() == http.StatusNoContent {
+		return nil, nil
+	}
+	if resp.StatusCode() != http.StatusOK {
+		return nil, responseError("get organizations", resp)
+	}
+
+	var orgs []*models.Org
+	if err = json.Unmarshal(resp.Body(), &orgs); err != nil {
+		return nil, err
+	}
+
+	return orgs, nil
+}
+
+// CreateOrg creates the organization with the given name on the
+// remote server.
+func (ac *ApiClient) CreateOrg(name string) (*models.Org, error) {
+	resp, err := ac.resty.R().SetBody(models.Org{Name: name}).Put(apiOrgsEndpoint)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode() != http.StatusCreated {
+		return nil, responseError("create organization", resp)
+	}
+
+	org := &models.Org{}
+	if err = json.Unmarshal(resp.Body(), org); err != nil {
+		return nil, err
+	}
+
+	return org, nil
+}
+
+// DeleteOrg deletes the organization with the given id.
+func (ac *ApiClient) DeleteOrg(orgID string) error {
+	resp, err := ac.resty.R().Delete(fmt.Sprintf("%v/%v", apiOrgsEndpoint, url.PathEscape(orgID)))
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode() != http.StatusOK {
+		return responseError("delete organization", resp)
+	}
+
+	return nil
+}
+
+// Members returns the members of the organization with the given id.
+func (ac *ApiClient) Members(orgID string) ([]*models.Member, error) {
+	resp, err := ac.resty.R().Get(fmt.Sprintf("%v/%v/members", apiOrgsEndpoint, url.PathEscape(orgID)))
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode() != http.StatusOK {
+		return nil, responseError("get members", resp)
+	}
+
+	var members []*models.Member
+	if err = json.Unmarshal(resp.Body(), &members); err != nil {
+		return nil, err
+	}
+
+	return members, nil
+}
+
+// SetMember adds the member to the organization with the given id or
+// changes the role of the member.
+func (ac *ApiClient) SetMember(orgID string, member *models.Member) error {
+	resp, err := ac.resty.R().SetBody(member).
+		Put(fmt.Sprintf("%v/%v/members", apiOrgsEndpoint, url.PathEscape(orgID)))
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode() != http.StatusOK {
+		return responseError("set member", resp)
+	}
+
+	return nil
+}
+
+// RemoveMember removes the member with the given login from the
+// organization with the given id.
+func (ac *ApiClient) RemoveMember(orgID, login string) error {
+	resp, err := ac.resty.R().
+		Delete(fmt.Sprintf("%v/%v/members/%v", apiOrgsEndpoint, url.PathEscape(orgID), url.PathEscape(login)))
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode() != http.StatusOK {
+		return responseError("remove member", resp)
+	}
+
+	return nil
+}
+
+// Collections returns the collections of the organization with the
+// given id.
+func (ac *ApiClient) Collections(orgID string) ([]*models.Collection, error) {
+	resp, err := ac.resty.R().Get(fmt.Sprintf("%v/%v/collections", apiOrgsEndpoint, url.PathEscape(orgID)))
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode() == http.StatusNoContent {
+		return nil, nil
+	}
+	if resp.StatusCode() != http.StatusOK {
+		return nil, responseError("get collections", resp)
+	}
+
+	var collections []*models.Collection
+	if err = json.Unmarshal(resp.Body(), &collections); err != nil {
+		return nil, err
+	}
+
+	return collections, nil
+}
+
+// CreateCollection creates the collection with the given name in the
+// organization with the given id.
+func (ac *ApiClient) CreateCollection(orgID, name string) (*models.Collection, error) {
+	resp, err := ac.resty.R().SetBody(models.Collection{Name: name}).
+		Put(fmt.Sprintf("%v/%v/collections", apiOrgsEndpoint, url.PathEscape(orgID)))
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode() != http.StatusCreated {
+		return nil, responseError("create collection", resp)
+	}
+
+	c := &models.Collection{}
+	if err = json.Unmarshal(resp.Body(), c); err != nil {
+		return nil, err
+	}
+
+	return c, nil
+}
+
+// DeleteCollection deletes the collection with the given id of the
+// organization with the given id.
+func (ac *ApiClient) DeleteCollection(orgID, collectionID string) error {
+	resp, err := ac.resty.R().Delete(fmt.Sprintf("%v/%v/collections/%v",
+		apiOrgsEndpoint, url.PathEscape(orgID), url.PathEscape(collectionID)))
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode() != http.StatusOK {
+		return responseError("delete collection", resp)
+	}
+
+	return nil
+}
+
+// Grant grants the emergency access to the vault of the user to the
+// grantee of the access.
+func (ac *ApiClient) Grant(access *models.EmergencyAccess) error {
+	resp, err := ac.resty.R().SetBody(access).Put(apiGrantedEndpoint)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode() != http.StatusOK {
+		return responseError("grant emergency access", resp)
+	}
+
+	return nil
+}
+
+// Granted returns the emergency access granted by the user.
+func (ac *ApiClient) Granted() ([]*models.EmergencyAccess, error) {
+	return ac.emergencyAccess(apiGrantedEndpoint)
+}
+
+// Trusted returns the emergency access granted to the user.
+func (ac *ApiClient) Trusted() ([]*models.EmergencyAccess, error) {
+	return ac.emergencyAccess(apiTrustedEndpoint)
+}
+
+// Revoke revokes the emergency access granted to the user with the
+// given login.
+func (ac *ApiClient) Revoke(login string) error {
+	resp, err := ac.resty.R().Delete(fmt.Sprintf("%v/%v", apiGrantedEndpoint, url.PathEscape(login)))
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode() != http.StatusOK {
+		return responseError("revoke emergency access", resp)
+	}
+
+	return nil
+}
+
+// RequestAccess requests the emergency access to the vault of the user
+// with the given login.
+func (ac *ApiClient) RequestAccess(login string) (*models.EmergencyAccess, error) {
+	resp, err := ac.resty.R().Post(fmt.Sprintf("%v/%v/request", apiTrustedEndpoint, url.PathEscape(login)))
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode() != http.StatusOK {
+		return nil, responseError("request emergency access", resp)
+	}
+
+	access := &models.EmergencyAccess{}
+	if err = json.Unmarshal(resp.Body(), access); err != nil {
+		return nil, err
+	}
+
+	return access, nil
+}
+
+// Approve approves the emergency access requested by the user with the
+// given login.
+func (ac *ApiClient) Approve(login string) error {
+	resp, err := ac.resty.R().Post(fmt.Sprintf("%v/%v/approve", apiGrantedEndpoint, url.PathEscape(login)))
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode() != http.StatusOK {
+		return responseError("approve emergency access", resp)
+	}
+
+	return nil
+}
+
+// Deny denies the emergency access requested by the user with the
+// given login.
+func (ac *ApiClient) Deny(login string) error {
+	resp, err := ac.resty.R().Post(fmt.Sprintf("%v/%v/deny", apiGrantedEndpoint, url.PathEscape(login)))
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode() != http.StatusOK {
+		return responseError("deny emergency access", resp)
+	}
+
+	return nil
+}
+
+// EmergencyAccess returns the approved emergency access to the vault
+// of the user with the given login with the sealed vault key.
+func (ac *ApiClient) EmergencyAccess(login string) (*models.EmergencyAccess, error) {
+	resp, err := ac.resty.R().Get(fmt.Sprintf("%v/%v", apiTrustedEndpoint, url.PathEscape(login)))
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode() != http.StatusOK {
+		return nil, responseError("get emergency access", resp)
+	}
+
+	access := &models.EmergencyAccess{}
+	if err = json.Unmarshal(resp.Body(), access); err != nil {
+		return nil, err
+	}
+
+	return access, nil
+}
+
+// EmergencyItems returns the items of the vault of the user with the
+// given login accessed with the approved emergency access.
+func (ac *ApiClient) EmergencyItems(login string) ([]*models.Item, error) {
+	resp, err := ac.resty.R().Get(fmt.Sprintf("%v/%v/item", apiTrustedEndpoint, url.PathEscape(login)))
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode() == http.StatusNoContent {
+		return nil, nil
+	}
+	if resp.StatusCode() != http.StatusOK {
+		return nil, responseError("get emergency items", resp)
+	}
+
+	got := &models.Items{}
+	if err = json.Unmarshal(resp.Body(), got); err != nil {
+		return nil, err
+	}
+
+	return got.Data, nil
+}
+
+// emergencyAccess returns the list of the emergency access from the
+// given endpoint.
+func (ac *ApiClient) emergencyAccess(endpoint string) ([]*models.EmergencyAccess, error) {
+	resp, err := ac.resty.R().Get(endpoint)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode() == http.StatusNoContent {
+		return nil, nil
+	}
+	if resp.StatusCode() != http.StatusOK {
+		return nil, responseError("get emergency access", resp)
+	}
+
+	var access []*models.EmergencyAccess
+	if err = json.Unmarshal(resp.Body(), &access); err != nil {
+		return nil, err
+	}
+
+	return access, nil
+}
+
+// Audit returns the events of the audit log of the user matching the
+// filter, the most recent first.
+func (ac *ApiClient) Audit(filter *models.AuditFilter) ([]*models.AuditEvent, error) {
+	req := ac.resty.R()
+	if filter.Action != "" {
+		req.SetQueryParam("action", string(filter.Action))
+	}
+	if filter.Since != nil {
+		req.SetQueryParam("since", filter.Since.UTC().Format(time.RFC3339))
+	}
+	if filter.Limit > 0 {
+		req.SetQueryParam("limit", fmt.Sprintf("%d", filter.Limit))
+	}
+	if filter.Offset > 0 {
+		req.SetQueryParam("offset", fmt.Sprintf("%d", filter.Offset))
+	}
+
+	resp, err := req.Get(apiAuditEndpoint)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode() == http.StatusNoContent {
+		return nil, nil
+	}
+	if resp.StatusCode() != http.StatusOK {
+		return nil, responseError("get audit log", resp)
+	}
+
+	var events []*models.AuditEvent
+	if err = json.Unmarshal(resp.Body(), &events); err != nil {
+		return nil, err
+	}
+
+	return events, nil
+}
+
+// AdminUsers returns all the users with their usage and quotas.
+func (ac *ApiClient) AdminUsers() ([]*models.User, error) {
+	resp, err := ac.resty.R().Get(apiAdminUsersEndpoint)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode() != http.StatusOK {
+		return nil, responseError("list users", resp)
+	}
+
+	var users []*models.User
+	if err = json.Unmarshal(resp.Body(), &users); err != nil {
+		return nil, err
+	}
+
+	return users, nil
+}
+
+// AdminUser returns the user with the given login with the usage and
+// the quota.
+func (ac *ApiClient) AdminUser(login string) (*models.User, error) {
+	resp, err := ac.resty.R().Get(fmt.Sprintf("%v/%v", apiAdminUsersEndpoint, url.PathEscape(login)))
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode() != http.StatusOK {
+		return nil, responseError("get user", resp)
+	}
+
+	u := &models.User{}
+	if err = json.Unmarshal(resp.Body(), u); err != nil {
+		return nil, err
+	}
+
+	return u, nil
+}
+
+// AdminDisable disables the user with the given login.
+func (ac *ApiClient) AdminDisable(login string) error {
+	return ac.adminAction(login, "disable")
+}
+
+// AdminEnable enables the user with the given login.
+func (ac *ApiClient) AdminEnable(login string) error {
+	return ac.adminAction(login, "enable")
+}
+
+// AdminLogout revokes all the sessions of the user with the given login.
+func (ac *ApiClient) AdminLogout(login string) error {
+	return ac.adminAction(login, "logout")
+}
+
+// AdminSetQuota sets the quota of the user with the given login.
+func (ac *ApiClient) AdminSetQuota(login string, quota *models.Quota) error {
+	resp, err := ac.resty.R().SetBody(quota).
+		Put(fmt.Sprintf("%v/%v/quota", apiAdminUsersEndpoint, url.PathEscape(login)))
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode() != http.StatusOK {
+		return responseError("set quota", resp)
+	}
+
+	return nil
+}
+
+// AdminResetQuota deletes the quota set for the user with the given
+// login, so that the default quota applies.
+func (ac *ApiClient) AdminResetQuota(login string) error {
+	resp, err := ac.resty.R().Delete(fmt.Sprintf("%v/%v/quota", apiAdminUsersEndpoint, url.PathEscape(login)))
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode() != http.StatusOK {
+		return responseError("reset quota", resp)
+	}
+
+	return nil
+}
+
+// adminAction applies the action of the admin api to the user with the
+// given login.
+func (ac *ApiClient) adminAction(login, action string) error {
+	resp, err := ac.resty.R().Post(fmt.Sprintf("%v/%v/%v", apiAdminUsersEndpoint, url.PathEscape(login), action))
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode() != http.StatusOK {
+		return responseError(action+" user", resp)
+	}
+
+	return nil
+}
+
+// AdminResetTwoFactor disables the second factor of the user with the
+// given login.
+func (ac *ApiClient) AdminResetTwoFactor(login string) error {
+	resp, err := ac.resty.R().Delete(fmt.Sprintf("%v/%v/2fa", apiAdminUsersEndpoint, url.PathEscape(login)))
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode() != http.StatusOK {
+		return responseError("reset two-factor authentication", resp)
+	}
+
+	return nil
+}
+
+// EnableTwoFactor generates the TOTP secret of the second factor of the
+// user. The second factor is enabled by `ApiClient.ConfirmTwoFactor`.
+func (ac *ApiClient) EnableTwoFactor() (*models.TwoFactor, error) {
+	resp, err := ac.resty.R().Post(apiTwoFactorEndpoint)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode() != http.StatusOK {
+		return nil, responseError("enable two-factor authentication", resp)
+	}
+
+	tf := &models.TwoFactor{}
+	if err = json.Unmarshal(resp.Body(), tf); err != nil {
+		return nil, err
+	}
+
+	return tf, nil
+}
+
+// ConfirmTwoFactor enables the second factor of the user with the code
+// of the generated secret.
+func (ac *ApiClient) ConfirmTwoFactor(code string) error {
+	return ac.twoFactorAction("confirm", code)
+}
+
+// DisableTwoFactor disables the second factor of the user with the code
+// of its secret.
+func (ac *ApiClient) DisableTwoFactor(code string) error {
+	return ac.twoFactorAction("disable", code)
+}
+
+// twoFactorAction applies the action of the second factor api with the
+// given code.
+func (ac *ApiClient) twoFactorAction(action, code string) error {
+	resp, err := ac.resty.R().SetBody(models.TwoFactor{Code: code}).
+		Post(fmt.Sprintf("%v/%v", apiTwoFactorEndpoint, action))
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode() != http.StatusOK {
+		return responseError(action+" two-factor authentication", resp)
+	}
+
+	return nil
+}
+
+// Usage returns the user with the storage usage and the quota.
+func (ac *ApiClient) Usage() (*models.User, error) {
+	resp, err := ac.resty.R().Get(apiUsageEndpoint)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode() != http.StatusOK {
+		return nil, responseError("get usage", resp)
+	}
+
+	u := &models.User{}
+	if err = json.Unmarshal(resp.Body(), u); err != nil {
+		return nil, err
+	}
+
+	return u, nil
+}
@@ -0,0 +1,149 @@
+package api_client
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/go-resty/resty/v2"
+	"github.com/iryzzh/y-gophkeeper/internal/models"
+	jsoniter "github.com/json-iterator/go"
+)
+
+var (
+	// ErrUnauthorized is returned when the credentials or the tokens are
+	// invalid or expired.
+	ErrUnauthorized = errors.New("unauthorized")
+	// ErrOTPRequired is returned when the user with the second factor
+	// logs in without the one-time code.
+	ErrOTPRequired = errors.New("one-time code required")
+	// ErrForbidden is returned when the user is not allowed to make the request.
+	ErrForbidden = errors.New("forbidden")
+	// ErrNotFound is returned when the requested resource is not found.
+	ErrNotFound = errors.New("not found")
+	// ErrConflict is returned when the request conflicts with the state
+	// of the resource on the remote server.
+	ErrConflict = errors.New("conflict")
+	// ErrRateLimited is returned when the remote server rejects the
+	// request because of too many requests.
+	ErrRateLimited = errors.New("rate limited")
+	// ErrValidation is returned when the remote server rejects the
+	// values of the request.
+	ErrValidation = errors.New("validation failed")
+	// ErrQuotaExceeded is returned when the remote server rejects the
+	// item because of the storage quota of the user.
+	ErrQuotaExceeded = errors.New("quota exceeded")
+	// ErrServer is returned when the remote server fails to process the
+	// request.
+	ErrServer = errors.New("server error")
+)
+
+// Error is the error returned by the remote server. It matches one of
+// the errors above with `errors.Is`.
+type Error struct {
+	// Op is the operation which failed, such as "get items".
+	Op string
+	// Status is the http status of the response.
+	Status int
+	// Code, Message, RequestID and Fields are decoded from `models.Error`.
+	Code      models.ErrorCode
+	Message   string
+	RequestID string
+	Fields    map[string]string
+	// RetryAfter is the delay requested by the rate limited response.
+	RetryAfter time.Duration
+}
+
+// Error returns the description of the error.
+func (e *Error) Error() string {
+	msg := e.Message
+	if len(e.Fields) != 0 {
+		fields := make([]string, 0, len(e.Fields))
+		for k, v := range e.Fields {
+			fields = append(fields, k+": "+v)
+		}
+		sort.Strings(fields)
+		msg += " (" + strings.Join(fields, ", ") + ")"
+	}
+
+	return fmt.Sprintf("remote %s failed: %s", e.Op, msg)
+}
+
+// Unwrap returns the kind of the error.
+func (e *Error) Unwrap() error {
+	switch e.Code {
+	case models.CodeUnauthorized:
+		return ErrUnauthorized
+	case models.CodeOTPRequired:
+		return ErrOTPRequired
+	case models.CodeForbidden:
+		return ErrForbidden
+	case models.CodeNotFound:
+		return ErrNotFound
+	case models.CodeConflict:
+		return ErrConflict
+	case models.CodeRateLimited:
+		return ErrRateLimited
+	case models.CodeValidation, models.CodeBadRequest:
+		return ErrValidation
+	case models.CodeQuotaExceeded:
+		return ErrQuotaExceeded
+	case models.CodeInternal:
+		return ErrServer
+	}
+
+	return nil
+}
+
+// responseError returns the `Error` of the failed operation decoded
+// from the response. The response of a server or a proxy responding
+// without `models.Error` is described with its status.
+func responseError(op string, resp *resty.Response) error {
+	var body models.Error
+	json := jsoniter.ConfigCompatibleWithStandardLibrary
+	if err := json.Unmarshal(resp.Body(), &body); err != nil || body.Code == "" {
+		body = models.Error{Code: statusCode(resp.StatusCode()), Message: strings.TrimSpace(resp.String())}
+	}
+
+	e := &Error{
+		Op:        op,
+		Status:    resp.StatusCode(),
+		Code:      body.Code,
+		Message:   body.Message,
+		RequestID: body.RequestID,
+		Fields:    body.Fields,
+	}
+	if e.Message == "" {
+		e.Message = strings.ToLower(http.StatusText(resp.StatusCode()))
+	}
+	if seconds, err := strconv.Atoi(resp.Header().Get("Retry-After")); err == nil {
+		e.RetryAfter = time.Duration(seconds) * time.Second
+	}
+
+	return e
+}
+
+// statusCode returns the code of the error matching the status.
+func statusCode(status int) models.ErrorCode {
+	switch status {
+	case http.StatusUnauthorized:
+		return models.CodeUnauthorized
+	case http.StatusForbidden:
+		return models.CodeForbidden
+	case http.StatusNotFound:
+		return models.CodeNotFound
+	case http.StatusConflict:
+		return models.CodeConflict
+	case http.StatusTooManyRequests:
+		return models.CodeRateLimited
+	}
+	if status >= http.StatusInternalServerError {
+		return models.CodeInternal
+	}
+
+	return models.CodeBadRequest
+}
@@ -0,0 +1,56 @@
+package audit
+
+import (
+	"context"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/iryzzh/y-gophkeeper/internal/logger"
+	"github.com/iryzzh/y-gophkeeper/internal/models"
+	"github.com/iryzzh/y-gophkeeper/internal/store"
+)
+
+// maxLimit is the maximum and the default number of events returned
+// at once.
+const maxLimit = 1000
+
+// Service is the service responsible for the audit log of the
+// security-relevant events.
+type Service struct {
+	store store.Store
+}
+
+// NewService creates a new audit service.
+func NewService(s store.Store) *Service {
+	return &Service{store: s}
+}
+
+// Record appends the event to the audit log.
+func (s *Service) Record(ctx context.Context, event *models.AuditEvent) error {
+	return logger.Failure(ctx, s.store.Audit().Append(ctx, event),
+		"audit event append failed", logrus.Fields{"action": event.Action})
+}
+
+// Events returns the events of the user matching the filter, the most
+// recent first.
+func (s *Service) Events(ctx context.Context, userID string, filter *models.AuditFilter) ([]*models.AuditEvent, error) {
+	if filter.Limit <= 0 || filter.Limit > maxLimit {
+		filter.Limit = maxLimit
+	}
+	if filter.Offset < 0 {
+		filter.Offset = 0
+	}
+
+	events, err := s.store.Audit().FindByUserID(ctx, userID, filter)
+
+	return events, logger.Failure(ctx, err, "audit event list failed", nil)
+}
+
+// Purge removes the events older than the retention period and returns
+// the number of the removed events.
+func (s *Service) Purge(ctx context.Context, retention time.Duration) (int, error) {
+	purged, err := s.store.Audit().Purge(ctx, time.Now().Add(-retention))
+
+	return purged, logger.Failure(ctx, err, "audit log purge failed", nil)
+}
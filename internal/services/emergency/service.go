@@ -0,0 +1,226 @@
+package emergency
+
+import (
+	"context"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+
+	"github.com/iryzzh/y-gophkeeper/internal/logger"
+	"github.com/iryzzh/y-gophkeeper/internal/models"
+	"github.com/iryzzh/y-gophkeeper/internal/store"
+)
+
+var (
+	// ErrInvalidGrant is returned when the vault key is empty, the
+	// waiting period is negative or the contact is the grantor.
+	ErrInvalidGrant = errors.New("invalid emergency access")
+	// ErrContactNotFound is returned when the trusted contact or the grantor is not found.
+	ErrContactNotFound = errors.New("contact not found")
+	// ErrNotFound is returned when the emergency access is not granted.
+	ErrNotFound = errors.New("emergency access not found")
+	// ErrInvalidStatus is returned when the status of the emergency access does not allow the action.
+	ErrInvalidStatus = errors.New("invalid emergency access status")
+	// ErrNotApproved is returned when the contact accesses the vault before the release.
+	ErrNotApproved = errors.New("emergency access is not approved")
+)
+
+// Service is the service responsible for the emergency access of the
+// trusted contacts to the vaults of the users.
+type Service struct {
+	store store.Store
+	wait  time.Duration
+}
+
+// NewService creates a new emergency access service. The waiting
+// period is used for the access granted without one.
+func NewService(s store.Store, wait time.Duration) *Service {
+	return &Service{store: s, wait: wait}
+}
+
+// Grant grants the user with the login of the grantee the emergency
+// access to the vault of the user. The access granted earlier to the
+// same contact is replaced.
+func (s *Service) Grant(ctx context.Context, userID string, access *models.EmergencyAccess) error {
+	if len(access.Key) == 0 {
+		return errors.Wrap(ErrInvalidGrant, "the vault key is empty")
+	}
+	if access.Wait < 0 {
+		return errors.Wrap(ErrInvalidGrant, "the waiting period is negative")
+	}
+	if access.Wait == 0 {
+		access.Wait = s.wait
+	}
+
+	contact, err := s.contact(ctx, access.Grantee)
+	if err != nil {
+		return err
+	}
+	if contact.ID == userID {
+		return errors.Wrap(ErrInvalidGrant, "cannot grant access to yourself")
+	}
+
+	access.GrantorID, access.GranteeID = userID, contact.ID
+
+	return logger.Failure(ctx, s.store.Emergency().Grant(ctx, access),
+		"emergency access grant failed", logrus.Fields{"grantee_id": contact.ID})
+}
+
+// Granted returns the emergency access granted by the user without
+// the vault keys.
+func (s *Service) Granted(ctx context.Context, userID string) ([]*models.EmergencyAccess, error) {
+	access, err := s.store.Emergency().FindByGrantor(ctx, userID)
+	for _, a := range access {
+		a.Key = nil
+	}
+
+	return access, logger.Failure(ctx, err, "emergency access list failed", nil)
+}
+
+// Trusted returns the emergency access granted to the user. The vault
+// keys are returned for the approved access only.
+func (s *Service) Trusted(ctx context.Context, userID string) ([]*models.EmergencyAccess, error) {
+	access, err := s.store.Emergency().FindByGrantee(ctx, userID)
+	for _, a := range access {
+		if a.Status != models.EmergencyApproved {
+			a.Key = nil
+		}
+	}
+
+	return access, logger.Failure(ctx, err, "emergency access list failed", nil)
+}
+
+// Revoke revokes the emergency access granted by the user to the user
+// with the given login.
+func (s *Service) Revoke(ctx context.Context, userID, login string) error {
+	contact, err := s.contact(ctx, login)
+	if err != nil {
+		return err
+	}
+
+	return s.notFound(ctx, s.store.Emergency().Revoke(ctx, userID, contact.ID), "emergency access revoke failed")
+}
+
+// Request requests the emergency access to the vault of the user with
+// the given login. The access is released after the waiting period
+// unless the grantor denies it.
+func (s *Service) Request(ctx context.Context, userID, login string) (*models.EmergencyAccess, error) {
+	grantor, err := s.contact(ctx, login)
+	if err != nil {
+		return nil, err
+	}
+
+	access, err := s.find(ctx, grantor.ID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if access.Status != models.EmergencyIdle {
+		return nil, errors.Wrapf(ErrInvalidStatus, "the access is already %v", access.Status)
+	}
+
+	if err = s.notFound(ctx, s.store.Emergency().Request(ctx, grantor.ID, userID),
+		"emergency access request failed"); err != nil {
+		return nil, err
+	}
+
+	return s.find(ctx, grantor.ID, userID)
+}
+
+// Approve releases the emergency access requested by the user with the
+// given login without waiting for the end of the waiting period.
+func (s *Service) Approve(ctx context.Context, userID, login string) error {
+	contact, err := s.contact(ctx, login)
+	if err != nil {
+		return err
+	}
+
+	access, err := s.find(ctx, userID, contact.ID)
+	if err != nil {
+		return err
+	}
+	if access.Status != models.EmergencyRequested {
+		return errors.Wrapf(ErrInvalidStatus, "the access is %v", access.Status)
+	}
+
+	return s.notFound(ctx, s.store.Emergency().Approve(ctx, userID, contact.ID), "emergency access approve failed")
+}
+
+// Deny denies the emergency access requested by the user with the
+// given login, or locks the vault again if the access was released.
+func (s *Service) Deny(ctx context.Context, userID, login string) error {
+	contact, err := s.contact(ctx, login)
+	if err != nil {
+		return err
+	}
+
+	access, err := s.find(ctx, userID, contact.ID)
+	if err != nil {
+		return err
+	}
+	if access.Status == models.EmergencyIdle {
+		return errors.Wrap(ErrInvalidStatus, "the access is not requested")
+	}
+
+	return s.notFound(ctx, s.store.Emergency().Deny(ctx, userID, contact.ID), "emergency access deny failed")
+}
+
+// Access returns the approved emergency access of the user to the
+// vault of the user with the given login, including the vault key.
+// The access requested longer than the waiting period ago is released
+// first.
+func (s *Service) Access(ctx context.Context, userID, login string) (*models.EmergencyAccess, error) {
+	grantor, err := s.contact(ctx, login)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err = s.Release(ctx); err != nil {
+		return nil, err
+	}
+
+	access, err := s.find(ctx, grantor.ID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if access.Status != models.EmergencyApproved {
+		return nil, ErrNotApproved
+	}
+
+	return access, nil
+}
+
+// Release releases the emergency access requested longer than the
+// waiting period ago and returns the number of the released accesses.
+func (s *Service) Release(ctx context.Context) (int, error) {
+	released, err := s.store.Emergency().Release(ctx, time.Now())
+
+	return released, logger.Failure(ctx, err, "emergency access release failed", nil)
+}
+
+// contact returns the user with the given login.
+func (s *Service) contact(ctx context.Context, login string) (*models.User, error) {
+	u, err := s.store.User().FindByLogin(ctx, login)
+	if errors.Is(err, store.ErrUserNotFound) {
+		return nil, ErrContactNotFound
+	}
+
+	return u, logger.Failure(ctx, err, "contact lookup failed", nil)
+}
+
+// find returns the emergency access granted by the grantor to the grantee.
+func (s *Service) find(ctx context.Context, grantorID, granteeID string) (*models.EmergencyAccess, error) {
+	access, err := s.store.Emergency().Find(ctx, grantorID, granteeID)
+
+	return access, s.notFound(ctx, err, "emergency access lookup failed")
+}
+
+// notFound maps the store error to `ErrNotFound`. The other errors are
+// logged with the message.
+func (s *Service) notFound(ctx context.Context, err error, msg string) error {
+	if errors.Is(err, store.ErrEmergencyAccessNotFound) {
+		return ErrNotFound
+	}
+
+	return logger.Failure(ctx, err, msg, nil)
+}
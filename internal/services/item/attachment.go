@@ -0,0 +1,222 @@
+package item
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+
+	"github.com/iryzzh/y-gophkeeper/internal/logger"
+	"github.com/iryzzh/y-gophkeeper/internal/models"
+	"github.com/iryzzh/y-gophkeeper/internal/store"
+)
+
+// maxAttachmentName is the maximum length of the name of an attachment.
+const maxAttachmentName = 255
+
+var (
+	// ErrAttachmentNotFound is returned when the attachment is not found.
+	ErrAttachmentNotFound = errors.New("attachment not found")
+	// ErrInvalidAttachment is returned when the name of the attachment is invalid.
+	ErrInvalidAttachment = errors.New("invalid attachment name")
+)
+
+// Attach saves the content read from the reader as the attachment of
+// the item with the given id, replacing the attachment with the same
+// name. The content is limited by the quota of the vault of the owner
+// of the item: the reading fails with `ErrQuotaExceeded` as soon as it
+// does not fit.
+func (s *Service) Attach(ctx context.Context, userID, id string, attachment *models.Attachment, r io.Reader) error {
+	attachment.Name = strings.TrimSpace(attachment.Name)
+	if attachment.Name == "" || len(attachment.Name) > maxAttachmentName || strings.ContainsAny(attachment.Name, "/\\") {
+		return errors.Wrapf(ErrInvalidAttachment, "'%v'", attachment.Name)
+	}
+
+	i, userID, err := s.attachmentItem(ctx, userID, id)
+	if err != nil {
+		return err
+	}
+
+	owner, err := s.itemOwner(ctx, userID, i)
+	if err != nil {
+		return err
+	}
+	unlock := s.lockVault(owner)
+	defer unlock()
+
+	if r, err = s.limitAttachment(ctx, userID, i, attachment.Name, r); err != nil {
+		return err
+	}
+
+	return itemError(ctx, s.store.Item().Attach(ctx, userID, i, attachment, r), "attach failed", i)
+}
+
+// MaxAttachmentSize returns the maximum size of an attachment of the
+// item with the given id: the size of an item allowed by the quota of
+// the vault of its owner, 0 if unlimited.
+func (s *Service) MaxAttachmentSize(ctx context.Context, userID, id string) (int64, error) {
+	i, userID, err := s.attachmentItem(ctx, userID, id)
+	if err != nil {
+		return 0, err
+	}
+
+	owner, err := s.itemOwner(ctx, userID, i)
+	if err != nil {
+		return 0, err
+	}
+
+	q, err := s.Quota(ctx, owner)
+	if err != nil {
+		return 0, err
+	}
+
+	return q.MaxItemBytes, nil
+}
+
+// Attachments returns the attachments of the item with the given id.
+func (s *Service) Attachments(ctx context.Context, userID, id string) ([]*models.Attachment, error) {
+	i, userID, err := s.attachmentItem(ctx, userID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	attachments, err := s.store.Item().Attachments(ctx, userID, i)
+
+	return attachments, itemError(ctx, err, "attachment list failed", i)
+}
+
+// OpenAttachment returns the attachment of the item with the given id
+// and its content, which must be closed by the caller.
+func (s *Service) OpenAttachment(ctx context.Context, userID, id, name string,
+) (*models.Attachment, io.ReadCloser, error) {
+	i, userID, err := s.attachmentItem(ctx, userID, id)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	attachment, rc, err := s.store.Item().OpenAttachment(ctx, userID, i, name)
+	if err != nil {
+		return nil, nil, itemError(ctx, err, "attachment open failed", i)
+	}
+
+	return attachment, rc, nil
+}
+
+// Detach deletes the attachment of the item with the given id.
+func (s *Service) Detach(ctx context.Context, userID, id, name string) error {
+	i, userID, err := s.attachmentItem(ctx, userID, id)
+	if err != nil {
+		return err
+	}
+
+	return itemError(ctx, s.store.Item().Detach(ctx, userID, i, name), "detach failed", i)
+}
+
+// attachmentItem parses the item id and returns it with the id of the
+// owner of the items processed in the context.
+func (s *Service) attachmentItem(ctx context.Context, userID, id string) (int, string, error) {
+	var i int
+	if _, err := fmt.Sscan(id, &i); err != nil {
+		return 0, "", ErrIncorrectItemID
+	}
+
+	userID, err := s.vault(ctx, userID, models.RoleMember)
+	if err != nil {
+		return 0, "", err
+	}
+
+	return i, userID, nil
+}
+
+// limitAttachment returns the reader of the attachment of the item
+// failing with `ErrQuotaExceeded` once the content is larger than an
+// item may be, or than the space left in the vault of the owner of the
+// item. The attachment replaced frees its space.
+func (s *Service) limitAttachment(ctx context.Context, userID string, itemID int, name string, r io.Reader,
+) (io.Reader, error) {
+	it, err := s.store.Item().FindByID(ctx, userID, itemID)
+	if err != nil {
+		return nil, itemError(ctx, err, "item lookup failed", itemID)
+	}
+
+	q, err := s.Quota(ctx, it.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	limited := &quotaReader{r: r, left: -1}
+	if q.MaxItemBytes > 0 {
+		limited.left = q.MaxItemBytes
+		limited.err = errors.Wrapf(ErrQuotaExceeded, "the attachment is over the limit of %d bytes", q.MaxItemBytes)
+	}
+	if q.MaxBytes > 0 {
+		usage, err := s.store.User().Usage(ctx, it.UserID)
+		if err != nil {
+			return nil, logger.Failure(ctx, err, "usage lookup failed", logrus.Fields{"owner_id": it.UserID})
+		}
+
+		left := q.MaxBytes - usage.Bytes
+		for _, a := range it.Attachments {
+			if a.Name == name {
+				left += a.Size
+			}
+		}
+		if left < 0 {
+			left = 0
+		}
+		if limited.left < 0 || left < limited.left {
+			limited.left = left
+			limited.err = errors.Wrapf(ErrQuotaExceeded, "%d of %d bytes are used, the attachment does not fit",
+				usage.Bytes, q.MaxBytes)
+		}
+	}
+
+	return limited, nil
+}
+
+// quotaReader reads at most left bytes from the reader, then fails
+// with the error. It never fails if left is negative.
+type quotaReader struct {
+	r    io.Reader
+	left int64
+	err  error
+}
+
+// Read reads from the reader until the limit is exceeded.
+func (q *quotaReader) Read(p []byte) (int, error) {
+	if q.left < 0 {
+		return q.r.Read(p)
+	}
+
+	if int64(len(p)) > q.left+1 {
+		p = p[:q.left+1]
+	}
+	n, err := q.r.Read(p)
+	if int64(n) > q.left {
+		return 0, q.err
+	}
+	q.left -= int64(n)
+
+	return n, err
+}
+
+// itemError returns the error of the service matching the error of the
+// store. The unexpected errors are logged with the message and the id
+// of the item.
+func itemError(ctx context.Context, err error, msg string, itemID int) error {
+	switch {
+	case errors.Is(err, store.ErrItemNotFound):
+		return ErrItemNotFound
+	case errors.Is(err, store.ErrItemReadOnly):
+		return ErrItemReadOnly
+	case errors.Is(err, store.ErrAttachmentNotFound):
+		return ErrAttachmentNotFound
+	case errors.Is(err, ErrQuotaExceeded):
+		return err
+	}
+
+	return logger.Failure(ctx, err, msg, logrus.Fields{"item_id": itemID})
+}
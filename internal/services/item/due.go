@@ -0,0 +1,86 @@
+package item
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/sirupsen/logrus"
+
+	"github.com/iryzzh/y-gophkeeper/internal/logger"
+	"github.com/iryzzh/y-gophkeeper/internal/models"
+)
+
+// day is the unit of the rotation periods.
+const day = 24 * time.Hour
+
+// Due returns the items of the user expiring or due for rotation
+// within the period from now, the overdue ones included, sorted by
+// the due time.
+func (s *Service) Due(ctx context.Context, userID string, within time.Duration) ([]*models.Due, error) {
+	if userID == "" {
+		return nil, ErrItemNotFound
+	}
+
+	userID, err := s.vault(ctx, userID, models.RoleMember)
+	if err != nil {
+		return nil, err
+	}
+
+	items, err := s.store.Item().Scheduled(ctx, userID)
+	if err != nil {
+		return nil, logger.Failure(ctx, err, "scheduled item list failed", logrus.Fields{"owner_id": userID})
+	}
+
+	return due(items, time.Now(), within), nil
+}
+
+// DueAll returns the items of all the users expiring or due for
+// rotation within the period from now, sorted by the due time.
+func (s *Service) DueAll(ctx context.Context, within time.Duration) ([]*models.Due, error) {
+	items, err := s.store.Item().Scheduled(ctx, "")
+	if err != nil {
+		return nil, logger.Failure(ctx, err, "scheduled item list failed", nil)
+	}
+
+	return due(items, time.Now(), within), nil
+}
+
+// due returns the items expiring or due for rotation before the end
+// of the period from now. The rotation is due the rotation period
+// after the last change of the item.
+func due(items []*models.Item, now time.Time, within time.Duration) []*models.Due {
+	until := now.Add(within)
+	result := make([]*models.Due, 0)
+	add := func(it *models.Item, reason string, at time.Time) {
+		if at.After(until) {
+			return
+		}
+		result = append(result, &models.Due{
+			ItemID:   it.ID,
+			UserID:   it.UserID,
+			Meta:     it.Meta,
+			DataType: it.DataType,
+			Reason:   reason,
+			DueAt:    at,
+			Overdue:  !at.After(now),
+		})
+	}
+
+	for _, it := range items {
+		if it.ExpiresAt != nil {
+			add(it, models.DueExpires, *it.ExpiresAt)
+		}
+		changed := it.UpdatedAt
+		if changed == nil {
+			changed = it.CreatedAt
+		}
+		if it.RotateDays > 0 && changed != nil {
+			add(it, models.DueRotate, changed.Add(time.Duration(it.RotateDays)*day))
+		}
+	}
+
+	sort.SliceStable(result, func(i, j int) bool { return result[i].DueAt.Before(result[j].DueAt) })
+
+	return result
+}
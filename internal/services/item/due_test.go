@@ -0,0 +1,47 @@
+package item
+
+import (
+	"testing"
+	"time"
+
+	"github.com/iryzzh/y-gophkeeper/internal/models"
+	"github.com/stretchr/testify/require"
+)
+
+func Test_due(t *testing.T) {
+	now := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	at := func(days int) *time.Time {
+		t := now.Add(time.Duration(days) * day)
+		return &t
+	}
+
+	items := []*models.Item{
+		{ID: 1, Meta: "expired", ExpiresAt: at(-1)},
+		{ID: 2, Meta: "expiring", ExpiresAt: at(5)},
+		{ID: 3, Meta: "far", ExpiresAt: at(60)},
+		{ID: 4, Meta: "rotate", CreatedAt: at(-100), RotateDays: 90},
+		{ID: 5, Meta: "rotated", CreatedAt: at(-100), UpdatedAt: at(-10), RotateDays: 90},
+		{ID: 6, Meta: "both", CreatedAt: at(-40), ExpiresAt: at(3), RotateDays: 30},
+	}
+
+	got := due(items, now, 30*day)
+	want := []struct {
+		meta, reason string
+		overdue      bool
+	}{
+		{meta: "rotate", reason: models.DueRotate, overdue: true},
+		{meta: "both", reason: models.DueRotate, overdue: true},
+		{meta: "expired", reason: models.DueExpires, overdue: true},
+		{meta: "both", reason: models.DueExpires},
+		{meta: "expiring", reason: models.DueExpires},
+	}
+	require.Len(t, got, len(want))
+	for i, w := range want {
+		require.Equal(t, w.meta, got[i].Meta, i)
+		require.Equal(t, w.reason, got[i].Reason, i)
+		require.Equal(t, w.overdue, got[i].Overdue, i)
+	}
+
+	require.Empty(t, due(items[2:3], now, 0))
+	require.NotNil(t, due(nil, now, day))
+}
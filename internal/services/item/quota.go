@@ -0,0 +1,159 @@
+package item
+
+import (
+	"context"
+	"sync"
+
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+
+	"github.com/iryzzh/y-gophkeeper/internal/logger"
+	"github.com/iryzzh/y-gophkeeper/internal/models"
+	"github.com/iryzzh/y-gophkeeper/internal/store"
+)
+
+// Quota returns the quota of the vault of the user: the quota set for
+// the user, or the default one.
+func (s *Service) Quota(ctx context.Context, userID string) (*models.Quota, error) {
+	q, err := s.store.User().Quota(ctx, userID)
+	if errors.Is(err, store.ErrQuotaNotFound) {
+		q = &models.Quota{}
+		if s.quota != nil {
+			*q = *s.quota
+		}
+		return q, nil
+	}
+
+	return q, logger.Failure(ctx, err, "quota lookup failed", logrus.Fields{"owner_id": userID})
+}
+
+// Usage fills the storage usage and the quota of the user.
+func (s *Service) Usage(ctx context.Context, u *models.User) (err error) {
+	if u.Usage, err = s.store.User().Usage(ctx, u.ID); err != nil {
+		return logger.Failure(ctx, err, "usage lookup failed", logrus.Fields{"owner_id": u.ID})
+	}
+	u.Quota, err = s.Quota(ctx, u.ID)
+
+	return err
+}
+
+// checkQuota returns `ErrQuotaExceeded` if the data of the given size
+// does not fit in the quota of the vault, which grows by the given
+// number of items and bytes. The total quotas only limit the growth,
+// so that the user can always free the space.
+func (s *Service) checkQuota(ctx context.Context, owner string, size, items, bytes int64) error {
+	q, err := s.Quota(ctx, owner)
+	if err != nil {
+		return err
+	}
+	if q.MaxItemBytes > 0 && size > q.MaxItemBytes {
+		return errors.Wrapf(ErrQuotaExceeded, "the item is %d bytes, the limit is %d bytes", size, q.MaxItemBytes)
+	}
+	if items <= 0 && bytes <= 0 {
+		return nil
+	}
+
+	usage, err := s.store.User().Usage(ctx, owner)
+	if err != nil {
+		return logger.Failure(ctx, err, "usage lookup failed", logrus.Fields{"owner_id": owner})
+	}
+	if items > 0 && q.MaxItems > 0 && usage.Items+items > q.MaxItems {
+		return errors.Wrapf(ErrQuotaExceeded, "the limit of %d items is reached", q.MaxItems)
+	}
+	if bytes > 0 && q.MaxBytes > 0 && usage.Bytes+bytes > q.MaxBytes {
+		return errors.Wrapf(ErrQuotaExceeded, "%d of %d bytes are used, %d more bytes do not fit",
+			usage.Bytes, q.MaxBytes, bytes)
+	}
+
+	return nil
+}
+
+// checkUpdateQuota checks the quota of the vault of the owner of the
+// updated item against the new data. The quota is not checked if the
+// item is not found, the update fails then anyway.
+func (s *Service) checkUpdateQuota(ctx context.Context, item *models.Item) error {
+	if item.ItemData == nil {
+		return nil
+	}
+
+	old, err := s.store.Item().FindByID(ctx, item.UserID, item.ID)
+	if errors.Is(err, store.ErrItemNotFound) {
+		return nil
+	}
+	if err != nil {
+		return logger.Failure(ctx, err, "item lookup failed", logrus.Fields{"item_id": item.ID})
+	}
+
+	size := itemSize(item)
+
+	return s.checkQuota(ctx, old.UserID, size, 0, size-itemSize(old))
+}
+
+// itemSize returns the size of the data of the item as it is stored.
+func itemSize(item *models.Item) int64 {
+	if item.ItemData == nil {
+		return 0
+	}
+
+	return int64(len(item.ItemData.Data))
+}
+
+// vaultLocks serializes the changes of the usage of the vaults, so
+// that the concurrent requests of a vault cannot all pass the check of
+// its quota and exceed it together. The locks of the vaults no request
+// holds are dropped.
+type vaultLocks struct {
+	mu    sync.Mutex
+	locks map[string]*vaultLock
+}
+
+type vaultLock struct {
+	sync.Mutex
+	waiting int
+}
+
+// lockVault locks the vault of the owner from the check of its quota
+// through the write of the data, until the returned function is called.
+func (s *Service) lockVault(owner string) func() {
+	l := &s.locks
+
+	l.mu.Lock()
+	if l.locks == nil {
+		l.locks = make(map[string]*vaultLock)
+	}
+	vl, ok := l.locks[owner]
+	if !ok {
+		vl = &vaultLock{}
+		l.locks[owner] = vl
+	}
+	vl.waiting++
+	l.mu.Unlock()
+
+	vl.Lock()
+
+	return func() {
+		vl.Unlock()
+
+		l.mu.Lock()
+		if vl.waiting--; vl.waiting == 0 {
+			delete(l.locks, owner)
+		}
+		l.mu.Unlock()
+	}
+}
+
+// itemOwner returns the id of the owner of the item with the given id
+// in the vault of the user, which differs for the items shared with
+// the user. It returns the user id if the item is not found: the
+// request fails then anyway.
+func (s *Service) itemOwner(ctx context.Context, userID string, id int) (string, error) {
+	it, err := s.store.Item().FindByID(ctx, userID, id)
+	if errors.Is(err, store.ErrItemNotFound) {
+		return userID, nil
+	}
+	if err != nil {
+		return "", logger.Failure(ctx, err, "item lookup failed", logrus.Fields{"item_id": id})
+	}
+
+	return it.UserID, nil
+}
@@ -0,0 +1,75 @@
+package item
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/iryzzh/y-gophkeeper/internal/models"
+	"github.com/iryzzh/y-gophkeeper/internal/store"
+	"github.com/iryzzh/y-gophkeeper/internal/store/sqlite"
+	"github.com/stretchr/testify/require"
+)
+
+// slowUsage delays the usage reads, so that the concurrent requests
+// all read the usage before any of them writes.
+type slowUsage struct {
+	store.Store
+}
+
+func (s slowUsage) User() store.UserRepository {
+	return slowUsageRepository{s.Store.User()}
+}
+
+type slowUsageRepository struct {
+	store.UserRepository
+}
+
+func (r slowUsageRepository) Usage(ctx context.Context, userID string) (*models.Usage, error) {
+	time.Sleep(20 * time.Millisecond)
+
+	return r.UserRepository.Usage(ctx, userID)
+}
+
+func TestService_quotaConcurrent(t *testing.T) {
+	st, err := sqlite.NewStore("file:"+filepath.Join(t.TempDir(), "test.db")+"?_busy_timeout=5000",
+		"../../../migrations")
+	require.NoError(t, err)
+	defer func() { _ = st.Close() }()
+	ctx := context.Background()
+
+	u := &models.User{Login: "bob", Password: "test"}
+	require.NoError(t, st.User().Create(ctx, u))
+	// The quota set for the user is enforced without a default quota.
+	require.NoError(t, st.User().SetQuota(ctx, u.ID, &models.Quota{MaxItems: 3}))
+
+	s := NewService(slowUsage{st}, nil)
+
+	const n = 10
+	var wg sync.WaitGroup
+	errs := make(chan error, n)
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			errs <- s.Create(ctx, &models.Item{UserID: u.ID, Meta: fmt.Sprintf("item-%d", i), DataType: "text",
+				ItemData: &models.ItemData{Data: []byte(uuid.NewString())}})
+		}(i)
+	}
+	wg.Wait()
+	close(errs)
+
+	created := 0
+	for err := range errs {
+		if err == nil {
+			created++
+			continue
+		}
+		require.ErrorIs(t, err, ErrQuotaExceeded)
+	}
+	require.Equal(t, 3, created)
+}
@@ -4,35 +4,95 @@ import (
 	"context"
 	"fmt"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
 
+	"github.com/iryzzh/y-gophkeeper/internal/logger"
 	"github.com/iryzzh/y-gophkeeper/internal/models"
 	"github.com/iryzzh/y-gophkeeper/internal/store"
 )
 
+// blobGracePeriod is the period the unreferenced blobs are kept, so
+// that the blobs of the items being saved are not collected.
+const blobGracePeriod = time.Hour
+
 var (
 	// ErrItemNotFound is returned when the item is not found.
 	ErrItemNotFound = errors.New("item not found")
 	// ErrIncorrectItemID is returned when the received item id is incorrect.
 	ErrIncorrectItemID = errors.New("incorrect item id")
+	// ErrInvalidField is returned when the custom field name is empty or duplicated.
+	ErrInvalidField = errors.New("invalid field")
+	// ErrInvalidRotation is returned when the rotation period of the item is negative.
+	ErrInvalidRotation = errors.New("invalid rotation period")
+	// ErrItemExists is returned when an item with the same name already exists.
+	ErrItemExists = errors.New("item already exists")
+	// ErrInvalidMove is returned when the source or the destination of the move is invalid.
+	ErrInvalidMove = errors.New("invalid move")
+	// ErrItemReadOnly is returned when the item is shared read-only with the user.
+	ErrItemReadOnly = errors.New("item is read-only")
+	// ErrInvalidShare is returned when the item cannot be shared with the user.
+	ErrInvalidShare = errors.New("invalid share")
+	// ErrRecipientNotFound is returned when the user to share the item with is not found.
+	ErrRecipientNotFound = errors.New("recipient not found")
+	// ErrForbidden is returned when the role of the user in the organization does not allow the action.
+	ErrForbidden = errors.New("permission denied")
+	// ErrQuotaExceeded is returned when the item does not fit in the quota of the vault.
+	ErrQuotaExceeded = errors.New("quota exceeded")
 )
 
+type collectionKey struct{}
+
 // Service is the service responsible for processing items.
 type Service struct {
 	store store.Store
+	quota *models.Quota
+	locks vaultLocks
+}
+
+// NewService creates a new item service. The quota is the default
+// quota of the vaults, overridden by the quotas set for the users. The
+// vaults are not limited by default if it is nil.
+func NewService(s store.Store, quota *models.Quota) *Service {
+	return &Service{store: s, quota: quota}
 }
 
-// NewService creates a new item service.
-func NewService(s store.Store) *Service {
-	return &Service{store: s}
+// WithCollection returns a copy of the context in which the service
+// processes the items of the collection with the given id instead of
+// the items of the user. Every operation then checks the role of the
+// user in the organization of the collection.
+func WithCollection(ctx context.Context, collectionID string) context.Context {
+	return context.WithValue(ctx, collectionKey{}, collectionID)
 }
 
 // Create creates a new item in the database.
 func (s *Service) Create(ctx context.Context, item *models.Item) error {
-	err := s.store.Item().Create(ctx, item)
+	if err := normalize(item); err != nil {
+		return err
+	}
+
+	owner, err := s.vault(ctx, item.UserID, models.RoleMember)
+	if err != nil {
+		return err
+	}
+	item.UserID = owner
+
+	unlock := s.lockVault(owner)
+	defer unlock()
+
+	if err = s.checkQuota(ctx, owner, itemSize(item), 1, itemSize(item)); err != nil {
+		return err
+	}
+
+	err = s.store.Item().Create(ctx, item)
+	if errors.Is(err, store.ErrItemExists) {
+		return ErrItemExists
+	}
 
-	return err
+	return logger.Failure(ctx, err, "item create failed", logrus.Fields{"owner_id": owner})
 }
 
 // FindByID returns the item with the given id.
@@ -42,22 +102,32 @@ func (s *Service) FindByID(ctx context.Context, userID, id string) (*models.Item
 		return nil, ErrIncorrectItemID
 	}
 
+	userID, err := s.vault(ctx, userID, models.RoleMember)
+	if err != nil {
+		return nil, err
+	}
+
 	item, err := s.store.Item().FindByID(ctx, userID, i)
 	if errors.Is(err, store.ErrItemNotFound) {
 		return nil, ErrItemNotFound
 	}
 
-	return item, err
+	return item, logger.Failure(ctx, err, "item lookup failed", logrus.Fields{"item_id": i})
 }
 
 // FindByMetaName returns the item with the given meta name.
 func (s *Service) FindByMetaName(ctx context.Context, userID, metaName string) (*models.Item, error) {
+	userID, err := s.vault(ctx, userID, models.RoleMember)
+	if err != nil {
+		return nil, err
+	}
+
 	item, err := s.store.Item().FindByMetaName(ctx, userID, metaName)
 	if errors.Is(err, store.ErrItemNotFound) {
 		return nil, ErrItemNotFound
 	}
 
-	return item, err
+	return item, logger.Failure(ctx, err, "item lookup failed", logrus.Fields{"owner_id": userID})
 }
 
 // FindByUserID returns a set of items.
@@ -73,12 +143,17 @@ func (s *Service) FindByUserID(ctx context.Context, userID, limit, offset string
 		intOffset = 0
 	}
 
+	userID, err := s.vault(ctx, userID, models.RoleMember)
+	if err != nil {
+		return nil, err
+	}
+
 	items, err := s.store.Item().FindByUserID(ctx, userID, intLimit, intOffset)
 	if errors.Is(err, store.ErrItemNotFound) {
 		return nil, ErrItemNotFound
 	}
 
-	return items, err
+	return items, logger.Failure(ctx, err, "item list failed", logrus.Fields{"owner_id": userID})
 }
 
 // Update updates the item in the database.
@@ -86,13 +161,376 @@ func (s *Service) Update(ctx context.Context, item *models.Item) error {
 	if item.ItemData == nil {
 		item.ItemData = nil
 	}
-	err := s.store.Item().Update(ctx, item)
-	return err
+	if err := normalize(item); err != nil {
+		return err
+	}
+
+	owner, err := s.vault(ctx, item.UserID, models.RoleMember)
+	if err != nil {
+		return err
+	}
+	item.UserID = owner
+
+	if item.ItemData != nil {
+		if owner, err = s.itemOwner(ctx, owner, item.ID); err != nil {
+			return err
+		}
+		unlock := s.lockVault(owner)
+		defer unlock()
+	}
+
+	if err = s.checkUpdateQuota(ctx, item); err != nil {
+		return err
+	}
+
+	err = s.store.Item().Update(ctx, item)
+	if errors.Is(err, store.ErrItemNotFound) || errors.Is(err, store.ErrItemDataNotFound) {
+		return ErrItemNotFound
+	}
+	if errors.Is(err, store.ErrItemReadOnly) {
+		return ErrItemReadOnly
+	}
+
+	return logger.Failure(ctx, err, "item update failed", logrus.Fields{"item_id": item.ID})
 }
 
-// Delete deletes the item from the database.
+// Delete moves the item to the trash. The items of a collection can
+// be deleted by the admins and the owners of the organization only.
 func (s *Service) Delete(ctx context.Context, item *models.Item) error {
-	err := s.store.Item().Delete(ctx, item)
+	owner, err := s.vault(ctx, item.UserID, models.RoleAdmin)
+	if err != nil {
+		return err
+	}
+	item.UserID = owner
+
+	err = s.store.Item().Delete(ctx, item)
+	if errors.Is(err, store.ErrItemNotFound) {
+		return ErrItemNotFound
+	}
+
+	return logger.Failure(ctx, err, "item delete failed", logrus.Fields{"item_id": item.ID})
+}
+
+// Trash returns a set of the items in the trash.
+func (s *Service) Trash(ctx context.Context, userID, limit, offset string) (*models.Items, error) {
+	intLimit, _ := strconv.Atoi(limit)
+	intOffset, _ := strconv.Atoi(offset)
+
+	if intLimit == 0 {
+		intLimit = 1000
+	}
+
+	userID, err := s.vault(ctx, userID, models.RoleMember)
+	if err != nil {
+		return nil, err
+	}
+
+	items, err := s.store.Item().Trash(ctx, userID, intLimit, intOffset)
+	if errors.Is(err, store.ErrItemNotFound) {
+		return nil, ErrItemNotFound
+	}
+
+	return items, logger.Failure(ctx, err, "trash list failed", logrus.Fields{"owner_id": userID})
+}
+
+// Restore moves the item with the given id out of the trash. The
+// items of a collection can be restored by the admins and the owners
+// of the organization only.
+func (s *Service) Restore(ctx context.Context, userID, id string) (*models.Item, error) {
+	var i int
+	if _, err := fmt.Sscan(id, &i); err != nil {
+		return nil, ErrIncorrectItemID
+	}
+
+	userID, err := s.vault(ctx, userID, models.RoleAdmin)
+	if err != nil {
+		return nil, err
+	}
+
+	item, err := s.store.Item().Restore(ctx, userID, i)
+	if errors.Is(err, store.ErrItemNotFound) {
+		return nil, ErrItemNotFound
+	}
+	if errors.Is(err, store.ErrItemExists) {
+		return nil, ErrItemExists
+	}
+
+	return item, logger.Failure(ctx, err, "item restore failed", logrus.Fields{"item_id": i})
+}
+
+// EmptyTrash permanently deletes the items in the trash of the user
+// and returns their number.
+func (s *Service) EmptyTrash(ctx context.Context, userID string) (int, error) {
+	if userID == "" {
+		return 0, ErrItemNotFound
+	}
+
+	userID, err := s.vault(ctx, userID, models.RoleAdmin)
+	if err != nil {
+		return 0, err
+	}
+
+	purged, err := s.store.Item().Purge(ctx, userID, time.Now())
+
+	return purged, logger.Failure(ctx, err, "trash empty failed", logrus.Fields{"owner_id": userID})
+}
+
+// Tombstones returns the items of the user deleted since the given time.
+func (s *Service) Tombstones(ctx context.Context, userID string, since time.Time) ([]*models.Tombstone, error) {
+	userID, err := s.vault(ctx, userID, models.RoleMember)
+	if err != nil {
+		return nil, err
+	}
+
+	tombstones, err := s.store.Item().Tombstones(ctx, userID, since)
+
+	return tombstones, logger.Failure(ctx, err, "tombstone list failed", logrus.Fields{"owner_id": userID})
+}
+
+// SetRemoteID records the id of the item with the given id on the
+// remote server.
+func (s *Service) SetRemoteID(ctx context.Context, userID string, id, remoteID int) error {
+	userID, err := s.vault(ctx, userID, models.RoleMember)
+	if err != nil {
+		return err
+	}
+
+	err = s.store.Item().SetRemoteID(ctx, userID, id, remoteID)
+	if errors.Is(err, store.ErrItemNotFound) {
+		return ErrItemNotFound
+	}
+
+	return logger.Failure(ctx, err, "remote id update failed", logrus.Fields{"item_id": id})
+}
+
+// FindByRemoteID returns the item with the given id on the remote
+// server.
+func (s *Service) FindByRemoteID(ctx context.Context, userID string, remoteID int) (*models.Item, error) {
+	vault, err := s.vault(ctx, userID, models.RoleMember)
+	if err != nil {
+		return nil, err
+	}
+
+	id, err := s.store.Item().FindByRemoteID(ctx, vault, remoteID)
+	if errors.Is(err, store.ErrItemNotFound) {
+		return nil, ErrItemNotFound
+	}
+	if err != nil {
+		return nil, logger.Failure(ctx, err, "remote id lookup failed", logrus.Fields{"remote_id": remoteID})
+	}
+
+	return s.FindByID(ctx, userID, strconv.Itoa(id))
+}
+
+// PurgeTrash permanently deletes the items of all the users which
+// have been in the trash longer than the retention period, along
+// with their tombstones. It returns the number of the deleted items.
+func (s *Service) PurgeTrash(ctx context.Context, retention time.Duration) (int, error) {
+	until := time.Now().Add(-retention)
+
+	purged, err := s.store.Item().Purge(ctx, "", until)
+	if err != nil {
+		return 0, logger.Failure(ctx, err, "trash purge failed", nil)
+	}
+
+	_, err = s.store.Item().PurgeTombstones(ctx, until)
+
+	return purged, logger.Failure(ctx, err, "tombstone purge failed", nil)
+}
+
+// CollectBlobs deletes the blobs unreferenced for longer than the
+// grace period from the blob storage of the item data. It returns the
+// number of the deleted blobs.
+func (s *Service) CollectBlobs(ctx context.Context) (int, error) {
+	collected, err := s.store.Item().CollectBlobs(ctx, time.Now().Add(-blobGracePeriod))
+
+	return collected, logger.Failure(ctx, err, "blob collection failed", logrus.Fields{"collected": collected})
+}
+
+// Move renames or moves the item or the folder with all its items
+// and sets the number of the moved items.
+func (s *Service) Move(ctx context.Context, userID string, move *models.Move) error {
+	from, to := models.CleanPath(move.From), models.CleanPath(move.To)
+	if from == "" || to == "" || from == to {
+		return ErrInvalidMove
+	}
+
+	userID, err := s.vault(ctx, userID, models.RoleMember)
+	if err != nil {
+		return err
+	}
+
+	moved, err := s.store.Item().Move(ctx, userID, from, to)
+	if errors.Is(err, store.ErrItemNotFound) {
+		return ErrItemNotFound
+	}
+	if errors.Is(err, store.ErrItemExists) {
+		return ErrItemExists
+	}
+	move.Moved = moved
+
+	return logger.Failure(ctx, err, "item move failed", logrus.Fields{"owner_id": userID})
+}
+
+// Share grants the user with the login of the share access to the
+// item with the given id. Only the owner can share the item, and the
+// data of the item must be encrypted with the item key beforehand.
+func (s *Service) Share(ctx context.Context, userID, id string, share *models.Share) error {
+	it, err := s.FindByID(ctx, userID, id)
+	if err != nil {
+		return err
+	}
+	if it.UserID != userID {
+		return ErrItemNotFound
+	}
+	if it.Key == nil {
+		return errors.Wrap(ErrInvalidShare, "the item is not encrypted")
+	}
+	if len(share.Key) == 0 {
+		return errors.Wrap(ErrInvalidShare, "the item key is empty")
+	}
+
+	recipient, err := s.recipient(ctx, userID, share.Login)
+	if err != nil {
+		return err
+	}
+
+	return logger.Failure(ctx, s.store.Item().Share(ctx, it.ID, recipient.ID, share.Key, share.ReadOnly),
+		"item share failed", logrus.Fields{"item_id": it.ID, "recipient_id": recipient.ID})
+}
+
+// Unshare revokes the access of the user with the given login to the
+// item with the given id.
+func (s *Service) Unshare(ctx context.Context, userID, id, login string) error {
+	it, err := s.FindByID(ctx, userID, id)
+	if err != nil {
+		return err
+	}
+	if it.UserID != userID {
+		return ErrItemNotFound
+	}
+
+	recipient, err := s.recipient(ctx, userID, login)
+	if err != nil {
+		return err
+	}
+
+	err = s.store.Item().Unshare(ctx, it.ID, recipient.ID)
+	if errors.Is(err, store.ErrItemNotFound) {
+		return ErrItemNotFound
+	}
+
+	return logger.Failure(ctx, err, "item unshare failed", logrus.Fields{"item_id": it.ID, "recipient_id": recipient.ID})
+}
+
+// Shared returns the items of the other users shared with the user.
+func (s *Service) Shared(ctx context.Context, userID string) (*models.Items, error) {
+	items, err := s.store.Item().Shared(ctx, userID)
+	if errors.Is(err, store.ErrItemNotFound) {
+		return nil, ErrItemNotFound
+	}
+
+	return items, logger.Failure(ctx, err, "shared item list failed", nil)
+}
+
+// recipient returns the user with the given login other than the user.
+func (s *Service) recipient(ctx context.Context, userID, login string) (*models.User, error) {
+	recipient, err := s.store.User().FindByLogin(ctx, login)
+	if errors.Is(err, store.ErrUserNotFound) {
+		return nil, ErrRecipientNotFound
+	}
+	if err != nil {
+		return nil, logger.Failure(ctx, err, "recipient lookup failed", nil)
+	}
+	if recipient.ID == userID {
+		return nil, errors.Wrap(ErrInvalidShare, "cannot share with yourself")
+	}
+
+	return recipient, nil
+}
+
+// Search returns the items of the user matching the filter.
+func (s *Service) Search(ctx context.Context, userID string, filter *models.ItemFilter) (*models.Items, error) {
+	if filter.Limit == 0 {
+		filter.Limit = 1000
+	}
+	filter.Tags = normalizeTags(filter.Tags)
+	filter.Folder = models.CleanPath(filter.Folder)
+
+	userID, err := s.vault(ctx, userID, models.RoleMember)
+	if err != nil {
+		return nil, err
+	}
+
+	items, err := s.store.Item().Search(ctx, userID, filter)
+	if errors.Is(err, store.ErrItemNotFound) {
+		return nil, ErrItemNotFound
+	}
+
+	return items, logger.Failure(ctx, err, "item search failed", logrus.Fields{"owner_id": userID})
+}
+
+// vault returns the id of the owner of the items processed in the
+// context: the collection set with `WithCollection` if the role of the
+// user in its organization includes the wanted role, or the user.
+func (s *Service) vault(ctx context.Context, userID string, want models.Role) (string, error) {
+	collectionID, ok := ctx.Value(collectionKey{}).(string)
+	if !ok {
+		return userID, nil
+	}
+
+	c, err := s.store.Org().FindCollection(ctx, collectionID)
+	if errors.Is(err, store.ErrCollectionNotFound) {
+		return "", ErrForbidden
+	}
+	if err != nil {
+		return "", logger.Failure(ctx, err, "collection lookup failed", logrus.Fields{"collection_id": collectionID})
+	}
+
+	role, err := s.store.Org().Role(ctx, c.OrgID, userID)
+	if errors.Is(err, store.ErrMemberNotFound) || (err == nil && !role.Includes(want)) {
+		return "", ErrForbidden
+	}
+	if err != nil {
+		return "", logger.Failure(ctx, err, "collection role lookup failed", logrus.Fields{"collection_id": collectionID})
+	}
+
+	return collectionID, nil
+}
+
+// normalize normalizes the tags of the item and validates its
+// custom fields and rotation period.
+func normalize(item *models.Item) error {
+	item.Tags = normalizeTags(item.Tags)
+
+	if item.RotateDays < 0 {
+		return errors.Wrapf(ErrInvalidRotation, "%d days", item.RotateDays)
+	}
+
+	names := make(map[string]struct{}, len(item.Fields))
+	for _, f := range item.Fields {
+		f.Name = strings.TrimSpace(f.Name)
+		if _, ok := names[f.Name]; ok || f.Name == "" {
+			return errors.Wrapf(ErrInvalidField, "'%v'", f.Name)
+		}
+		names[f.Name] = struct{}{}
+	}
+
+	return nil
+}
+
+// normalizeTags trims the tags and drops the empty and duplicate ones.
+func normalizeTags(tags []string) []string {
+	var result []string
+	seen := make(map[string]struct{}, len(tags))
+	for _, tag := range tags {
+		tag = strings.TrimSpace(tag)
+		if _, ok := seen[tag]; ok || tag == "" {
+			continue
+		}
+		seen[tag] = struct{}{}
+		result = append(result, tag)
+	}
 
-	return err
+	return result
 }
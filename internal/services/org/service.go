@@ -0,0 +1,318 @@
+package org
+
+import (
+	"context"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+
+	"github.com/iryzzh/y-gophkeeper/internal/logger"
+	"github.com/iryzzh/y-gophkeeper/internal/models"
+	"github.com/iryzzh/y-gophkeeper/internal/store"
+)
+
+var (
+	// ErrInvalidOrg is returned when the organization name is empty.
+	ErrInvalidOrg = errors.New("invalid organization")
+	// ErrOrgExists is returned when an organization with the same name already exists.
+	ErrOrgExists = errors.New("organization already exists")
+	// ErrOrgNotFound is returned when the organization is not found or the user is not its member.
+	ErrOrgNotFound = errors.New("organization not found")
+	// ErrOrgNotEmpty is returned when the deleted organization has collections.
+	ErrOrgNotEmpty = errors.New("organization has collections")
+	// ErrForbidden is returned when the role of the user does not allow the action.
+	ErrForbidden = errors.New("permission denied")
+	// ErrInvalidRole is returned when the role is unknown.
+	ErrInvalidRole = errors.New("invalid role")
+	// ErrLastOwner is returned when the last owner of the organization is removed or demoted.
+	ErrLastOwner = errors.New("organization must have an owner")
+	// ErrUserNotFound is returned when the user to add to the organization is not found.
+	ErrUserNotFound = errors.New("user not found")
+	// ErrMemberNotFound is returned when the user is not a member of the organization.
+	ErrMemberNotFound = errors.New("member not found")
+	// ErrInvalidCollection is returned when the collection name is empty.
+	ErrInvalidCollection = errors.New("invalid collection")
+	// ErrCollectionExists is returned when a collection with the same name already exists.
+	ErrCollectionExists = errors.New("collection already exists")
+	// ErrCollectionNotFound is returned when the collection is not found.
+	ErrCollectionNotFound = errors.New("collection not found")
+	// ErrCollectionNotEmpty is returned when the deleted collection has items.
+	ErrCollectionNotEmpty = errors.New("collection has items")
+)
+
+// Service is the service responsible for the organizations, their
+// members and collections.
+type Service struct {
+	store store.Store
+}
+
+// NewService creates a new organization service.
+func NewService(s store.Store) *Service {
+	return &Service{store: s}
+}
+
+// Create creates the organization with the user as its owner.
+func (s *Service) Create(ctx context.Context, userID string, org *models.Org) error {
+	org.Name = strings.TrimSpace(org.Name)
+	if org.Name == "" {
+		return ErrInvalidOrg
+	}
+	org.ID = uuid.New().String()
+
+	err := s.store.Org().Create(ctx, org, userID)
+	if errors.Is(err, store.ErrOrgExists) {
+		return ErrOrgExists
+	}
+
+	return logger.Failure(ctx, err, "organization create failed", logrus.Fields{"org_id": org.ID})
+}
+
+// List returns the organizations the user is a member of.
+func (s *Service) List(ctx context.Context, userID string) ([]*models.Org, error) {
+	orgs, err := s.store.Org().FindByUserID(ctx, userID)
+
+	return orgs, logger.Failure(ctx, err, "organization list failed", nil)
+}
+
+// Find returns the organization with the given id if the user is its
+// member.
+func (s *Service) Find(ctx context.Context, userID, orgID string) (*models.Org, error) {
+	return s.authorize(ctx, userID, orgID, models.RoleMember)
+}
+
+// Delete deletes the organization. Only the owners can delete the
+// organization, and its collections must be deleted beforehand.
+func (s *Service) Delete(ctx context.Context, userID, orgID string) error {
+	if _, err := s.authorize(ctx, userID, orgID, models.RoleOwner); err != nil {
+		return err
+	}
+
+	err := s.store.Org().Delete(ctx, orgID)
+	if errors.Is(err, store.ErrOrgNotEmpty) {
+		return ErrOrgNotEmpty
+	}
+	if errors.Is(err, store.ErrOrgNotFound) {
+		return ErrOrgNotFound
+	}
+
+	return logger.Failure(ctx, err, "organization delete failed", logrus.Fields{"org_id": orgID})
+}
+
+// Members returns the members of the organization.
+func (s *Service) Members(ctx context.Context, userID, orgID string) ([]*models.Member, error) {
+	if _, err := s.authorize(ctx, userID, orgID, models.RoleMember); err != nil {
+		return nil, err
+	}
+
+	members, err := s.store.Org().Members(ctx, orgID)
+
+	return members, logger.Failure(ctx, err, "member list failed", logrus.Fields{"org_id": orgID})
+}
+
+// SetMember adds the user with the login of the member to the
+// organization or changes the role of the member. The admins manage
+// the members and the admins, only the owners manage the owners.
+func (s *Service) SetMember(ctx context.Context, userID, orgID string, member *models.Member) error {
+	if member.Role == "" {
+		member.Role = models.RoleMember
+	}
+	if !member.Role.Valid() {
+		return errors.Wrapf(ErrInvalidRole, "'%v'", member.Role)
+	}
+
+	org, err := s.authorize(ctx, userID, orgID, models.RoleAdmin)
+	if err != nil {
+		return err
+	}
+
+	u, err := s.store.User().FindByLogin(ctx, member.Login)
+	if errors.Is(err, store.ErrUserNotFound) {
+		return ErrUserNotFound
+	}
+	if err != nil {
+		return logger.Failure(ctx, err, "member lookup failed", logrus.Fields{"org_id": orgID})
+	}
+	member.UserID = u.ID
+
+	current, err := s.store.Org().Role(ctx, orgID, u.ID)
+	if err != nil && !errors.Is(err, store.ErrMemberNotFound) {
+		return logger.Failure(ctx, err, "member role lookup failed", logrus.Fields{"org_id": orgID, "member_id": u.ID})
+	}
+	if (member.Role == models.RoleOwner || current == models.RoleOwner) && org.Role != models.RoleOwner {
+		return ErrForbidden
+	}
+	if current == models.RoleOwner && member.Role != models.RoleOwner {
+		if err = s.keepOwner(ctx, orgID); err != nil {
+			return err
+		}
+	}
+
+	return logger.Failure(ctx, s.store.Org().SetMember(ctx, orgID, member),
+		"member update failed", logrus.Fields{"org_id": orgID, "member_id": u.ID})
+}
+
+// RemoveMember removes the user with the given login from the
+// organization. Every member can leave the organization, the admins
+// remove the members and the admins, only the owners remove the owners.
+func (s *Service) RemoveMember(ctx context.Context, userID, orgID, login string) error {
+	org, err := s.authorize(ctx, userID, orgID, models.RoleMember)
+	if err != nil {
+		return err
+	}
+
+	u, err := s.store.User().FindByLogin(ctx, login)
+	if errors.Is(err, store.ErrUserNotFound) {
+		return ErrMemberNotFound
+	}
+	if err != nil {
+		return logger.Failure(ctx, err, "member lookup failed", logrus.Fields{"org_id": orgID})
+	}
+
+	role, err := s.store.Org().Role(ctx, orgID, u.ID)
+	if errors.Is(err, store.ErrMemberNotFound) {
+		return ErrMemberNotFound
+	}
+	if err != nil {
+		return logger.Failure(ctx, err, "member role lookup failed", logrus.Fields{"org_id": orgID, "member_id": u.ID})
+	}
+
+	if u.ID != userID && (!org.Role.Includes(models.RoleAdmin) || !org.Role.Includes(role)) {
+		return ErrForbidden
+	}
+	if role == models.RoleOwner {
+		if err = s.keepOwner(ctx, orgID); err != nil {
+			return err
+		}
+	}
+
+	err = s.store.Org().RemoveMember(ctx, orgID, u.ID)
+	if errors.Is(err, store.ErrMemberNotFound) {
+		return ErrMemberNotFound
+	}
+
+	return logger.Failure(ctx, err, "member remove failed", logrus.Fields{"org_id": orgID, "member_id": u.ID})
+}
+
+// Collections returns the collections of the organization.
+func (s *Service) Collections(ctx context.Context, userID, orgID string) ([]*models.Collection, error) {
+	if _, err := s.authorize(ctx, userID, orgID, models.RoleMember); err != nil {
+		return nil, err
+	}
+
+	collections, err := s.store.Org().Collections(ctx, orgID)
+
+	return collections, logger.Failure(ctx, err, "collection list failed", logrus.Fields{"org_id": orgID})
+}
+
+// CreateCollection creates the collection in the organization. Only
+// the admins and the owners can create collections.
+func (s *Service) CreateCollection(ctx context.Context, userID, orgID string, collection *models.Collection) error {
+	collection.Name = strings.TrimSpace(collection.Name)
+	if collection.Name == "" {
+		return ErrInvalidCollection
+	}
+
+	if _, err := s.authorize(ctx, userID, orgID, models.RoleAdmin); err != nil {
+		return err
+	}
+
+	collection.ID = uuid.New().String()
+	collection.OrgID = orgID
+
+	err := s.store.Org().CreateCollection(ctx, collection)
+	if errors.Is(err, store.ErrCollectionExists) {
+		return ErrCollectionExists
+	}
+
+	return logger.Failure(ctx, err, "collection create failed",
+		logrus.Fields{"org_id": orgID, "collection_id": collection.ID})
+}
+
+// DeleteCollection deletes the collection of the organization. Only
+// the admins and the owners can delete collections, and the items of
+// the collection must be deleted beforehand.
+func (s *Service) DeleteCollection(ctx context.Context, userID, orgID, collectionID string) error {
+	if _, err := s.authorize(ctx, userID, orgID, models.RoleAdmin); err != nil {
+		return err
+	}
+
+	c, err := s.store.Org().FindCollection(ctx, collectionID)
+	if errors.Is(err, store.ErrCollectionNotFound) || (err == nil && c.OrgID != orgID) {
+		return ErrCollectionNotFound
+	}
+	if err != nil {
+		return logger.Failure(ctx, err, "collection lookup failed", logrus.Fields{"collection_id": collectionID})
+	}
+
+	err = s.store.Org().DeleteCollection(ctx, collectionID)
+	if errors.Is(err, store.ErrCollectionNotEmpty) {
+		return ErrCollectionNotEmpty
+	}
+	if errors.Is(err, store.ErrCollectionNotFound) {
+		return ErrCollectionNotFound
+	}
+
+	return logger.Failure(ctx, err, "collection delete failed", logrus.Fields{"collection_id": collectionID})
+}
+
+// Collection returns the collection with the given id if the user is
+// a member of its organization.
+func (s *Service) Collection(ctx context.Context, userID, collectionID string) (*models.Collection, error) {
+	fields := logrus.Fields{"collection_id": collectionID}
+	c, err := s.store.Org().FindCollection(ctx, collectionID)
+	if errors.Is(err, store.ErrCollectionNotFound) {
+		return nil, ErrCollectionNotFound
+	}
+	if err != nil {
+		return nil, logger.Failure(ctx, err, "collection lookup failed", fields)
+	}
+
+	if _, err = s.store.Org().Role(ctx, c.OrgID, userID); errors.Is(err, store.ErrMemberNotFound) {
+		return nil, ErrCollectionNotFound
+	} else if err != nil {
+		return nil, logger.Failure(ctx, err, "member role lookup failed", fields)
+	}
+
+	return c, nil
+}
+
+// authorize returns the organization with the given id if the role
+// of the user in it includes the wanted role.
+func (s *Service) authorize(ctx context.Context, userID, orgID string, want models.Role) (*models.Org, error) {
+	org, err := s.store.Org().FindByID(ctx, orgID, userID)
+	if errors.Is(err, store.ErrOrgNotFound) {
+		return nil, ErrOrgNotFound
+	}
+	if err != nil {
+		return nil, logger.Failure(ctx, err, "organization lookup failed", logrus.Fields{"org_id": orgID})
+	}
+
+	if !org.Role.Includes(want) {
+		return nil, ErrForbidden
+	}
+
+	return org, nil
+}
+
+// keepOwner returns `ErrLastOwner` unless the organization has more
+// than one owner.
+func (s *Service) keepOwner(ctx context.Context, orgID string) error {
+	members, err := s.store.Org().Members(ctx, orgID)
+	if err != nil {
+		return logger.Failure(ctx, err, "member list failed", logrus.Fields{"org_id": orgID})
+	}
+
+	owners := 0
+	for _, m := range members {
+		if m.Role == models.RoleOwner {
+			owners++
+		}
+	}
+	if owners < 2 {
+		return ErrLastOwner
+	}
+
+	return nil
+}
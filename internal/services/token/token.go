@@ -7,6 +7,7 @@ import (
 	"time"
 
 	"github.com/golang-jwt/jwt/v4"
+	"github.com/google/uuid"
 	"github.com/iryzzh/y-gophkeeper/internal/models"
 	"github.com/iryzzh/y-gophkeeper/internal/store"
 	"golang.org/x/net/context"
@@ -45,12 +46,19 @@ func NewService(s store.Store, atExpiresIn, rtExpiresIn int, accessSecret, refre
 
 type claims struct {
 	jwt.RegisteredClaims
-	Login  string `json:"login"`
-	UserID string `json:"user_id"`
+	Login     string `json:"login"`
+	UserID    string `json:"user_id"`
+	SessionID string `json:"sid,omitempty"`
+	Version   int    `json:"ver,omitempty"`
 }
 
-// Create creates a new token.
+// Create creates a new token starting a new session.
 func (s *Service) Create(_ context.Context, user *models.User) (*models.Token, error) {
+	return s.create(user, uuid.New().String())
+}
+
+// create creates a new token of the session.
+func (s *Service) create(user *models.User, sessionID string) (*models.Token, error) {
 	if user == nil {
 		return nil, ErrInvalidUser
 	}
@@ -62,8 +70,10 @@ func (s *Service) Create(_ context.Context, user *models.User) (*models.Token, e
 			ExpiresAt: jwt.NewNumericDate(now.Add(time.Minute * time.Duration(s.atExpiresIn))),
 			IssuedAt:  jwt.NewNumericDate(now),
 		},
-		Login:  user.Login,
-		UserID: user.ID,
+		Login:     user.Login,
+		UserID:    user.ID,
+		SessionID: sessionID,
+		Version:   user.TokenVersion,
 	}).SignedString(s.accessSecret)
 	if err != nil {
 		return nil, err
@@ -74,8 +84,10 @@ func (s *Service) Create(_ context.Context, user *models.User) (*models.Token, e
 			ExpiresAt: jwt.NewNumericDate(now.Add(time.Minute * time.Duration(s.atExpiresIn))),
 			IssuedAt:  jwt.NewNumericDate(now),
 		},
-		Login:  user.Login,
-		UserID: user.ID,
+		Login:     user.Login,
+		UserID:    user.ID,
+		SessionID: sessionID,
+		Version:   user.TokenVersion,
 	}).SignedString(s.refreshSecret)
 	if err != nil {
 		return nil, err
@@ -86,17 +98,24 @@ func (s *Service) Create(_ context.Context, user *models.User) (*models.Token, e
 		RefreshToken: rt,
 		Login:        user.Login,
 		UserID:       user.ID,
+		SessionID:    sessionID,
 	}, nil
 }
 
-// Validate validates the token.
-func (s *Service) Validate(_ context.Context, tokenStr string) (*models.Token, error) {
+// Validate validates the token. The token of the disabled user or of
+// the revoked session is invalid.
+func (s *Service) Validate(ctx context.Context, tokenStr string) (*models.Token, error) {
 	var err error
 	if token, err := parseWithClaims(tokenStr, s.accessSecret); err == nil {
 		if claims, ok := token.Claims.(*claims); ok && token.Valid {
+			if _, err = s.user(ctx, claims); err != nil {
+				return nil, err
+			}
+
 			return &models.Token{
-				Login:  claims.Login,
-				UserID: claims.UserID,
+				Login:     claims.Login,
+				UserID:    claims.UserID,
+				SessionID: claims.SessionID,
 			}, nil
 		}
 	}
@@ -107,6 +126,9 @@ func (s *Service) Validate(_ context.Context, tokenStr string) (*models.Token, e
 // ParseUserIDFromToken returns the user id from the received token.
 func ParseUserIDFromToken(tokenStr string) (userID string, err error) {
 	token, _ := jwt.Parse(tokenStr, nil)
+	if token == nil {
+		return "", fmt.Errorf("invalid jwt")
+	}
 
 	mapClaims, _ := token.Claims.(jwt.MapClaims)
 
@@ -136,19 +158,41 @@ func parseWithClaims(tokenStr string, secret []byte) (*jwt.Token, error) {
 	return token, err
 }
 
-// Refresh refreshes the token.
+// Refresh refreshes the token keeping its session.
 func (s *Service) Refresh(ctx context.Context, tokenStr string) (*models.Token, error) {
 	var err error
 	if token, err := parseWithClaims(tokenStr, s.refreshSecret); err == nil {
 		if claims, ok := token.Claims.(*claims); ok && token.Valid {
-			user, err := s.store.User().FindByID(ctx, claims.UserID)
+			user, err := s.user(ctx, claims)
 			if err != nil {
 				return nil, err
 			}
 
-			return s.Create(ctx, user)
+			sessionID := claims.SessionID
+			if sessionID == "" {
+				sessionID = uuid.New().String()
+			}
+
+			return s.create(user, sessionID)
 		}
 	}
 
 	return nil, err
 }
+
+// user returns the user of the token claims unless the user is
+// disabled or the session of the token is revoked.
+func (s *Service) user(ctx context.Context, claims *claims) (*models.User, error) {
+	user, err := s.store.User().FindByID(ctx, claims.UserID)
+	if errors.Is(err, store.ErrUserNotFound) {
+		return nil, ErrInvalidToken
+	}
+	if err != nil {
+		return nil, err
+	}
+	if user.DisabledAt != nil || user.TokenVersion != claims.Version {
+		return nil, ErrInvalidToken
+	}
+
+	return user, nil
+}
@@ -201,6 +201,12 @@ func TestService_RefreshToken(t *testing.T) {
 			newToken, rtErr := s.Refresh(context.Background(), token.RefreshToken)
 			require.Equal(t, tt.wantErr, rtErr)
 			require.NotEqual(t, token, newToken)
+			require.NotEmpty(t, newToken.SessionID)
+			require.Equal(t, token.SessionID, newToken.SessionID)
+
+			validated, err := s.Validate(context.Background(), newToken.AccessToken)
+			require.NoError(t, err)
+			require.Equal(t, token.SessionID, validated.SessionID)
 		})
 	}
 }
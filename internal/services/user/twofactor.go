@@ -0,0 +1,124 @@
+package user
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/iryzzh/y-gophkeeper/internal/logger"
+	"github.com/iryzzh/y-gophkeeper/internal/models"
+	"github.com/iryzzh/y-gophkeeper/internal/store"
+	"github.com/iryzzh/y-gophkeeper/internal/totp"
+	"github.com/sirupsen/logrus"
+)
+
+// issuer is the issuer of the TOTP secrets shown by the authenticator
+// apps.
+const issuer = "GophKeeper"
+
+var (
+	// ErrOTPRequired returns when the user with the second factor logs
+	// in without the one-time code.
+	ErrOTPRequired = errors.New("one-time code required")
+	// ErrInvalidOTP returns when the one-time code is invalid or used
+	// already.
+	ErrInvalidOTP = errors.New("invalid one-time code")
+	// ErrTwoFactorEnabled returns when the second factor is enabled
+	// already.
+	ErrTwoFactorEnabled = errors.New("two-factor authentication is already enabled")
+	// ErrTwoFactorDisabled returns when the second factor is neither
+	// enabled nor being enabled.
+	ErrTwoFactorDisabled = errors.New("two-factor authentication is not enabled")
+)
+
+// EnableTwoFactor generates the TOTP secret of the second factor of the
+// user, which is enabled once `ConfirmTwoFactor` receives a code of it.
+// The secret replaces the one being enabled, if any.
+func (s *Service) EnableTwoFactor(ctx context.Context, userID string) (*models.TwoFactor, error) {
+	u, err := s.FindByID(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	if u.TwoFactor {
+		return nil, ErrTwoFactorEnabled
+	}
+
+	secret, err := totp.NewSecret()
+	if err != nil {
+		return nil, err
+	}
+	if err = s.store.User().SetTOTP(ctx, u.ID, "", secret); err != nil {
+		return nil, err
+	}
+
+	return &models.TwoFactor{Secret: secret, URL: totp.URL(issuer, u.Login, secret)}, nil
+}
+
+// ConfirmTwoFactor enables the second factor of the user with the
+// secret being enabled, if the code matches it.
+func (s *Service) ConfirmTwoFactor(ctx context.Context, userID, code string) error {
+	u, err := s.FindByID(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if u.TwoFactor {
+		return ErrTwoFactorEnabled
+	}
+	if u.TOTPPending == "" {
+		return ErrTwoFactorDisabled
+	}
+
+	if err = s.useOTP(ctx, u.ID, u.TOTPPending, code); err != nil {
+		return err
+	}
+
+	return s.store.User().SetTOTP(ctx, u.ID, u.TOTPPending, "")
+}
+
+// DisableTwoFactor disables the second factor of the user, if the code
+// matches its secret.
+func (s *Service) DisableTwoFactor(ctx context.Context, userID, code string) error {
+	u, err := s.FindByID(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if !u.TwoFactor {
+		return ErrTwoFactorDisabled
+	}
+
+	if err = s.useOTP(ctx, u.ID, u.TOTPSecret, code); err != nil {
+		return err
+	}
+
+	return s.store.User().SetTOTP(ctx, u.ID, "", "")
+}
+
+// ResetTwoFactor disables the second factor of the user with the given
+// login without a code, for the user who lost it.
+func (s *Service) ResetTwoFactor(ctx context.Context, login string) error {
+	u, err := s.FindByLogin(ctx, login)
+	if err != nil {
+		return err
+	}
+
+	return s.store.User().SetTOTP(ctx, u.ID, "", "")
+}
+
+// useOTP checks the one-time code against the secret and records its
+// use, so that it cannot be used again.
+func (s *Service) useOTP(ctx context.Context, userID, secret, code string) error {
+	step, err := totp.Validate(secret, code, time.Now())
+	if errors.Is(err, totp.ErrInvalidCode) {
+		return ErrInvalidOTP
+	}
+	if err != nil {
+		return logger.Failure(ctx, err, "one-time code check failed", logrus.Fields{"user_id": userID})
+	}
+
+	err = s.store.User().UseOTP(ctx, userID, step)
+	if errors.Is(err, store.ErrOTPUsed) {
+		return ErrInvalidOTP
+	}
+
+	return logger.Failure(ctx, err, "one-time code use failed", logrus.Fields{"user_id": userID})
+}
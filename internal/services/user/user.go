@@ -6,8 +6,11 @@ import (
 
 	"github.com/alexedwards/argon2id"
 	"github.com/google/uuid"
+	"github.com/iryzzh/y-gophkeeper/internal/keys"
+	"github.com/iryzzh/y-gophkeeper/internal/logger"
 	"github.com/iryzzh/y-gophkeeper/internal/models"
 	"github.com/iryzzh/y-gophkeeper/internal/store"
+	"github.com/sirupsen/logrus"
 )
 
 var (
@@ -21,6 +24,16 @@ var (
 	ErrLoginOrPasswordIsInvalid = errors.New("login or password is invalid")
 	// ErrUserNotFound returns when the user is not found.
 	ErrUserNotFound = errors.New("user not found")
+	// ErrInvalidPublicKey returns when the public key has an invalid size.
+	ErrInvalidPublicKey = errors.New("invalid public key")
+	// ErrPublicKeyExists returns when the user has another public key.
+	ErrPublicKeyExists = errors.New("public key already exists")
+	// ErrPublicKeyNotFound returns when the user has no public key.
+	ErrPublicKeyNotFound = errors.New("public key not found")
+	// ErrUserDisabled returns when the user is disabled.
+	ErrUserDisabled = errors.New("user is disabled")
+	// ErrInvalidQuota returns when the quota has a negative limit.
+	ErrInvalidQuota = errors.New("invalid quota")
 )
 
 // Service is a service for user interaction.
@@ -72,7 +85,7 @@ func (s *Service) Create(ctx context.Context, user *models.User) error {
 	return err
 }
 
-func (s *Service) Login(ctx context.Context, user, password string) (*models.User, error) {
+func (s *Service) Login(ctx context.Context, user, password, otp string) (*models.User, error) {
 	if user == "" {
 		return nil, ErrInvalidUser
 	}
@@ -82,14 +95,34 @@ func (s *Service) Login(ctx context.Context, user, password string) (*models.Use
 	}
 
 	u, err := s.Find(ctx, user)
+	if errors.Is(err, store.ErrUserNotFound) {
+		return nil, ErrLoginOrPasswordIsInvalid
+	}
 	if err != nil {
-		return nil, ErrUserNotFound
+		return nil, logger.Failure(ctx, err, "user lookup failed", nil)
 	}
 
-	if ok, _ := argon2id.ComparePasswordAndHash(password, u.PasswordHash); !ok {
+	ok, err := argon2id.ComparePasswordAndHash(password, u.PasswordHash)
+	if err != nil {
+		return nil, logger.Failure(ctx, err, "password hash comparison failed", logrus.Fields{"user_id": u.ID})
+	}
+	if !ok {
 		return nil, ErrLoginOrPasswordIsInvalid
 	}
 
+	if u.DisabledAt != nil {
+		return nil, ErrUserDisabled
+	}
+
+	if u.TwoFactor {
+		if otp == "" {
+			return nil, ErrOTPRequired
+		}
+		if err = s.useOTP(ctx, u.ID, u.TOTPSecret, otp); err != nil {
+			return nil, err
+		}
+	}
+
 	return u, nil
 }
 
@@ -97,3 +130,121 @@ func (s *Service) Login(ctx context.Context, user, password string) (*models.Use
 func (s *Service) Find(ctx context.Context, user string) (*models.User, error) {
 	return s.store.User().FindByLogin(ctx, user)
 }
+
+// SetPublicKey registers the public key of the user. The key cannot be
+// replaced, since the items shared with the user are sealed with it.
+func (s *Service) SetPublicKey(ctx context.Context, userID string, key []byte) error {
+	if len(key) != keys.KeySize {
+		return ErrInvalidPublicKey
+	}
+
+	err := s.store.User().SetPublicKey(ctx, userID, key)
+	if errors.Is(err, store.ErrPublicKeyExists) {
+		return ErrPublicKeyExists
+	}
+
+	return err
+}
+
+// PublicKey returns the public key of the user with the given login.
+func (s *Service) PublicKey(ctx context.Context, login string) (*models.PublicKey, error) {
+	u, err := s.Find(ctx, login)
+	if errors.Is(err, store.ErrUserNotFound) {
+		return nil, ErrUserNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	key, err := s.store.User().FindPublicKey(ctx, u.ID)
+	if errors.Is(err, store.ErrPublicKeyNotFound) {
+		return nil, ErrPublicKeyNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.PublicKey{Login: u.Login, Key: key}, nil
+}
+
+// List returns all the users.
+func (s *Service) List(ctx context.Context) ([]*models.User, error) {
+	return s.store.User().List(ctx)
+}
+
+// FindByID returns the user with the given id.
+func (s *Service) FindByID(ctx context.Context, userID string) (*models.User, error) {
+	u, err := s.store.User().FindByID(ctx, userID)
+	if errors.Is(err, store.ErrUserNotFound) {
+		return nil, ErrUserNotFound
+	}
+
+	return u, err
+}
+
+// SetDisabled disables or enables the user with the given login.
+func (s *Service) SetDisabled(ctx context.Context, login string, disabled bool) error {
+	u, err := s.FindByLogin(ctx, login)
+	if err != nil {
+		return err
+	}
+
+	return s.store.User().SetDisabled(ctx, u.ID, disabled)
+}
+
+// SetAdmin grants or revokes the admin role of the user with the given
+// login.
+func (s *Service) SetAdmin(ctx context.Context, login string, admin bool) error {
+	u, err := s.FindByLogin(ctx, login)
+	if err != nil {
+		return err
+	}
+
+	return s.store.User().SetAdmin(ctx, u.ID, admin)
+}
+
+// RevokeSessions logs the user with the given login out of all the
+// sessions.
+func (s *Service) RevokeSessions(ctx context.Context, login string) error {
+	u, err := s.FindByLogin(ctx, login)
+	if err != nil {
+		return err
+	}
+
+	return s.store.User().RevokeSessions(ctx, u.ID)
+}
+
+// SetQuota sets the quota of the user with the given login.
+func (s *Service) SetQuota(ctx context.Context, login string, quota *models.Quota) error {
+	if quota.MaxBytes < 0 || quota.MaxItems < 0 || quota.MaxItemBytes < 0 {
+		return ErrInvalidQuota
+	}
+
+	u, err := s.FindByLogin(ctx, login)
+	if err != nil {
+		return err
+	}
+
+	return s.store.User().SetQuota(ctx, u.ID, quota)
+}
+
+// ResetQuota deletes the quota set for the user with the given login,
+// so that the default quota applies.
+func (s *Service) ResetQuota(ctx context.Context, login string) error {
+	u, err := s.FindByLogin(ctx, login)
+	if err != nil {
+		return err
+	}
+
+	return s.store.User().DeleteQuota(ctx, u.ID)
+}
+
+// FindByLogin returns the user with the given login, or `ErrUserNotFound`.
+func (s *Service) FindByLogin(ctx context.Context, login string) (*models.User, error) {
+	u, err := s.Find(ctx, login)
+	if errors.Is(err, store.ErrUserNotFound) {
+		return nil, ErrUserNotFound
+	}
+
+	return u, err
+}
@@ -33,4 +33,41 @@ var (
 	ErrItemDataDeleteFailed = errors.New("item data delete failed")
 	// ErrItemMetaIsRequired returns when the item meta is nil.
 	ErrItemMetaIsRequired = errors.New("meta is required")
+	// ErrItemMoveFailed returns when the item move failed.
+	ErrItemMoveFailed = errors.New("item move failed")
+	// ErrItemReadOnly returns when the item is shared read-only with the user.
+	ErrItemReadOnly = errors.New("item is read-only")
+	// ErrPublicKeyExists returns when the user has another public key.
+	ErrPublicKeyExists = errors.New("public key already exists")
+	// ErrPublicKeyNotFound returns when the user has no public key.
+	ErrPublicKeyNotFound = errors.New("public key not found")
+	// ErrItemRestoreFailed returns when the item restore failed.
+	ErrItemRestoreFailed = errors.New("item restore failed")
+	// ErrItemPurgeFailed returns when the trashed items purge failed.
+	ErrItemPurgeFailed = errors.New("item purge failed")
+	// ErrOrgExists returns when the organization already exists.
+	ErrOrgExists = errors.New("organization already exists")
+	// ErrOrgNotFound returns when the organization is not found.
+	ErrOrgNotFound = errors.New("organization not found")
+	// ErrOrgNotEmpty returns when the deleted organization has collections.
+	ErrOrgNotEmpty = errors.New("organization is not empty")
+	// ErrMemberNotFound returns when the user is not a member of the organization.
+	ErrMemberNotFound = errors.New("member not found")
+	// ErrCollectionExists returns when the collection already exists.
+	ErrCollectionExists = errors.New("collection already exists")
+	// ErrCollectionNotFound returns when the collection is not found.
+	ErrCollectionNotFound = errors.New("collection not found")
+	// ErrCollectionNotEmpty returns when the deleted collection has items.
+	ErrCollectionNotEmpty = errors.New("collection is not empty")
+	// ErrEmergencyAccessNotFound returns when the emergency access is not found.
+	ErrEmergencyAccessNotFound = errors.New("emergency access not found")
+	// ErrAttachmentNotFound returns when the attachment is not found.
+	ErrAttachmentNotFound = errors.New("attachment not found")
+	// ErrQuotaNotFound returns when the user has no quota of its own.
+	ErrQuotaNotFound = errors.New("quota not found")
+	// ErrFTS5NotSupported returns when the sqlite3 driver is built
+	// without FTS5, which the full-text index of the items requires.
+	ErrFTS5NotSupported = errors.New("sqlite3 is built without FTS5, build with the 'sqlite_fts5' tag")
+	// ErrOTPUsed returns when the one-time code was used already.
+	ErrOTPUsed = errors.New("one-time code already used")
 )
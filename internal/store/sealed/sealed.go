@@ -0,0 +1,281 @@
+// Package sealed implements the store of the local vault encrypting
+// the data of the items at rest. It wraps another store: the data is
+// encrypted before it is saved and decrypted after it is read with
+// the key of the vault, so every operation on the items needs the
+// vault to be unlocked.
+package sealed
+
+import (
+	"bytes"
+	"context"
+	"io"
+
+	"github.com/iryzzh/y-gophkeeper/internal/keys"
+	"github.com/iryzzh/y-gophkeeper/internal/models"
+	"github.com/iryzzh/y-gophkeeper/internal/store"
+	"github.com/pkg/errors"
+)
+
+// prefix marks the sealed data. The data saved before the vault was
+// sealed is base64 and never starts with it.
+var prefix = []byte("gks1:")
+
+// KeyFunc returns the key of the vault, or an error if it is locked.
+type KeyFunc func() (*[keys.KeySize]byte, error)
+
+// Store is the store encrypting the data of the items.
+type Store struct {
+	store.Store
+	items *ItemRepository
+}
+
+var _ store.Store = (*Store)(nil)
+
+// NewStore returns the store encrypting the data of the items of the
+// given store with the key returned by the function.
+func NewStore(s store.Store, key KeyFunc) *Store {
+	return &Store{Store: s, items: &ItemRepository{ItemRepository: s.Item(), key: key}}
+}
+
+// Item returns the repository of the items encrypting their data.
+func (s *Store) Item() store.ItemRepository {
+	return s.items
+}
+
+// SealAll seals the data of the items of the user saved before the
+// vault was sealed and returns their number. The items in the trash
+// are left as is.
+func (s *Store) SealAll(ctx context.Context, userID string) (int, error) {
+	const limit = 1000
+
+	var sealed int
+	for offset := 0; ; offset += limit {
+		items, err := s.Store.Item().FindByUserID(ctx, userID, limit, offset)
+		if errors.Is(err, store.ErrItemNotFound) {
+			return sealed, nil
+		}
+		if err != nil {
+			return sealed, err
+		}
+
+		for _, item := range items.Data {
+			if item.ItemData == nil || bytes.HasPrefix(item.ItemData.Data, prefix) {
+				continue
+			}
+			if err = s.items.Update(ctx, item); err != nil {
+				return sealed, err
+			}
+			sealed++
+		}
+		if len(items.Data) < limit {
+			return sealed, nil
+		}
+	}
+}
+
+// ItemRepository is the repository of the items encrypting their data.
+type ItemRepository struct {
+	store.ItemRepository
+	key KeyFunc
+}
+
+// Create seals the data of the item and creates the item.
+func (r *ItemRepository) Create(ctx context.Context, item *models.Item) error {
+	restore, err := r.seal(item)
+	if err != nil {
+		return err
+	}
+	defer restore()
+
+	return r.ItemRepository.Create(ctx, item)
+}
+
+// Update seals the data of the item and updates the item.
+func (r *ItemRepository) Update(ctx context.Context, item *models.Item) error {
+	restore, err := r.seal(item)
+	if err != nil {
+		return err
+	}
+	defer restore()
+
+	return r.ItemRepository.Update(ctx, item)
+}
+
+// FindByID returns the item with the given id with the data opened.
+func (r *ItemRepository) FindByID(ctx context.Context, userID string, id int) (*models.Item, error) {
+	return r.one(r.ItemRepository.FindByID(ctx, userID, id))
+}
+
+// FindByMetaName returns the item with the given name with the data
+// opened.
+func (r *ItemRepository) FindByMetaName(ctx context.Context, userID, metaName string) (*models.Item, error) {
+	return r.one(r.ItemRepository.FindByMetaName(ctx, userID, metaName))
+}
+
+// FindByUserID returns a set of items with the data opened.
+func (r *ItemRepository) FindByUserID(ctx context.Context, userID string, limit, offset int) (*models.Items, error) {
+	return r.all(r.ItemRepository.FindByUserID(ctx, userID, limit, offset))
+}
+
+// Search returns the items matching the filter with the data opened.
+func (r *ItemRepository) Search(ctx context.Context, userID string, filter *models.ItemFilter) (*models.Items, error) {
+	return r.all(r.ItemRepository.Search(ctx, userID, filter))
+}
+
+// Trash returns a set of the items in the trash with the data opened.
+func (r *ItemRepository) Trash(ctx context.Context, userID string, limit, offset int) (*models.Items, error) {
+	return r.all(r.ItemRepository.Trash(ctx, userID, limit, offset))
+}
+
+// Restore moves the item out of the trash and returns it with the
+// data opened.
+func (r *ItemRepository) Restore(ctx context.Context, userID string, id int) (*models.Item, error) {
+	return r.one(r.ItemRepository.Restore(ctx, userID, id))
+}
+
+// Shared returns the items shared with the user with the data opened.
+func (r *ItemRepository) Shared(ctx context.Context, userID string) (*models.Items, error) {
+	return r.all(r.ItemRepository.Shared(ctx, userID))
+}
+
+// Attach seals the content read from the reader and saves it as the
+// attachment of the item.
+func (r *ItemRepository) Attach(ctx context.Context, userID string, itemID int, attachment *models.Attachment,
+	rd io.Reader,
+) error {
+	key, err := r.key()
+	if err != nil {
+		return err
+	}
+
+	data, err := io.ReadAll(rd)
+	if err != nil {
+		return err
+	}
+	sealed, err := keys.Encrypt(data, key)
+	if err != nil {
+		return err
+	}
+
+	if err = r.ItemRepository.Attach(ctx, userID, itemID, attachment,
+		io.MultiReader(bytes.NewReader(prefix), bytes.NewReader(sealed))); err != nil {
+		return err
+	}
+	attachment.Size = int64(len(data))
+
+	return nil
+}
+
+// Attachments returns the attachments of the item with the size of
+// their opened content.
+func (r *ItemRepository) Attachments(ctx context.Context, userID string, itemID int) ([]*models.Attachment, error) {
+	attachments, err := r.ItemRepository.Attachments(ctx, userID, itemID)
+	if err != nil {
+		return nil, err
+	}
+	openedSize(attachments)
+
+	return attachments, nil
+}
+
+// OpenAttachment returns the attachment of the item with its content
+// opened. The size of the attachment is the size of the opened
+// content.
+func (r *ItemRepository) OpenAttachment(ctx context.Context, userID string, itemID int, name string,
+) (*models.Attachment, io.ReadCloser, error) {
+	key, err := r.key()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	attachment, rc, err := r.ItemRepository.OpenAttachment(ctx, userID, itemID, name)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer func() { _ = rc.Close() }()
+
+	data, err := io.ReadAll(rc)
+	if err != nil {
+		return nil, nil, err
+	}
+	if bytes.HasPrefix(data, prefix) {
+		if data, err = keys.Decrypt(data[len(prefix):], key); err != nil {
+			return nil, nil, err
+		}
+	}
+	attachment.Size = int64(len(data))
+
+	return attachment, io.NopCloser(bytes.NewReader(data)), nil
+}
+
+// seal replaces the data of the item with the sealed one and returns
+// the function putting the original data back, so that the caller
+// keeps the item in the clear.
+func (r *ItemRepository) seal(item *models.Item) (func(), error) {
+	key, err := r.key()
+	if err != nil {
+		return nil, err
+	}
+	if item.ItemData == nil {
+		return func() {}, nil
+	}
+
+	data := item.ItemData.Data
+	sealed, err := keys.Encrypt(data, key)
+	if err != nil {
+		return nil, err
+	}
+	item.ItemData.Data = append(append([]byte{}, prefix...), sealed...)
+
+	return func() { item.ItemData.Data = data }, nil
+}
+
+// one opens the data of the item returned with the error.
+func (r *ItemRepository) one(item *models.Item, err error) (*models.Item, error) {
+	if err != nil {
+		return nil, err
+	}
+
+	return item, r.open(item)
+}
+
+// all opens the data of the items returned with the error.
+func (r *ItemRepository) all(items *models.Items, err error) (*models.Items, error) {
+	if err != nil || items == nil {
+		return items, err
+	}
+
+	return items, r.open(items.Data...)
+}
+
+// open opens the sealed data of the items. The data saved before the
+// vault was sealed is left as is.
+func (r *ItemRepository) open(items ...*models.Item) error {
+	key, err := r.key()
+	if err != nil {
+		return err
+	}
+
+	for _, item := range items {
+		openedSize(item.Attachments)
+		if item.ItemData == nil || !bytes.HasPrefix(item.ItemData.Data, prefix) {
+			continue
+		}
+		if item.ItemData.Data, err = keys.Decrypt(item.ItemData.Data[len(prefix):], key); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// openedSize sets the size of the attachments to the size of their
+// opened content. The attachments are always sealed, since they were
+// introduced after the vaults.
+func openedSize(attachments []*models.Attachment) {
+	for _, a := range attachments {
+		if a.Size >= int64(len(prefix)+keys.Overhead) {
+			a.Size -= int64(len(prefix) + keys.Overhead)
+		}
+	}
+}
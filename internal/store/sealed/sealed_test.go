@@ -0,0 +1,133 @@
+package sealed
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"testing"
+
+	"github.com/iryzzh/y-gophkeeper/internal/keys"
+	"github.com/iryzzh/y-gophkeeper/internal/models"
+	"github.com/iryzzh/y-gophkeeper/internal/store"
+	"github.com/iryzzh/y-gophkeeper/internal/store/sqlite"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/require"
+)
+
+var errLocked = errors.New("locked")
+
+func setupStore(t *testing.T) (*Store, store.Store, *bool) {
+	t.Helper()
+
+	st, err := sqlite.NewStore(":memory:", "")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = st.Close() })
+
+	key, err := keys.NewItemKey()
+	require.NoError(t, err)
+
+	locked := new(bool)
+	keyFunc := func() (*[keys.KeySize]byte, error) {
+		if *locked {
+			return nil, errLocked
+		}
+		return key, nil
+	}
+
+	return NewStore(st, keyFunc), st, locked
+}
+
+func TestStore(t *testing.T) {
+	s, raw, locked := setupStore(t)
+	ctx := context.Background()
+
+	it := &models.Item{UserID: "user", Meta: "secret", ItemData: &models.ItemData{Data: []byte("c2VjcmV0")}}
+	require.NoError(t, s.Item().Create(ctx, it))
+	require.Equal(t, []byte("c2VjcmV0"), it.ItemData.Data, "the item of the caller is sealed")
+
+	stored, err := raw.Item().FindByID(ctx, "user", it.ID)
+	require.NoError(t, err)
+	require.True(t, bytes.HasPrefix(stored.ItemData.Data, prefix))
+	require.NotContains(t, string(stored.ItemData.Data), "c2VjcmV0")
+
+	got, err := s.Item().FindByMetaName(ctx, "user", "secret")
+	require.NoError(t, err)
+	require.Equal(t, []byte("c2VjcmV0"), got.ItemData.Data)
+
+	got.ItemData.Data = []byte("b3RoZXI=")
+	require.NoError(t, s.Item().Update(ctx, got))
+
+	items, err := s.Item().FindByUserID(ctx, "user", 10, 0)
+	require.NoError(t, err)
+	require.Len(t, items.Data, 1)
+	require.Equal(t, []byte("b3RoZXI="), items.Data[0].ItemData.Data)
+
+	*locked = true
+	_, err = s.Item().FindByID(ctx, "user", it.ID)
+	require.ErrorIs(t, err, errLocked)
+	err = s.Item().Create(ctx, &models.Item{UserID: "user", Meta: "other", ItemData: &models.ItemData{Data: []byte("x")}})
+	require.ErrorIs(t, err, errLocked)
+}
+
+func TestStore_SealAll(t *testing.T) {
+	s, raw, _ := setupStore(t)
+	ctx := context.Background()
+
+	legacy := &models.Item{UserID: "user", Meta: "legacy", ItemData: &models.ItemData{Data: []byte("bGVnYWN5")}}
+	require.NoError(t, raw.Item().Create(ctx, legacy))
+	require.NoError(t, s.Item().Create(ctx, &models.Item{UserID: "user", Meta: "new",
+		ItemData: &models.ItemData{Data: []byte("bmV3")}}))
+
+	got, err := s.Item().FindByID(ctx, "user", legacy.ID)
+	require.NoError(t, err)
+	require.Equal(t, []byte("bGVnYWN5"), got.ItemData.Data, "the legacy data is read as is")
+
+	n, err := s.SealAll(ctx, "user")
+	require.NoError(t, err)
+	require.Equal(t, 1, n)
+
+	stored, err := raw.Item().FindByID(ctx, "user", legacy.ID)
+	require.NoError(t, err)
+	require.True(t, bytes.HasPrefix(stored.ItemData.Data, prefix))
+
+	got, err = s.Item().FindByID(ctx, "user", legacy.ID)
+	require.NoError(t, err)
+	require.Equal(t, []byte("bGVnYWN5"), got.ItemData.Data)
+}
+
+func TestStore_Attachment(t *testing.T) {
+	s, raw, locked := setupStore(t)
+	ctx := context.Background()
+
+	it := &models.Item{UserID: "user", Meta: "login"}
+	require.NoError(t, s.Item().Create(ctx, it))
+	require.NoError(t, s.Item().Attach(ctx, "user", it.ID, &models.Attachment{Name: "key"},
+		bytes.NewReader([]byte("private key"))))
+
+	_, rc, err := raw.Item().OpenAttachment(ctx, "user", it.ID, "key")
+	require.NoError(t, err)
+	stored, err := io.ReadAll(rc)
+	require.NoError(t, err)
+	require.True(t, bytes.HasPrefix(stored, prefix))
+	require.NotContains(t, string(stored), "private key")
+
+	attachment, rc, err := s.Item().OpenAttachment(ctx, "user", it.ID, "key")
+	require.NoError(t, err)
+	content, err := io.ReadAll(rc)
+	require.NoError(t, err)
+	require.Equal(t, "private key", string(content))
+	require.Equal(t, int64(len(content)), attachment.Size)
+
+	attachments, err := s.Item().Attachments(ctx, "user", it.ID)
+	require.NoError(t, err)
+	require.Len(t, attachments, 1)
+	require.Equal(t, attachment.Size, attachments[0].Size)
+
+	got, err := s.Item().FindByID(ctx, "user", it.ID)
+	require.NoError(t, err)
+	require.Equal(t, attachment.Size, got.Attachments[0].Size)
+
+	*locked = true
+	_, _, err = s.Item().OpenAttachment(ctx, "user", it.ID, "key")
+	require.ErrorIs(t, err, errLocked)
+}
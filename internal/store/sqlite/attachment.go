@@ -0,0 +1,192 @@
+package sqlite
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"io"
+	"strings"
+
+	"github.com/iryzzh/y-gophkeeper/internal/models"
+	"github.com/iryzzh/y-gophkeeper/internal/store"
+	"github.com/pkg/errors"
+)
+
+// Attach saves the content read from the reader as the attachment of
+// the item with the given id, replacing the attachment with the same
+// name. The user must be allowed to modify the item. The id, size and
+// creation time of the attachment are filled in.
+func (r *ItemRepository) Attach(ctx context.Context, userID string, itemID int, attachment *models.Attachment,
+	rd io.Reader,
+) error {
+	hash, size, err := r.saveBlob(ctx, rd)
+	if err != nil {
+		return errors.Wrap(err, store.ErrItemDataCreateFailed.Error())
+	}
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer rollback(ctx, tx)
+
+	if _, err = writableBy(ctx, tx, itemID, userID); err != nil {
+		return err
+	}
+
+	if err = deleteAttachment(ctx, tx, itemID, attachment.Name); err != nil && !errors.Is(err, store.ErrAttachmentNotFound) {
+		return errors.Wrap(err, store.ErrItemUpdateFailed.Error())
+	}
+
+	dataID, err := insertData(ctx, tx, hash, size)
+	if err != nil {
+		return errors.Wrap(err, store.ErrItemDataCreateFailed.Error())
+	}
+
+	if err = tx.QueryRowContext(ctx,
+		`insert into items_attachments (item_id, name, data_id, size) values ($1, $2, $3, $4)
+			returning id, created_at`,
+		itemID, attachment.Name, dataID, size).Scan(&attachment.ID, &attachment.CreatedAt); err != nil {
+		return errors.Wrap(err, store.ErrItemUpdateFailed.Error())
+	}
+	attachment.ItemID, attachment.Size = itemID, size
+
+	return tx.Commit()
+}
+
+// Attachments returns the attachments of the item with the given id
+// owned by the user or shared with the user, sorted by name.
+func (r *ItemRepository) Attachments(ctx context.Context, userID string, itemID int) ([]*models.Attachment, error) {
+	if err := r.readable(ctx, userID, itemID); err != nil {
+		return nil, err
+	}
+
+	items := []*models.Item{{ID: itemID}}
+	if err := r.loadAttachments(ctx, items...); err != nil {
+		return nil, err
+	}
+	if items[0].Attachments == nil {
+		return []*models.Attachment{}, nil
+	}
+
+	return items[0].Attachments, nil
+}
+
+// OpenAttachment returns the attachment of the item with the given id
+// owned by the user or shared with the user, and its content streamed
+// from the blob storage.
+func (r *ItemRepository) OpenAttachment(ctx context.Context, userID string, itemID int, name string,
+) (*models.Attachment, io.ReadCloser, error) {
+	if err := r.readable(ctx, userID, itemID); err != nil {
+		return nil, nil, err
+	}
+
+	attachment := &models.Attachment{}
+	var data []byte
+	var hash sql.NullString
+	err := r.db.QueryRowContext(ctx,
+		`select a.id, a.item_id, a.name, a.size, a.created_at, d.data, d.blob
+			from items_attachments a
+			join items_data d on d.id = a.data_id
+			where a.item_id = $1 and a.name = $2`,
+		itemID, name).Scan(&attachment.ID, &attachment.ItemID, &attachment.Name, &attachment.Size,
+		&attachment.CreatedAt, &data, &hash)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil, store.ErrAttachmentNotFound
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+	if hash.Valid {
+		rc, err := r.openBlob(ctx, hash.String)
+		if err != nil {
+			return nil, nil, err
+		}
+		return attachment, rc, nil
+	}
+
+	return attachment, io.NopCloser(bytes.NewReader(data)), nil
+}
+
+// Detach deletes the attachment of the item with the given id. The
+// user must be allowed to modify the item.
+func (r *ItemRepository) Detach(ctx context.Context, userID string, itemID int, name string) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer rollback(ctx, tx)
+
+	if _, err = writableBy(ctx, tx, itemID, userID); err != nil {
+		return err
+	}
+
+	if err = deleteAttachment(ctx, tx, itemID, name); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+// deleteAttachment deletes the attachment of the item with its data.
+func deleteAttachment(ctx context.Context, tx *sql.Tx, itemID int, name string) error {
+	var dataID int
+	err := tx.QueryRowContext(ctx,
+		`delete from items_attachments where item_id = $1 and name = $2 returning data_id`,
+		itemID, name).Scan(&dataID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return store.ErrAttachmentNotFound
+	}
+	if err != nil {
+		return err
+	}
+
+	return deleteData(ctx, tx, `$1`, dataID)
+}
+
+// readable returns `store.ErrItemNotFound` unless the item with the
+// given id is owned by the user or shared with the user.
+func (r *ItemRepository) readable(ctx context.Context, userID string, itemID int) error {
+	var found int
+	err := r.db.QueryRowContext(ctx,
+		`select 1 from items
+			where (items.user_id = $1 or exists (select 1 from items_keys where item_id = items.id and user_id = $1))
+				and items.id = $2 and items.deleted_at is null`,
+		userID, itemID).Scan(&found)
+	if errors.Is(err, sql.ErrNoRows) {
+		return store.ErrItemNotFound
+	}
+
+	return err
+}
+
+// loadAttachments fills in the attachments of the items.
+func (r *ItemRepository) loadAttachments(ctx context.Context, items ...*models.Item) error {
+	if len(items) == 0 {
+		return nil
+	}
+
+	byID := make(map[int]*models.Item, len(items))
+	args := make([]interface{}, 0, len(items))
+	for _, it := range items {
+		byID[it.ID] = it
+		args = append(args, it.ID)
+	}
+
+	rows, err := r.db.QueryContext(ctx, //nolint:gosec // only placeholders are added.
+		`select id, item_id, name, size, created_at from items_attachments
+			where item_id in (?`+strings.Repeat(`, ?`, len(items)-1)+`) order by name`, args...)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = rows.Close() }()
+	for rows.Next() {
+		a := &models.Attachment{}
+		if err = rows.Scan(&a.ID, &a.ItemID, &a.Name, &a.Size, &a.CreatedAt); err != nil {
+			return err
+		}
+		byID[a.ItemID].Attachments = append(byID[a.ItemID].Attachments, a)
+	}
+
+	return rows.Err()
+}
@@ -0,0 +1,83 @@
+package sqlite
+
+import (
+	"context"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/iryzzh/y-gophkeeper/internal/models"
+	"github.com/iryzzh/y-gophkeeper/internal/store"
+	"github.com/stretchr/testify/require"
+)
+
+func TestItemRepository_Attachments(t *testing.T) {
+	db := setupStore(t)
+	defer func() { _ = db.Close() }()
+	r := itemRepository(db)
+	u := &UserRepository{db: db}
+	ctx := context.Background()
+
+	it := sampleItem(t, uuid.NewString())
+	require.NoError(t, r.Create(ctx, it))
+
+	attach := func(name, content string) *models.Attachment {
+		t.Helper()
+		a := &models.Attachment{Name: name}
+		require.NoError(t, r.Attach(ctx, it.UserID, it.ID, a, strings.NewReader(content)))
+		return a
+	}
+	read := func(name string) string {
+		t.Helper()
+		_, rc, err := r.OpenAttachment(ctx, it.UserID, it.ID, name)
+		require.NoError(t, err)
+		defer func() { _ = rc.Close() }()
+		content, err := io.ReadAll(rc)
+		require.NoError(t, err)
+		return string(content)
+	}
+
+	a := attach("id_rsa", "private key")
+	require.NotZero(t, a.ID)
+	require.Equal(t, int64(11), a.Size)
+	attach("notes.txt", "notes")
+	attach("id_rsa", "new key")
+	require.Equal(t, "new key", read("id_rsa"))
+	require.Equal(t, "notes", read("notes.txt"))
+
+	attachments, err := r.Attachments(ctx, it.UserID, it.ID)
+	require.NoError(t, err)
+	require.Len(t, attachments, 2)
+	require.Equal(t, "id_rsa", attachments[0].Name)
+	require.Equal(t, int64(7), attachments[0].Size)
+
+	found, err := r.FindByID(ctx, it.UserID, it.ID)
+	require.NoError(t, err)
+	require.Equal(t, attachments, found.Attachments)
+
+	usage, err := u.Usage(ctx, it.UserID)
+	require.NoError(t, err)
+	require.Equal(t, int64(len(it.ItemData.Data)+7+5), usage.Bytes)
+
+	_, err = r.Attachments(ctx, uuid.NewString(), it.ID)
+	require.ErrorIs(t, err, store.ErrItemNotFound)
+	err = r.Attach(ctx, uuid.NewString(), it.ID, &models.Attachment{Name: "x"}, strings.NewReader("x"))
+	require.ErrorIs(t, err, store.ErrItemNotFound)
+
+	require.NoError(t, r.Detach(ctx, it.UserID, it.ID, "notes.txt"))
+	require.ErrorIs(t, r.Detach(ctx, it.UserID, it.ID, "notes.txt"), store.ErrAttachmentNotFound)
+	_, _, err = r.OpenAttachment(ctx, it.UserID, it.ID, "notes.txt")
+	require.ErrorIs(t, err, store.ErrAttachmentNotFound)
+
+	require.NoError(t, r.Delete(ctx, it))
+	_, err = r.Purge(ctx, it.UserID, time.Now())
+	require.NoError(t, err)
+
+	var count int
+	require.NoError(t, db.QueryRow(`select count(*) from items_attachments`).Scan(&count))
+	require.Zero(t, count)
+	require.NoError(t, db.QueryRow(`select count(*) from items_data`).Scan(&count))
+	require.Zero(t, count)
+}
@@ -0,0 +1,91 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+	"time"
+
+	"github.com/iryzzh/y-gophkeeper/internal/models"
+)
+
+type AuditRepository struct {
+	db *sql.DB
+}
+
+// Append records the event in the audit log.
+func (r *AuditRepository) Append(ctx context.Context, event *models.AuditEvent) error {
+	res, err := r.db.ExecContext(ctx,
+		`insert into audit (user_id, session_id, ip, action, item_id, target) values ($1, $2, $3, $4, $5, $6)`,
+		event.UserID, event.SessionID, event.IP, event.Action, event.ItemID, event.Target)
+	if err != nil {
+		return err
+	}
+
+	event.ID, err = res.LastInsertId()
+
+	return err
+}
+
+// FindByUserID returns the events of the user matching the filter, the
+// most recent first.
+func (r *AuditRepository) FindByUserID(ctx context.Context, userID string,
+	filter *models.AuditFilter) ([]*models.AuditEvent, error) {
+	where := []string{`user_id = ?`}
+	args := []interface{}{userID}
+
+	if filter.Action != "" {
+		where = append(where, `action = ?`)
+		args = append(args, filter.Action)
+	}
+
+	if filter.Since != nil {
+		where = append(where, `created_at >= ?`)
+		args = append(args, filter.Since.UTC().Format(dateLayout))
+	}
+
+	limit := filter.Limit
+	if limit <= 0 {
+		limit = -1
+	}
+	args = append(args, limit, filter.Offset)
+
+	//nolint:gosec // the conditions contain only placeholders.
+	rows, err := r.db.QueryContext(ctx,
+		`select audit_id, user_id, session_id, ip, action, item_id, target, created_at
+			from audit
+			where `+strings.Join(where, ` and `)+`
+			order by audit_id desc
+			limit ? offset ?`,
+		args...)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	events := make([]*models.AuditEvent, 0)
+	for rows.Next() {
+		e := &models.AuditEvent{}
+		if err = rows.Scan(&e.ID, &e.UserID, &e.SessionID, &e.IP, &e.Action, &e.ItemID, &e.Target,
+			&e.CreatedAt); err != nil {
+			return nil, err
+		}
+		events = append(events, e)
+	}
+
+	return events, rows.Err()
+}
+
+// Purge removes the events recorded before the given time and returns
+// the number of the removed events.
+func (r *AuditRepository) Purge(ctx context.Context, before time.Time) (int, error) {
+	res, err := r.db.ExecContext(ctx,
+		`delete from audit where created_at < $1`,
+		before.UTC().Format(dateLayout))
+	if err != nil {
+		return 0, err
+	}
+	n, _ := res.RowsAffected()
+
+	return int(n), nil
+}
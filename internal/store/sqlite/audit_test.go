@@ -0,0 +1,62 @@
+package sqlite
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/iryzzh/y-gophkeeper/internal/models"
+	"github.com/stretchr/testify/require"
+)
+
+func TestAuditRepository(t *testing.T) {
+	db := setupStore(t)
+	defer func() { _ = db.Close() }()
+	r := &AuditRepository{db: db}
+	ctx := context.Background()
+
+	events := []*models.AuditEvent{
+		{UserID: "user", SessionID: "session", IP: "127.0.0.1", Action: models.AuditLogin},
+		{UserID: "user", SessionID: "session", IP: "127.0.0.1", Action: models.AuditItemCreate, ItemID: 1},
+		{UserID: "user", SessionID: "session", IP: "127.0.0.1", Action: models.AuditItemRead, ItemID: 1},
+		{UserID: "other", Action: models.AuditLoginFailed, Target: "other"},
+	}
+	for _, e := range events {
+		require.NoError(t, r.Append(ctx, e))
+		require.NotZero(t, e.ID)
+	}
+
+	found, err := r.FindByUserID(ctx, "user", &models.AuditFilter{})
+	require.NoError(t, err)
+	require.Len(t, found, 3)
+	require.Equal(t, models.AuditItemRead, found[0].Action)
+	require.Equal(t, 1, found[0].ItemID)
+	require.Equal(t, "session", found[0].SessionID)
+	require.Equal(t, "127.0.0.1", found[0].IP)
+	require.NotNil(t, found[0].CreatedAt)
+
+	found, err = r.FindByUserID(ctx, "user", &models.AuditFilter{Action: models.AuditLogin})
+	require.NoError(t, err)
+	require.Len(t, found, 1)
+
+	found, err = r.FindByUserID(ctx, "user", &models.AuditFilter{Limit: 1, Offset: 1})
+	require.NoError(t, err)
+	require.Len(t, found, 1)
+	require.Equal(t, models.AuditItemCreate, found[0].Action)
+
+	future := time.Now().Add(time.Hour)
+	found, err = r.FindByUserID(ctx, "user", &models.AuditFilter{Since: &future})
+	require.NoError(t, err)
+	require.Empty(t, found)
+
+	// the events cannot be changed
+	_, err = db.ExecContext(ctx, `update audit set action = 'login' where user_id = 'other'`)
+	require.Error(t, err)
+
+	n, err := r.Purge(ctx, time.Now().Add(-time.Hour))
+	require.NoError(t, err)
+	require.Zero(t, n)
+	n, err = r.Purge(ctx, future)
+	require.NoError(t, err)
+	require.Equal(t, 4, n)
+}
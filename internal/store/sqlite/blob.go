@@ -0,0 +1,256 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/iryzzh/y-gophkeeper/internal/blob"
+	"github.com/iryzzh/y-gophkeeper/internal/logger"
+	"github.com/iryzzh/y-gophkeeper/internal/models"
+	"github.com/iryzzh/y-gophkeeper/internal/store"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+)
+
+// SetBlobStorage makes the store save the item data to the blob storage
+// instead of the storage in the database. The data saved before to the
+// storage in the database is not moved.
+func (s *Store) SetBlobStorage(blobs blob.Storage) {
+	s.blobs = blobs
+}
+
+// CollectBlobs deletes the blobs no longer referenced by any item data
+// since before the given time from the blob storage. The blobs saved
+// after that time are kept even if unreferenced, since the item data
+// referencing them may be being saved. It returns the number of the
+// deleted blobs.
+func (r *ItemRepository) CollectBlobs(ctx context.Context, before time.Time) (int, error) {
+	b := before.UTC().Format(dateLayout)
+	rows, err := r.db.QueryContext(ctx,
+		`select hash from items_blobs where refs <= 0 and updated_at <= $1`, b)
+	if err != nil {
+		return 0, err
+	}
+	var hashes []string
+	for rows.Next() {
+		var hash string
+		if err = rows.Scan(&hash); err != nil {
+			_ = rows.Close()
+			return 0, err
+		}
+		hashes = append(hashes, hash)
+	}
+	_ = rows.Close()
+	if err = rows.Err(); err != nil {
+		return 0, err
+	}
+
+	var collected int
+	for _, hash := range hashes {
+		ok, err := r.collectBlob(ctx, hash, b)
+		if err != nil {
+			return collected, err
+		}
+		if ok {
+			collected++
+		}
+	}
+
+	return collected, nil
+}
+
+// collectBlob deletes the unreferenced blob. The blob is deleted from
+// the storage while the transaction holds the database, so that it
+// cannot be referenced again in the meantime: the storage in the
+// database runs in the transaction.
+func (r *ItemRepository) collectBlob(ctx context.Context, hash, before string) (bool, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return false, err
+	}
+	defer rollback(ctx, tx)
+
+	res, err := tx.ExecContext(ctx,
+		`delete from items_blobs where hash = $1 and refs <= 0 and updated_at <= $2`, hash, before)
+	if err != nil {
+		return false, err
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return false, nil
+	}
+
+	if err = r.blobs.Delete(withTx(ctx, tx), hash); err != nil {
+		return false, logger.Failure(ctx, err, "blob delete failed", logrus.Fields{"blob": hash})
+	}
+
+	return true, tx.Commit()
+}
+
+// saveBlob streams the content to the blob storage and returns its hash
+// to be referenced by the item data, and its size. It must be called
+// before the transaction saving the item data: the blob is recorded
+// unreferenced, so that it is collected if the transaction fails. The
+// blob must still exist once recorded, since an unreferenced blob of
+// the same content may have been collected in the meantime.
+func (r *ItemRepository) saveBlob(ctx context.Context, rd io.Reader) (string, int64, error) {
+	hash, size, err := r.blobs.Put(ctx, rd)
+	if err != nil {
+		return "", 0, err
+	}
+	if _, err = r.db.ExecContext(ctx,
+		`insert into items_blobs (hash, size) values ($1, $2)
+			on conflict (hash) do update set updated_at = current_timestamp`,
+		hash, size); err != nil {
+		return "", 0, err
+	}
+
+	rc, err := r.blobs.Get(ctx, hash)
+	if err != nil {
+		return "", 0, logger.Failure(ctx, err, "blob check failed", logrus.Fields{"blob": hash})
+	}
+	_ = rc.Close()
+
+	return hash, size, nil
+}
+
+// insertData inserts the item data referencing the blob with the hash
+// and the size and returns its id.
+func insertData(ctx context.Context, tx *sql.Tx, hash string, size int64) (int, error) {
+	var id int
+	if err := tx.QueryRowContext(ctx,
+		`insert into items_data (data, blob, size) values (x'', $1, $2) returning id`,
+		hash, size).Scan(&id); err != nil {
+		return 0, err
+	}
+
+	return id, refBlob(ctx, tx, hash)
+}
+
+// updateData replaces the item data with the given id of the item with
+// the given id owned by the user: it must be the current data of the
+// item. The blob referenced before, if any, is released.
+func updateData(ctx context.Context, tx *sql.Tx, owner string, itemID, id int, hash string, size int64) error {
+	if _, err := tx.ExecContext(ctx,
+		`update items_blobs set refs = refs - 1, updated_at = current_timestamp
+			where hash = (select blob from items_data
+				where id = (select data_id from items where id = $1 and user_id = $2 and data_id = $3))`,
+		itemID, owner, id); err != nil {
+		return err
+	}
+
+	res, err := tx.ExecContext(ctx,
+		`update items_data set data = x'', blob = $1, size = $2
+			where id = (select data_id from items where id = $3 and user_id = $4 and data_id = $5)`,
+		hash, size, itemID, owner, id)
+	if err != nil {
+		return err
+	}
+	if rowsAffected, _ := res.RowsAffected(); rowsAffected == 0 {
+		return store.ErrItemDataNotFound
+	}
+
+	return refBlob(ctx, tx, hash)
+}
+
+// deleteData deletes the item data with the ids selected by the query
+// with the arguments and releases the blobs they reference.
+func deleteData(ctx context.Context, tx *sql.Tx, ids string, args ...interface{}) error {
+	//nolint:gosec // the ids are selected by a query of the package.
+	if _, err := tx.ExecContext(ctx,
+		`update items_blobs set updated_at = current_timestamp,
+				refs = refs - (select count(*) from items_data where blob = items_blobs.hash and id in (`+ids+`))
+			where hash in (select blob from items_data where id in (`+ids+`))`,
+		args...); err != nil {
+		return err
+	}
+
+	//nolint:gosec // the ids are selected by a query of the package.
+	_, err := tx.ExecContext(ctx, `delete from items_data where id in (`+ids+`)`, args...)
+
+	return err
+}
+
+// refBlob adds a reference to the blob with the hash.
+func refBlob(ctx context.Context, tx *sql.Tx, hash string) error {
+	res, err := tx.ExecContext(ctx,
+		`update items_blobs set refs = refs + 1, updated_at = current_timestamp where hash = $1`, hash)
+	if err != nil {
+		return err
+	}
+	if rowsAffected, _ := res.RowsAffected(); rowsAffected == 0 {
+		return errors.Wrapf(blob.ErrNotFound, "'%v'", hash)
+	}
+
+	return nil
+}
+
+// openBlob returns the content of the blob with the hash from the blob
+// storage, which must be closed by the caller.
+func (r *ItemRepository) openBlob(ctx context.Context, hash string) (io.ReadCloser, error) {
+	rc, err := r.blobs.Get(ctx, hash)
+
+	return rc, logger.Failure(ctx, err, "blob open failed", logrus.Fields{"blob": hash})
+}
+
+// readBlob returns the data of the blob with the hash from the blob
+// storage. Only the item data is read as a whole, the attachments are
+// streamed with `openBlob`.
+func (r *ItemRepository) readBlob(ctx context.Context, hash string) ([]byte, error) {
+	rc, err := r.openBlob(ctx, hash)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rc.Close() }()
+
+	data, err := io.ReadAll(rc)
+
+	return data, logger.Failure(ctx, err, "blob read failed", logrus.Fields{"blob": hash})
+}
+
+// loadBlobs fills in the data of the items saved to the blob storage.
+func (r *ItemRepository) loadBlobs(ctx context.Context, items ...*models.Item) error {
+	byDataID := make(map[int]*models.Item, len(items))
+	args := make([]interface{}, 0, len(items))
+	for _, it := range items {
+		if it.ItemData == nil || it.ItemData.ID == 0 || len(it.ItemData.Data) > 0 {
+			continue
+		}
+		byDataID[it.ItemData.ID] = it
+		args = append(args, it.ItemData.ID)
+	}
+	if len(args) == 0 {
+		return nil
+	}
+
+	rows, err := r.db.QueryContext(ctx, //nolint:gosec // only placeholders are added.
+		`select id, blob from items_data
+			where blob is not null and id in (?`+strings.Repeat(`, ?`, len(args)-1)+`)`, args...)
+	if err != nil {
+		return err
+	}
+	blobs := make(map[int]string)
+	for rows.Next() {
+		var id int
+		var hash string
+		if err = rows.Scan(&id, &hash); err != nil {
+			_ = rows.Close()
+			return err
+		}
+		blobs[id] = hash
+	}
+	_ = rows.Close()
+	if err = rows.Err(); err != nil {
+		return err
+	}
+
+	for id, hash := range blobs {
+		if byDataID[id].ItemData.Data, err = r.readBlob(ctx, hash); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,252 @@
+package sqlite
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"database/sql"
+	"io"
+	iofs "io/fs"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"testing/iotest"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/iryzzh/y-gophkeeper/internal/blob"
+	"github.com/iryzzh/y-gophkeeper/internal/models"
+	"github.com/iryzzh/y-gophkeeper/internal/utils"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDBStorage_chunks(t *testing.T) {
+	db := setupStore(t)
+	s := &dbStorage{db: db}
+	ctx := context.Background()
+
+	data := bytes.Repeat([]byte("0123456789abcdef"), blobChunkSize/8+1)
+	for i := 0; i < 2; i++ {
+		key, size, err := s.Put(ctx, bytes.NewReader(data))
+		require.NoError(t, err)
+		require.Equal(t, blob.Key(data), key)
+		require.Equal(t, int64(len(data)), size)
+	}
+	var chunks int
+	require.NoError(t, db.QueryRow(`select count(*) from blobs`).Scan(&chunks))
+	require.Equal(t, 3, chunks)
+
+	rc, err := s.Get(ctx, blob.Key(data))
+	require.NoError(t, err)
+	got, err := io.ReadAll(rc)
+	require.NoError(t, err)
+	require.NoError(t, rc.Close())
+	require.Equal(t, data, got)
+
+	_, _, err = s.Put(ctx, io.MultiReader(bytes.NewReader(data), iotest.ErrReader(io.ErrClosedPipe)))
+	require.ErrorIs(t, err, io.ErrClosedPipe)
+	require.NoError(t, db.QueryRow(`select count(*) from blobs`).Scan(&chunks))
+	require.Equal(t, 3, chunks)
+
+	require.NoError(t, s.Delete(ctx, blob.Key(data)))
+	_, err = s.Get(ctx, blob.Key(data))
+	require.ErrorIs(t, err, blob.ErrNotFound)
+}
+
+// TestStore_blobsMigrationDown checks that the rollback of the storage
+// in the database copies the data back into the item data.
+func TestStore_blobsMigrationDown(t *testing.T) {
+	cfg, err := utils.TestConfig(t)
+	require.NoError(t, err)
+	st, err := NewStore(cfg.DB.DSN, cfg.DB.MigrationsPath)
+	require.NoError(t, err)
+	defer func() { _ = st.Close() }()
+	ctx := context.Background()
+
+	data := make([]byte, blobChunkSize*2+blobChunkSize/2)
+	_, err = rand.Read(data)
+	require.NoError(t, err)
+	r := itemRepository(st.db)
+	large := &models.Item{UserID: uuid.NewString(), Meta: "large", ItemData: &models.ItemData{Data: data}}
+	require.NoError(t, r.Create(ctx, large))
+	empty := &models.Item{UserID: large.UserID, Meta: "empty", ItemData: &models.ItemData{Data: []byte{}}}
+	require.NoError(t, r.Create(ctx, empty))
+
+	// roll back the migration creating the blobs.
+	status, err := st.Migrations()
+	require.NoError(t, err)
+	require.NoError(t, st.MigrateDown(int(status.Latest)-15))
+
+	for _, it := range []*models.Item{large, empty} {
+		var got []byte
+		var hash sql.NullString
+		require.NoError(t, st.db.QueryRow(`select data, blob from items_data where id = $1`, it.DataID).
+			Scan(&got, &hash))
+		require.False(t, hash.Valid)
+		require.Equal(t, len(it.ItemData.Data), len(got))
+		require.True(t, bytes.Equal(it.ItemData.Data, got))
+	}
+	var n int
+	require.NoError(t, st.db.QueryRow(`select count(*) from items_blobs`).Scan(&n))
+	require.Zero(t, n)
+
+	require.NoError(t, st.MigrateUp())
+	got, err := r.FindByID(ctx, large.UserID, large.ID)
+	require.NoError(t, err)
+	require.True(t, bytes.Equal(data, got.ItemData.Data))
+}
+
+func TestItemRepository_Blobs(t *testing.T) {
+	t.Run("db", func(t *testing.T) {
+		var db *sql.DB
+		testBlobs(t, func(d *sql.DB) blob.Storage {
+			db = d
+			return &dbStorage{db: d}
+		}, func() int {
+			t.Helper()
+			var n int
+			require.NoError(t, db.QueryRow(`select count(distinct hash) from blobs`).Scan(&n))
+			return n
+		})
+	})
+
+	t.Run("fs", func(t *testing.T) {
+		dir := t.TempDir()
+		blobs, err := blob.NewFS(dir)
+		require.NoError(t, err)
+
+		testBlobs(t, func(*sql.DB) blob.Storage { return blobs }, func() int {
+			t.Helper()
+			var n int
+			require.NoError(t, filepath.WalkDir(dir, func(_ string, d iofs.DirEntry, err error) error {
+				if err == nil && !d.IsDir() {
+					n++
+				}
+				return err
+			}))
+			return n
+		})
+	})
+
+	t.Run("s3", func(t *testing.T) {
+		var mu sync.Mutex
+		objects := make(map[string][]byte)
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			mu.Lock()
+			defer mu.Unlock()
+			data, ok := objects[r.URL.Path]
+			switch {
+			case r.Method == http.MethodPut:
+				objects[r.URL.Path], _ = io.ReadAll(r.Body)
+			case r.Method == http.MethodDelete:
+				delete(objects, r.URL.Path)
+				w.WriteHeader(http.StatusNoContent)
+			case !ok:
+				w.WriteHeader(http.StatusNotFound)
+				_, _ = io.WriteString(w, `<Error><Code>NoSuchKey</Code></Error>`)
+			default:
+				_, _ = w.Write(data)
+			}
+		}))
+		defer srv.Close()
+
+		blobs, err := blob.NewS3(blob.S3Config{Endpoint: srv.URL, Bucket: "vault", PathStyle: true})
+		require.NoError(t, err)
+
+		testBlobs(t, func(*sql.DB) blob.Storage { return blobs }, func() int {
+			mu.Lock()
+			defer mu.Unlock()
+			return len(objects)
+		})
+	})
+}
+
+// testBlobs checks that the item data is saved to the blob storage
+// returned for the database, deduplicated and collected. The count
+// function returns the number of the blobs in the storage.
+func testBlobs(t *testing.T, storage func(db *sql.DB) blob.Storage, count func() int) {
+	t.Helper()
+	db := setupStore(t)
+	defer func() { _ = db.Close() }()
+	ctx := context.Background()
+
+	// the item data saved to the database before the blob storages.
+	legacy := sampleItem(t, uuid.NewString())
+	require.NoError(t, db.QueryRow(`insert into items_data (data, size) values ($1, $2) returning id`,
+		legacy.ItemData.Data, len(legacy.ItemData.Data)).Scan(&legacy.DataID))
+	require.NoError(t, db.QueryRow(`insert into items (user_id, meta, data_id) values ($1, $2, $3) returning id`,
+		legacy.UserID, legacy.Meta, legacy.DataID).Scan(&legacy.ID))
+
+	r := &ItemRepository{db: db, blobs: storage(db)}
+	u := &UserRepository{db: db}
+
+	refs := func(data string) int {
+		t.Helper()
+		var n int
+		require.NoError(t, db.QueryRow(`select refs from items_blobs where hash = $1`,
+			blob.Key([]byte(data))).Scan(&n))
+		return n
+	}
+
+	first, second := sampleItem(t, uuid.NewString()), sampleItem(t, uuid.NewString())
+	first.ItemData.Data, second.ItemData.Data = []byte("same ciphertext"), []byte("same ciphertext")
+	require.NoError(t, r.Create(ctx, first))
+	require.NoError(t, r.Create(ctx, second))
+	require.Equal(t, 1, count())
+	require.Equal(t, 2, refs("same ciphertext"))
+
+	got, err := r.FindByID(ctx, first.UserID, first.ID)
+	require.NoError(t, err)
+	require.Equal(t, "same ciphertext", string(got.ItemData.Data))
+	got, err = r.FindByID(ctx, legacy.UserID, legacy.ID)
+	require.NoError(t, err)
+	require.Equal(t, legacy.ItemData.Data, got.ItemData.Data)
+
+	usage, err := u.Usage(ctx, first.UserID)
+	require.NoError(t, err)
+	require.Equal(t, int64(len("same ciphertext")), usage.Bytes)
+
+	require.NoError(t, r.Attach(ctx, second.UserID, second.ID, &models.Attachment{Name: "copy"},
+		strings.NewReader("same ciphertext")))
+	require.Equal(t, 3, refs("same ciphertext"))
+	_, rc, err := r.OpenAttachment(ctx, second.UserID, second.ID, "copy")
+	require.NoError(t, err)
+	content, err := io.ReadAll(rc)
+	require.NoError(t, err)
+	require.Equal(t, "same ciphertext", string(content))
+
+	first.ItemData = &models.ItemData{ID: first.DataID, Data: []byte("new ciphertext")}
+	require.NoError(t, r.Update(ctx, first))
+	require.Equal(t, 2, refs("same ciphertext"))
+	require.Equal(t, 1, refs("new ciphertext"))
+	items, err := r.FindByUserID(ctx, first.UserID, 10, 0)
+	require.NoError(t, err)
+	require.Equal(t, "new ciphertext", string(items.Data[0].ItemData.Data))
+
+	require.NoError(t, r.Delete(ctx, second))
+	_, err = r.Purge(ctx, second.UserID, time.Now().Add(time.Minute))
+	require.NoError(t, err)
+	require.Equal(t, 0, refs("same ciphertext"))
+	require.Equal(t, 2, count())
+
+	collected, err := r.CollectBlobs(ctx, time.Now().Add(-time.Hour))
+	require.NoError(t, err)
+	require.Zero(t, collected)
+
+	collected, err = r.CollectBlobs(ctx, time.Now().Add(time.Minute))
+	require.NoError(t, err)
+	require.Equal(t, 1, collected)
+	require.Equal(t, 1, count())
+
+	got, err = r.FindByID(ctx, first.UserID, first.ID)
+	require.NoError(t, err)
+	require.Equal(t, "new ciphertext", string(got.ItemData.Data))
+
+	legacy.ItemData = &models.ItemData{ID: legacy.DataID, Data: []byte("new ciphertext")}
+	require.NoError(t, r.Update(ctx, legacy))
+	require.Equal(t, 2, refs("new ciphertext"))
+	require.Equal(t, 1, count())
+}
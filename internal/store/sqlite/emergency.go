@@ -0,0 +1,168 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/iryzzh/y-gophkeeper/internal/models"
+	"github.com/iryzzh/y-gophkeeper/internal/store"
+)
+
+// emergencyColumns selects the emergency access with the logins of
+// the grantor and the grantee.
+const emergencyColumns = `select e.grantor_id, e.grantee_id, ifnull(g.login, ''), ifnull(c.login, ''), e.vault_key,
+		e.wait_seconds, e.status, e.requested_at, e.released_at, e.created_at
+		from emergency_access e
+		left join users g on g.user_id = e.grantor_id
+		left join users c on c.user_id = e.grantee_id`
+
+type EmergencyRepository struct {
+	db *sql.DB
+}
+
+// Grant grants the emergency access to the vault of the grantor to the
+// grantee. The access granted earlier is replaced and its request, if
+// any, is dropped.
+func (r *EmergencyRepository) Grant(ctx context.Context, access *models.EmergencyAccess) error {
+	_, err := r.db.ExecContext(ctx,
+		`insert into emergency_access (grantor_id, grantee_id, vault_key, wait_seconds) values ($1, $2, $3, $4)
+			on conflict (grantor_id, grantee_id) do update set vault_key = excluded.vault_key,
+				wait_seconds = excluded.wait_seconds, status = 'idle', requested_at = null, released_at = null`,
+		access.GrantorID, access.GranteeID, access.Key, int64(access.Wait/time.Second))
+	if err != nil {
+		return err
+	}
+	access.Status = models.EmergencyIdle
+
+	return nil
+}
+
+// Find returns the emergency access granted by the grantor to the
+// grantee.
+func (r *EmergencyRepository) Find(ctx context.Context, grantorID, granteeID string) (*models.EmergencyAccess, error) {
+	rows, err := r.db.QueryContext(ctx,
+		emergencyColumns+` where e.grantor_id = $1 and e.grantee_id = $2`,
+		grantorID, granteeID)
+	if err != nil {
+		return nil, err
+	}
+
+	access, err := scanEmergencyAccess(rows)
+	if err != nil {
+		return nil, err
+	}
+	if len(access) == 0 {
+		return nil, store.ErrEmergencyAccessNotFound
+	}
+
+	return access[0], nil
+}
+
+// FindByGrantor returns the emergency access granted by the grantor.
+func (r *EmergencyRepository) FindByGrantor(ctx context.Context, grantorID string) ([]*models.EmergencyAccess, error) {
+	rows, err := r.db.QueryContext(ctx,
+		emergencyColumns+` where e.grantor_id = $1 order by c.login`,
+		grantorID)
+	if err != nil {
+		return nil, err
+	}
+
+	return scanEmergencyAccess(rows)
+}
+
+// FindByGrantee returns the emergency access granted to the grantee.
+func (r *EmergencyRepository) FindByGrantee(ctx context.Context, granteeID string) ([]*models.EmergencyAccess, error) {
+	rows, err := r.db.QueryContext(ctx,
+		emergencyColumns+` where e.grantee_id = $1 order by g.login`,
+		granteeID)
+	if err != nil {
+		return nil, err
+	}
+
+	return scanEmergencyAccess(rows)
+}
+
+// Revoke revokes the emergency access granted by the grantor to the
+// grantee.
+func (r *EmergencyRepository) Revoke(ctx context.Context, grantorID, granteeID string) error {
+	return r.exec(ctx,
+		`delete from emergency_access where grantor_id = $1 and grantee_id = $2`,
+		grantorID, granteeID)
+}
+
+// Request starts the waiting period of the idle emergency access.
+func (r *EmergencyRepository) Request(ctx context.Context, grantorID, granteeID string) error {
+	return r.exec(ctx,
+		`update emergency_access set status = 'requested', requested_at = current_timestamp
+			where grantor_id = $1 and grantee_id = $2 and status = 'idle'`,
+		grantorID, granteeID)
+}
+
+// Approve releases the requested emergency access before the end of
+// the waiting period.
+func (r *EmergencyRepository) Approve(ctx context.Context, grantorID, granteeID string) error {
+	return r.exec(ctx,
+		`update emergency_access set status = 'approved', released_at = current_timestamp
+			where grantor_id = $1 and grantee_id = $2 and status = 'requested'`,
+		grantorID, granteeID)
+}
+
+// Deny returns the requested or approved emergency access to the idle
+// status.
+func (r *EmergencyRepository) Deny(ctx context.Context, grantorID, granteeID string) error {
+	return r.exec(ctx,
+		`update emergency_access set status = 'idle', requested_at = null, released_at = null
+			where grantor_id = $1 and grantee_id = $2 and status != 'idle'`,
+		grantorID, granteeID)
+}
+
+// Release approves the emergency access requested before the given
+// time minus its waiting period. It returns the number of the
+// released accesses.
+func (r *EmergencyRepository) Release(ctx context.Context, until time.Time) (int, error) {
+	res, err := r.db.ExecContext(ctx,
+		`update emergency_access set status = 'approved', released_at = current_timestamp
+			where status = 'requested' and datetime(requested_at, '+' || wait_seconds || ' seconds') <= $1`,
+		until.UTC().Format(dateLayout))
+	if err != nil {
+		return 0, err
+	}
+	n, _ := res.RowsAffected()
+
+	return int(n), nil
+}
+
+// exec executes the query changing a single emergency access and
+// returns `store.ErrEmergencyAccessNotFound` if nothing was changed.
+func (r *EmergencyRepository) exec(ctx context.Context, query string, args ...interface{}) error {
+	res, err := r.db.ExecContext(ctx, query, args...)
+	if err != nil {
+		return err
+	}
+	if rowsAffected, _ := res.RowsAffected(); rowsAffected == 0 {
+		return store.ErrEmergencyAccessNotFound
+	}
+
+	return nil
+}
+
+// scanEmergencyAccess scans the rows selected with `emergencyColumns`
+// and closes them.
+func scanEmergencyAccess(rows *sql.Rows) ([]*models.EmergencyAccess, error) {
+	defer func() { _ = rows.Close() }()
+
+	result := make([]*models.EmergencyAccess, 0)
+	for rows.Next() {
+		a := &models.EmergencyAccess{}
+		var wait int64
+		if err := rows.Scan(&a.GrantorID, &a.GranteeID, &a.Grantor, &a.Grantee, &a.Key, &wait, &a.Status,
+			&a.RequestedAt, &a.ReleasedAt, &a.CreatedAt); err != nil {
+			return nil, err
+		}
+		a.Wait = time.Duration(wait) * time.Second
+		result = append(result, a)
+	}
+
+	return result, rows.Err()
+}
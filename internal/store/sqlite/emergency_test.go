@@ -0,0 +1,85 @@
+package sqlite
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/iryzzh/y-gophkeeper/internal/models"
+	"github.com/iryzzh/y-gophkeeper/internal/store"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEmergencyRepository(t *testing.T) {
+	db := setupStore(t)
+	defer func() { _ = db.Close() }()
+	r := &EmergencyRepository{db: db}
+	users := &UserRepository{db: db}
+	ctx := context.Background()
+
+	grantor, grantee := makeUser(t), makeUser(t)
+	grantor.Login, grantee.Login = "grantor", "grantee"
+	for _, u := range []*models.User{grantor, grantee} {
+		require.NoError(t, users.Create(ctx, u))
+	}
+
+	access := &models.EmergencyAccess{
+		GrantorID: grantor.ID,
+		GranteeID: grantee.ID,
+		Key:       []byte("vault key"),
+		Wait:      time.Hour,
+	}
+	require.NoError(t, r.Grant(ctx, access))
+
+	found, err := r.Find(ctx, grantor.ID, grantee.ID)
+	require.NoError(t, err)
+	require.Equal(t, "grantor", found.Grantor)
+	require.Equal(t, "grantee", found.Grantee)
+	require.Equal(t, []byte("vault key"), found.Key)
+	require.Equal(t, time.Hour, found.Wait)
+	require.Equal(t, models.EmergencyIdle, found.Status)
+	_, err = r.Find(ctx, grantee.ID, grantor.ID)
+	require.ErrorIs(t, err, store.ErrEmergencyAccessNotFound)
+
+	granted, err := r.FindByGrantor(ctx, grantor.ID)
+	require.NoError(t, err)
+	require.Len(t, granted, 1)
+	trusted, err := r.FindByGrantee(ctx, grantee.ID)
+	require.NoError(t, err)
+	require.Len(t, trusted, 1)
+
+	require.ErrorIs(t, r.Approve(ctx, grantor.ID, grantee.ID), store.ErrEmergencyAccessNotFound)
+	require.ErrorIs(t, r.Deny(ctx, grantor.ID, grantee.ID), store.ErrEmergencyAccessNotFound)
+	require.NoError(t, r.Request(ctx, grantor.ID, grantee.ID))
+	require.ErrorIs(t, r.Request(ctx, grantor.ID, grantee.ID), store.ErrEmergencyAccessNotFound)
+
+	// the access is released once the waiting period passes
+	n, err := r.Release(ctx, time.Now())
+	require.NoError(t, err)
+	require.Zero(t, n)
+	n, err = r.Release(ctx, time.Now().Add(2*time.Hour))
+	require.NoError(t, err)
+	require.Equal(t, 1, n)
+
+	found, err = r.Find(ctx, grantor.ID, grantee.ID)
+	require.NoError(t, err)
+	require.Equal(t, models.EmergencyApproved, found.Status)
+	require.NotNil(t, found.RequestedAt)
+	require.NotNil(t, found.ReleasedAt)
+
+	require.NoError(t, r.Deny(ctx, grantor.ID, grantee.ID))
+	require.NoError(t, r.Request(ctx, grantor.ID, grantee.ID))
+	require.NoError(t, r.Approve(ctx, grantor.ID, grantee.ID))
+
+	// granting again resets the access
+	access.Key = []byte("new key")
+	require.NoError(t, r.Grant(ctx, access))
+	found, err = r.Find(ctx, grantor.ID, grantee.ID)
+	require.NoError(t, err)
+	require.Equal(t, []byte("new key"), found.Key)
+	require.Equal(t, models.EmergencyIdle, found.Status)
+	require.Nil(t, found.RequestedAt)
+
+	require.NoError(t, r.Revoke(ctx, grantor.ID, grantee.ID))
+	require.ErrorIs(t, r.Revoke(ctx, grantor.ID, grantee.ID), store.ErrEmergencyAccessNotFound)
+}
@@ -0,0 +1,73 @@
+package sqlite
+
+import (
+	"context"
+
+	"github.com/iryzzh/y-gophkeeper/internal/store"
+	"github.com/mattn/go-sqlite3"
+	"github.com/pkg/errors"
+)
+
+// Move renames the item named `from` and moves the items of the
+// folder `from` to the folder `to` in a single transaction. It
+// returns the number of the moved items. The names are expected to
+// be cleaned with `models.CleanPath`.
+func (r *ItemRepository) Move(ctx context.Context, userID, from, to string) (int, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer rollback(ctx, tx)
+
+	// sqlite checks the unique constraint for every updated row, so the
+	// items are renamed in the order of the move: when moving `a/b` to
+	// `a`, `a/b` must be renamed before `a/b/b` takes its name.
+	order := `desc`
+	if len(to) < len(from) {
+		order = `asc`
+	}
+
+	rows, err := tx.QueryContext(ctx,
+		`select id, meta from items
+			where user_id = $1 and deleted_at is null and (meta = $2 or substr(meta, 1, length($2) + 1) = $2 || '/')
+			order by length(meta) `+order,
+		userID, from)
+	if err != nil {
+		return 0, errors.Wrap(err, store.ErrItemMoveFailed.Error())
+	}
+	var ids []int
+	var names []string
+	for rows.Next() {
+		var id int
+		var meta string
+		if err = rows.Scan(&id, &meta); err != nil {
+			_ = rows.Close()
+			return 0, errors.Wrap(err, store.ErrItemMoveFailed.Error())
+		}
+		ids = append(ids, id)
+		names = append(names, to+meta[len(from):])
+	}
+	_ = rows.Close()
+	if len(ids) == 0 {
+		return 0, store.ErrItemNotFound
+	}
+
+	for i, id := range ids {
+		if _, err = tx.ExecContext(ctx,
+			`update items set meta = $1, updated_at = current_timestamp where id = $2`,
+			names[i], id); err != nil {
+			vErr, ok := errors.Cause(err).(sqlite3.Error)
+			if ok && vErr.ExtendedCode == sqlite3.ErrConstraintUnique {
+				return 0, store.ErrItemExists
+			}
+
+			return 0, errors.Wrap(err, store.ErrItemMoveFailed.Error())
+		}
+
+		if err = index(ctx, tx, id); err != nil {
+			return 0, errors.Wrap(err, store.ErrItemMoveFailed.Error())
+		}
+	}
+
+	return len(ids), tx.Commit()
+}
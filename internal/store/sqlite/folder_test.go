@@ -0,0 +1,111 @@
+package sqlite
+
+import (
+	"context"
+	"sort"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/iryzzh/y-gophkeeper/internal/models"
+	"github.com/iryzzh/y-gophkeeper/internal/store"
+	"github.com/stretchr/testify/require"
+)
+
+func TestItemRepository_Move(t *testing.T) {
+	tests := []struct {
+		name      string
+		from, to  string
+		wantMoved int
+		wantErr   error
+		want      []string
+	}{
+		{
+			name:      "rename entry",
+			from:      "mailbox",
+			to:        "inbox",
+			wantMoved: 1,
+			want:      []string{"inbox", "mail", "mail/home", "mail/work", "web/github"},
+		},
+		{
+			name:      "move folder",
+			from:      "mail",
+			to:        "web/mail",
+			wantMoved: 3,
+			want:      []string{"mailbox", "web/github", "web/mail", "web/mail/home", "web/mail/work"},
+		},
+		{
+			name:      "move entry into folder",
+			from:      "mailbox",
+			to:        "mail/box",
+			wantMoved: 1,
+			want:      []string{"mail", "mail/box", "mail/home", "mail/work", "web/github"},
+		},
+		{
+			name:      "move folder up",
+			from:      "mail/work",
+			to:        "work",
+			wantMoved: 1,
+			want:      []string{"mail", "mail/home", "mailbox", "web/github", "work"},
+		},
+		{
+			name:      "move folder into itself",
+			from:      "mail",
+			to:        "mail/old",
+			wantMoved: 3,
+			want:      []string{"mail/old", "mail/old/home", "mail/old/work", "mailbox", "web/github"},
+		},
+		{
+			name:      "move folder out of itself",
+			from:      "web",
+			to:        "github",
+			wantMoved: 1,
+			want:      []string{"github/github", "mail", "mail/home", "mail/work", "mailbox"},
+		},
+		{
+			name:    "conflict",
+			from:    "mail/home",
+			to:      "mail/work",
+			wantErr: store.ErrItemExists,
+			want:    []string{"mail", "mail/home", "mail/work", "mailbox", "web/github"},
+		},
+		{
+			name:    "not found",
+			from:    "unknown",
+			to:      "known",
+			wantErr: store.ErrItemNotFound,
+			want:    []string{"mail", "mail/home", "mail/work", "mailbox", "web/github"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := itemRepository(setupStore(t))
+			defer func() { _ = r.db.Close() }()
+
+			userID := uuid.NewString()
+			for _, meta := range []string{"mail", "mail/home", "mail/work", "mailbox", "web/github"} {
+				it := sampleItem(t, userID)
+				it.Meta = meta
+				require.NoError(t, r.Create(context.Background(), it))
+			}
+
+			moved, err := r.Move(context.Background(), userID, tt.from, tt.to)
+			require.ErrorIs(t, err, tt.wantErr)
+			require.Equal(t, tt.wantMoved, moved)
+
+			items, err := r.FindByUserID(context.Background(), userID, 100, 0)
+			require.NoError(t, err)
+			var got []string
+			for _, it := range items.Data {
+				got = append(got, it.Meta)
+			}
+			sort.Strings(got)
+			require.Equal(t, tt.want, got)
+
+			if tt.wantErr == nil {
+				found, err := r.Search(context.Background(), userID, &models.ItemFilter{Folder: tt.to})
+				require.NoError(t, err)
+				require.Len(t, found.Data, tt.wantMoved)
+			}
+		})
+	}
+}
@@ -0,0 +1,9 @@
+//go:build !sqlite_fts5 && !fts5
+
+package sqlite
+
+// The search index of the items is an FTS5 table, which go-sqlite3 only
+// compiles in with the `sqlite_fts5` build tag: the build fails here
+// without it, instead of the migration at the startup. Build with
+// `go build -tags sqlite_fts5 ./...`.
+var _ = buildTagSqliteFTS5IsRequired
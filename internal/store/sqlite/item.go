@@ -2,9 +2,11 @@
 package sqlite
 
 import (
+	"bytes"
 	"context"
 	"database/sql"
 
+	"github.com/iryzzh/y-gophkeeper/internal/blob"
 	"github.com/iryzzh/y-gophkeeper/internal/models"
 	"github.com/iryzzh/y-gophkeeper/internal/store"
 	"github.com/mattn/go-sqlite3"
@@ -13,23 +15,32 @@ import (
 
 type ItemRepository struct {
 	db *sql.DB
+	// blobs is the storage of the item data.
+	blobs blob.Storage
 }
 
 func (r *ItemRepository) Create(ctx context.Context, item *models.Item) error {
+	if item.Meta == "" {
+		return store.ErrItemMetaIsRequired
+	}
+
+	var hash string
+	var size int64
+	var err error
+	if item.ItemData != nil {
+		if hash, size, err = r.saveBlob(ctx, bytes.NewReader(item.ItemData.Data)); err != nil {
+			return errors.Wrap(err, store.ErrItemDataCreateFailed.Error())
+		}
+	}
+
 	tx, err := r.db.BeginTx(ctx, nil)
 	if err != nil {
 		return err
 	}
-	defer func() { _ = tx.Rollback() }()
-
-	if item.Meta == "" {
-		return store.ErrItemMetaIsRequired
-	}
+	defer rollback(ctx, tx)
 
 	if item.ItemData != nil && item.ItemData.Data != nil {
-		if err = tx.QueryRowContext(ctx,
-			`insert into items_data (data) values (?) returning id`,
-			item.ItemData.Data).Scan(&item.DataID); err != nil || item.DataID == 0 {
+		if item.DataID, err = insertData(ctx, tx, hash, size); err != nil || item.DataID == 0 {
 			vErr, ok := errors.Cause(err).(sqlite3.Error)
 			if ok && vErr.ExtendedCode == sqlite3.ErrConstraintUnique {
 				return store.ErrItemExists
@@ -52,18 +63,36 @@ func (r *ItemRepository) Create(ctx context.Context, item *models.Item) error {
 		return errors.Wrap(err, store.ErrItemCreateFailed.Error())
 	}
 
+	if err = saveMetadata(ctx, tx, item); err != nil {
+		return errors.Wrap(err, store.ErrItemCreateFailed.Error())
+	}
+
+	if item.Key != nil {
+		if err = saveKey(ctx, tx, item.ID, item.UserID, item.Key); err != nil {
+			return errors.Wrap(err, store.ErrItemCreateFailed.Error())
+		}
+	}
+
+	if err = index(ctx, tx, item.ID); err != nil {
+		return errors.Wrap(err, store.ErrItemCreateFailed.Error())
+	}
+
 	return tx.Commit()
 }
 
+// FindByID returns the item with the given id owned by the user or
+// shared with the user.
 func (r *ItemRepository) FindByID(ctx context.Context, userID string, id int) (*models.Item, error) {
 	item := &models.Item{}
 	itemData := &models.ItemData{}
 	err := r.db.QueryRowContext(ctx,
 		`select items.id, items.user_id, items.meta, items.data_id, items.data_type, items.created_at,
-	       			items.updated_at, idt.id, idt.data
+	       			items.updated_at, ifnull(idt.id, 0), idt.data
 					from items
 					left join items_data idt on idt.id = items.data_id				
-					where items.user_id = $1 and items.id = $2				
+					where (items.user_id = $1 or
+						exists (select 1 from items_keys where item_id = items.id and user_id = $1))
+						and items.id = $2 and items.deleted_at is null
 					order by items.id`,
 		userID, id).
 		Scan(&item.ID, &item.UserID, &item.Meta, &item.DataID, &item.DataType, &item.CreatedAt, &item.UpdatedAt, &itemData.ID,
@@ -77,7 +106,7 @@ func (r *ItemRepository) FindByID(ctx context.Context, userID string, id int) (*
 
 	item.ItemData = itemData
 
-	return item, nil
+	return item, r.load(ctx, userID, item)
 }
 
 func (r *ItemRepository) FindByMetaName(ctx context.Context, userID string, metaName string) (*models.Item, error) {
@@ -85,10 +114,10 @@ func (r *ItemRepository) FindByMetaName(ctx context.Context, userID string, meta
 	itemData := &models.ItemData{}
 	err := r.db.QueryRowContext(ctx,
 		`select items.id, items.user_id, items.meta, items.data_id, items.data_type, items.created_at,
-	       			items.updated_at, idt.id, idt.data
+	       			items.updated_at, ifnull(idt.id, 0), idt.data
 					from items
 					left join items_data idt on idt.id = items.data_id				
-					where items.user_id = $1 and items.meta = $2				
+					where items.user_id = $1 and items.meta = $2 and items.deleted_at is null
 					order by items.id`,
 		userID, metaName).
 		Scan(&item.ID, &item.UserID, &item.Meta, &item.DataID, &item.DataType, &item.CreatedAt,
@@ -102,17 +131,17 @@ func (r *ItemRepository) FindByMetaName(ctx context.Context, userID string, meta
 
 	item.ItemData = itemData
 
-	return item, nil
+	return item, r.load(ctx, userID, item)
 }
 
 func (r *ItemRepository) FindByUserID(ctx context.Context, userID string, limit, offset int) (*models.Items, error) {
 	rows, err := r.db.QueryContext(ctx,
 		`select items.id, items.user_id, items.meta, items.data_id, items.data_type,  items.created_at,
-       			items.updated_at, idt.id, idt.data,
-       			(select count(*) from items where user_id = $1)
+       			items.updated_at, items.deleted_at, ifnull(idt.id, 0), idt.data,
+       			(select count(*) from items where user_id = $1 and deleted_at is null)
 				from items
-				left join items_data idt on idt.id = items.data_id				
-				where user_id = $1
+				left join items_data idt on idt.id = items.data_id
+				where user_id = $1 and deleted_at is null
 				group by items.id
 				order by items.id
 				limit $2 offset $3`,
@@ -124,72 +153,76 @@ func (r *ItemRepository) FindByUserID(ctx context.Context, userID string, limit,
 	}
 	defer func() { _ = rows.Close() }()
 
-	var total int
-	var items []*models.Item
-	for rows.Next() {
-		item := &models.Item{}
-		itemData := &models.ItemData{}
-		err := rows.Scan(
-			&item.ID,
-			&item.UserID,
-			&item.Meta,
-			&item.DataID,
-			&item.DataType,
-			&item.CreatedAt,
-			&item.UpdatedAt,
-			&itemData.ID,
-			&itemData.Data,
-			&total,
-		)
-		if err != nil {
-			panic("item FindByUserID sql scan error: " + err.Error())
-		}
-		item.ItemData = itemData
-		items = append(items, item)
+	items, total, err := scanItems(rows)
+	if err != nil {
+		return nil, err
 	}
 
 	if len(items) > 0 {
 		return &models.Items{
 			Meta: models.Meta{TotalItems: total},
 			Data: items,
-		}, rows.Err()
+		}, r.load(ctx, userID, items...)
 	}
 
 	return nil, store.ErrItemNotFound
 }
 
+// Update updates the item owned by the user or shared with the user
+// in read-write mode. If the item key is set, it is saved as the item
+// key of the user.
 func (r *ItemRepository) Update(ctx context.Context, item *models.Item) error {
 	if item.Meta == "" {
 		return store.ErrItemMetaIsRequired
 	}
 
+	if item.ItemData != nil && item.ItemData.ID == 0 {
+		return store.ErrItemDataInvalidID
+	}
+
+	if item.ID == 0 {
+		return store.ErrItemInvalidID
+	}
+
+	var hash string
+	var size int64
+	var err error
+	if item.ItemData != nil {
+		if hash, size, err = r.saveBlob(ctx, bytes.NewReader(item.ItemData.Data)); err != nil {
+			return errors.Wrap(err, store.ErrItemUpdateFailed.Error())
+		}
+	}
+
 	tx, err := r.db.BeginTx(ctx, nil)
 	if err != nil {
 		return err
 	}
-	defer func() { _ = tx.Rollback() }()
+	defer rollback(ctx, tx)
 
+	owner, err := writableBy(ctx, tx, item.ID, item.UserID)
+	if err != nil {
+		return err
+	}
+
+	// the data is replaced in place: the data id of the item is never
+	// taken from the request, since it may be the data of another item.
 	if item.ItemData != nil {
-		if item.ItemData.ID == 0 {
-			return store.ErrItemDataInvalidID
-		}
-		res, _ := tx.ExecContext(ctx,
-			`update items_data set data = $1 
-                  	where id = (select data_id from items where user_id = $2 and data_id = $3)`,
-			item.ItemData.Data, item.UserID, item.DataID)
-		if rowsAffected, _ := res.RowsAffected(); rowsAffected == 0 {
+		if item.DataID != item.ItemData.ID {
 			return store.ErrItemDataNotFound
 		}
-	} else {
-		item.ItemData = &models.ItemData{}
+		err = updateData(ctx, tx, owner, item.ID, item.ItemData.ID, hash, size)
+		if errors.Is(err, store.ErrItemDataNotFound) {
+			return err
+		}
+		if err != nil {
+			return errors.Wrap(err, store.ErrItemUpdateFailed.Error())
+		}
 	}
 
-	if item.ID == 0 {
-		return store.ErrItemInvalidID
-	}
 	res, err := tx.ExecContext(ctx,
-		`update items set meta = $1, data_id = $2, data_type = $3 where id = $4 and user_id = $5`,
-		item.Meta, item.ItemData.ID, item.DataType, item.ID, item.UserID)
+		`update items set meta = $1, data_type = $2, updated_at = current_timestamp
+			where id = $3 and user_id = $4 and deleted_at is null`,
+		item.Meta, item.DataType, item.ID, owner)
 	if err != nil {
 		return errors.Wrap(err, store.ErrItemUpdateFailed.Error())
 	}
@@ -198,29 +231,84 @@ func (r *ItemRepository) Update(ctx context.Context, item *models.Item) error {
 		return store.ErrItemNotFound
 	}
 
+	if item.Key != nil {
+		if err = saveKey(ctx, tx, item.ID, item.UserID, item.Key); err != nil {
+			return errors.Wrap(err, store.ErrItemUpdateFailed.Error())
+		}
+	}
+
+	if err = saveMetadata(ctx, tx, item); err != nil {
+		return errors.Wrap(err, store.ErrItemUpdateFailed.Error())
+	}
+
+	if err = index(ctx, tx, item.ID); err != nil {
+		return errors.Wrap(err, store.ErrItemUpdateFailed.Error())
+	}
+
 	return tx.Commit()
 }
 
+// Delete moves the item to the trash and records a tombstone, so
+// that the other clients of the user can delete it on sync. The
+// item is deleted permanently by `ItemRepository.Purge`.
 func (r *ItemRepository) Delete(ctx context.Context, item *models.Item) error {
 	tx, err := r.db.BeginTx(ctx, nil)
 	if err != nil {
 		return err
 	}
-	defer func() { _ = tx.Rollback() }()
+	defer rollback(ctx, tx)
 
-	_, err = tx.ExecContext(ctx,
-		`delete from items_data where id = (select data_id from items where user_id = $1 and id = $2)`,
-		item.UserID, item.ID)
-	if err != nil {
-		return errors.Wrap(err, store.ErrItemDataDeleteFailed.Error())
+	if err = tx.QueryRowContext(ctx,
+		`update items set deleted_at = current_timestamp
+			where user_id = $1 and id = $2 and deleted_at is null
+			returning deleted_at`,
+		item.UserID, item.ID).Scan(&item.DeletedAt); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return store.ErrItemNotFound
+		}
+
+		return errors.Wrap(err, store.ErrItemDeleteFailed.Error())
 	}
 
-	_, err = tx.ExecContext(ctx,
-		`delete from items where user_id = $1 and id = $2`,
-		item.UserID, item.ID)
-	if err != nil {
+	if _, err = tx.ExecContext(ctx,
+		`insert into items_tombstones (item_id, user_id, meta, deleted_at)
+			select id, user_id, meta, deleted_at from items where id = $1`,
+		item.ID); err != nil {
 		return errors.Wrap(err, store.ErrItemDeleteFailed.Error())
 	}
 
 	return tx.Commit()
 }
+
+// scanItems scans the rows selected with the item columns followed
+// by the total count and closes them, so that the connection can be
+// reused by the following queries.
+func scanItems(rows *sql.Rows) ([]*models.Item, int, error) {
+	defer func() { _ = rows.Close() }()
+
+	var total int
+	var items []*models.Item
+	for rows.Next() {
+		item := &models.Item{}
+		itemData := &models.ItemData{}
+		if err := rows.Scan(
+			&item.ID,
+			&item.UserID,
+			&item.Meta,
+			&item.DataID,
+			&item.DataType,
+			&item.CreatedAt,
+			&item.UpdatedAt,
+			&item.DeletedAt,
+			&itemData.ID,
+			&itemData.Data,
+			&total,
+		); err != nil {
+			return nil, 0, err
+		}
+		item.ItemData = itemData
+		items = append(items, item)
+	}
+
+	return items, total, rows.Err()
+}
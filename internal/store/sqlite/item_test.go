@@ -83,9 +83,7 @@ func TestItemRepository_Create(t *testing.T) {
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			r := &ItemRepository{
-				db: setupStore(t),
-			}
+			r := itemRepository(setupStore(t))
 			defer func() { _ = r.db.Close() }()
 			var err error
 			for _, item := range tt.items {
@@ -103,9 +101,7 @@ func TestItemRepository_Create(t *testing.T) {
 }
 
 func generateTestItems(t *testing.T, db *sql.DB, userID string, count int) []*models.Item {
-	r := &ItemRepository{
-		db: db,
-	}
+	r := itemRepository(db)
 
 	var items []*models.Item
 	for i := 0; i < count; i++ {
@@ -141,9 +137,7 @@ func TestItemRepository_FindByUserID(t *testing.T) {
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			r := &ItemRepository{
-				db: setupStore(t),
-			}
+			r := itemRepository(setupStore(t))
 			defer func() { _ = r.db.Close() }()
 
 			genItems := generateTestItems(t, r.db, tt.userID, tt.count)
@@ -178,9 +172,7 @@ func TestItemRepository_Update(t *testing.T) {
 			wantErr: nil,
 			item: func() *models.Item {
 				item := sampleItem(t, uuid.NewString())
-				r := &ItemRepository{
-					db: db,
-				}
+				r := itemRepository(db)
 				if err := r.Create(context.Background(), item); err != nil {
 					t.Fatal(err)
 				}
@@ -192,9 +184,7 @@ func TestItemRepository_Update(t *testing.T) {
 			wantErr: store.ErrItemNotFound,
 			item: func() *models.Item {
 				item := sampleItem(t, uuid.NewString())
-				r := &ItemRepository{
-					db: db,
-				}
+				r := itemRepository(db)
 				if err := r.Create(context.Background(), item); err != nil {
 					t.Fatal(err)
 				}
@@ -207,9 +197,7 @@ func TestItemRepository_Update(t *testing.T) {
 			wantErr: store.ErrItemDataNotFound,
 			item: func() *models.Item {
 				item := sampleItem(t, uuid.NewString())
-				r := &ItemRepository{
-					db: db,
-				}
+				r := itemRepository(db)
 				if err := r.Create(context.Background(), item); err != nil {
 					t.Fatal(err)
 				}
@@ -223,9 +211,7 @@ func TestItemRepository_Update(t *testing.T) {
 			wantErr: store.ErrItemDataInvalidID,
 			item: func() *models.Item {
 				item := sampleItem(t, uuid.NewString())
-				r := &ItemRepository{
-					db: db,
-				}
+				r := itemRepository(db)
 				if err := r.Create(context.Background(), item); err != nil {
 					t.Fatal(err)
 				}
@@ -238,9 +224,7 @@ func TestItemRepository_Update(t *testing.T) {
 			wantErr: store.ErrItemInvalidID,
 			item: func() *models.Item {
 				item := sampleItem(t, uuid.NewString())
-				r := &ItemRepository{
-					db: db,
-				}
+				r := itemRepository(db)
 				if err := r.Create(context.Background(), item); err != nil {
 					t.Fatal(err)
 				}
@@ -253,9 +237,7 @@ func TestItemRepository_Update(t *testing.T) {
 			wantErr: store.ErrItemMetaIsRequired,
 			item: func() *models.Item {
 				item := sampleItem(t, uuid.NewString())
-				r := &ItemRepository{
-					db: db,
-				}
+				r := itemRepository(db)
 				if err := r.Create(context.Background(), item); err != nil {
 					t.Fatal(err)
 				}
@@ -267,9 +249,7 @@ func TestItemRepository_Update(t *testing.T) {
 			name: "item empty data",
 			item: func() *models.Item {
 				item := sampleItem(t, uuid.NewString())
-				r := &ItemRepository{
-					db: db,
-				}
+				r := itemRepository(db)
 				if err := r.Create(context.Background(), item); err != nil {
 					t.Fatal(err)
 				}
@@ -280,9 +260,7 @@ func TestItemRepository_Update(t *testing.T) {
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			r := &ItemRepository{
-				db: db,
-			}
+			r := itemRepository(db)
 			err := r.Update(context.Background(), tt.item)
 			require.Conditionf(t, func() (success bool) {
 				if tt.wantErr == nil && err == nil {
@@ -318,9 +296,7 @@ func TestItemRepository_Delete(t *testing.T) {
 			wantErr: nil,
 			item: func() *models.Item {
 				item := sampleItem(t, uuid.NewString())
-				r := &ItemRepository{
-					db: db,
-				}
+				r := itemRepository(db)
 				if err := r.Create(context.Background(), item); err != nil {
 					t.Fatal(err)
 				}
@@ -330,9 +306,7 @@ func TestItemRepository_Delete(t *testing.T) {
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			r := &ItemRepository{
-				db: db,
-			}
+			r := itemRepository(db)
 			defer func() { _ = r.db.Close() }()
 			err := r.Delete(context.Background(), tt.item)
 			require.Equal(t, tt.wantErr == nil, err == nil)
@@ -0,0 +1,19 @@
+-- noinspection SqlNoDataSourceInspectionForFile
+
+create table if not exists items_tags
+(
+    item_id integer not null,
+    tag     text    not null,
+    CONSTRAINT items_tags_uniq UNIQUE (item_id, tag)
+);
+
+create table if not exists items_fields
+(
+    item_id integer not null,
+    name    text    not null,
+    value   text    not null default '',
+    secret  boolean not null default false,
+    CONSTRAINT items_fields_uniq UNIQUE (item_id, name)
+);
+
+create index if not exists items_tags_tag on items_tags (tag);
@@ -0,0 +1,28 @@
+-- noinspection SqlNoDataSourceInspectionForFile
+
+drop table if exists items_tombstones;
+
+delete from items_data where id in (select data_id from items where deleted_at is not null);
+delete from items_tags where item_id in (select id from items where deleted_at is not null);
+delete from items_fields where item_id in (select id from items where deleted_at is not null);
+delete from items where deleted_at is not null;
+
+create table if not exists items_old
+(
+    id         integer primary key autoincrement,
+    user_id    text,
+    meta       text,
+    data_id    integer,
+    data_type  text     default 'text',
+    created_at datetime default current_timestamp,
+    updated_at datetime default null,
+    CONSTRAINT uniq UNIQUE (user_id, meta)
+);
+
+insert into items_old (id, user_id, meta, data_id, data_type, created_at, updated_at)
+select id, user_id, meta, data_id, data_type, created_at, updated_at
+from items;
+
+drop table items;
+
+alter table items_old rename to items;
@@ -0,0 +1,35 @@
+-- noinspection SqlNoDataSourceInspectionForFile
+
+create table if not exists items_new
+(
+    id         integer primary key autoincrement,
+    user_id    text,
+    meta       text,
+    data_id    integer,
+    data_type  text     default 'text',
+    created_at datetime default current_timestamp,
+    updated_at datetime default null,
+    deleted_at datetime default null
+);
+
+insert into items_new (id, user_id, meta, data_id, data_type, created_at, updated_at)
+select id, user_id, meta, data_id, data_type, created_at, updated_at
+from items;
+
+drop table items;
+
+alter table items_new rename to items;
+
+create unique index if not exists items_uniq on items (user_id, meta) where deleted_at is null;
+
+create index if not exists items_deleted_at on items (deleted_at) where deleted_at is not null;
+
+create table if not exists items_tombstones
+(
+    item_id    integer  not null,
+    user_id    text     not null,
+    meta       text     not null,
+    deleted_at datetime not null default current_timestamp
+);
+
+create index if not exists items_tombstones_user on items_tombstones (user_id, deleted_at);
@@ -0,0 +1,18 @@
+-- noinspection SqlNoDataSourceInspectionForFile
+
+create table if not exists emergency_access
+(
+    grantor_id   text    not null,
+    grantee_id   text    not null,
+    vault_key    blob    not null,
+    wait_seconds integer not null,
+    status       text    not null default 'idle',
+    requested_at datetime default null,
+    released_at  datetime default null,
+    created_at   datetime default current_timestamp,
+    CONSTRAINT emergency_access_uniq UNIQUE (grantor_id, grantee_id)
+);
+
+create index if not exists emergency_access_grantee on emergency_access (grantee_id);
+
+create index if not exists emergency_access_requested on emergency_access (requested_at) where status = 'requested';
@@ -0,0 +1,5 @@
+-- noinspection SqlNoDataSourceInspectionForFile
+
+drop trigger if exists audit_append_only;
+
+drop table if exists audit;
@@ -0,0 +1,26 @@
+-- noinspection SqlNoDataSourceInspectionForFile
+
+create table if not exists audit
+(
+    audit_id   integer primary key autoincrement,
+    user_id    text     not null default '',
+    session_id text     not null default '',
+    ip         text     not null default '',
+    action     text     not null,
+    item_id    integer  not null default 0,
+    target     text     not null default '',
+    created_at datetime not null default current_timestamp
+);
+
+create index if not exists audit_user on audit (user_id, created_at);
+
+create index if not exists audit_created on audit (created_at);
+
+-- the audit log is append-only: the events are removed only by the
+-- retention job and never changed.
+create trigger if not exists audit_append_only
+    before update
+    on audit
+begin
+    select raise(abort, 'the audit log is append-only');
+end;
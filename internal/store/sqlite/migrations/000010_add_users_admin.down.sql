@@ -0,0 +1,7 @@
+-- noinspection SqlNoDataSourceInspectionForFile
+
+drop table if exists users_quotas;
+
+alter table users drop column token_version;
+
+alter table users drop column admin;
@@ -0,0 +1,13 @@
+-- noinspection SqlNoDataSourceInspectionForFile
+
+alter table users add column admin integer not null default 0;
+
+alter table users add column token_version integer not null default 0;
+
+create table if not exists users_quotas
+(
+    user_id        text primary key,
+    max_bytes      integer not null default 0,
+    max_items      integer not null default 0,
+    max_item_bytes integer not null default 0
+);
@@ -0,0 +1,5 @@
+-- noinspection SqlNoDataSourceInspectionForFile
+
+delete from items_data where id in (select data_id from items_attachments);
+
+drop table if exists items_attachments;
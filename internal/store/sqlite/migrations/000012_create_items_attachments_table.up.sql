@@ -0,0 +1,12 @@
+-- noinspection SqlNoDataSourceInspectionForFile
+
+create table if not exists items_attachments
+(
+    id         integer primary key autoincrement,
+    item_id    integer  not null,
+    name       text     not null,
+    data_id    integer  not null,
+    size       integer  not null default 0,
+    created_at datetime not null default current_timestamp,
+    CONSTRAINT items_attachments_uniq UNIQUE (item_id, name)
+);
@@ -0,0 +1,9 @@
+-- noinspection SqlNoDataSourceInspectionForFile
+
+-- the ids of the items on the remote server, known to the cli only.
+create table if not exists items_remote
+(
+    item_id   integer primary key,
+    remote_id integer not null,
+    CONSTRAINT items_remote_uniq UNIQUE (remote_id)
+);
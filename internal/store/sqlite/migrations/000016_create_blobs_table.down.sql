@@ -0,0 +1,19 @@
+-- noinspection SqlNoDataSourceInspectionForFile
+
+-- the data saved to the storage in the database is copied back into
+-- the item data, where the previous versions keep it, before the blobs
+-- are dropped.
+update items_data
+set data = (with recursive chunks(seq, data) as
+                (select seq, data from blobs where hash = items_data.blob and seq = 0
+                 union all
+                 select b.seq, cast(chunks.data || b.data as blob)
+                 from chunks
+                          join blobs b on b.hash = items_data.blob and b.seq = chunks.seq + 1)
+            select data from chunks order by seq desc limit 1),
+    blob = null
+where blob in (select hash from blobs);
+
+delete from items_blobs where hash in (select hash from blobs);
+
+drop table if exists blobs;
@@ -0,0 +1,11 @@
+-- noinspection SqlNoDataSourceInspectionForFile
+
+-- the blobs of the storage in the database, the default one, split in
+-- chunks numbered from 0 so that a blob is never read as a whole.
+create table if not exists blobs
+(
+    hash text    not null,
+    seq  integer not null,
+    data blob    not null,
+    primary key (hash, seq)
+);
@@ -0,0 +1,7 @@
+-- noinspection SqlNoDataSourceInspectionForFile
+
+alter table users drop column totp_step;
+
+alter table users drop column totp_pending;
+
+alter table users drop column totp_secret;
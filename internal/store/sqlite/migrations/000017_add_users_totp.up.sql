@@ -0,0 +1,10 @@
+-- noinspection SqlNoDataSourceInspectionForFile
+
+-- the TOTP secret of the second factor, empty if it is disabled, the
+-- secret being enabled until a code of it is confirmed, and the step of
+-- the last code used, which cannot be used again.
+alter table users add column totp_secret text not null default '';
+
+alter table users add column totp_pending text not null default '';
+
+alter table users add column totp_step integer not null default 0;
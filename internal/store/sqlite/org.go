@@ -0,0 +1,274 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/iryzzh/y-gophkeeper/internal/models"
+	"github.com/iryzzh/y-gophkeeper/internal/store"
+	"github.com/mattn/go-sqlite3"
+	"github.com/pkg/errors"
+)
+
+type OrgRepository struct {
+	db *sql.DB
+}
+
+// Create creates the organization with the user as its owner.
+func (r *OrgRepository) Create(ctx context.Context, org *models.Org, ownerID string) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer rollback(ctx, tx)
+
+	if err = tx.QueryRowContext(ctx,
+		`insert into orgs (org_id, name) values ($1, $2) returning created_at`,
+		org.ID, org.Name).Scan(&org.CreatedAt); err != nil {
+		vErr, ok := errors.Cause(err).(sqlite3.Error)
+		if ok && vErr.ExtendedCode == sqlite3.ErrConstraintUnique {
+			return store.ErrOrgExists
+		}
+
+		return err
+	}
+
+	if _, err = tx.ExecContext(ctx,
+		`insert into orgs_members (org_id, user_id, role) values ($1, $2, $3)`,
+		org.ID, ownerID, models.RoleOwner); err != nil {
+		return err
+	}
+	org.Role = models.RoleOwner
+
+	return tx.Commit()
+}
+
+// FindByID returns the organization with the given id and the role
+// of the user in it. It returns `store.ErrOrgNotFound` if the user is
+// not a member of the organization.
+func (r *OrgRepository) FindByID(ctx context.Context, orgID, userID string) (*models.Org, error) {
+	org := &models.Org{}
+	err := r.db.QueryRowContext(ctx,
+		`select orgs.org_id, orgs.name, m.role, orgs.created_at from orgs
+			join orgs_members m on m.org_id = orgs.org_id and m.user_id = $1
+			where orgs.org_id = $2`,
+		userID, orgID).Scan(&org.ID, &org.Name, &org.Role, &org.CreatedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, store.ErrOrgNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return org, nil
+}
+
+// FindByUserID returns the organizations the user is a member of.
+func (r *OrgRepository) FindByUserID(ctx context.Context, userID string) ([]*models.Org, error) {
+	rows, err := r.db.QueryContext(ctx,
+		`select orgs.org_id, orgs.name, m.role, orgs.created_at from orgs
+			join orgs_members m on m.org_id = orgs.org_id
+			where m.user_id = $1
+			order by orgs.name`,
+		userID)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	orgs := make([]*models.Org, 0)
+	for rows.Next() {
+		org := &models.Org{}
+		if err = rows.Scan(&org.ID, &org.Name, &org.Role, &org.CreatedAt); err != nil {
+			return nil, err
+		}
+		orgs = append(orgs, org)
+	}
+
+	return orgs, rows.Err()
+}
+
+// Delete deletes the organization with its members. It returns
+// `store.ErrOrgNotEmpty` if the organization has collections.
+func (r *OrgRepository) Delete(ctx context.Context, orgID string) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer rollback(ctx, tx)
+
+	var collections bool
+	if err = tx.QueryRowContext(ctx,
+		`select exists (select 1 from orgs_collections where org_id = $1)`, orgID).Scan(&collections); err != nil {
+		return err
+	}
+	if collections {
+		return store.ErrOrgNotEmpty
+	}
+
+	if _, err = tx.ExecContext(ctx, `delete from orgs_members where org_id = $1`, orgID); err != nil {
+		return err
+	}
+
+	res, err := tx.ExecContext(ctx, `delete from orgs where org_id = $1`, orgID)
+	if err != nil {
+		return err
+	}
+	if rowsAffected, _ := res.RowsAffected(); rowsAffected == 0 {
+		return store.ErrOrgNotFound
+	}
+
+	return tx.Commit()
+}
+
+// Members returns the members of the organization.
+func (r *OrgRepository) Members(ctx context.Context, orgID string) ([]*models.Member, error) {
+	rows, err := r.db.QueryContext(ctx,
+		`select m.user_id, ifnull(u.login, ''), m.role from orgs_members m
+			left join users u on u.user_id = m.user_id
+			where m.org_id = $1
+			order by u.login`,
+		orgID)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	members := make([]*models.Member, 0)
+	for rows.Next() {
+		m := &models.Member{}
+		if err = rows.Scan(&m.UserID, &m.Login, &m.Role); err != nil {
+			return nil, err
+		}
+		members = append(members, m)
+	}
+
+	return members, rows.Err()
+}
+
+// SetMember adds the user to the organization or changes the role of
+// the member.
+func (r *OrgRepository) SetMember(ctx context.Context, orgID string, member *models.Member) error {
+	_, err := r.db.ExecContext(ctx,
+		`insert into orgs_members (org_id, user_id, role) values ($1, $2, $3)
+			on conflict (org_id, user_id) do update set role = excluded.role`,
+		orgID, member.UserID, member.Role)
+
+	return err
+}
+
+// RemoveMember removes the user from the organization.
+func (r *OrgRepository) RemoveMember(ctx context.Context, orgID, userID string) error {
+	res, err := r.db.ExecContext(ctx,
+		`delete from orgs_members where org_id = $1 and user_id = $2`, orgID, userID)
+	if err != nil {
+		return err
+	}
+	if rowsAffected, _ := res.RowsAffected(); rowsAffected == 0 {
+		return store.ErrMemberNotFound
+	}
+
+	return nil
+}
+
+// Role returns the role of the user in the organization.
+func (r *OrgRepository) Role(ctx context.Context, orgID, userID string) (models.Role, error) {
+	var role models.Role
+	err := r.db.QueryRowContext(ctx,
+		`select role from orgs_members where org_id = $1 and user_id = $2`,
+		orgID, userID).Scan(&role)
+	if errors.Is(err, sql.ErrNoRows) {
+		return "", store.ErrMemberNotFound
+	}
+
+	return role, err
+}
+
+// CreateCollection creates the collection in the organization.
+func (r *OrgRepository) CreateCollection(ctx context.Context, collection *models.Collection) error {
+	err := r.db.QueryRowContext(ctx,
+		`insert into orgs_collections (collection_id, org_id, name) values ($1, $2, $3) returning created_at`,
+		collection.ID, collection.OrgID, collection.Name).Scan(&collection.CreatedAt)
+	if err != nil {
+		vErr, ok := errors.Cause(err).(sqlite3.Error)
+		if ok && vErr.ExtendedCode == sqlite3.ErrConstraintUnique {
+			return store.ErrCollectionExists
+		}
+	}
+
+	return err
+}
+
+// FindCollection returns the collection with the given id.
+func (r *OrgRepository) FindCollection(ctx context.Context, collectionID string) (*models.Collection, error) {
+	c := &models.Collection{}
+	err := r.db.QueryRowContext(ctx,
+		`select collection_id, org_id, name, created_at from orgs_collections where collection_id = $1`,
+		collectionID).Scan(&c.ID, &c.OrgID, &c.Name, &c.CreatedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, store.ErrCollectionNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return c, nil
+}
+
+// Collections returns the collections of the organization.
+func (r *OrgRepository) Collections(ctx context.Context, orgID string) ([]*models.Collection, error) {
+	rows, err := r.db.QueryContext(ctx,
+		`select collection_id, org_id, name, created_at from orgs_collections
+			where org_id = $1
+			order by name`,
+		orgID)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	collections := make([]*models.Collection, 0)
+	for rows.Next() {
+		c := &models.Collection{}
+		if err = rows.Scan(&c.ID, &c.OrgID, &c.Name, &c.CreatedAt); err != nil {
+			return nil, err
+		}
+		collections = append(collections, c)
+	}
+
+	return collections, rows.Err()
+}
+
+// DeleteCollection deletes the collection with its tombstones. It
+// returns `store.ErrCollectionNotEmpty` if the collection has items,
+// including the items in its trash.
+func (r *OrgRepository) DeleteCollection(ctx context.Context, collectionID string) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer rollback(ctx, tx)
+
+	var items bool
+	if err = tx.QueryRowContext(ctx,
+		`select exists (select 1 from items where user_id = $1)`, collectionID).Scan(&items); err != nil {
+		return err
+	}
+	if items {
+		return store.ErrCollectionNotEmpty
+	}
+
+	if _, err = tx.ExecContext(ctx, `delete from items_tombstones where user_id = $1`, collectionID); err != nil {
+		return err
+	}
+
+	res, err := tx.ExecContext(ctx, `delete from orgs_collections where collection_id = $1`, collectionID)
+	if err != nil {
+		return err
+	}
+	if rowsAffected, _ := res.RowsAffected(); rowsAffected == 0 {
+		return store.ErrCollectionNotFound
+	}
+
+	return tx.Commit()
+}
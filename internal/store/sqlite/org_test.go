@@ -0,0 +1,103 @@
+package sqlite
+
+import (
+	"context"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/iryzzh/y-gophkeeper/internal/models"
+	"github.com/iryzzh/y-gophkeeper/internal/store"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOrgRepository(t *testing.T) {
+	db := setupStore(t)
+	defer func() { _ = db.Close() }()
+	r := &OrgRepository{db: db}
+	items := itemRepository(db)
+	users := &UserRepository{db: db}
+	ctx := context.Background()
+
+	owner, member, stranger := makeUser(t), makeUser(t), makeUser(t)
+	owner.Login, member.Login, stranger.Login = "owner", "member", "stranger"
+	for _, u := range []*models.User{owner, member, stranger} {
+		require.NoError(t, users.Create(ctx, u))
+	}
+
+	org := &models.Org{ID: uuid.New().String(), Name: "acme"}
+	require.NoError(t, r.Create(ctx, org, owner.ID))
+	require.Equal(t, models.RoleOwner, org.Role)
+	require.NotNil(t, org.CreatedAt)
+	require.ErrorIs(t, r.Create(ctx, &models.Org{ID: uuid.New().String(), Name: "acme"}, member.ID),
+		store.ErrOrgExists)
+
+	require.NoError(t, r.SetMember(ctx, org.ID, &models.Member{UserID: member.ID, Role: models.RoleMember}))
+	require.NoError(t, r.SetMember(ctx, org.ID, &models.Member{UserID: member.ID, Role: models.RoleAdmin}))
+
+	role, err := r.Role(ctx, org.ID, member.ID)
+	require.NoError(t, err)
+	require.Equal(t, models.RoleAdmin, role)
+	_, err = r.Role(ctx, org.ID, stranger.ID)
+	require.ErrorIs(t, err, store.ErrMemberNotFound)
+
+	found, err := r.FindByID(ctx, org.ID, member.ID)
+	require.NoError(t, err)
+	require.Equal(t, "acme", found.Name)
+	require.Equal(t, models.RoleAdmin, found.Role)
+	_, err = r.FindByID(ctx, org.ID, stranger.ID)
+	require.ErrorIs(t, err, store.ErrOrgNotFound)
+
+	orgs, err := r.FindByUserID(ctx, member.ID)
+	require.NoError(t, err)
+	require.Len(t, orgs, 1)
+	orgs, err = r.FindByUserID(ctx, stranger.ID)
+	require.NoError(t, err)
+	require.Empty(t, orgs)
+
+	members, err := r.Members(ctx, org.ID)
+	require.NoError(t, err)
+	require.Equal(t, []*models.Member{
+		{UserID: member.ID, Login: "member", Role: models.RoleAdmin},
+		{UserID: owner.ID, Login: "owner", Role: models.RoleOwner},
+	}, members)
+
+	c := &models.Collection{ID: uuid.New().String(), OrgID: org.ID, Name: "infra"}
+	require.NoError(t, r.CreateCollection(ctx, c))
+	require.ErrorIs(t, r.CreateCollection(ctx, &models.Collection{ID: uuid.New().String(), OrgID: org.ID, Name: "infra"}),
+		store.ErrCollectionExists)
+
+	foundCollection, err := r.FindCollection(ctx, c.ID)
+	require.NoError(t, err)
+	require.Equal(t, org.ID, foundCollection.OrgID)
+	_, err = r.FindCollection(ctx, "unknown")
+	require.ErrorIs(t, err, store.ErrCollectionNotFound)
+
+	collections, err := r.Collections(ctx, org.ID)
+	require.NoError(t, err)
+	require.Len(t, collections, 1)
+
+	// the items of the collection are owned by the collection.
+	it := sampleItem(t, c.ID)
+	require.NoError(t, items.Create(ctx, it))
+	_, err = items.FindByID(ctx, owner.ID, it.ID)
+	require.ErrorIs(t, err, store.ErrItemNotFound)
+
+	require.ErrorIs(t, r.Delete(ctx, org.ID), store.ErrOrgNotEmpty)
+	require.ErrorIs(t, r.DeleteCollection(ctx, c.ID), store.ErrCollectionNotEmpty)
+
+	require.NoError(t, items.Delete(ctx, it))
+	require.ErrorIs(t, r.DeleteCollection(ctx, c.ID), store.ErrCollectionNotEmpty)
+
+	_, err = items.Purge(ctx, c.ID, it.DeletedAt.Add(1))
+	require.NoError(t, err)
+	require.NoError(t, r.DeleteCollection(ctx, c.ID))
+	require.ErrorIs(t, r.DeleteCollection(ctx, c.ID), store.ErrCollectionNotFound)
+
+	require.NoError(t, r.RemoveMember(ctx, org.ID, member.ID))
+	require.ErrorIs(t, r.RemoveMember(ctx, org.ID, member.ID), store.ErrMemberNotFound)
+
+	require.NoError(t, r.Delete(ctx, org.ID))
+	require.ErrorIs(t, r.Delete(ctx, org.ID), store.ErrOrgNotFound)
+	_, err = r.Role(ctx, org.ID, owner.ID)
+	require.ErrorIs(t, err, store.ErrMemberNotFound)
+}
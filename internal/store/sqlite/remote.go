@@ -0,0 +1,53 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/iryzzh/y-gophkeeper/internal/store"
+	"github.com/pkg/errors"
+)
+
+// SetRemoteID records the id of the item of the user on the remote
+// server. The item previously recorded with the remote id is unlinked.
+func (r *ItemRepository) SetRemoteID(ctx context.Context, userID string, id, remoteID int) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer rollback(ctx, tx)
+
+	if _, err = tx.ExecContext(ctx, `delete from items_remote where remote_id = $1`, remoteID); err != nil {
+		return err
+	}
+
+	res, err := tx.ExecContext(ctx,
+		`insert into items_remote (item_id, remote_id)
+			select id, $1 from items where id = $2 and user_id = $3
+			on conflict (item_id) do update set remote_id = excluded.remote_id`,
+		remoteID, id, userID)
+	if err != nil {
+		return err
+	}
+	if rowsAffected, _ := res.RowsAffected(); rowsAffected == 0 {
+		return store.ErrItemNotFound
+	}
+
+	return tx.Commit()
+}
+
+// FindByRemoteID returns the id of the item of the user with the given
+// id on the remote server.
+func (r *ItemRepository) FindByRemoteID(ctx context.Context, userID string, remoteID int) (int, error) {
+	var id int
+	err := r.db.QueryRowContext(ctx,
+		`select items.id from items_remote
+			join items on items.id = items_remote.item_id
+			where items_remote.remote_id = $1 and items.user_id = $2`,
+		remoteID, userID).Scan(&id)
+	if errors.Is(err, sql.ErrNoRows) {
+		return 0, store.ErrItemNotFound
+	}
+
+	return id, err
+}
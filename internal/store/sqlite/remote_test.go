@@ -0,0 +1,46 @@
+package sqlite
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/iryzzh/y-gophkeeper/internal/store"
+	"github.com/stretchr/testify/require"
+)
+
+func TestItemRepository_RemoteID(t *testing.T) {
+	r := itemRepository(setupStore(t))
+	defer func() { _ = r.db.Close() }()
+	ctx := context.Background()
+
+	userID := uuid.NewString()
+	first, second := sampleItem(t, userID), sampleItem(t, userID)
+	require.NoError(t, r.Create(ctx, first))
+	require.NoError(t, r.Create(ctx, second))
+
+	_, err := r.FindByRemoteID(ctx, userID, 42)
+	require.ErrorIs(t, err, store.ErrItemNotFound)
+	require.ErrorIs(t, r.SetRemoteID(ctx, uuid.NewString(), first.ID, 42), store.ErrItemNotFound)
+
+	require.NoError(t, r.SetRemoteID(ctx, userID, first.ID, 42))
+	id, err := r.FindByRemoteID(ctx, userID, 42)
+	require.NoError(t, err)
+	require.Equal(t, first.ID, id)
+	_, err = r.FindByRemoteID(ctx, uuid.NewString(), 42)
+	require.ErrorIs(t, err, store.ErrItemNotFound)
+
+	// the remote id is moved to the item linked last.
+	require.NoError(t, r.SetRemoteID(ctx, userID, second.ID, 42))
+	require.NoError(t, r.SetRemoteID(ctx, userID, first.ID, 7))
+	id, err = r.FindByRemoteID(ctx, userID, 42)
+	require.NoError(t, err)
+	require.Equal(t, second.ID, id)
+
+	require.NoError(t, r.Delete(ctx, second))
+	_, err = r.Purge(ctx, userID, time.Now().Add(time.Minute))
+	require.NoError(t, err)
+	_, err = r.FindByRemoteID(ctx, userID, 42)
+	require.ErrorIs(t, err, store.ErrItemNotFound)
+}
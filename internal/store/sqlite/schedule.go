@@ -0,0 +1,38 @@
+package sqlite
+
+import (
+	"context"
+
+	"github.com/iryzzh/y-gophkeeper/internal/models"
+)
+
+// Scheduled returns the items of the user with an expiry date or a
+// rotation period, without their data. If the user id is empty, the
+// items of every user are returned. The items in the trash are
+// skipped.
+func (r *ItemRepository) Scheduled(ctx context.Context, userID string) ([]*models.Item, error) {
+	rows, err := r.db.QueryContext(ctx,
+		`select items.id, items.user_id, items.meta, items.data_type, items.created_at, items.updated_at,
+				s.expires_at, s.rotate_days
+			from items
+			join items_schedule s on s.item_id = items.id
+			where ($1 = '' or items.user_id = $1) and items.deleted_at is null
+			order by items.id`,
+		userID)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	items := make([]*models.Item, 0)
+	for rows.Next() {
+		it := &models.Item{}
+		if err = rows.Scan(&it.ID, &it.UserID, &it.Meta, &it.DataType, &it.CreatedAt, &it.UpdatedAt,
+			&it.ExpiresAt, &it.RotateDays); err != nil {
+			return nil, err
+		}
+		items = append(items, it)
+	}
+
+	return items, rows.Err()
+}
@@ -0,0 +1,64 @@
+package sqlite
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/require"
+)
+
+func TestItemRepository_Scheduled(t *testing.T) {
+	r := itemRepository(setupStore(t))
+	defer func() { _ = r.db.Close() }()
+
+	alice, bob := uuid.NewString(), uuid.NewString()
+	expires := time.Date(2030, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	expiring := sampleItem(t, alice)
+	expiring.ExpiresAt = &expires
+	require.NoError(t, r.Create(context.Background(), expiring))
+
+	rotated := sampleItem(t, bob)
+	rotated.RotateDays = 90
+	require.NoError(t, r.Create(context.Background(), rotated))
+
+	require.NoError(t, r.Create(context.Background(), sampleItem(t, alice)))
+
+	found, err := r.FindByID(context.Background(), alice, expiring.ID)
+	require.NoError(t, err)
+	require.True(t, expires.Equal(*found.ExpiresAt))
+	require.Zero(t, found.RotateDays)
+
+	items, err := r.Scheduled(context.Background(), "")
+	require.NoError(t, err)
+	require.Len(t, items, 2)
+	require.Equal(t, expiring.Meta, items[0].Meta)
+	require.True(t, expires.Equal(*items[0].ExpiresAt))
+	require.Nil(t, items[0].ItemData)
+	require.Equal(t, 90, items[1].RotateDays)
+	require.Nil(t, items[1].ExpiresAt)
+
+	items, err = r.Scheduled(context.Background(), bob)
+	require.NoError(t, err)
+	require.Len(t, items, 1)
+	require.Equal(t, rotated.ID, items[0].ID)
+
+	rotated.RotateDays = 0
+	require.NoError(t, r.Update(context.Background(), rotated))
+	items, err = r.Scheduled(context.Background(), bob)
+	require.NoError(t, err)
+	require.Empty(t, items)
+
+	require.NoError(t, r.Delete(context.Background(), expiring))
+	items, err = r.Scheduled(context.Background(), "")
+	require.NoError(t, err)
+	require.Empty(t, items)
+
+	_, err = r.Purge(context.Background(), alice, time.Now())
+	require.NoError(t, err)
+	var count int
+	require.NoError(t, r.db.QueryRow(`select count(*) from items_schedule`).Scan(&count))
+	require.Zero(t, count)
+}
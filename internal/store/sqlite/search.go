@@ -0,0 +1,181 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+	"unicode"
+
+	"github.com/iryzzh/y-gophkeeper/internal/models"
+	"github.com/iryzzh/y-gophkeeper/internal/store"
+)
+
+// dateLayout is the layout in which sqlite stores `current_timestamp`.
+const dateLayout = "2006-01-02 15:04:05"
+
+// indexColumns selects the indexed columns of the items: the name
+// and the metadata made of the tags and the non-secret fields, as
+// the migration creating the index does.
+const indexColumns = `id, meta,
+	coalesce((select group_concat(tag, ' ') from items_tags where item_id = items.id), '') || ' ' ||
+	coalesce((select group_concat(name || ' ' || value, ' ') from items_fields
+		where item_id = items.id and not secret), '')`
+
+// checkFTS5 checks that the sqlite3 driver supports FTS5, which the
+// full-text index of the items is created with: the driver must be
+// built with the `sqlite_fts5` tag.
+func (s *Store) checkFTS5() error {
+	var fts5 bool
+	if err := s.db.QueryRow(`select sqlite_compileoption_used('ENABLE_FTS5')`).Scan(&fts5); err != nil {
+		return err
+	}
+	if !fts5 {
+		return store.ErrFTS5NotSupported
+	}
+
+	return nil
+}
+
+// index updates the full-text index entry of the item with the given id.
+func index(ctx context.Context, tx *sql.Tx, id int) error {
+	if _, err := tx.ExecContext(ctx, `delete from items_fts where rowid = $1`, id); err != nil {
+		return err
+	}
+
+	_, err := tx.ExecContext(ctx,
+		`insert into items_fts (rowid, meta, metadata) select `+indexColumns+` from items where id = $1`, id)
+
+	return err
+}
+
+// Search returns the items of the user matching the filter. Items
+// whose name matches the query exactly come first, followed by full
+// word and prefix matches, followed by fuzzy matches.
+func (r *ItemRepository) Search(ctx context.Context, userID string, filter *models.ItemFilter) (*models.Items, error) {
+	where := []string{`items.user_id = ?`, `items.deleted_at is null`}
+	args := []interface{}{userID}
+	order := `items.meta`
+	var orderArgs []interface{}
+
+	if query := strings.TrimSpace(filter.Query); query != "" {
+		fts, ftsArgs := `0`, []interface{}{}
+		if match := matchExpression(query, filter.WithMetadata); match != "" {
+			fts, ftsArgs = `items.id in (select rowid from items_fts where items_fts match ?)`, []interface{}{match}
+		}
+
+		where = append(where, `(`+fts+` or lower(items.meta) like ? escape '\')`)
+		args = append(append(args, ftsArgs...), fuzzyPattern(query))
+
+		order = `case when lower(items.meta) = lower(?) then 0 when ` + fts + ` then 1 else 2 end,
+			length(items.meta), items.meta`
+		orderArgs = append([]interface{}{query}, ftsArgs...)
+	}
+
+	if len(filter.Tags) > 0 {
+		where = append(where, `items.id in (select item_id from items_tags where tag in (?`+
+			strings.Repeat(`, ?`, len(filter.Tags)-1)+`) group by item_id having count(*) = ?)`)
+		for _, t := range filter.Tags {
+			args = append(args, t)
+		}
+		args = append(args, len(filter.Tags))
+	}
+
+	if filter.Folder != "" {
+		where = append(where, `(items.meta = ? or substr(items.meta, 1, length(?) + 1) = ? || '/')`)
+		args = append(args, filter.Folder, filter.Folder, filter.Folder)
+	}
+
+	if len(filter.Types) > 0 {
+		where = append(where, `items.data_type in (?`+strings.Repeat(`, ?`, len(filter.Types)-1)+`)`)
+		for _, t := range filter.Types {
+			args = append(args, t)
+		}
+	}
+
+	if filter.Since != nil {
+		where = append(where, `coalesce(items.updated_at, items.created_at) >= ?`)
+		args = append(args, filter.Since.UTC().Format(dateLayout))
+	}
+
+	if filter.Until != nil {
+		where = append(where, `coalesce(items.updated_at, items.created_at) <= ?`)
+		args = append(args, filter.Until.UTC().Format(dateLayout))
+	}
+
+	limit := filter.Limit
+	if limit <= 0 {
+		limit = -1
+	}
+
+	args = append(args, orderArgs...)
+	args = append(args, limit, filter.Offset)
+
+	//nolint:gosec // the conditions contain only placeholders.
+	rows, err := r.db.QueryContext(ctx,
+		`select items.id, items.user_id, items.meta, items.data_id, items.data_type, items.created_at,
+       			items.updated_at, items.deleted_at, ifnull(idt.id, 0), idt.data, count(*) over ()
+				from items
+				left join items_data idt on idt.id = items.data_id
+				where `+strings.Join(where, ` and `)+`
+				order by `+order+`
+				limit ? offset ?`,
+		args...)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	items, total, err := scanItems(rows)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(items) == 0 {
+		return nil, store.ErrItemNotFound
+	}
+
+	return &models.Items{
+		Meta: models.Meta{TotalItems: total},
+		Data: items,
+	}, r.load(ctx, userID, items...)
+}
+
+// matchExpression converts the query into a full-text expression in
+// which every word of the query must match as a prefix. Unless
+// withMetadata is set, only the item name is matched.
+func matchExpression(query string, withMetadata bool) string {
+	words := strings.FieldsFunc(strings.ToLower(query), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+
+	terms := make([]string, 0, len(words))
+	for _, w := range words {
+		if withMetadata {
+			terms = append(terms, w+"*")
+		} else {
+			terms = append(terms, "meta:"+w+"*")
+		}
+	}
+
+	return strings.Join(terms, " ")
+}
+
+// fuzzyPattern returns a `like` pattern matching names that contain
+// the characters of the query in the same order, e.g. "gml" matches
+// "google/mail".
+func fuzzyPattern(query string) string {
+	var b strings.Builder
+	b.WriteByte('%')
+	for _, r := range strings.ToLower(query) {
+		if unicode.IsSpace(r) {
+			continue
+		}
+		if r == '%' || r == '_' || r == '\\' {
+			b.WriteByte('\\')
+		}
+		b.WriteRune(r)
+		b.WriteByte('%')
+	}
+
+	return b.String()
+}
@@ -0,0 +1,169 @@
+package sqlite
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/iryzzh/y-gophkeeper/internal/models"
+	"github.com/iryzzh/y-gophkeeper/internal/store"
+	"github.com/iryzzh/y-gophkeeper/internal/utils"
+	"github.com/stretchr/testify/require"
+)
+
+func TestItemRepository_Search(t *testing.T) {
+	r := itemRepository(setupStore(t))
+	defer func() { _ = r.db.Close() }()
+
+	userID := uuid.NewString()
+	for _, it := range []*models.Item{
+		{Meta: "google/mail", DataType: models.EntryTypeText},
+		{Meta: "google", DataType: models.EntryTypeText},
+		{Meta: "gmail", DataType: models.EntryTypeText},
+		{Meta: "work/gitlab", DataType: models.EntryTypeText},
+		{Meta: "cards/visa", DataType: models.EntryTypeCard},
+		{Meta: "documents/passport", DataType: models.EntryTypeImage},
+	} {
+		it.UserID = userID
+		it.ItemData = &models.ItemData{Data: []byte("data")}
+		require.NoError(t, r.Create(context.Background(), it))
+	}
+	require.NoError(t, r.Create(context.Background(), &models.Item{UserID: uuid.NewString(), Meta: "google"}))
+
+	future := time.Now().Add(24 * time.Hour)
+	past := time.Now().Add(-24 * time.Hour)
+
+	tests := []struct {
+		name    string
+		filter  *models.ItemFilter
+		want    []string
+		wantErr error
+	}{
+		{
+			name:   "exact match first",
+			filter: &models.ItemFilter{Query: "google"},
+			want:   []string{"google", "google/mail"},
+		},
+		{
+			name:   "prefix",
+			filter: &models.ItemFilter{Query: "pass"},
+			want:   []string{"documents/passport"},
+		},
+		{
+			name:   "prefix before fuzzy",
+			filter: &models.ItemFilter{Query: "mail"},
+			want:   []string{"google/mail", "gmail"},
+		},
+		{
+			name:   "fuzzy",
+			filter: &models.ItemFilter{Query: "gml"},
+			want:   []string{"gmail", "google/mail"},
+		},
+		{
+			name:   "type",
+			filter: &models.ItemFilter{Types: []string{models.EntryTypeCard, models.EntryTypeImage}},
+			want:   []string{"cards/visa", "documents/passport"},
+		},
+		{
+			name:    "query and type",
+			filter:  &models.ItemFilter{Query: "visa", Types: []string{models.EntryTypeText}},
+			wantErr: store.ErrItemNotFound,
+		},
+		{
+			name:   "limit",
+			filter: &models.ItemFilter{Query: "google", Limit: 1},
+			want:   []string{"google"},
+		},
+		{
+			name:   "since",
+			filter: &models.ItemFilter{Query: "visa", Since: &past},
+			want:   []string{"cards/visa"},
+		},
+		{
+			name:    "since in the future",
+			filter:  &models.ItemFilter{Query: "visa", Since: &future},
+			wantErr: store.ErrItemNotFound,
+		},
+		{
+			name:    "until in the past",
+			filter:  &models.ItemFilter{Query: "visa", Until: &past},
+			wantErr: store.ErrItemNotFound,
+		},
+		{
+			name:    "not found",
+			filter:  &models.ItemFilter{Query: "unknown"},
+			wantErr: store.ErrItemNotFound,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			items, err := r.Search(context.Background(), userID, tt.filter)
+			require.ErrorIs(t, err, tt.wantErr)
+			if tt.wantErr != nil {
+				return
+			}
+
+			var got []string
+			for _, it := range items.Data {
+				got = append(got, it.Meta)
+			}
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestItemRepository_SearchIndex(t *testing.T) {
+	r := itemRepository(setupStore(t))
+	defer func() { _ = r.db.Close() }()
+
+	it := sampleItem(t, uuid.NewString())
+	it.Meta = "old/name"
+	require.NoError(t, r.Create(context.Background(), it))
+
+	it.Meta = "new/name"
+	require.NoError(t, r.Update(context.Background(), it))
+
+	_, err := r.Search(context.Background(), it.UserID, &models.ItemFilter{Query: "old"})
+	require.ErrorIs(t, err, store.ErrItemNotFound)
+
+	found, err := r.Search(context.Background(), it.UserID, &models.ItemFilter{Query: "new"})
+	require.NoError(t, err)
+	require.Equal(t, it.ID, found.Data[0].ID)
+
+	require.NoError(t, r.Delete(context.Background(), it))
+
+	_, err = r.Search(context.Background(), it.UserID, &models.ItemFilter{Query: "new"})
+	require.ErrorIs(t, err, store.ErrItemNotFound)
+}
+
+// TestStore_searchIndexMigration checks that the migration replaces an
+// existing index with the FTS5 one built from the items.
+func TestStore_searchIndexMigration(t *testing.T) {
+	cfg, err := utils.TestConfig(t)
+	require.NoError(t, err)
+	st, err := NewStore(cfg.DB.DSN, cfg.DB.MigrationsPath)
+	require.NoError(t, err)
+	defer func() { _ = st.Close() }()
+
+	r := itemRepository(st.db)
+	it := &models.Item{UserID: uuid.NewString(), Meta: "google/mail"}
+	require.NoError(t, r.Create(context.Background(), it))
+
+	// roll back to the version before the migration creating the index.
+	status, err := st.Migrations()
+	require.NoError(t, err)
+	require.NoError(t, st.MigrateDown(int(status.Latest)-14))
+	_, err = st.db.Exec(`create virtual table items_fts using fts4(meta, metadata)`)
+	require.NoError(t, err)
+	require.NoError(t, st.MigrateUp())
+
+	var module string
+	require.NoError(t, st.db.QueryRow(`select sql from sqlite_master where name = 'items_fts'`).Scan(&module))
+	require.Contains(t, module, "fts5")
+
+	found, err := r.Search(context.Background(), it.UserID, &models.ItemFilter{Query: "mail"})
+	require.NoError(t, err)
+	require.Len(t, found.Data, 1)
+	require.Equal(t, it.ID, found.Data[0].ID)
+}
@@ -0,0 +1,173 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+
+	"github.com/iryzzh/y-gophkeeper/internal/models"
+	"github.com/iryzzh/y-gophkeeper/internal/store"
+	"github.com/pkg/errors"
+)
+
+// Share grants the user access to the item with the item key sealed
+// for the user. The access of a user the item is already shared with
+// is replaced.
+func (r *ItemRepository) Share(ctx context.Context, itemID int, userID string, key []byte, readOnly bool) error {
+	_, err := r.db.ExecContext(ctx,
+		`insert into items_keys (item_id, user_id, item_key, read_only) values ($1, $2, $3, $4)
+			on conflict (item_id, user_id) do update set item_key = excluded.item_key, read_only = excluded.read_only`,
+		itemID, userID, key, readOnly)
+
+	return err
+}
+
+// Unshare revokes the access of the user to the item.
+func (r *ItemRepository) Unshare(ctx context.Context, itemID int, userID string) error {
+	res, err := r.db.ExecContext(ctx,
+		`delete from items_keys where item_id = $1 and user_id = $2
+			and user_id != (select user_id from items where id = $1)`,
+		itemID, userID)
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected, _ := res.RowsAffected(); rowsAffected == 0 {
+		return store.ErrItemNotFound
+	}
+
+	return nil
+}
+
+// Shared returns the items of the other users shared with the user.
+func (r *ItemRepository) Shared(ctx context.Context, userID string) (*models.Items, error) {
+	rows, err := r.db.QueryContext(ctx,
+		`select items.id, items.user_id, items.meta, items.data_id, items.data_type, items.created_at,
+       			items.updated_at, items.deleted_at, ifnull(idt.id, 0), idt.data, count(*) over ()
+				from items
+				join items_keys k on k.item_id = items.id and k.user_id = $1
+				left join items_data idt on idt.id = items.data_id
+				where items.user_id != $1 and items.deleted_at is null
+				order by items.meta, items.id`,
+		userID)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	items, total, err := scanItems(rows)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(items) == 0 {
+		return nil, store.ErrItemNotFound
+	}
+
+	return &models.Items{
+		Meta: models.Meta{TotalItems: total},
+		Data: items,
+	}, r.load(ctx, userID, items...)
+}
+
+// writableBy returns the owner of the item if the user can modify it,
+// that is if the user owns the item or the item is shared with the
+// user in read-write mode.
+func writableBy(ctx context.Context, tx *sql.Tx, itemID int, userID string) (string, error) {
+	var owner string
+	var readOnly sql.NullBool
+	err := tx.QueryRowContext(ctx,
+		`select items.user_id, k.read_only from items
+			left join items_keys k on k.item_id = items.id and k.user_id = $1
+			where items.id = $2 and items.deleted_at is null`,
+		userID, itemID).Scan(&owner, &readOnly)
+	if errors.Is(err, sql.ErrNoRows) {
+		return "", store.ErrItemNotFound
+	}
+	if err != nil {
+		return "", err
+	}
+
+	switch {
+	case owner == userID:
+		return owner, nil
+	case !readOnly.Valid:
+		return "", store.ErrItemNotFound
+	case readOnly.Bool:
+		return "", store.ErrItemReadOnly
+	}
+
+	return owner, nil
+}
+
+// saveKey saves the item key sealed for the user.
+func saveKey(ctx context.Context, tx *sql.Tx, itemID int, userID string, key []byte) error {
+	_, err := tx.ExecContext(ctx,
+		`insert into items_keys (item_id, user_id, item_key) values ($1, $2, $3)
+			on conflict (item_id, user_id) do update set item_key = excluded.item_key`,
+		itemID, userID, key)
+
+	return err
+}
+
+// load fills in the metadata of the items and the access of the user
+// to them.
+func (r *ItemRepository) load(ctx context.Context, userID string, items ...*models.Item) error {
+	if err := r.loadMetadata(ctx, items...); err != nil {
+		return err
+	}
+	if err := r.loadAttachments(ctx, items...); err != nil {
+		return err
+	}
+	if err := r.loadBlobs(ctx, items...); err != nil {
+		return err
+	}
+
+	return r.loadKeys(ctx, userID, items...)
+}
+
+// loadKeys fills in the item keys sealed for the user and, for the
+// items of the other users, the access mode and the login of the owner.
+func (r *ItemRepository) loadKeys(ctx context.Context, userID string, items ...*models.Item) error {
+	if len(items) == 0 {
+		return nil
+	}
+
+	byID := make(map[int]*models.Item, len(items))
+	args := make([]interface{}, 0, len(items)+1)
+	args = append(args, userID)
+	for _, it := range items {
+		byID[it.ID] = it
+		args = append(args, it.ID)
+	}
+
+	rows, err := r.db.QueryContext(ctx, //nolint:gosec // only placeholders are added.
+		`select k.item_id, k.item_key, k.read_only, ifnull(u.login, '') from items_keys k
+			join items on items.id = k.item_id
+			left join users u on u.user_id = items.user_id
+			where k.user_id = ? and k.item_id in (?`+strings.Repeat(`, ?`, len(items)-1)+`)`,
+		args...)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = rows.Close() }()
+
+	for rows.Next() {
+		var id int
+		var key []byte
+		var readOnly bool
+		var owner string
+		if err = rows.Scan(&id, &key, &readOnly, &owner); err != nil {
+			return err
+		}
+
+		it := byID[id]
+		it.Key = key
+		if it.UserID != userID {
+			it.ReadOnly = readOnly
+			it.Owner = owner
+		}
+	}
+
+	return rows.Err()
+}
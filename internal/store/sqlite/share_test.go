@@ -0,0 +1,131 @@
+package sqlite
+
+import (
+	"context"
+	"testing"
+
+	"github.com/iryzzh/y-gophkeeper/internal/models"
+	"github.com/iryzzh/y-gophkeeper/internal/store"
+	"github.com/stretchr/testify/require"
+)
+
+func TestItemRepository_Share(t *testing.T) {
+	db := setupStore(t)
+	defer func() { _ = db.Close() }()
+	r := itemRepository(db)
+	users := &UserRepository{db: db}
+	ctx := context.Background()
+
+	owner, reader, writer, stranger := makeUser(t), makeUser(t), makeUser(t), makeUser(t)
+	owner.Login, reader.Login, writer.Login, stranger.Login = "owner", "reader", "writer", "stranger"
+	for _, u := range []*models.User{owner, reader, writer, stranger} {
+		require.NoError(t, users.Create(ctx, u))
+	}
+
+	it := sampleItem(t, owner.ID)
+	it.Key = []byte("owner key")
+	require.NoError(t, r.Create(ctx, it))
+
+	require.NoError(t, r.Share(ctx, it.ID, reader.ID, []byte("reader key"), true))
+	require.NoError(t, r.Share(ctx, it.ID, writer.ID, []byte("writer key"), false))
+
+	found, err := r.FindByID(ctx, owner.ID, it.ID)
+	require.NoError(t, err)
+	require.Equal(t, []byte("owner key"), found.Key)
+	require.Empty(t, found.Owner)
+
+	found, err = r.FindByID(ctx, reader.ID, it.ID)
+	require.NoError(t, err)
+	require.Equal(t, []byte("reader key"), found.Key)
+	require.Equal(t, "owner", found.Owner)
+	require.True(t, found.ReadOnly)
+
+	_, err = r.FindByID(ctx, stranger.ID, it.ID)
+	require.ErrorIs(t, err, store.ErrItemNotFound)
+
+	// the shared items are not part of the vault of the recipient.
+	_, err = r.FindByUserID(ctx, reader.ID, 10, 0)
+	require.ErrorIs(t, err, store.ErrItemNotFound)
+
+	shared, err := r.Shared(ctx, writer.ID)
+	require.NoError(t, err)
+	require.Len(t, shared.Data, 1)
+	require.Equal(t, it.Meta, shared.Data[0].Meta)
+	require.Equal(t, []byte("writer key"), shared.Data[0].Key)
+	require.False(t, shared.Data[0].ReadOnly)
+
+	_, err = r.Shared(ctx, owner.ID)
+	require.ErrorIs(t, err, store.ErrItemNotFound)
+
+	update := func(userID string) error {
+		found, err := r.FindByID(ctx, owner.ID, it.ID)
+		require.NoError(t, err)
+		found.UserID = userID
+		found.Key = nil
+		found.ItemData.Data = []byte("changed by " + userID)
+
+		return r.Update(ctx, found)
+	}
+	require.ErrorIs(t, update(reader.ID), store.ErrItemReadOnly)
+	require.ErrorIs(t, update(stranger.ID), store.ErrItemNotFound)
+	require.NoError(t, update(writer.ID))
+
+	found, err = r.FindByID(ctx, owner.ID, it.ID)
+	require.NoError(t, err)
+	require.Equal(t, []byte("changed by "+writer.ID), found.ItemData.Data)
+	require.Equal(t, owner.ID, found.UserID)
+	require.Equal(t, []byte("owner key"), found.Key)
+
+	// the writer can neither overwrite the data of the other items of
+	// the owner nor point the shared item at it.
+	other := sampleItem(t, owner.ID)
+	require.NoError(t, r.Create(ctx, other))
+	found, err = r.FindByID(ctx, writer.ID, it.ID)
+	require.NoError(t, err)
+	found.UserID, found.Key = writer.ID, nil
+	found.DataID, found.ItemData = other.DataID, &models.ItemData{ID: other.DataID, Data: []byte("overwritten")}
+	require.ErrorIs(t, r.Update(ctx, found), store.ErrItemDataNotFound)
+	found.ItemData = nil
+	require.NoError(t, r.Update(ctx, found))
+
+	found, err = r.FindByID(ctx, owner.ID, other.ID)
+	require.NoError(t, err)
+	require.Equal(t, other.ItemData.Data, found.ItemData.Data)
+	found, err = r.FindByID(ctx, owner.ID, it.ID)
+	require.NoError(t, err)
+	require.Equal(t, it.DataID, found.DataID)
+	require.Equal(t, []byte("changed by "+writer.ID), found.ItemData.Data)
+
+	require.NoError(t, r.Unshare(ctx, it.ID, reader.ID))
+	require.ErrorIs(t, r.Unshare(ctx, it.ID, reader.ID), store.ErrItemNotFound)
+	require.ErrorIs(t, r.Unshare(ctx, it.ID, owner.ID), store.ErrItemNotFound)
+	_, err = r.FindByID(ctx, reader.ID, it.ID)
+	require.ErrorIs(t, err, store.ErrItemNotFound)
+
+	// the recipients lose the access to the trashed items.
+	require.NoError(t, r.Delete(ctx, it))
+	_, err = r.Shared(ctx, writer.ID)
+	require.ErrorIs(t, err, store.ErrItemNotFound)
+}
+
+func TestUserRepository_PublicKey(t *testing.T) {
+	r := &UserRepository{
+		db: setupStore(t),
+	}
+	defer func() { _ = r.db.Close() }()
+	ctx := context.Background()
+
+	u := makeUser(t)
+	require.NoError(t, r.Create(ctx, u))
+
+	_, err := r.FindPublicKey(ctx, u.ID)
+	require.ErrorIs(t, err, store.ErrPublicKeyNotFound)
+
+	require.NoError(t, r.SetPublicKey(ctx, u.ID, []byte("public key")))
+	require.NoError(t, r.SetPublicKey(ctx, u.ID, []byte("public key")))
+	require.ErrorIs(t, r.SetPublicKey(ctx, u.ID, []byte("another key")), store.ErrPublicKeyExists)
+
+	key, err := r.FindPublicKey(ctx, u.ID)
+	require.NoError(t, err)
+	require.Equal(t, []byte("public key"), key)
+}
@@ -0,0 +1,154 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"io"
+
+	"github.com/google/uuid"
+	"github.com/iryzzh/y-gophkeeper/internal/blob"
+	"github.com/pkg/errors"
+)
+
+// blobChunkSize is the size of the chunks of the blobs in the database.
+const blobChunkSize = 1 << 20
+
+// txKey is the key of the transaction in the context of the calls to
+// the storage of the blobs in the database.
+type txKey struct{}
+
+// withTx returns the context in which the storage of the blobs in the
+// database runs its queries in the transaction, so that it can be used
+// while the transaction holds the database.
+func withTx(ctx context.Context, tx *sql.Tx) context.Context {
+	return context.WithValue(ctx, txKey{}, tx)
+}
+
+// dbStorage is the storage of the blobs in the database of the store.
+// The blobs are split in chunks, so that a blob is never held in memory
+// as a whole: the chunk with the sequence number 0 always exists.
+type dbStorage struct {
+	db *sql.DB
+}
+
+// DBStorage returns the storage of the blobs in the database, the
+// default storage of the item data.
+func (s *Store) DBStorage() blob.Storage {
+	return &dbStorage{db: s.db}
+}
+
+// Put inserts the chunks of the content under a temporary key while
+// hashing it, then renames them to the key of the content, unless it
+// exists.
+func (s *dbStorage) Put(ctx context.Context, r io.Reader) (string, int64, error) {
+	tmp := "tmp-" + uuid.NewString()
+	defer func() { _ = s.Delete(ctx, tmp) }()
+
+	h := blob.Hasher()
+	r = io.TeeReader(r, h)
+	chunk := make([]byte, blobChunkSize)
+	var size int64
+	for seq := 0; ; seq++ {
+		n, err := io.ReadFull(r, chunk)
+		if err != nil && !errors.Is(err, io.EOF) && !errors.Is(err, io.ErrUnexpectedEOF) {
+			return "", 0, err
+		}
+		if n > 0 || seq == 0 {
+			if _, err := s.conn(ctx).ExecContext(ctx,
+				`insert into blobs (hash, seq, data) values ($1, $2, $3)`, tmp, seq, chunk[:n]); err != nil {
+				return "", 0, err
+			}
+		}
+		size += int64(n)
+		if n < len(chunk) {
+			break
+		}
+	}
+
+	key := blob.HashKey(h)
+	if _, err := s.conn(ctx).ExecContext(ctx,
+		`update blobs set hash = $1 where hash = $2 and not exists (select 1 from blobs where hash = $1)`,
+		key, tmp); err != nil {
+		return "", 0, err
+	}
+
+	return key, size, nil
+}
+
+// Get returns the reader of the chunks of the key, which selects them
+// one by one.
+func (s *dbStorage) Get(ctx context.Context, key string) (io.ReadCloser, error) {
+	c := &chunkReader{ctx: ctx, s: s, key: key}
+	if err := c.next(); err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil, errors.Wrapf(blob.ErrNotFound, "'%v'", key)
+		}
+		return nil, err
+	}
+
+	return c, nil
+}
+
+// Delete deletes the data of the key, if any.
+func (s *dbStorage) Delete(ctx context.Context, key string) error {
+	_, err := s.conn(ctx).ExecContext(ctx, `delete from blobs where hash = $1`, key)
+
+	return err
+}
+
+// chunkReader reads the chunks of the blob with the key in order.
+type chunkReader struct {
+	ctx   context.Context //nolint:containedctx // the chunks are selected while reading.
+	s     *dbStorage
+	key   string
+	seq   int
+	chunk []byte
+}
+
+// Read reads the chunk selected last, then selects the next one.
+func (c *chunkReader) Read(p []byte) (int, error) {
+	for len(c.chunk) == 0 {
+		if err := c.next(); err != nil {
+			return 0, err
+		}
+	}
+
+	n := copy(p, c.chunk)
+	c.chunk = c.chunk[n:]
+
+	return n, nil
+}
+
+// next selects the next chunk, `io.EOF` if there is none.
+func (c *chunkReader) next() error {
+	err := c.s.conn(c.ctx).QueryRowContext(c.ctx,
+		`select data from blobs where hash = $1 and seq = $2`, c.key, c.seq).Scan(&c.chunk)
+	if errors.Is(err, sql.ErrNoRows) {
+		return io.EOF
+	}
+	if err != nil {
+		return err
+	}
+	c.seq++
+
+	return nil
+}
+
+// Close releases the chunk selected last.
+func (c *chunkReader) Close() error {
+	c.chunk = nil
+
+	return nil
+}
+
+// conn returns the transaction of the context, if any, or the database.
+func (s *dbStorage) conn(ctx context.Context) interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+} {
+	if tx, ok := ctx.Value(txKey{}).(*sql.Tx); ok {
+		return tx
+	}
+
+	return s.db
+}
@@ -1,17 +1,23 @@
 package sqlite
 
 import (
+	"context"
 	"database/sql"
 	"embed"
 	"fmt"
 	"os"
+	"time"
 
 	"github.com/golang-migrate/migrate/v4"
 	"github.com/golang-migrate/migrate/v4/database/sqlite3"
 	"github.com/golang-migrate/migrate/v4/source"
-	_ "github.com/golang-migrate/migrate/v4/source/file" // fs source
+	"github.com/golang-migrate/migrate/v4/source/file"
 	"github.com/golang-migrate/migrate/v4/source/iofs"
+	"github.com/iryzzh/y-gophkeeper/internal/blob"
+	"github.com/iryzzh/y-gophkeeper/internal/logger"
+	"github.com/iryzzh/y-gophkeeper/internal/models"
 	"github.com/iryzzh/y-gophkeeper/internal/store"
+	"github.com/pkg/errors"
 )
 
 var (
@@ -21,20 +27,37 @@ var (
 
 // Store is a store.
 type Store struct {
-	db *sql.DB
+	db             *sql.DB
+	dsn            string
+	migrationsPath string
+	blobs          blob.Storage
 }
 
+// NewStore opens the store and applies the migrations.
 func NewStore(dsn, migrationsPath string) (*Store, error) {
-	db, _ := sql.Open("sqlite3", dsn)
-	s := &Store{
-		db: db,
+	s, err := Open(dsn, migrationsPath)
+	if err != nil {
+		return nil, err
 	}
 
-	if err := s.migrate(dsn, migrationsPath); err != nil {
+	if err = s.MigrateUp(); err != nil {
 		return nil, err
 	}
 
-	return s, db.Ping()
+	return s, s.db.Ping()
+}
+
+// Open opens the store without applying the migrations.
+func Open(dsn, migrationsPath string) (*Store, error) {
+	db, err := sql.Open("sqlite3", dsn)
+	if err != nil {
+		return nil, err
+	}
+
+	s := &Store{db: db, dsn: dsn, migrationsPath: migrationsPath}
+	s.blobs = s.DBStorage()
+
+	return s, nil
 }
 
 // Close closes the database and prevents new queries from starting.
@@ -68,35 +91,138 @@ func (s *Store) IsItemsExist() (result bool, err error) {
 	return result, nil
 }
 
-// migrate uses dsn and the migration file path as parameters. if
-// the path does not exist, or some error occurs while trying to
-// read the specified path, then the embedded filesystem with the
-// default migration configuration is used.
-func (s *Store) migrate(dsn, path string) error {
-	var m *migrate.Migrate
+// Stats returns the counters of the data in the database. The users
+// having events in the audit log since the given time are counted as
+// active.
+func (s *Store) Stats(ctx context.Context, activeSince time.Time) (*models.Stats, error) {
+	stats := &models.Stats{}
+	err := s.db.QueryRowContext(ctx,
+		`select (select count(*) from users),
+				(select count(distinct user_id) from audit where user_id != '' and created_at >= $1),
+				(select count(*) from items where deleted_at is null),
+				(select count(*) from items where deleted_at is not null),
+				(select count(*) from items_data where blob is null) + (select count(*) from items_blobs),
+				(select ifnull(sum(length(data)), 0) from items_data) +
+					(select ifnull(sum(size), 0) from items_blobs)`,
+		activeSince.UTC().Format(dateLayout)).
+		Scan(&stats.Users, &stats.ActiveUsers, &stats.Items, &stats.TrashedItems, &stats.Blobs, &stats.BlobBytes)
+	if err != nil {
+		return nil, err
+	}
 
-	_, err := os.Stat(path)
-	if err == nil {
-		driver, _ := sqlite3.WithInstance(s.db, &sqlite3.Config{})
-		m, _ = migrate.NewWithDatabaseInstance(fmt.Sprintf("file://%s", path), "sqlite3", driver)
-	} else {
-		// log.Printf("error during an attempt to read the migration file path: %v. default migrations are used.", err)
-		var d source.Driver
-		d, _ = iofs.New(fs, "migrations")
-		m, _ = migrate.NewWithSourceInstance("iofs", d, "sqlite3://"+dsn)
+	return stats, nil
+}
+
+// DBStats returns the statistics of the connection pool.
+func (s *Store) DBStats() sql.DBStats {
+	return s.db.Stats()
+}
+
+// MigrateUp applies all the migrations. It fails before applying any
+// if the sqlite3 driver does not support FTS5.
+func (s *Store) MigrateUp() error {
+	if err := s.checkFTS5(); err != nil {
+		return err
+	}
+
+	m, _, err := s.migrator()
+	if err != nil {
+		return err
+	}
+
+	if err = m.Up(); err != nil && !errors.Is(err, migrate.ErrNoChange) {
+		return err
 	}
 
-	if err = m.Up(); err != nil && err != migrate.ErrNoChange {
+	return nil
+}
+
+// MigrateDown rolls back the given number of the applied migrations.
+func (s *Store) MigrateDown(steps int) error {
+	m, _, err := s.migrator()
+	if err != nil {
+		return err
+	}
+
+	if err = m.Steps(-steps); err != nil && !errors.Is(err, migrate.ErrNoChange) {
 		return err
 	}
 
 	return nil
 }
 
+// Migrations returns the status of the migrations.
+func (s *Store) Migrations() (*models.MigrationStatus, error) {
+	m, src, err := s.migrator()
+	if err != nil {
+		return nil, err
+	}
+
+	status := &models.MigrationStatus{}
+	status.Version, status.Dirty, err = m.Version()
+	if err != nil && !errors.Is(err, migrate.ErrNilVersion) {
+		return nil, err
+	}
+
+	for v, err := src.First(); err == nil; v, err = src.Next(v) {
+		status.Latest = v
+	}
+
+	return status, nil
+}
+
+// migrator returns the migrations of the store with their source. If
+// the migrations path does not exist, or some error occurs while trying
+// to read it, then the embedded filesystem with the default migrations
+// is used.
+func (s *Store) migrator() (*migrate.Migrate, source.Driver, error) {
+	driver, err := sqlite3.WithInstance(s.db, &sqlite3.Config{DatabaseName: s.dsn})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var src source.Driver
+	if _, err = os.Stat(s.migrationsPath); err == nil {
+		src, err = (&file.File{}).Open(fmt.Sprintf("file://%s", s.migrationsPath))
+	} else {
+		src, err = iofs.New(fs, "migrations")
+	}
+	if err != nil {
+		return nil, nil, err
+	}
+
+	m, err := migrate.NewWithInstance("source", src, "sqlite3", driver)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return m, src, nil
+}
+
 func (s *Store) User() store.UserRepository {
 	return &UserRepository{db: s.db}
 }
 
 func (s *Store) Item() store.ItemRepository {
-	return &ItemRepository{db: s.db}
+	return &ItemRepository{db: s.db, blobs: s.blobs}
+}
+
+func (s *Store) Org() store.OrgRepository {
+	return &OrgRepository{db: s.db}
+}
+
+func (s *Store) Emergency() store.EmergencyRepository {
+	return &EmergencyRepository{db: s.db}
+}
+
+func (s *Store) Audit() store.AuditRepository {
+	return &AuditRepository{db: s.db}
+}
+
+// rollback rolls back the transaction unless it is committed and logs
+// the failure with the logger of the context.
+func rollback(ctx context.Context, tx *sql.Tx) {
+	if err := tx.Rollback(); err != nil && !errors.Is(err, sql.ErrTxDone) {
+		logger.FromContext(ctx).WithError(err).Warn("transaction rollback failed")
+	}
 }
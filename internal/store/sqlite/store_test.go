@@ -26,6 +26,12 @@ func setupStore(t *testing.T) *sql.DB {
 	return st.db
 }
 
+// itemRepository returns the item repository saving the item data to
+// the storage in the database.
+func itemRepository(db *sql.DB) *ItemRepository {
+	return &ItemRepository{db: db, blobs: &dbStorage{db: db}}
+}
+
 func TestStore_Close(t *testing.T) {
 	cfg, err := utils.TestConfig(t)
 	if err != nil {
@@ -142,3 +148,35 @@ func TestNewStore(t *testing.T) {
 		})
 	}
 }
+
+func TestStore_Migrations(t *testing.T) {
+	cfg, err := utils.TestConfig(t)
+	require.NoError(t, err)
+
+	st, err := Open(cfg.DB.DSN, cfg.DB.MigrationsPath)
+	require.NoError(t, err)
+	defer func() { _ = st.Close() }()
+
+	status, err := st.Migrations()
+	require.NoError(t, err)
+	require.Equal(t, uint(0), status.Version)
+	require.NotZero(t, status.Latest)
+	require.False(t, status.UpToDate())
+
+	require.NoError(t, st.MigrateUp())
+	status, err = st.Migrations()
+	require.NoError(t, err)
+	require.True(t, status.UpToDate())
+	latest := status.Latest
+
+	require.NoError(t, st.MigrateDown(2))
+	status, err = st.Migrations()
+	require.NoError(t, err)
+	require.Equal(t, latest-2, status.Version)
+	require.False(t, status.UpToDate())
+
+	require.NoError(t, st.MigrateUp())
+	status, err = st.Migrations()
+	require.NoError(t, err)
+	require.True(t, status.UpToDate())
+}
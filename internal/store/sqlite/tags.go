@@ -0,0 +1,136 @@
+package sqlite
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+	"time"
+
+	"github.com/iryzzh/y-gophkeeper/internal/models"
+)
+
+// saveMetadata replaces the tags, custom fields and schedule of the
+// item.
+func saveMetadata(ctx context.Context, tx *sql.Tx, item *models.Item) error {
+	if err := deleteMetadata(ctx, tx, item.ID); err != nil {
+		return err
+	}
+
+	for _, tag := range item.Tags {
+		if _, err := tx.ExecContext(ctx,
+			`insert or ignore into items_tags (item_id, tag) values ($1, $2)`,
+			item.ID, tag); err != nil {
+			return err
+		}
+	}
+
+	for _, f := range item.Fields {
+		if _, err := tx.ExecContext(ctx,
+			`insert or replace into items_fields (item_id, name, value, secret) values ($1, $2, $3, $4)`,
+			item.ID, f.Name, f.Value, f.Secret); err != nil {
+			return err
+		}
+	}
+
+	if item.ExpiresAt != nil || item.RotateDays > 0 {
+		var expiresAt interface{}
+		if item.ExpiresAt != nil {
+			expiresAt = item.ExpiresAt.UTC().Format(dateLayout)
+		}
+		if _, err := tx.ExecContext(ctx,
+			`insert into items_schedule (item_id, expires_at, rotate_days) values ($1, $2, $3)`,
+			item.ID, expiresAt, item.RotateDays); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// deleteMetadata deletes the tags, custom fields and schedule of the
+// item.
+func deleteMetadata(ctx context.Context, tx *sql.Tx, itemID int) error {
+	for _, query := range []string{
+		`delete from items_tags where item_id = $1`,
+		`delete from items_fields where item_id = $1`,
+		`delete from items_schedule where item_id = $1`,
+	} {
+		if _, err := tx.ExecContext(ctx, query, itemID); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// loadMetadata fills in the tags, custom fields and schedule of the
+// items.
+func (r *ItemRepository) loadMetadata(ctx context.Context, items ...*models.Item) error {
+	if len(items) == 0 {
+		return nil
+	}
+
+	byID := make(map[int]*models.Item, len(items))
+	args := make([]interface{}, 0, len(items))
+	for _, it := range items {
+		byID[it.ID] = it
+		args = append(args, it.ID)
+	}
+	in := `(?` + strings.Repeat(`, ?`, len(items)-1) + `)`
+
+	rows, err := r.db.QueryContext(ctx, //nolint:gosec // only placeholders are added.
+		`select item_id, tag from items_tags where item_id in `+in+` order by tag`, args...)
+	if err != nil {
+		return err
+	}
+	for rows.Next() {
+		var id int
+		var tag string
+		if err = rows.Scan(&id, &tag); err != nil {
+			_ = rows.Close()
+			return err
+		}
+		byID[id].Tags = append(byID[id].Tags, tag)
+	}
+	_ = rows.Close()
+	if err = rows.Err(); err != nil {
+		return err
+	}
+
+	rows, err = r.db.QueryContext(ctx, //nolint:gosec // only placeholders are added.
+		`select item_id, name, value, secret from items_fields where item_id in `+in+` order by rowid`, args...)
+	if err != nil {
+		return err
+	}
+	for rows.Next() {
+		var id int
+		f := &models.Field{}
+		if err = rows.Scan(&id, &f.Name, &f.Value, &f.Secret); err != nil {
+			_ = rows.Close()
+			return err
+		}
+		byID[id].Fields = append(byID[id].Fields, f)
+	}
+	_ = rows.Close()
+	if err = rows.Err(); err != nil {
+		return err
+	}
+
+	rows, err = r.db.QueryContext(ctx, //nolint:gosec // only placeholders are added.
+		`select item_id, expires_at, rotate_days from items_schedule where item_id in `+in, args...)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = rows.Close() }()
+	for rows.Next() {
+		var id int
+		var expiresAt *time.Time
+		var rotateDays int
+		if err = rows.Scan(&id, &expiresAt, &rotateDays); err != nil {
+			return err
+		}
+		byID[id].ExpiresAt, byID[id].RotateDays = expiresAt, rotateDays
+	}
+
+	return rows.Err()
+}
@@ -0,0 +1,86 @@
+package sqlite
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/iryzzh/y-gophkeeper/internal/models"
+	"github.com/iryzzh/y-gophkeeper/internal/store"
+	"github.com/stretchr/testify/require"
+)
+
+func TestItemRepository_Metadata(t *testing.T) {
+	r := itemRepository(setupStore(t))
+	defer func() { _ = r.db.Close() }()
+
+	it := sampleItem(t, uuid.NewString())
+	it.Meta = "inbox"
+	it.Tags = []string{"personal", "email"}
+	it.Fields = []*models.Field{
+		{Name: "username", Value: "alice"},
+		{Name: "url", Value: "https://mail.example.com"},
+		{Name: "pin", Value: "1234", Secret: true},
+	}
+	require.NoError(t, r.Create(context.Background(), it))
+
+	found, err := r.FindByID(context.Background(), it.UserID, it.ID)
+	require.NoError(t, err)
+	require.ElementsMatch(t, it.Tags, found.Tags)
+	require.Equal(t, it.Fields, found.Fields)
+
+	found, err = r.FindByMetaName(context.Background(), it.UserID, it.Meta)
+	require.NoError(t, err)
+	require.ElementsMatch(t, it.Tags, found.Tags)
+
+	items, err := r.FindByUserID(context.Background(), it.UserID, 10, 0)
+	require.NoError(t, err)
+	require.ElementsMatch(t, it.Tags, items.Data[0].Tags)
+	require.Equal(t, it.Fields, items.Data[0].Fields)
+
+	searches := []struct {
+		name    string
+		filter  *models.ItemFilter
+		wantErr error
+	}{
+		{name: "tag", filter: &models.ItemFilter{Tags: []string{"email"}}},
+		{name: "all tags", filter: &models.ItemFilter{Tags: []string{"email", "personal"}}},
+		{name: "missing tag", filter: &models.ItemFilter{Tags: []string{"email", "work"}}, wantErr: store.ErrItemNotFound},
+		{name: "field value", filter: &models.ItemFilter{Query: "alice", WithMetadata: true}},
+		{name: "tag as query", filter: &models.ItemFilter{Query: "personal", WithMetadata: true}},
+		{name: "without metadata", filter: &models.ItemFilter{Query: "alice"}, wantErr: store.ErrItemNotFound},
+		{name: "secret field", filter: &models.ItemFilter{Query: "1234", WithMetadata: true}, wantErr: store.ErrItemNotFound},
+	}
+	for _, tt := range searches {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := r.Search(context.Background(), it.UserID, tt.filter)
+			require.ErrorIs(t, err, tt.wantErr)
+		})
+	}
+
+	it.Tags = []string{"work"}
+	it.Fields = nil
+	require.NoError(t, r.Update(context.Background(), it))
+
+	found, err = r.FindByID(context.Background(), it.UserID, it.ID)
+	require.NoError(t, err)
+	require.Equal(t, []string{"work"}, found.Tags)
+	require.Nil(t, found.Fields)
+	require.NotNil(t, found.UpdatedAt)
+
+	_, err = r.Search(context.Background(), it.UserID, &models.ItemFilter{Query: "alice", WithMetadata: true})
+	require.ErrorIs(t, err, store.ErrItemNotFound)
+
+	require.NoError(t, r.Delete(context.Background(), it))
+
+	var count int
+	require.NoError(t, r.db.QueryRow(`select count(*) from items_tags`).Scan(&count))
+	require.Equal(t, 1, count)
+
+	_, err = r.Purge(context.Background(), it.UserID, time.Now())
+	require.NoError(t, err)
+
+	require.NoError(t, r.db.QueryRow(`select count(*) from items_tags`).Scan(&count))
+	require.Zero(t, count)
+}
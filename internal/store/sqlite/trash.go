@@ -0,0 +1,166 @@
+package sqlite
+
+import (
+	"context"
+	"time"
+
+	"github.com/iryzzh/y-gophkeeper/internal/models"
+	"github.com/iryzzh/y-gophkeeper/internal/store"
+	"github.com/mattn/go-sqlite3"
+	"github.com/pkg/errors"
+)
+
+// purged selects the trashed items deleted until $1 of the user $2,
+// or of all the users if $2 is empty.
+const purged = `select id from items
+	where deleted_at is not null and deleted_at <= $1 and ($2 = '' or user_id = $2)`
+
+// Trash returns the items of the user in the trash, the most recently
+// deleted first.
+func (r *ItemRepository) Trash(ctx context.Context, userID string, limit, offset int) (*models.Items, error) {
+	rows, err := r.db.QueryContext(ctx,
+		`select items.id, items.user_id, items.meta, items.data_id, items.data_type, items.created_at,
+       			items.updated_at, items.deleted_at, ifnull(idt.id, 0), idt.data, count(*) over ()
+				from items
+				left join items_data idt on idt.id = items.data_id
+				where items.user_id = $1 and items.deleted_at is not null
+				order by items.deleted_at desc, items.id desc
+				limit $2 offset $3`,
+		userID, limit, offset)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	items, total, err := scanItems(rows)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(items) == 0 {
+		return nil, store.ErrItemNotFound
+	}
+
+	return &models.Items{
+		Meta: models.Meta{TotalItems: total},
+		Data: items,
+	}, r.load(ctx, userID, items...)
+}
+
+// Restore moves the item with the given id out of the trash and drops
+// its tombstones. It returns `store.ErrItemExists` if an item with
+// the same name has been created in the meantime.
+func (r *ItemRepository) Restore(ctx context.Context, userID string, id int) (*models.Item, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer rollback(ctx, tx)
+
+	res, err := tx.ExecContext(ctx,
+		`update items set deleted_at = null, updated_at = current_timestamp
+			where user_id = $1 and id = $2 and deleted_at is not null`,
+		userID, id)
+	if err != nil {
+		vErr, ok := errors.Cause(err).(sqlite3.Error)
+		if ok && vErr.ExtendedCode == sqlite3.ErrConstraintUnique {
+			return nil, store.ErrItemExists
+		}
+
+		return nil, errors.Wrap(err, store.ErrItemRestoreFailed.Error())
+	}
+
+	if rowsAffected, _ := res.RowsAffected(); rowsAffected == 0 {
+		return nil, store.ErrItemNotFound
+	}
+
+	if _, err = tx.ExecContext(ctx, `delete from items_tombstones where item_id = $1`, id); err != nil {
+		return nil, errors.Wrap(err, store.ErrItemRestoreFailed.Error())
+	}
+
+	if err = tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	return r.FindByID(ctx, userID, id)
+}
+
+// Purge permanently deletes the items moved to the trash until the
+// given time. If the user id is empty, the trash of every user is
+// purged. It returns the number of the deleted items.
+func (r *ItemRepository) Purge(ctx context.Context, userID string, until time.Time) (int, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer rollback(ctx, tx)
+
+	u := until.UTC().Format(dateLayout)
+	for _, ids := range []string{
+		`select data_id from items where id in (` + purged + `)`,
+		`select data_id from items_attachments where item_id in (` + purged + `)`,
+	} {
+		if err = deleteData(ctx, tx, ids, u, userID); err != nil {
+			return 0, errors.Wrap(err, store.ErrItemPurgeFailed.Error())
+		}
+	}
+	for _, query := range []string{
+		`delete from items_attachments where item_id in (` + purged + `)`,
+		`delete from items_tags where item_id in (` + purged + `)`,
+		`delete from items_fields where item_id in (` + purged + `)`,
+		`delete from items_schedule where item_id in (` + purged + `)`,
+		`delete from items_fts where rowid in (` + purged + `)`,
+		`delete from items_keys where item_id in (` + purged + `)`,
+		`delete from items_remote where item_id in (` + purged + `)`,
+	} {
+		if _, err = tx.ExecContext(ctx, query, u, userID); err != nil {
+			return 0, errors.Wrap(err, store.ErrItemPurgeFailed.Error())
+		}
+	}
+
+	res, err := tx.ExecContext(ctx, `delete from items where id in (`+purged+`)`, u, userID)
+	if err != nil {
+		return 0, errors.Wrap(err, store.ErrItemPurgeFailed.Error())
+	}
+	n, _ := res.RowsAffected()
+
+	return int(n), tx.Commit()
+}
+
+// Tombstones returns the tombstones of the items of the user deleted
+// since the given time.
+func (r *ItemRepository) Tombstones(ctx context.Context, userID string, since time.Time) ([]*models.Tombstone, error) {
+	rows, err := r.db.QueryContext(ctx,
+		`select item_id, meta, deleted_at from items_tombstones
+			where user_id = $1 and deleted_at >= $2
+			order by deleted_at, item_id`,
+		userID, since.UTC().Format(dateLayout))
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	tombstones := make([]*models.Tombstone, 0)
+	for rows.Next() {
+		t := &models.Tombstone{}
+		if err = rows.Scan(&t.ItemID, &t.Meta, &t.DeletedAt); err != nil {
+			return nil, err
+		}
+		tombstones = append(tombstones, t)
+	}
+
+	return tombstones, rows.Err()
+}
+
+// PurgeTombstones deletes the tombstones recorded before the given
+// time. It returns the number of the deleted tombstones.
+func (r *ItemRepository) PurgeTombstones(ctx context.Context, before time.Time) (int, error) {
+	res, err := r.db.ExecContext(ctx,
+		`delete from items_tombstones where deleted_at < $1`, before.UTC().Format(dateLayout))
+	if err != nil {
+		return 0, errors.Wrap(err, store.ErrItemPurgeFailed.Error())
+	}
+	n, _ := res.RowsAffected()
+
+	return int(n), nil
+}
@@ -0,0 +1,117 @@
+package sqlite
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/iryzzh/y-gophkeeper/internal/models"
+	"github.com/iryzzh/y-gophkeeper/internal/store"
+	"github.com/stretchr/testify/require"
+)
+
+func TestItemRepository_Trash(t *testing.T) {
+	r := itemRepository(setupStore(t))
+	defer func() { _ = r.db.Close() }()
+	ctx := context.Background()
+
+	userID := uuid.NewString()
+	it := sampleItem(t, userID)
+	it.Tags = []string{"work"}
+	require.NoError(t, r.Create(ctx, it))
+
+	require.NoError(t, r.Delete(ctx, it))
+	require.NotNil(t, it.DeletedAt)
+	require.ErrorIs(t, r.Delete(ctx, it), store.ErrItemNotFound)
+
+	_, err := r.FindByID(ctx, userID, it.ID)
+	require.ErrorIs(t, err, store.ErrItemNotFound)
+	_, err = r.FindByUserID(ctx, userID, 10, 0)
+	require.ErrorIs(t, err, store.ErrItemNotFound)
+	_, err = r.Search(ctx, userID, &models.ItemFilter{Tags: []string{"work"}})
+	require.ErrorIs(t, err, store.ErrItemNotFound)
+
+	trashed, err := r.Trash(ctx, userID, 10, 0)
+	require.NoError(t, err)
+	require.Len(t, trashed.Data, 1)
+	require.Equal(t, it.Meta, trashed.Data[0].Meta)
+	require.Equal(t, []string{"work"}, trashed.Data[0].Tags)
+	require.NotNil(t, trashed.Data[0].DeletedAt)
+
+	tombstones, err := r.Tombstones(ctx, userID, time.Now().Add(-time.Hour))
+	require.NoError(t, err)
+	require.Len(t, tombstones, 1)
+	require.Equal(t, it.ID, tombstones[0].ItemID)
+	require.Equal(t, it.Meta, tombstones[0].Meta)
+
+	tombstones, err = r.Tombstones(ctx, userID, time.Now().Add(time.Hour))
+	require.NoError(t, err)
+	require.Empty(t, tombstones)
+
+	// the name of a trashed item can be reused, the trashed item
+	// cannot be restored while the name is taken.
+	other := sampleItem(t, userID)
+	other.Meta = it.Meta
+	require.NoError(t, r.Create(ctx, other))
+	_, err = r.Restore(ctx, userID, it.ID)
+	require.ErrorIs(t, err, store.ErrItemExists)
+	require.NoError(t, r.Delete(ctx, other))
+
+	restored, err := r.Restore(ctx, userID, it.ID)
+	require.NoError(t, err)
+	require.Equal(t, it.Meta, restored.Meta)
+	require.Nil(t, restored.DeletedAt)
+	_, err = r.Restore(ctx, userID, it.ID)
+	require.ErrorIs(t, err, store.ErrItemNotFound)
+
+	tombstones, err = r.Tombstones(ctx, userID, time.Time{})
+	require.NoError(t, err)
+	require.Len(t, tombstones, 1)
+	require.Equal(t, other.ID, tombstones[0].ItemID)
+}
+
+func TestItemRepository_Purge(t *testing.T) {
+	r := itemRepository(setupStore(t))
+	defer func() { _ = r.db.Close() }()
+	ctx := context.Background()
+
+	userID, otherUserID := uuid.NewString(), uuid.NewString()
+	for _, id := range []string{userID, userID, otherUserID} {
+		it := sampleItem(t, id)
+		require.NoError(t, r.Create(ctx, it))
+		require.NoError(t, r.Delete(ctx, it))
+	}
+	kept := sampleItem(t, userID)
+	require.NoError(t, r.Create(ctx, kept))
+
+	purged, err := r.Purge(ctx, userID, time.Now().Add(-time.Hour))
+	require.NoError(t, err)
+	require.Equal(t, 0, purged)
+
+	purged, err = r.Purge(ctx, userID, time.Now())
+	require.NoError(t, err)
+	require.Equal(t, 2, purged)
+
+	_, err = r.Trash(ctx, userID, 10, 0)
+	require.ErrorIs(t, err, store.ErrItemNotFound)
+	_, err = r.FindByID(ctx, userID, kept.ID)
+	require.NoError(t, err)
+
+	purged, err = r.Purge(ctx, "", time.Now())
+	require.NoError(t, err)
+	require.Equal(t, 1, purged)
+
+	var orphans int
+	require.NoError(t, r.db.QueryRow(
+		`select count(*) from items_data where id not in (select data_id from items)`).Scan(&orphans))
+	require.Zero(t, orphans)
+
+	tombstones, err := r.Tombstones(ctx, userID, time.Time{})
+	require.NoError(t, err)
+	require.Len(t, tombstones, 2)
+
+	removed, err := r.PurgeTombstones(ctx, time.Now().Add(time.Hour))
+	require.NoError(t, err)
+	require.Equal(t, 3, removed)
+}
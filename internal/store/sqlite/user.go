@@ -1,6 +1,7 @@
 package sqlite
 
 import (
+	"bytes"
 	"context"
 	"database/sql"
 
@@ -17,8 +18,11 @@ func (r *UserRepository) FindByID(ctx context.Context, userID string) (*models.U
 	u := &models.User{}
 
 	err := r.db.QueryRowContext(ctx,
-		"SELECT user_id, login, password FROM users WHERE user_id = $1",
-		userID).Scan(&u.ID, &u.Login, &u.PasswordHash)
+		"SELECT user_id, login, password, admin, disabled_at, token_version, totp_secret, totp_pending FROM users "+
+			"WHERE user_id = $1",
+		userID).Scan(&u.ID, &u.Login, &u.PasswordHash, &u.Admin, &u.DisabledAt, &u.TokenVersion,
+		&u.TOTPSecret, &u.TOTPPending)
+	u.TwoFactor = u.TOTPSecret != ""
 
 	if errors.Is(err, sql.ErrNoRows) {
 		return nil, store.ErrUserNotFound
@@ -47,8 +51,11 @@ func (r *UserRepository) FindByLogin(ctx context.Context, login string) (*models
 	u := &models.User{}
 
 	err := r.db.QueryRowContext(ctx,
-		"SELECT user_id, login, password FROM users WHERE login = $1",
-		login).Scan(&u.ID, &u.Login, &u.PasswordHash)
+		"SELECT user_id, login, password, admin, disabled_at, token_version, totp_secret, totp_pending FROM users "+
+			"WHERE login = $1",
+		login).Scan(&u.ID, &u.Login, &u.PasswordHash, &u.Admin, &u.DisabledAt, &u.TokenVersion,
+		&u.TOTPSecret, &u.TOTPPending)
+	u.TwoFactor = u.TOTPSecret != ""
 
 	if errors.Is(err, sql.ErrNoRows) {
 		return nil, store.ErrUserNotFound
@@ -56,3 +63,168 @@ func (r *UserRepository) FindByLogin(ctx context.Context, login string) (*models
 
 	return u, err
 }
+
+// SetPublicKey registers the public key of the user. It returns
+// `store.ErrPublicKeyExists` if the user has another public key.
+func (r *UserRepository) SetPublicKey(ctx context.Context, userID string, key []byte) error {
+	res, err := r.db.ExecContext(ctx,
+		`insert into users_keys (user_id, public_key) values ($1, $2) on conflict (user_id) do nothing`,
+		userID, key)
+	if err != nil {
+		return err
+	}
+
+	if rowsAffected, _ := res.RowsAffected(); rowsAffected != 0 {
+		return nil
+	}
+
+	existing, err := r.FindPublicKey(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	if !bytes.Equal(existing, key) {
+		return store.ErrPublicKeyExists
+	}
+
+	return nil
+}
+
+// FindPublicKey returns the public key of the user.
+func (r *UserRepository) FindPublicKey(ctx context.Context, userID string) ([]byte, error) {
+	var key []byte
+	err := r.db.QueryRowContext(ctx,
+		`select public_key from users_keys where user_id = $1`, userID).Scan(&key)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, store.ErrPublicKeyNotFound
+	}
+
+	return key, err
+}
+
+// List returns the users ordered by the login.
+func (r *UserRepository) List(ctx context.Context) ([]*models.User, error) {
+	rows, err := r.db.QueryContext(ctx,
+		`select user_id, login, admin, totp_secret != '', disabled_at from users order by login`)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = rows.Close() }()
+
+	users := make([]*models.User, 0)
+	for rows.Next() {
+		u := &models.User{}
+		if err = rows.Scan(&u.ID, &u.Login, &u.Admin, &u.TwoFactor, &u.DisabledAt); err != nil {
+			return nil, err
+		}
+		users = append(users, u)
+	}
+
+	return users, rows.Err()
+}
+
+// SetDisabled disables or enables the user.
+func (r *UserRepository) SetDisabled(ctx context.Context, userID string, disabled bool) error {
+	query := `update users set disabled_at = null where user_id = $1`
+	if disabled {
+		query = `update users set disabled_at = coalesce(disabled_at, current_timestamp) where user_id = $1`
+	}
+
+	return r.exec(ctx, query, userID)
+}
+
+// SetAdmin grants or revokes the admin role of the user.
+func (r *UserRepository) SetAdmin(ctx context.Context, userID string, admin bool) error {
+	return r.exec(ctx, `update users set admin = $1 where user_id = $2`, admin, userID)
+}
+
+// RevokeSessions revokes the tokens issued to the user until now by
+// incrementing the token version of the user.
+func (r *UserRepository) RevokeSessions(ctx context.Context, userID string) error {
+	return r.exec(ctx, `update users set token_version = token_version + 1 where user_id = $1`, userID)
+}
+
+// Usage returns the storage used by the personal vault of the user.
+func (r *UserRepository) Usage(ctx context.Context, userID string) (*models.Usage, error) {
+	usage := &models.Usage{}
+	err := r.db.QueryRowContext(ctx,
+		`select count(*), ifnull(sum(d.size), 0) +
+				(select ifnull(sum(a.size), 0) from items_attachments a
+					join items ai on ai.id = a.item_id where ai.user_id = $1)
+			from items i left join items_data d on d.id = i.data_id
+			where i.user_id = $1`,
+		userID).Scan(&usage.Items, &usage.Bytes)
+	if err != nil {
+		return nil, err
+	}
+
+	return usage, nil
+}
+
+// Quota returns the quota set for the user, or `store.ErrQuotaNotFound`
+// if the user has none.
+func (r *UserRepository) Quota(ctx context.Context, userID string) (*models.Quota, error) {
+	q := &models.Quota{}
+	err := r.db.QueryRowContext(ctx,
+		`select max_bytes, max_items, max_item_bytes from users_quotas where user_id = $1`,
+		userID).Scan(&q.MaxBytes, &q.MaxItems, &q.MaxItemBytes)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, store.ErrQuotaNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return q, nil
+}
+
+// SetQuota sets the quota of the user.
+func (r *UserRepository) SetQuota(ctx context.Context, userID string, quota *models.Quota) error {
+	_, err := r.db.ExecContext(ctx,
+		`insert into users_quotas (user_id, max_bytes, max_items, max_item_bytes) values ($1, $2, $3, $4)
+			on conflict (user_id) do update set max_bytes = excluded.max_bytes, max_items = excluded.max_items,
+				max_item_bytes = excluded.max_item_bytes`,
+		userID, quota.MaxBytes, quota.MaxItems, quota.MaxItemBytes)
+
+	return err
+}
+
+// DeleteQuota deletes the quota set for the user.
+func (r *UserRepository) DeleteQuota(ctx context.Context, userID string) error {
+	_, err := r.db.ExecContext(ctx, `delete from users_quotas where user_id = $1`, userID)
+
+	return err
+}
+
+// SetTOTP sets the TOTP secret of the second factor of the user, empty
+// to disable it, and the secret being enabled, if any.
+func (r *UserRepository) SetTOTP(ctx context.Context, userID, secret, pending string) error {
+	return r.exec(ctx, `update users set totp_secret = $1, totp_pending = $2 where user_id = $3`,
+		secret, pending, userID)
+}
+
+// UseOTP records the use of the one-time code of the step. It returns
+// `store.ErrOTPUsed` unless the step is after the step of the code used
+// last, so that a code cannot be used twice.
+func (r *UserRepository) UseOTP(ctx context.Context, userID string, step int64) error {
+	err := r.exec(ctx, `update users set totp_step = $1 where user_id = $2 and totp_step < $1`, step, userID)
+	if errors.Is(err, store.ErrUserNotFound) {
+		return store.ErrOTPUsed
+	}
+
+	return err
+}
+
+// exec executes the query changing the user and returns
+// `store.ErrUserNotFound` if nothing was changed.
+func (r *UserRepository) exec(ctx context.Context, query string, args ...interface{}) error {
+	res, err := r.db.ExecContext(ctx, query, args...)
+	if err != nil {
+		return err
+	}
+	if rowsAffected, _ := res.RowsAffected(); rowsAffected == 0 {
+		return store.ErrUserNotFound
+	}
+
+	return nil
+}
@@ -162,3 +162,82 @@ func TestUserRepository_FindByLogin(t *testing.T) {
 		})
 	}
 }
+
+func TestUserRepository_SetDisabled(t *testing.T) {
+	db := setupStore(t)
+	repo := &UserRepository{db: db}
+	ctx := context.Background()
+
+	u := makeUser(t)
+	if err := repo.Create(ctx, u); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := repo.SetDisabled(ctx, u.ID, true); err != nil {
+		t.Fatal(err)
+	}
+	got, err := repo.FindByLogin(ctx, u.Login)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.DisabledAt == nil {
+		t.Errorf("SetDisabled() the user is not disabled")
+	}
+
+	users, err := repo.List(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(users) != 1 || users[0].ID != u.ID || users[0].DisabledAt == nil {
+		t.Errorf("List() = %v, want the disabled user", users)
+	}
+
+	if err = repo.SetDisabled(ctx, u.ID, false); err != nil {
+		t.Fatal(err)
+	}
+	got, err = repo.FindByID(ctx, u.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.DisabledAt != nil {
+		t.Errorf("SetDisabled() the user is still disabled")
+	}
+
+	if err = repo.SetDisabled(ctx, "missing", true); err != store.ErrUserNotFound {
+		t.Errorf("SetDisabled() error = %v, want %v", err, store.ErrUserNotFound)
+	}
+}
+
+func TestUserRepository_Quota(t *testing.T) {
+	db := setupStore(t)
+	repo := &UserRepository{db: db}
+	ctx := context.Background()
+
+	u := makeUser(t)
+	if err := repo.Create(ctx, u); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := repo.Quota(ctx, u.ID); err != store.ErrQuotaNotFound {
+		t.Errorf("Quota() error = %v, want %v", err, store.ErrQuotaNotFound)
+	}
+
+	want := &models.Quota{MaxBytes: 100, MaxItems: 2}
+	if err := repo.SetQuota(ctx, u.ID, want); err != nil {
+		t.Fatal(err)
+	}
+	got, err := repo.Quota(ctx, u.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if *got != *want {
+		t.Errorf("Quota() = %v, want %v", got, want)
+	}
+
+	if err = repo.DeleteQuota(ctx, u.ID); err != nil {
+		t.Fatal(err)
+	}
+	if _, err = repo.Quota(ctx, u.ID); err != store.ErrQuotaNotFound {
+		t.Errorf("Quota() after DeleteQuota() error = %v, want %v", err, store.ErrQuotaNotFound)
+	}
+}
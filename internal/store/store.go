@@ -2,6 +2,9 @@ package store
 
 import (
 	"context"
+	"database/sql"
+	"io"
+	"time"
 
 	"github.com/iryzzh/y-gophkeeper/internal/models"
 )
@@ -11,6 +14,9 @@ type Store interface {
 	Status
 	User() UserRepository
 	Item() ItemRepository
+	Org() OrgRepository
+	Emergency() EmergencyRepository
+	Audit() AuditRepository
 	Close() error
 }
 
@@ -19,6 +25,9 @@ type Status interface {
 	Ping() error
 	IsUsersExist() (bool, error)
 	IsItemsExist() (bool, error)
+	Stats(ctx context.Context, activeSince time.Time) (*models.Stats, error)
+	DBStats() sql.DBStats
+	Migrations() (*models.MigrationStatus, error)
 }
 
 // UserRepository represents ways to interact with users in the database.
@@ -26,6 +35,18 @@ type UserRepository interface {
 	Create(ctx context.Context, user *models.User) error
 	FindByLogin(ctx context.Context, login string) (*models.User, error)
 	FindByID(ctx context.Context, userID string) (*models.User, error)
+	SetPublicKey(ctx context.Context, userID string, key []byte) error
+	FindPublicKey(ctx context.Context, userID string) ([]byte, error)
+	List(ctx context.Context) ([]*models.User, error)
+	SetDisabled(ctx context.Context, userID string, disabled bool) error
+	SetAdmin(ctx context.Context, userID string, admin bool) error
+	RevokeSessions(ctx context.Context, userID string) error
+	Usage(ctx context.Context, userID string) (*models.Usage, error)
+	Quota(ctx context.Context, userID string) (*models.Quota, error)
+	SetQuota(ctx context.Context, userID string, quota *models.Quota) error
+	DeleteQuota(ctx context.Context, userID string) error
+	SetTOTP(ctx context.Context, userID, secret, pending string) error
+	UseOTP(ctx context.Context, userID string, step int64) error
 }
 
 // ItemRepository represents ways to interact with items in the database.
@@ -34,6 +55,63 @@ type ItemRepository interface {
 	FindByID(ctx context.Context, userID string, id int) (*models.Item, error)
 	FindByMetaName(ctx context.Context, userID string, metaName string) (*models.Item, error)
 	FindByUserID(ctx context.Context, userID string, limit, offset int) (*models.Items, error)
+	Search(ctx context.Context, userID string, filter *models.ItemFilter) (*models.Items, error)
 	Update(ctx context.Context, item *models.Item) error
 	Delete(ctx context.Context, item *models.Item) error
+	Move(ctx context.Context, userID, from, to string) (int, error)
+	Trash(ctx context.Context, userID string, limit, offset int) (*models.Items, error)
+	Restore(ctx context.Context, userID string, id int) (*models.Item, error)
+	Purge(ctx context.Context, userID string, until time.Time) (int, error)
+	Tombstones(ctx context.Context, userID string, since time.Time) ([]*models.Tombstone, error)
+	PurgeTombstones(ctx context.Context, before time.Time) (int, error)
+	SetRemoteID(ctx context.Context, userID string, id, remoteID int) error
+	FindByRemoteID(ctx context.Context, userID string, remoteID int) (int, error)
+	Share(ctx context.Context, itemID int, userID string, key []byte, readOnly bool) error
+	Unshare(ctx context.Context, itemID int, userID string) error
+	Shared(ctx context.Context, userID string) (*models.Items, error)
+	Scheduled(ctx context.Context, userID string) ([]*models.Item, error)
+	Attach(ctx context.Context, userID string, itemID int, attachment *models.Attachment, r io.Reader) error
+	Attachments(ctx context.Context, userID string, itemID int) ([]*models.Attachment, error)
+	OpenAttachment(ctx context.Context, userID string, itemID int, name string) (*models.Attachment, io.ReadCloser, error)
+	Detach(ctx context.Context, userID string, itemID int, name string) error
+	CollectBlobs(ctx context.Context, before time.Time) (int, error)
+}
+
+// OrgRepository represents ways to interact with organizations, their
+// members and collections in the database.
+type OrgRepository interface {
+	Create(ctx context.Context, org *models.Org, ownerID string) error
+	FindByID(ctx context.Context, orgID, userID string) (*models.Org, error)
+	FindByUserID(ctx context.Context, userID string) ([]*models.Org, error)
+	Delete(ctx context.Context, orgID string) error
+	Members(ctx context.Context, orgID string) ([]*models.Member, error)
+	SetMember(ctx context.Context, orgID string, member *models.Member) error
+	RemoveMember(ctx context.Context, orgID, userID string) error
+	Role(ctx context.Context, orgID, userID string) (models.Role, error)
+	CreateCollection(ctx context.Context, collection *models.Collection) error
+	FindCollection(ctx context.Context, collectionID string) (*models.Collection, error)
+	Collections(ctx context.Context, orgID string) ([]*models.Collection, error)
+	DeleteCollection(ctx context.Context, collectionID string) error
+}
+
+// EmergencyRepository represents ways to interact with the emergency
+// access of the users in the database.
+type EmergencyRepository interface {
+	Grant(ctx context.Context, access *models.EmergencyAccess) error
+	Find(ctx context.Context, grantorID, granteeID string) (*models.EmergencyAccess, error)
+	FindByGrantor(ctx context.Context, grantorID string) ([]*models.EmergencyAccess, error)
+	FindByGrantee(ctx context.Context, granteeID string) ([]*models.EmergencyAccess, error)
+	Revoke(ctx context.Context, grantorID, granteeID string) error
+	Request(ctx context.Context, grantorID, granteeID string) error
+	Approve(ctx context.Context, grantorID, granteeID string) error
+	Deny(ctx context.Context, grantorID, granteeID string) error
+	Release(ctx context.Context, until time.Time) (int, error)
+}
+
+// AuditRepository represents ways to interact with the append-only
+// audit log in the database.
+type AuditRepository interface {
+	Append(ctx context.Context, event *models.AuditEvent) error
+	FindByUserID(ctx context.Context, userID string, filter *models.AuditFilter) ([]*models.AuditEvent, error)
+	Purge(ctx context.Context, before time.Time) (int, error)
 }
@@ -0,0 +1,136 @@
+// Package totp implements the time-based one-time passwords of RFC 6238
+// used as the second factor of the login, with the parameters of the
+// authenticator apps: HMAC-SHA1, 6 digits and steps of 30 seconds. The
+// secrets are base32-encoded without padding.
+package totp
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha1" //nolint:gosec // RFC 6238 uses HMAC-SHA1 by default.
+	"crypto/subtle"
+	"encoding/base32"
+	"encoding/binary"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	// Digits is the number of digits of a code.
+	Digits = 6
+	// Period is the time step of the codes.
+	Period = 30 * time.Second
+
+	secretSize = 20
+	// skew is the number of the steps before and after the current one
+	// whose codes are accepted, for the clocks out of sync.
+	skew = 1
+)
+
+var (
+	// ErrInvalidCode is returned when the code does not match the secret.
+	ErrInvalidCode = errors.New("invalid one-time code")
+	// ErrInvalidSecret is returned when the secret is not base32-encoded.
+	ErrInvalidSecret = errors.New("invalid secret")
+)
+
+// encoding is the encoding of the secrets.
+var encoding = base32.StdEncoding.WithPadding(base32.NoPadding) //nolint:gochecknoglobals
+
+// NewSecret returns a new random secret.
+func NewSecret() (string, error) {
+	secret := make([]byte, secretSize)
+	if _, err := rand.Read(secret); err != nil {
+		return "", err
+	}
+
+	return encoding.EncodeToString(secret), nil
+}
+
+// URL returns the 'otpauth' URL of the secret of the account, which the
+// authenticator apps import.
+func URL(issuer, account, secret string) string {
+	u := url.URL{
+		Scheme: "otpauth",
+		Host:   "totp",
+		Path:   "/" + issuer + ":" + account,
+		RawQuery: url.Values{
+			"secret": {secret},
+			"issuer": {issuer},
+		}.Encode(),
+	}
+
+	return u.String()
+}
+
+// Code returns the code of the secret at the time.
+func Code(secret string, t time.Time) (string, error) {
+	key, err := decode(secret)
+	if err != nil {
+		return "", err
+	}
+
+	return code(key, step(t)), nil
+}
+
+// Validate returns the step of the code of the secret matching the
+// code near the time, or `ErrInvalidCode`. The step lets the caller
+// reject the codes used already.
+func Validate(secret, c string, t time.Time) (int64, error) {
+	key, err := decode(secret)
+	if err != nil {
+		return 0, err
+	}
+
+	c = strings.TrimSpace(c)
+	if len(c) != Digits {
+		return 0, ErrInvalidCode
+	}
+	now := step(t)
+	for s := now - skew; s <= now+skew; s++ {
+		if subtle.ConstantTimeCompare([]byte(code(key, s)), []byte(c)) == 1 {
+			return s, nil
+		}
+	}
+
+	return 0, ErrInvalidCode
+}
+
+// decode decodes the secret, ignoring the case and the spaces.
+func decode(secret string) ([]byte, error) {
+	key, err := encoding.DecodeString(strings.ToUpper(strings.ReplaceAll(secret, " ", "")))
+	if err != nil || len(key) == 0 {
+		return nil, ErrInvalidSecret
+	}
+
+	return key, nil
+}
+
+// step returns the number of the step of the time.
+func step(t time.Time) int64 {
+	return t.Unix() / int64(Period/time.Second)
+}
+
+// code returns the code of the key at the step, as HOTP of RFC 4226.
+func code(key []byte, s int64) string {
+	var msg [8]byte
+	binary.BigEndian.PutUint64(msg[:], uint64(s))
+
+	h := hmac.New(sha1.New, key)
+	_, _ = h.Write(msg[:])
+	sum := h.Sum(nil)
+
+	offset := sum[len(sum)-1] & 0x0f                            //nolint:gomnd
+	value := binary.BigEndian.Uint32(sum[offset:]) & 0x7fffffff //nolint:gomnd
+
+	mod := uint32(1)
+	for i := 0; i < Digits; i++ {
+		mod *= 10
+	}
+
+	return fmt.Sprintf("%0*d", Digits, value%mod)
+}
@@ -0,0 +1,55 @@
+package totp
+
+import (
+	"encoding/base32"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+// TestCode checks the codes of the test vectors of RFC 6238 (SHA1),
+// truncated to 6 digits.
+func TestCode(t *testing.T) {
+	secret := base32.StdEncoding.EncodeToString([]byte("12345678901234567890"))
+
+	for unix, want := range map[int64]string{
+		59:          "287082",
+		1111111109:  "081804",
+		1111111111:  "050471",
+		1234567890:  "005924",
+		2000000000:  "279037",
+		20000000000: "353130",
+	} {
+		got, err := Code(secret, time.Unix(unix, 0))
+		require.NoError(t, err)
+		require.Equal(t, want, got, unix)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	secret, err := NewSecret()
+	require.NoError(t, err)
+	now := time.Unix(1700000000, 0)
+
+	c, err := Code(secret, now)
+	require.NoError(t, err)
+	s, err := Validate(strings.ToLower(secret), " "+c, now)
+	require.NoError(t, err)
+	require.Equal(t, now.Unix()/30, s)
+
+	s, err = Validate(secret, c, now.Add(Period))
+	require.NoError(t, err)
+	require.Equal(t, now.Unix()/30, s)
+
+	_, err = Validate(secret, c, now.Add(3*Period))
+	require.ErrorIs(t, err, ErrInvalidCode)
+	_, err = Validate(secret, "12345", now)
+	require.ErrorIs(t, err, ErrInvalidCode)
+	_, err = Validate("not base32!", c, now)
+	require.ErrorIs(t, err, ErrInvalidSecret)
+
+	require.Equal(t, "otpauth://totp/GophKeeper:alice?issuer=GophKeeper&secret="+secret,
+		URL("GophKeeper", "alice", secret))
+}
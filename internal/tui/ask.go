@@ -97,6 +97,11 @@ func AskConfirm(message string, answer *bool) error {
 	return survey.AskOne(prompt, answer)
 }
 
+// AskPassword asks for a password without echoing it.
+func AskPassword(message string) (string, error) {
+	return askOne(&survey.Password{Message: message})
+}
+
 func askOne(prompt survey.Prompt) (string, error) {
 	output := ""
 	err := survey.AskOne(prompt, &output, survey.WithValidator(survey.Required),
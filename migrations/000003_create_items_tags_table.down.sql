@@ -0,0 +1,4 @@
+-- noinspection SqlNoDataSourceInspectionForFile
+
+drop table if exists items_tags;
+drop table if exists items_fields;
\ No newline at end of file
@@ -0,0 +1,5 @@
+-- noinspection SqlNoDataSourceInspectionForFile
+
+drop table if exists items_keys;
+
+drop table if exists users_keys;
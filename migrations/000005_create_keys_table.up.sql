@@ -0,0 +1,20 @@
+-- noinspection SqlNoDataSourceInspectionForFile
+
+create table if not exists users_keys
+(
+    user_id    text primary key,
+    public_key blob not null,
+    created_at datetime default current_timestamp
+);
+
+create table if not exists items_keys
+(
+    item_id    integer not null,
+    user_id    text    not null,
+    item_key   blob    not null,
+    read_only  boolean not null default false,
+    created_at datetime default current_timestamp,
+    CONSTRAINT items_keys_uniq UNIQUE (item_id, user_id)
+);
+
+create index if not exists items_keys_user on items_keys (user_id);
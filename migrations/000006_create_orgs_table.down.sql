@@ -0,0 +1,7 @@
+-- noinspection SqlNoDataSourceInspectionForFile
+
+drop table if exists orgs_collections;
+
+drop table if exists orgs_members;
+
+drop table if exists orgs;
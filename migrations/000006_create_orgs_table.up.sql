@@ -0,0 +1,28 @@
+-- noinspection SqlNoDataSourceInspectionForFile
+
+create table if not exists orgs
+(
+    org_id     text primary key,
+    name       text unique not null,
+    created_at datetime default current_timestamp
+);
+
+create table if not exists orgs_members
+(
+    org_id     text not null,
+    user_id    text not null,
+    role       text not null default 'member',
+    created_at datetime default current_timestamp,
+    CONSTRAINT orgs_members_uniq UNIQUE (org_id, user_id)
+);
+
+create index if not exists orgs_members_user on orgs_members (user_id);
+
+create table if not exists orgs_collections
+(
+    collection_id text primary key,
+    org_id        text not null,
+    name          text not null,
+    created_at    datetime default current_timestamp,
+    CONSTRAINT orgs_collections_uniq UNIQUE (org_id, name)
+);
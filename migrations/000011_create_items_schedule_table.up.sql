@@ -0,0 +1,10 @@
+-- noinspection SqlNoDataSourceInspectionForFile
+
+create table if not exists items_schedule
+(
+    item_id     integer primary key,
+    expires_at  datetime,
+    rotate_days integer not null default 0
+);
+
+create index if not exists items_schedule_expires_at on items_schedule (expires_at);
@@ -0,0 +1,10 @@
+-- noinspection SqlNoDataSourceInspectionForFile
+
+-- the data stored in a blob storage is not copied back to the database.
+drop table if exists items_blobs;
+
+drop index if exists items_data_blob_idx;
+
+alter table items_data drop column size;
+
+alter table items_data drop column blob;
@@ -0,0 +1,17 @@
+-- noinspection SqlNoDataSourceInspectionForFile
+
+alter table items_data add column blob text default null;
+
+alter table items_data add column size integer not null default 0;
+
+update items_data set size = length(data);
+
+create index if not exists items_data_blob_idx on items_data (blob);
+
+create table if not exists items_blobs
+(
+    hash       text primary key,
+    size       integer  not null default 0,
+    refs       integer  not null default 0,
+    updated_at datetime not null default current_timestamp
+);
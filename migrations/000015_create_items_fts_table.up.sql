@@ -0,0 +1,15 @@
+-- noinspection SqlNoDataSourceInspectionForFile
+
+-- the full-text index of the names and the metadata of the items: the
+-- tags and the non-secret fields, rebuilt from the items.
+drop table if exists items_fts;
+
+create virtual table items_fts using fts5(meta, metadata);
+
+insert into items_fts (rowid, meta, metadata)
+select id,
+       meta,
+       coalesce((select group_concat(tag, ' ') from items_tags where item_id = items.id), '') || ' ' ||
+       coalesce((select group_concat(name || ' ' || value, ' ') from items_fields
+                 where item_id = items.id and not secret), '')
+from items;